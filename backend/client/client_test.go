@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *ClientWithResponses {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts.MinBackoff = time.Millisecond
+	opts.MaxBackoff = 10 * time.Millisecond
+	c, err := New(srv.URL, opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_SendsTokenAndRequestID(t *testing.T) {
+	// Arrange
+	var got http.Header
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"Hello, World!"}`))
+	}, Options{Token: StaticToken("secret")})
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	// Act
+	res, err := c.GetHelloWithResponse(ctx, &GetHelloParams{})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, res.JSON200)
+	assert.Equal(t, "Hello, World!", res.JSON200.Message)
+	assert.Equal(t, "Bearer secret", got.Get("Authorization"))
+	assert.Equal(t, "req-1", got.Get(HeaderRequestID))
+}
+
+func TestNew_TokenError(t *testing.T) {
+	// Arrange
+	errExpired := errors.New("expired")
+	c := newTestClient(t, func(http.ResponseWriter, *http.Request) {
+		t.Error("request sent without a token")
+	}, Options{Token: func(context.Context) (string, error) { return "", errExpired }})
+
+	// Act
+	_, err := c.GetHelloWithResponse(context.Background(), &GetHelloParams{})
+
+	// Assert
+	assert.ErrorIs(t, err, errExpired)
+}
+
+func TestNew_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		status           int
+		retryAfter       string
+		maxRetries       int
+		expectedAttempts int32
+	}{
+		{name: "idempotent request on 503", method: http.MethodGet, status: http.StatusServiceUnavailable, expectedAttempts: 4},
+		{name: "idempotent request with a body", method: http.MethodPut, status: http.StatusBadGateway, expectedAttempts: 4},
+		{name: "non-idempotent request", method: http.MethodPost, status: http.StatusServiceUnavailable, expectedAttempts: 1},
+		{name: "client error", method: http.MethodGet, status: http.StatusNotFound, expectedAttempts: 1},
+		{name: "Retry-After beyond the maximum backoff", method: http.MethodGet, status: http.StatusTooManyRequests, retryAfter: "300", expectedAttempts: 1},
+		{name: "retries disabled", method: http.MethodGet, status: http.StatusServiceUnavailable, maxRetries: -1, expectedAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var attempts atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPut {
+					assert.JSONEq(t, `{"mode":"off"}`, string(body), "the body is sent again")
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}, Options{MaxRetries: tt.maxRetries})
+			ctx := context.Background()
+
+			// Act
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				_, err = c.GetHelloWithResponse(ctx, &GetHelloParams{})
+			case http.MethodPut:
+				_, err = c.SetMaintenanceWithResponse(ctx, &SetMaintenanceParams{}, SetMaintenanceJSONRequestBody{Mode: MaintenanceModeOff})
+			case http.MethodPost:
+				_, err = c.CreateOrgWithResponse(ctx, CreateOrgJSONRequestBody{Name: "Acme"})
+			}
+
+			// Assert
+			assert.Equal(t, tt.status, StatusCode(err))
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestNew_RecoversAfterRetry(t *testing.T) {
+	// Arrange
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"Hello, World!"}`))
+	}, Options{})
+
+	// Act
+	res, err := c.GetHelloWithResponse(context.Background(), &GetHelloParams{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode())
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestNew_DecodesErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedMessage string
+	}{
+		{name: "HTTPError", body: `{"message":"name too long"}`, expectedMessage: "name too long"},
+		{name: "ErrorResponse", body: `{"error":"name too long"}`, expectedMessage: "name too long"},
+		{name: "not JSON", body: `<html>bad gateway</html>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(HeaderRequestID, "req-2")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(tt.body))
+			}, Options{})
+
+			// Act
+			_, err := c.GetHelloWithResponse(context.Background(), &GetHelloParams{})
+
+			// Assert
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+			assert.Equal(t, tt.expectedMessage, apiErr.Message)
+			assert.Equal(t, "req-2", apiErr.RequestID)
+			assert.Equal(t, tt.body, string(apiErr.Body))
+		})
+	}
+}
+
+// TestGeneratedFromCurrentSpec fails when api/openapi.yaml changed without
+// running go generate ./client. It only catches a forgotten regeneration;
+// CI regenerates the client and fails on any difference from client.gen.go.
+func TestGeneratedFromCurrentSpec(t *testing.T) {
+	// Arrange
+	spec, err := os.ReadFile("../api/openapi.yaml")
+	require.NoError(t, err)
+	recorded, err := os.ReadFile("openapi.sha256")
+	require.NoError(t, err)
+
+	// Act
+	sum := sha256.Sum256(spec)
+
+	// Assert
+	assert.Equal(t, strings.TrimSpace(string(recorded)), hex.EncodeToString(sum[:]), "run go generate ./client")
+}
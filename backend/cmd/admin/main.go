@@ -0,0 +1,83 @@
+// Command admin runs operational tasks against the backend's stores and
+// Firebase Auth: looking up and disabling users, setting custom claims,
+// managing API keys, replaying dead webhook deliveries and jobs, and running
+// backfills. It loads the API's config from the environment and prints JSON
+// on stdout:
+//
+//	STORE_BACKEND=firestore go run ./cmd/admin users get alice@example.com
+//	STORE_BACKEND=firestore go run ./cmd/admin backfill run -dry-run orgs.member-emails
+//
+// Set FIRESTORE_EMULATOR_HOST and FIREBASE_AUTH_EMULATOR_HOST to run it
+// against the emulators.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/your-org/your-app/internal/admin"
+	"github.com/your-org/your-app/internal/app"
+	"github.com/your-org/your-app/internal/config"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "-help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, admin.Usage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.Store.Backend != config.StoreBackendFirestore {
+		fmt.Fprintln(os.Stderr, "admin: set STORE_BACKEND=firestore; the memory store is private to a running API. Set FIRESTORE_EMULATOR_HOST as well to use the emulator.")
+		return 2
+	}
+
+	var cli *admin.CLI
+	fxApp := fx.New(
+		app.Module(cfg),
+		admin.Module(),
+		fx.NopLogger,
+		fx.Populate(&cli),
+	)
+	startCtx, cancel := context.WithTimeout(context.Background(), fxApp.StartTimeout())
+	defer cancel()
+	if err := fxApp.Start(startCtx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Ctrl-C stops a backfill after its current batch
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = cli.Run(ctx, os.Args[1:], os.Getenv("USER"), os.Stdout, os.Stderr)
+	stop()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if stopErr := fxApp.Stop(stopCtx); stopErr != nil {
+		fmt.Fprintln(os.Stderr, stopErr)
+	}
+
+	switch {
+	case errors.Is(err, admin.ErrUsage):
+		return 2
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+// Command cronmanifest writes the cron job definitions as the JSON manifest
+// Pulumi uses to create Cloud Scheduler jobs. Run it via go generate.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/your-org/your-app/internal/cron"
+)
+
+func main() {
+	out := flag.String("o", "api/cron.json", "output file")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	if err := cron.WriteManifest(&buf, cron.Definitions()); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
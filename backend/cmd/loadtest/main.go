@@ -0,0 +1,100 @@
+// Command loadtest drives the API with the traffic of a scenario file and
+// reports latency percentiles, error rates and throughput. It exits with 1
+// when an SLO of the scenario is not met, so it can gate a promotion:
+//
+//	go run ./cmd/loadtest -url http://localhost:8080 loadtest/local.yaml
+//	go run ./cmd/loadtest -json report.json loadtest/soak.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/your-org/your-app/internal/loadtest"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	baseURL := flag.String("url", "", "API root, overriding the scenario's baseURL")
+	jsonOut := flag.String("json", "", "also write the report as JSON to this file, - for stdout")
+	progress := flag.Duration("progress", 10*time.Second, "interval of progress lines on stderr, 0 for none")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: loadtest [flags] scenario.yaml\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return 2
+	}
+
+	scenario, err := loadtest.Load(flag.Arg(0))
+	if err == nil && *baseURL != "" {
+		scenario.BaseURL = *baseURL
+		err = scenario.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Ctrl-C ends the run early and still reports it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "running %s against %s for %s\n", flag.Arg(0), scenario.BaseURL, scenario.Duration())
+	report, err := loadtest.Run(ctx, scenario, loadtest.Options{
+		Progress:      os.Stderr,
+		ProgressEvery: *progress,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	text := io.Writer(os.Stdout)
+	if *jsonOut == "-" {
+		text = os.Stderr
+	}
+	if err := report.WriteText(text); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := writeJSON(*jsonOut, report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+// writeJSON writes report to path, stdout for "-", nowhere for ""
+func writeJSON(path string, report *loadtest.Report) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		return report.WriteJSON(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
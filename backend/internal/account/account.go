@@ -0,0 +1,537 @@
+// Package account lets users export and delete everything stored for them.
+//
+// An export runs as a job: it zips every Firestore document under
+// users/{userId} and every Storage object under users/{userId}/ into
+// exports/{userId}/{exportId}.zip, which the owner downloads through a
+// signed URL until the export expires.
+//
+// Deletion is two-phase. Requesting it soft-deletes the account at once: the
+// user document is marked deleted and the Firebase Auth account disabled.
+// After the grace period the purge cron job hard-deletes the user's
+// documents, objects and Auth account. The deletion record outlives the
+// data, holding only the user ID, timestamps and counts, as the audit trail.
+// Subsystems keeping personal data elsewhere, such as organization
+// memberships, add it to exports and deletions as a Source.
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/store"
+)
+
+var (
+	// ErrNotFound is returned for unknown exports or those of another user,
+	// and for users without a deletion request
+	ErrNotFound = errors.New("account: not found")
+	// ErrExportInProgress is returned when the user already has a pending export
+	ErrExportInProgress = errors.New("account: an export is already in progress")
+	// ErrDeletionBlocked is returned when the user has to act before their
+	// account can be deleted, such as handing over an organization they own
+	// alone
+	ErrDeletionBlocked = errors.New("account: deletion blocked")
+)
+
+// Attempts at building an export before it is marked failed
+const exportAttempts = 3
+
+// purgeBatch caps the deletions a single purge run handles
+const purgeBatch = 50
+
+// ExportStatus is the state of an export
+type ExportStatus string
+
+// Export states
+const (
+	// ExportPending exports are being built
+	ExportPending ExportStatus = "pending"
+	// ExportReady exports can be downloaded until they expire
+	ExportReady ExportStatus = "ready"
+	// ExportFailed exports could not be built
+	ExportFailed ExportStatus = "failed"
+)
+
+// Export is a requested archive of a user's data
+type Export struct {
+	ID        string       `firestore:"-" json:"id"`
+	OwnerID   string       `firestore:"ownerId" json:"-"`
+	Status    ExportStatus `firestore:"status" json:"status"`
+	Object    string       `firestore:"object" json:"-"`
+	Size      int64        `firestore:"size" json:"size,omitempty"`
+	Documents int          `firestore:"documents" json:"documents,omitempty"`
+	Objects   int          `firestore:"objects" json:"objects,omitempty"`
+	CreatedAt time.Time    `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt *time.Time   `firestore:"expiresAt" json:"expiresAt,omitempty"`
+	// Download is signed on every read of a ready export
+	Download *files.SignedURL `firestore:"-" json:"download,omitempty"`
+}
+
+// DeletionStatus is the state of an account deletion
+type DeletionStatus string
+
+// Deletion states
+const (
+	// DeletionScheduled accounts are soft-deleted and wait for the purge
+	DeletionScheduled DeletionStatus = "scheduled"
+	// DeletionCompleted accounts have been hard-deleted
+	DeletionCompleted DeletionStatus = "completed"
+)
+
+// Deletion is the audit record of an account deletion
+type Deletion struct {
+	UserID      string         `firestore:"-" json:"userId"`
+	Status      DeletionStatus `firestore:"status" json:"status"`
+	RequestedAt time.Time      `firestore:"requestedAt" json:"requestedAt"`
+	PurgeAfter  time.Time      `firestore:"purgeAfter" json:"purgeAfter"`
+	CompletedAt *time.Time     `firestore:"completedAt" json:"completedAt,omitempty"`
+	// Documents and Objects count what the purge deleted
+	Documents int `firestore:"documentsDeleted" json:"documentsDeleted,omitempty"`
+	Objects   int `firestore:"objectsDeleted" json:"objectsDeleted,omitempty"`
+	// Attempts counts purge runs, including failed ones
+	Attempts  int    `firestore:"attempts" json:"-"`
+	LastError string `firestore:"lastError" json:"-"`
+}
+
+// Document is a Firestore document of a user
+type Document struct {
+	// Path is relative to the database root, e.g. users/{userId}/files/{fileId}
+	Path string
+	Data map[string]any
+}
+
+// Source is personal data another subsystem keeps outside users/{userId},
+// such as organization memberships. The service exports and purges it
+// together with the user's own documents.
+type Source interface {
+	// Documents returns the user's documents, with paths relative to the
+	// database root
+	Documents(ctx context.Context, userID string) ([]Document, error)
+	// CheckDeletion returns an error wrapping ErrDeletionBlocked while the
+	// user cannot be deleted yet
+	CheckDeletion(ctx context.Context, userID string) error
+	// SoftDelete runs when the account is soft-deleted, and again whenever
+	// the deletion is requested while still scheduled, so it must be
+	// idempotent
+	SoftDelete(ctx context.Context, userID string) error
+	// DeleteDocuments erases the user's data and reports how many documents
+	// were deleted
+	DeleteDocuments(ctx context.Context, userID string) (int, error)
+}
+
+// Options tunes exports and deletions
+type Options struct {
+	// GracePeriod is how long a soft-deleted account is kept before it is
+	// purged
+	GracePeriod time.Duration
+	// ExportTTL is how long an export can be downloaded
+	ExportTTL time.Duration
+	// URLExpiry is the lifetime of signed download URLs
+	URLExpiry time.Duration
+	// Clock and IDs default to the wall clock and crypto/rand
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// exportJob builds one export
+type exportJob struct {
+	OwnerID  string `json:"ownerId"`
+	ExportID string `json:"exportId"`
+}
+
+func (exportJob) JobType() string { return "account.export" }
+
+// Service exports and deletes user data
+type Service struct {
+	store    Store
+	storage  files.Storage
+	identity Identity
+	queue    *jobs.Queue
+	opts     Options
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	sources []Source
+}
+
+// NewService creates an account service and registers its export job with
+// registry, which must be the registry behind queue
+func NewService(st Store, storage files.Storage, identity Identity, queue *jobs.Queue, registry *jobs.Registry, opts Options, logger *zap.Logger) *Service {
+	opts.IDs = ids.OrRandom(opts.IDs)
+	s := &Service{
+		store:    st,
+		storage:  storage,
+		identity: identity,
+		queue:    queue,
+		opts:     opts,
+		logger:   logger,
+		now:      clock.OrSystem(opts.Clock).Now,
+	}
+	jobs.Handle(registry, exportAttempts, s.buildExport)
+	return s
+}
+
+// AddSource adds personal data kept by another subsystem to exports and
+// deletions
+func (s *Service) AddSource(src Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, src)
+}
+
+func (s *Service) dataSources() []Source {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sources
+}
+
+// RequestExport records a pending export of ownerID's data and starts
+// building it
+func (s *Service) RequestExport(ctx context.Context, ownerID string) (*Export, error) {
+	existing, err := s.store.ListExports(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.Status == ExportPending {
+			return nil, ErrExportInProgress
+		}
+	}
+
+	id := s.opts.IDs.NewID(10)
+	e := Export{
+		ID:        id,
+		OwnerID:   ownerID,
+		Status:    ExportPending,
+		Object:    ExportObject(ownerID, id),
+		CreatedAt: s.now(),
+	}
+	if err := s.store.CreateExport(ctx, e); err != nil {
+		return nil, err
+	}
+	if err := s.queue.Enqueue(ctx, exportJob{OwnerID: ownerID, ExportID: id}, jobs.Options{Name: "export-" + id}); err != nil {
+		e.Status = ExportFailed
+		return nil, errors.Join(err, s.store.UpdateExport(ctx, e))
+	}
+	return &e, nil
+}
+
+// GetExport returns one of the owner's exports, with a signed download URL
+// once it is ready. Expired exports are reported as not found.
+func (s *Service) GetExport(ctx context.Context, ownerID, id string) (*Export, error) {
+	e, err := s.store.GetExport(ctx, ownerID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != ExportReady {
+		return e, nil
+	}
+	if !s.now().Before(*e.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+
+	ttl := min(s.opts.URLExpiry, e.ExpiresAt.Sub(s.now()))
+	signed, err := s.storage.SignDownload(ctx, e.Object, ttl)
+	if err != nil {
+		return nil, err
+	}
+	e.Download = &signed
+	return e, nil
+}
+
+// buildExport runs exportJob. The archive is rebuilt from scratch on every
+// attempt; the last failed attempt marks the export failed.
+func (s *Service) buildExport(ctx context.Context, job exportJob) error {
+	e, err := s.store.GetExport(ctx, job.OwnerID, job.ExportID)
+	if errors.Is(err, store.ErrNotFound) {
+		// Purged with the account
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if e.Status != ExportPending {
+		return nil
+	}
+
+	if err := s.writeArchive(ctx, e); err != nil {
+		if jobs.Attempt(ctx) < exportAttempts {
+			return err
+		}
+		s.logger.Error("export failed", zap.String("user_id", e.OwnerID), zap.String("export_id", e.ID), zap.Error(err))
+		e.Status = ExportFailed
+		return errors.Join(err, s.storage.Delete(ctx, e.Object), s.store.UpdateExport(ctx, *e))
+	}
+
+	attrs, err := s.storage.Attrs(ctx, e.Object)
+	if err != nil {
+		return err
+	}
+	expires := s.now().Add(s.opts.ExportTTL)
+	e.Status = ExportReady
+	e.Size = attrs.Size
+	e.ExpiresAt = &expires
+	return s.store.UpdateExport(ctx, *e)
+}
+
+// manifest describes an archive; it is written last, as manifest.json
+type manifest struct {
+	UserID    string    `json:"userId"`
+	ExportID  string    `json:"exportId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Documents int       `json:"documents"`
+	Objects   int       `json:"objects"`
+}
+
+// writeArchive zips the owner's documents under firestore/ and objects under
+// storage/ into the export object, counting both on e
+func (s *Service) writeArchive(ctx context.Context, e *Export) error {
+	docs, err := s.store.Documents(ctx, e.OwnerID)
+	if err != nil {
+		return err
+	}
+	for _, src := range s.dataSources() {
+		more, err := src.Documents(ctx, e.OwnerID)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, more...)
+	}
+	objects, err := s.storage.List(ctx, UserPrefix(e.OwnerID))
+	if err != nil {
+		return err
+	}
+
+	// Cancelling abandons the upload, so a failure never publishes a
+	// truncated archive
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := s.storage.Create(ctx, e.Object, "application/zip")
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	err = s.writeEntries(ctx, zw, e, docs, objects)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("account: write export %s: %w", e.ID, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("account: write export %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+func (s *Service) writeEntries(ctx context.Context, zw *zip.Writer, e *Export, docs []Document, objects []string) error {
+	for _, doc := range docs {
+		if err := writeJSON(zw, "firestore/"+doc.Path+".json", doc.Data); err != nil {
+			return err
+		}
+	}
+	for _, object := range objects {
+		if err := s.copyObject(ctx, zw, object); err != nil {
+			return err
+		}
+	}
+
+	e.Documents, e.Objects = len(docs), len(objects)
+	return writeJSON(zw, "manifest.json", manifest{
+		UserID:    e.OwnerID,
+		ExportID:  e.ID,
+		CreatedAt: e.CreatedAt,
+		Documents: e.Documents,
+		Objects:   e.Objects,
+	})
+}
+
+func (s *Service) copyObject(ctx context.Context, zw *zip.Writer, object string) error {
+	r, err := s.storage.Open(ctx, object)
+	if errors.Is(err, files.ErrObjectNotFound) {
+		// Deleted since it was listed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	dst, err := zw.Create("storage/" + object)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(dst)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// RequestDeletion soft-deletes ownerID's account and schedules the purge.
+// Requesting again returns the existing deletion, after retrying the soft
+// delete in case it failed half-way. A new request fails with an error
+// wrapping ErrDeletionBlocked while a source blocks it.
+func (s *Service) RequestDeletion(ctx context.Context, ownerID string) (*Deletion, error) {
+	d, err := s.store.GetDeletion(ctx, ownerID)
+	if errors.Is(err, store.ErrNotFound) {
+		for _, src := range s.dataSources() {
+			if err := src.CheckDeletion(ctx, ownerID); err != nil {
+				return nil, err
+			}
+		}
+		now := s.now()
+		d = &Deletion{
+			UserID:      ownerID,
+			Status:      DeletionScheduled,
+			RequestedAt: now,
+			PurgeAfter:  now.Add(s.opts.GracePeriod),
+		}
+		// The record comes first, so a soft-deleted account is always scheduled
+		if err := s.store.SaveDeletion(ctx, *d); err != nil {
+			return nil, err
+		}
+		s.logger.Info("account deletion requested", zap.String("user_id", ownerID), zap.Time("purge_after", d.PurgeAfter))
+	} else if err != nil {
+		return nil, err
+	}
+	if d.Status != DeletionScheduled {
+		return d, nil
+	}
+
+	if err := s.store.MarkDeleted(ctx, ownerID, d.RequestedAt, d.PurgeAfter); err != nil {
+		return nil, err
+	}
+	for _, src := range s.dataSources() {
+		if err := src.SoftDelete(ctx, ownerID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.identity.Disable(ctx, ownerID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetDeletion returns the deletion of ownerID's account
+func (s *Service) GetDeletion(ctx context.Context, ownerID string) (*Deletion, error) {
+	d, err := s.store.GetDeletion(ctx, ownerID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return d, err
+}
+
+// Purge hard-deletes the accounts whose grace period is over. It is the
+// handler of the purge cron job; a failed account is retried on the next run
+// without holding up the others.
+func (s *Service) Purge(ctx context.Context) error {
+	due, err := s.store.DueDeletions(ctx, s.now(), purgeBatch)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range due {
+		if err := s.purge(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("account: purge %s: %w", d.UserID, err))
+		}
+	}
+	s.logger.Info("purged deleted accounts", zap.Int("due", len(due)), zap.Int("failed", len(errs)))
+	return errors.Join(errs...)
+}
+
+func (s *Service) purge(ctx context.Context, d Deletion) error {
+	d.Attempts++
+	objects, documents, err := s.hardDelete(ctx, d.UserID)
+	if err != nil {
+		d.LastError = err.Error()
+		return errors.Join(err, s.store.SaveDeletion(ctx, d))
+	}
+
+	now := s.now()
+	d.Status = DeletionCompleted
+	d.CompletedAt = &now
+	d.Objects = objects
+	d.Documents = documents
+	d.LastError = ""
+	if err := s.store.SaveDeletion(ctx, d); err != nil {
+		return err
+	}
+	s.logger.Info("account purged", zap.String("user_id", d.UserID), zap.Int("documents", documents), zap.Int("objects", objects))
+	return nil
+}
+
+// hardDelete removes objects first, then the documents of sources and the
+// user's own, and the Auth account last: until then a retry can still find
+// everything that is left
+func (s *Service) hardDelete(ctx context.Context, userID string) (objects, documents int, err error) {
+	for _, prefix := range []string{UserPrefix(userID), ExportPrefix(userID)} {
+		names, err := s.storage.List(ctx, prefix)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, name := range names {
+			if err := s.storage.Delete(ctx, name); err != nil {
+				return 0, 0, err
+			}
+		}
+		objects += len(names)
+	}
+
+	for _, src := range s.dataSources() {
+		deleted, err := src.DeleteDocuments(ctx, userID)
+		if err != nil {
+			return 0, 0, err
+		}
+		documents += deleted
+	}
+	deleted, err := s.store.DeleteDocuments(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	documents += deleted
+	if err := s.identity.Delete(ctx, userID); err != nil {
+		return 0, 0, err
+	}
+	return objects, documents, nil
+}
+
+// UserPrefix is the storage prefix of a user's objects
+func UserPrefix(userID string) string {
+	return "users/" + userID + "/"
+}
+
+// ExportPrefix is the storage prefix of a user's exports. It lies outside
+// users/{userId}/, which storage.rules lets any signed-in user read.
+func ExportPrefix(userID string) string {
+	return "exports/" + userID + "/"
+}
+
+// ExportObject returns the storage object of an export
+func ExportObject(userID, id string) string {
+	return ExportPrefix(userID) + id + ".zip"
+}
@@ -0,0 +1,415 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+// fakeIdentity records the accounts it was asked to disable and delete
+type fakeIdentity struct {
+	mu        sync.Mutex
+	disabled  []string
+	deleted   []string
+	deleteErr error
+}
+
+func (f *fakeIdentity) Disable(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disabled = append(f.disabled, userID)
+	return nil
+}
+
+func (f *fakeIdentity) Delete(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = append(f.deleted, userID)
+	return nil
+}
+
+type testEnv struct {
+	service  *Service
+	store    *MemoryStore
+	storage  *files.LocalStorage
+	identity *fakeIdentity
+	backend  *jobs.LocalBackend
+	now      time.Time
+}
+
+func setupService(t *testing.T) *testEnv {
+	t.Helper()
+
+	storage, err := files.NewLocalStorage(t.TempDir(), "http://files.test", []byte("test-key"), files.StorageOptions{})
+	require.NoError(t, err)
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 1})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	st := NewMemoryStore()
+	identity := &fakeIdentity{}
+	service := NewService(st, storage, identity, jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry, Options{
+		GracePeriod: 30 * 24 * time.Hour,
+		ExportTTL:   24 * time.Hour,
+		URLExpiry:   15 * time.Minute,
+	}, zap.NewNop())
+
+	env := &testEnv{service: service, store: st, storage: storage, identity: identity, backend: backend}
+	env.now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return env.now }
+
+	// alice and bob each have a profile, a file record and an uploaded object
+	for _, uid := range []string{"alice", "bob"} {
+		st.Put("users/"+uid, map[string]any{"displayName": uid})
+		st.Put("users/"+uid+"/files/f1", map[string]any{"name": "a.png"})
+		env.putObject(t, "users/"+uid+"/files/f1/a.png", "png:"+uid)
+	}
+	return env
+}
+
+func (env *testEnv) putObject(t *testing.T, object, content string) {
+	t.Helper()
+	w, err := env.storage.Create(context.Background(), object, "image/png")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+// readArchive returns the entries of an export by name
+func (env *testEnv) readArchive(t *testing.T, object string) map[string]string {
+	t.Helper()
+	r, err := env.storage.Open(context.Background(), object)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	entries := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		entries[f.Name] = string(content)
+	}
+	return entries
+}
+
+func TestService_Export(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+
+	// Act
+	requested, err := env.service.RequestExport(ctx, "alice")
+	require.NoError(t, err)
+	env.backend.Wait()
+	e, err := env.service.GetExport(ctx, "alice", requested.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ExportPending, requested.Status)
+	assert.Equal(t, ExportReady, e.Status)
+	require.NotNil(t, e.Download)
+	assert.Contains(t, e.Download.URL, "exports/alice/"+e.ID+".zip")
+	assert.Equal(t, env.now.Add(24*time.Hour), *e.ExpiresAt)
+
+	entries := env.readArchive(t, e.Object)
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"firestore/users/alice.json",
+		"firestore/users/alice/exports/" + e.ID + ".json",
+		"firestore/users/alice/files/f1.json",
+		"manifest.json",
+		"storage/users/alice/files/f1/a.png",
+	}, names)
+	assert.Equal(t, "png:alice", entries["storage/users/alice/files/f1/a.png"])
+	assert.JSONEq(t, `{"displayName":"alice"}`, entries["firestore/users/alice.json"])
+
+	var m manifest
+	require.NoError(t, json.Unmarshal([]byte(entries["manifest.json"]), &m))
+	assert.Equal(t, manifest{UserID: "alice", ExportID: e.ID, CreatedAt: env.now, Documents: 3, Objects: 1}, m)
+}
+
+func TestService_Export_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		arrange func(env *testEnv) (id string)
+		act     func(env *testEnv, id string) error
+		want    error
+	}{
+		{
+			name: "export already in progress",
+			arrange: func(env *testEnv) string {
+				require.NoError(t, env.store.CreateExport(context.Background(), Export{ID: "e1", OwnerID: "alice", Status: ExportPending}))
+				return "e1"
+			},
+			act: func(env *testEnv, _ string) error {
+				_, err := env.service.RequestExport(context.Background(), "alice")
+				return err
+			},
+			want: ErrExportInProgress,
+		},
+		{
+			name: "export of another user",
+			arrange: func(env *testEnv) string {
+				e, err := env.service.RequestExport(context.Background(), "alice")
+				require.NoError(t, err)
+				env.backend.Wait()
+				return e.ID
+			},
+			act: func(env *testEnv, id string) error {
+				_, err := env.service.GetExport(context.Background(), "bob", id)
+				return err
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "expired export",
+			arrange: func(env *testEnv) string {
+				e, err := env.service.RequestExport(context.Background(), "alice")
+				require.NoError(t, err)
+				env.backend.Wait()
+				env.now = env.now.Add(24 * time.Hour)
+				return e.ID
+			},
+			act: func(env *testEnv, id string) error {
+				_, err := env.service.GetExport(context.Background(), "alice", id)
+				return err
+			},
+			want: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupService(t)
+			id := tt.arrange(env)
+
+			// Act
+			err := tt.act(env, id)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestService_Deletion(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	export, err := env.service.RequestExport(ctx, "alice")
+	require.NoError(t, err)
+	env.backend.Wait()
+
+	// Act: request, request again, purge early, purge after the grace period
+	requested, err := env.service.RequestDeletion(ctx, "alice")
+	require.NoError(t, err)
+	env.now = env.now.Add(time.Hour)
+	again, err := env.service.RequestDeletion(ctx, "alice")
+	require.NoError(t, err)
+	require.NoError(t, env.service.Purge(ctx))
+	early, err := env.service.GetDeletion(ctx, "alice")
+	require.NoError(t, err)
+	env.now = requested.PurgeAfter
+	require.NoError(t, env.service.Purge(ctx))
+
+	// Assert
+	assert.Equal(t, DeletionScheduled, requested.Status)
+	assert.Equal(t, requested.RequestedAt.Add(30*24*time.Hour), requested.PurgeAfter)
+	assert.Equal(t, requested.RequestedAt, again.RequestedAt, "requesting again keeps the schedule")
+	assert.Equal(t, DeletionScheduled, early.Status, "not purged during the grace period")
+	assert.Equal(t, []string{"alice", "alice"}, env.identity.disabled)
+
+	d, err := env.service.GetDeletion(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, DeletionCompleted, d.Status)
+	assert.Equal(t, requested.PurgeAfter, *d.CompletedAt)
+	assert.Equal(t, 3, d.Documents, "profile, file record and export")
+	assert.Equal(t, 2, d.Objects, "upload and export archive")
+	assert.Equal(t, []string{"alice"}, env.identity.deleted)
+
+	for _, prefix := range []string{UserPrefix("alice"), ExportPrefix("alice")} {
+		names, err := env.storage.List(ctx, prefix)
+		require.NoError(t, err)
+		assert.Empty(t, names, prefix)
+	}
+	docs, err := env.store.Documents(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+	_, err = env.service.GetExport(ctx, "alice", export.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// bob is untouched
+	names, err := env.storage.List(ctx, UserPrefix("bob"))
+	require.NoError(t, err)
+	assert.Len(t, names, 1)
+	docs, err = env.store.Documents(ctx, "bob")
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+}
+
+func TestService_RequestDeletion_SoftDeletes(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+
+	// Act
+	d, err := env.service.RequestDeletion(ctx, "alice")
+
+	// Assert
+	require.NoError(t, err)
+	docs, err := env.store.Documents(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, "users/alice", docs[0].Path)
+	assert.Equal(t, map[string]any{
+		"displayName":   "alice",
+		fieldDeletedAt:  env.now,
+		fieldPurgeAfter: d.PurgeAfter,
+	}, docs[0].Data)
+}
+
+func TestService_Purge_RetriesFailedAccount(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	_, err := env.service.RequestDeletion(ctx, "alice")
+	require.NoError(t, err)
+	env.identity.deleteErr = errors.New("auth unavailable")
+	env.now = env.now.Add(31 * 24 * time.Hour)
+
+	// Act
+	failed := env.service.Purge(ctx)
+	d, err := env.service.GetDeletion(ctx, "alice")
+	require.NoError(t, err)
+	env.identity.deleteErr = nil
+	retried := env.service.Purge(ctx)
+
+	// Assert
+	assert.ErrorContains(t, failed, "auth unavailable")
+	assert.Equal(t, DeletionScheduled, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, "auth unavailable", d.LastError)
+
+	require.NoError(t, retried)
+	d, err = env.service.GetDeletion(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, DeletionCompleted, d.Status)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Empty(t, d.LastError)
+}
+
+// fakeSource keeps one document per user outside users/{userId}
+type fakeSource struct {
+	mu          sync.Mutex
+	docs        map[string]Document
+	blocked     map[string]bool
+	softDeleted map[string]int
+}
+
+func (f *fakeSource) Documents(_ context.Context, userID string) ([]Document, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d, ok := f.docs[userID]; ok {
+		return []Document{d}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeSource) CheckDeletion(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.blocked[userID] {
+		return ErrDeletionBlocked
+	}
+	return nil
+}
+
+func (f *fakeSource) SoftDelete(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.softDeleted[userID]++
+	return nil
+}
+
+func (f *fakeSource) DeleteDocuments(_ context.Context, userID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.docs[userID]; !ok {
+		return 0, nil
+	}
+	delete(f.docs, userID)
+	return 1, nil
+}
+
+func TestService_Sources(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	src := &fakeSource{
+		docs: map[string]Document{
+			"alice": {Path: "orgs/acme/members/alice", Data: map[string]any{"email": "alice@example.com"}},
+			"bob":   {Path: "orgs/acme/members/bob", Data: map[string]any{"email": "bob@example.com"}},
+		},
+		blocked:     map[string]bool{"bob": true},
+		softDeleted: map[string]int{},
+	}
+	env.service.AddSource(src)
+
+	// Act
+	requested, err := env.service.RequestExport(ctx, "alice")
+	require.NoError(t, err)
+	env.backend.Wait()
+	e, err := env.service.GetExport(ctx, "alice", requested.ID)
+	require.NoError(t, err)
+	entries := env.readArchive(t, e.Object)
+	d, err := env.service.RequestDeletion(ctx, "alice")
+	require.NoError(t, err)
+	_, err = env.service.RequestDeletion(ctx, "alice")
+	require.NoError(t, err)
+	_, blocked := env.service.RequestDeletion(ctx, "bob")
+	env.now = d.PurgeAfter
+	require.NoError(t, env.service.Purge(ctx))
+
+	// Assert
+	assert.JSONEq(t, `{"email":"alice@example.com"}`, entries["firestore/orgs/acme/members/alice.json"])
+
+	assert.Equal(t, map[string]int{"alice": 2}, src.softDeleted, "soft delete is retried with the request")
+	assert.ErrorIs(t, blocked, ErrDeletionBlocked)
+	_, err = env.service.GetDeletion(ctx, "bob")
+	assert.ErrorIs(t, err, ErrNotFound, "blocked deletion is not scheduled")
+
+	purged, err := env.service.GetDeletion(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, DeletionCompleted, purged.Status)
+	assert.Equal(t, 4, purged.Documents, "membership, profile, file record and export")
+	assert.NotContains(t, src.docs, "alice")
+	assert.Contains(t, src.docs, "bob")
+}
@@ -0,0 +1,202 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// deletionsCollection holds one deletion record per user ID. It is not
+// below users/{userId}, so it survives the purge.
+const deletionsCollection = "accountDeletions"
+
+// Fields set on users/{userId} by the soft delete
+const (
+	fieldDeletedAt  = "deletedAt"
+	fieldPurgeAfter = "purgeAfter"
+)
+
+// FirestoreStore keeps exports in users/{userId}/exports/{exportId} and
+// deletions in accountDeletions/{userId}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) user(userID string) *firestore.DocumentRef {
+	return s.client.Collection("users").Doc(userID)
+}
+
+func (s *FirestoreStore) exports(ownerID string) *firestore.CollectionRef {
+	return s.user(ownerID).Collection("exports")
+}
+
+// CreateExport implements Store
+func (s *FirestoreStore) CreateExport(ctx context.Context, e Export) error {
+	if _, err := s.exports(e.OwnerID).Doc(e.ID).Create(ctx, e); err != nil {
+		return fmt.Errorf("account: create export %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// GetExport implements Store
+func (s *FirestoreStore) GetExport(ctx context.Context, ownerID, id string) (*Export, error) {
+	snap, err := s.exports(ownerID).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("account: get export %s: %w", id, err)
+	}
+
+	var e Export
+	if err := snap.DataTo(&e); err != nil {
+		return nil, err
+	}
+	e.ID = snap.Ref.ID
+	return &e, nil
+}
+
+// UpdateExport implements Store
+func (s *FirestoreStore) UpdateExport(ctx context.Context, e Export) error {
+	if _, err := s.exports(e.OwnerID).Doc(e.ID).Set(ctx, e); err != nil {
+		return fmt.Errorf("account: update export %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// ListExports implements Store
+func (s *FirestoreStore) ListExports(ctx context.Context, ownerID string) ([]Export, error) {
+	docs, err := s.exports(ownerID).OrderBy("createdAt", firestore.Desc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("account: list exports: %w", err)
+	}
+
+	out := make([]Export, 0, len(docs))
+	for _, doc := range docs {
+		var e Export
+		if err := doc.DataTo(&e); err != nil {
+			return nil, err
+		}
+		e.ID = doc.Ref.ID
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// GetDeletion implements Store
+func (s *FirestoreStore) GetDeletion(ctx context.Context, userID string) (*Deletion, error) {
+	snap, err := s.client.Collection(deletionsCollection).Doc(userID).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("account: get deletion %s: %w", userID, err)
+	}
+
+	var d Deletion
+	if err := snap.DataTo(&d); err != nil {
+		return nil, err
+	}
+	d.UserID = snap.Ref.ID
+	return &d, nil
+}
+
+// SaveDeletion implements Store
+func (s *FirestoreStore) SaveDeletion(ctx context.Context, d Deletion) error {
+	if _, err := s.client.Collection(deletionsCollection).Doc(d.UserID).Set(ctx, d); err != nil {
+		return fmt.Errorf("account: save deletion %s: %w", d.UserID, err)
+	}
+	return nil
+}
+
+// DueDeletions implements Store. It relies on the (status, purgeAfter)
+// composite index declared in firestore.indexes.json.
+func (s *FirestoreStore) DueDeletions(ctx context.Context, now time.Time, limit int) ([]Deletion, error) {
+	query := s.client.Collection(deletionsCollection).
+		Where("status", "==", string(DeletionScheduled)).
+		Where("purgeAfter", "<=", now).
+		OrderBy("purgeAfter", firestore.Asc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("account: list due deletions: %w", err)
+	}
+
+	out := make([]Deletion, 0, len(docs))
+	for _, doc := range docs {
+		var d Deletion
+		if err := doc.DataTo(&d); err != nil {
+			return nil, err
+		}
+		d.UserID = doc.Ref.ID
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// MarkDeleted implements Store. It merges, so users without a profile get a
+// tombstone document.
+func (s *FirestoreStore) MarkDeleted(ctx context.Context, userID string, deletedAt, purgeAfter time.Time) error {
+	data := map[string]any{fieldDeletedAt: deletedAt, fieldPurgeAfter: purgeAfter}
+	if _, err := s.user(userID).Set(ctx, data, firestore.MergeAll); err != nil {
+		return fmt.Errorf("account: mark %s deleted: %w", userID, err)
+	}
+	return nil
+}
+
+// Documents implements Store
+func (s *FirestoreStore) Documents(ctx context.Context, userID string) ([]Document, error) {
+	var out []Document
+	err := store.Walk(ctx, s.user(userID), func(snap *firestore.DocumentSnapshot) error {
+		out = append(out, Document{Path: store.RelativePath(snap.Ref), Data: exportValue(snap.Data()).(map[string]any)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("account: read documents of %s: %w", userID, err)
+	}
+	return out, nil
+}
+
+// DeleteDocuments implements Store
+func (s *FirestoreStore) DeleteDocuments(ctx context.Context, userID string) (int, error) {
+	deleted, err := store.DeleteTree(ctx, s.client, s.user(userID))
+	if err != nil {
+		return deleted, fmt.Errorf("account: delete documents of %s: %w", userID, err)
+	}
+	return deleted, nil
+}
+
+// exportValue makes document data JSON-friendly: references become their
+// path, which would otherwise marshal the whole client
+func exportValue(v any) any {
+	switch v := v.(type) {
+	case *firestore.DocumentRef:
+		return store.RelativePath(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = exportValue(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = exportValue(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
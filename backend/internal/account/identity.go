@@ -0,0 +1,61 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	firebaseauth "firebase.google.com/go/v4/auth"
+)
+
+// Identity manages the sign-in accounts of users
+type Identity interface {
+	// Disable blocks sign-in and revokes refresh tokens. ID tokens already
+	// issued stay valid until they expire, within the hour.
+	Disable(ctx context.Context, userID string) error
+	// Delete removes the account; a missing account is not an error
+	Delete(ctx context.Context, userID string) error
+}
+
+// FirebaseIdentity manages Firebase Auth accounts
+type FirebaseIdentity struct {
+	client *firebaseauth.Client
+}
+
+// NewFirebaseIdentity creates an identity backed by client
+func NewFirebaseIdentity(client *firebaseauth.Client) *FirebaseIdentity {
+	return &FirebaseIdentity{client: client}
+}
+
+// Disable implements Identity
+func (i *FirebaseIdentity) Disable(ctx context.Context, userID string) error {
+	_, err := i.client.UpdateUser(ctx, userID, (&firebaseauth.UserToUpdate{}).Disabled(true))
+	if firebaseauth.IsUserNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("account: disable %s: %w", userID, err)
+	}
+	if err := i.client.RevokeRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("account: revoke tokens of %s: %w", userID, err)
+	}
+	return nil
+}
+
+// Delete implements Identity
+func (i *FirebaseIdentity) Delete(ctx context.Context, userID string) error {
+	err := i.client.DeleteUser(ctx, userID)
+	if err != nil && !firebaseauth.IsUserNotFound(err) {
+		return fmt.Errorf("account: delete %s: %w", userID, err)
+	}
+	return nil
+}
+
+// NopIdentity leaves sign-in accounts alone, for local development without
+// Firebase Auth, where no user can sign in anyway
+type NopIdentity struct{}
+
+// Disable implements Identity
+func (NopIdentity) Disable(context.Context, string) error { return nil }
+
+// Delete implements Identity
+func (NopIdentity) Delete(context.Context, string) error { return nil }
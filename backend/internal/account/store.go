@@ -0,0 +1,207 @@
+package account
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists exports and deletions and reaches the user's documents
+type Store interface {
+	CreateExport(ctx context.Context, e Export) error
+	// GetExport returns store.ErrNotFound if ownerID has no export id
+	GetExport(ctx context.Context, ownerID, id string) (*Export, error)
+	UpdateExport(ctx context.Context, e Export) error
+	ListExports(ctx context.Context, ownerID string) ([]Export, error)
+
+	// GetDeletion returns store.ErrNotFound if userID never asked for deletion
+	GetDeletion(ctx context.Context, userID string) (*Deletion, error)
+	SaveDeletion(ctx context.Context, d Deletion) error
+	// DueDeletions returns up to limit scheduled deletions with PurgeAfter at
+	// or before now, oldest first
+	DueDeletions(ctx context.Context, now time.Time, limit int) ([]Deletion, error)
+
+	// MarkDeleted soft-deletes the user document
+	MarkDeleted(ctx context.Context, userID string, deletedAt, purgeAfter time.Time) error
+	// Documents returns users/{userId} and every document below it, parents
+	// first
+	Documents(ctx context.Context, userID string) ([]Document, error)
+	// DeleteDocuments deletes what Documents returns and reports how many
+	// documents were deleted
+	DeleteDocuments(ctx context.Context, userID string) (int, error)
+}
+
+// MemoryStore is an in-process Store for local development and tests. Other
+// subsystems keep their memory stores to themselves, so its documents are
+// only those written through Put and MarkDeleted.
+type MemoryStore struct {
+	mu        sync.Mutex
+	exports   map[string]Export
+	deletions map[string]Deletion
+	documents map[string]map[string]any
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		exports:   make(map[string]Export),
+		deletions: make(map[string]Deletion),
+		documents: make(map[string]map[string]any),
+	}
+}
+
+// Put writes a document, replacing any previous one
+func (s *MemoryStore) Put(path string, data map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[path] = data
+}
+
+// CreateExport implements Store
+func (s *MemoryStore) CreateExport(_ context.Context, e Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exports[e.OwnerID+"/"+e.ID] = e
+	return nil
+}
+
+// GetExport implements Store
+func (s *MemoryStore) GetExport(_ context.Context, ownerID, id string) (*Export, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.exports[ownerID+"/"+id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &e, nil
+}
+
+// UpdateExport implements Store
+func (s *MemoryStore) UpdateExport(_ context.Context, e Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := e.OwnerID + "/" + e.ID
+	if _, ok := s.exports[key]; !ok {
+		return store.ErrNotFound
+	}
+	s.exports[key] = e
+	return nil
+}
+
+// ListExports implements Store
+func (s *MemoryStore) ListExports(_ context.Context, ownerID string) ([]Export, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Export
+	for _, e := range s.exports {
+		if e.OwnerID == ownerID {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// GetDeletion implements Store
+func (s *MemoryStore) GetDeletion(_ context.Context, userID string) (*Deletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deletions[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &d, nil
+}
+
+// SaveDeletion implements Store
+func (s *MemoryStore) SaveDeletion(_ context.Context, d Deletion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletions[d.UserID] = d
+	return nil
+}
+
+// DueDeletions implements Store
+func (s *MemoryStore) DueDeletions(_ context.Context, now time.Time, limit int) ([]Deletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Deletion
+	for _, d := range s.deletions {
+		if d.Status == DeletionScheduled && !d.PurgeAfter.After(now) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PurgeAfter.Before(out[j].PurgeAfter) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// MarkDeleted implements Store
+func (s *MemoryStore) MarkDeleted(_ context.Context, userID string, deletedAt, purgeAfter time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := "users/" + userID
+	doc := make(map[string]any, len(s.documents[path])+2)
+	for k, v := range s.documents[path] {
+		doc[k] = v
+	}
+	doc[fieldDeletedAt] = deletedAt
+	doc[fieldPurgeAfter] = purgeAfter
+	s.documents[path] = doc
+	return nil
+}
+
+// Documents implements Store. Exports are included, as they are
+// subcollection documents in Firestore.
+func (s *MemoryStore) Documents(_ context.Context, userID string) ([]Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Document
+	for path, data := range s.documents {
+		if ownedPath(path, userID) {
+			out = append(out, Document{Path: path, Data: data})
+		}
+	}
+	for _, e := range s.exports {
+		if e.OwnerID == userID {
+			out = append(out, Document{Path: "users/" + userID + "/exports/" + e.ID, Data: map[string]any{
+				"status":    string(e.Status),
+				"createdAt": e.CreatedAt,
+			}})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// DeleteDocuments implements Store
+func (s *MemoryStore) DeleteDocuments(_ context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for path := range s.documents {
+		if ownedPath(path, userID) {
+			delete(s.documents, path)
+			deleted++
+		}
+	}
+	for key, e := range s.exports {
+		if e.OwnerID == userID {
+			delete(s.exports, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ownedPath reports whether path is users/{userID} or below it
+func ownedPath(path, userID string) bool {
+	root := "users/" + userID
+	return path == root || strings.HasPrefix(path, root+"/")
+}
@@ -0,0 +1,220 @@
+// Package admin implements cmd/admin, the operator's command line.
+//
+// Commands run on the API's own fx graph, so they go through the same
+// services and stores as requests do, against Firestore or its emulator.
+// Every command prints JSON on stdout for scripts; progress lines and
+// warnings go to stderr. Changes are recorded in the audit log with the
+// operator as the actor.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+// ErrUsage is returned for unknown commands and invalid arguments, after
+// the problem was printed to stderr
+var ErrUsage = errors.New("admin: invalid usage")
+
+// Usage describes the commands
+const Usage = `usage: admin [-as operator] [-wait duration] <command> [arguments]
+
+Commands:
+  users get <uid|email>                  show a sign-in account
+  users disable <uid>                    block sign-in, revoke refresh tokens and
+                                         revoke API keys
+  users enable <uid>                     restore sign-in
+  users claims [-merge] <uid> <json>     replace custom claims, or merge into them
+                                         (with -merge, null removes a claim)
+
+  apikeys create -owner <uid> -name <name>
+                                         issue a key acting as the owner; the key
+                                         is only printed here
+  apikeys list -owner <uid>              list the owner's keys
+  apikeys revoke <id>                    stop a key from working
+
+  webhooks dead [-limit n]               list dead webhook deliveries of all owners
+  webhooks replay -owner <uid> -endpoint <id> <delivery-id>...
+                                         send the deliveries' events again
+
+  jobs dead [-limit n]                   list dead-lettered background jobs
+  jobs replay <id>...                    enqueue the jobs again and drop their
+                                         dead letters
+
+  backfill list                          list backfills
+  backfill status <name>                 show a backfill's checkpoint
+  backfill run [-dry-run] [-restart] [-batch n] [-max-batches n] <name>
+                                         run a backfill from its checkpoint
+`
+
+// CLI runs admin commands
+type CLI struct {
+	users       Users
+	apiKeys     *apikeys.Service
+	webhooks    *webhooks.Service
+	queue       *jobs.Queue
+	deadLetters jobs.Store
+	backfills   []Backfill
+	checkpoints Store
+	recorder    *audit.Recorder
+	now         func() time.Time
+}
+
+// NewCLI creates the command line over the API's services and stores
+func NewCLI(
+	users Users,
+	apiKeys *apikeys.Service,
+	webhooksService *webhooks.Service,
+	queue *jobs.Queue,
+	deadLetters jobs.Store,
+	orgStore orgs.Store,
+	checkpoints Store,
+	recorder *audit.Recorder,
+	clk clock.Clock,
+) *CLI {
+	return &CLI{
+		users:       users,
+		apiKeys:     apiKeys,
+		webhooks:    webhooksService,
+		queue:       queue,
+		deadLetters: deadLetters,
+		backfills:   Backfills(orgStore, users),
+		checkpoints: checkpoints,
+		recorder:    recorder,
+		now:         clk.Now,
+	}
+}
+
+// invocation is one run of a command
+type invocation struct {
+	*CLI
+	ctx      context.Context
+	stdout   io.Writer
+	stderr   io.Writer
+	operator string
+	// wait bounds how long jobs enqueued by the command may run in this
+	// process before it exits, with the in-process job backend
+	wait time.Duration
+}
+
+// Run runs the command in args. operator is the default actor of audit
+// events, overridden by -as.
+func (c *CLI) Run(ctx context.Context, args []string, operator string, stdout, stderr io.Writer) error {
+	inv := &invocation{CLI: c, ctx: ctx, stdout: stdout, stderr: stderr}
+
+	fs := inv.flags("admin")
+	fs.StringVar(&inv.operator, "as", operator, "operator recorded in the audit log")
+	fs.DurationVar(&inv.wait, "wait", 30*time.Second, "how long in-process jobs may run before exiting")
+	if err := inv.parse(fs, args); err != nil {
+		return err
+	}
+	if inv.operator == "" {
+		return inv.usage("-as is required when $USER is not set")
+	}
+
+	args = fs.Args()
+	if len(args) < 2 {
+		return inv.usage("missing command")
+	}
+	command, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		return inv.usage(fmt.Sprintf("unknown command %q", args[0]+" "+args[1]))
+	}
+	return command(inv, args[2:])
+}
+
+// commands maps "group command" to its implementation
+var commands = map[string]func(*invocation, []string) error{
+	"users get":       (*invocation).usersGet,
+	"users disable":   (*invocation).usersDisable,
+	"users enable":    (*invocation).usersEnable,
+	"users claims":    (*invocation).usersClaims,
+	"apikeys create":  (*invocation).apiKeysCreate,
+	"apikeys list":    (*invocation).apiKeysList,
+	"apikeys revoke":  (*invocation).apiKeysRevoke,
+	"webhooks dead":   (*invocation).webhooksDead,
+	"webhooks replay": (*invocation).webhooksReplay,
+	"jobs dead":       (*invocation).jobsDead,
+	"jobs replay":     (*invocation).jobsReplay,
+	"backfill list":   (*invocation).backfillList,
+	"backfill status": (*invocation).backfillStatus,
+	"backfill run":    (*invocation).backfillRun,
+}
+
+func (inv *invocation) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(inv.stderr)
+	fs.Usage = func() {}
+	return fs
+}
+
+// parse parses args, turning flag errors, already printed, into ErrUsage
+func (inv *invocation) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprint(inv.stderr, Usage)
+		return ErrUsage
+	}
+	return nil
+}
+
+// usage prints problem and the usage
+func (inv *invocation) usage(problem string) error {
+	fmt.Fprintf(inv.stderr, "admin: %s\n\n%s", problem, Usage)
+	return ErrUsage
+}
+
+// args parses the command's flags and checks that n positional arguments
+// follow, or at least one when n is negative
+func (inv *invocation) args(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := inv.parse(fs, args); err != nil {
+		return nil, err
+	}
+	rest := fs.Args()
+	if (n < 0 && len(rest) == 0) || (n >= 0 && len(rest) != n) {
+		return nil, inv.usage(fs.Name() + ": wrong number of arguments")
+	}
+	return rest, nil
+}
+
+// write prints v as indented JSON
+func (inv *invocation) write(v any) error {
+	enc := json.NewEncoder(inv.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// audit records a change made by the operator. The change already took
+// effect, so a failure is only reported.
+func (inv *invocation) audit(action audit.Action, targetType, targetID string, changes []audit.Change) {
+	err := inv.recorder.Record(inv.ctx, audit.Event{
+		Action:  action,
+		Actor:   audit.Actor{ID: "cli:" + inv.operator},
+		Target:  audit.Target{Type: targetType, ID: targetID},
+		Outcome: audit.OutcomeSuccess,
+		Changes: changes,
+	})
+	if err != nil {
+		fmt.Fprintf(inv.stderr, "admin: warning: recording audit event %s failed: %v\n", action, err)
+	}
+}
+
+// drain lets jobs enqueued by the command run before the process exits
+func (inv *invocation) drain() {
+	ctx, cancel := context.WithTimeout(inv.ctx, inv.wait)
+	defer cancel()
+	if err := inv.queue.Drain(ctx); err != nil {
+		fmt.Fprintf(inv.stderr, "admin: warning: jobs still running after %s are dropped; use JOBS_BACKEND=cloudtasks to leave them to the API\n", inv.wait)
+	}
+}
@@ -0,0 +1,296 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// fakeUsers keeps accounts in memory
+type fakeUsers struct {
+	mu    sync.Mutex
+	users map[string]User
+}
+
+func newFakeUsers(users ...User) *fakeUsers {
+	f := &fakeUsers{users: make(map[string]User)}
+	for _, u := range users {
+		f.users[u.UID] = u
+	}
+	return f
+}
+
+func (f *fakeUsers) Get(_ context.Context, uid string) (*User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[uid]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	u.CustomClaims = maps.Clone(u.CustomClaims)
+	return &u, nil
+}
+
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*User, error) {
+	f.mu.Lock()
+	var uid string
+	for _, u := range f.users {
+		if u.Email == email {
+			uid = u.UID
+		}
+	}
+	f.mu.Unlock()
+	return f.Get(ctx, uid)
+}
+
+func (f *fakeUsers) SetDisabled(_ context.Context, uid string, disabled bool) error {
+	return f.update(uid, func(u *User) { u.Disabled = disabled })
+}
+
+func (f *fakeUsers) SetClaims(_ context.Context, uid string, claims map[string]any) error {
+	return f.update(uid, func(u *User) { u.CustomClaims = claims })
+}
+
+func (f *fakeUsers) update(uid string, change func(*User)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[uid]
+	if !ok {
+		return ErrUserNotFound
+	}
+	change(&u)
+	f.users[uid] = u
+	return nil
+}
+
+type fixture struct {
+	cli         *CLI
+	users       *fakeUsers
+	apiKeys     *apikeys.Service
+	orgs        *orgs.MemoryStore
+	deadLetters *jobs.MemoryStore
+	registry    *jobs.Registry
+	audit       *audit.MemoryStore
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		users: newFakeUsers(
+			User{UID: "alice", Email: "alice@example.com", CustomClaims: map[string]any{"roles": []any{"admin"}}},
+			User{UID: "bob", Email: "bob@example.com"},
+		),
+		apiKeys:     apikeys.NewService(apikeys.NewMemoryStore(), apikeys.Options{}, zap.NewNop()),
+		orgs:        orgs.NewMemoryStore(),
+		deadLetters: jobs.NewMemoryStore(),
+		registry:    jobs.NewRegistry(),
+		audit:       audit.NewMemoryStore(),
+	}
+	backend := jobs.NewLocalBackend(f.registry, zap.NewNop(), jobs.LocalOptions{
+		MinBackoff:  time.Millisecond,
+		DeadLetters: f.deadLetters,
+	})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	f.cli = NewCLI(
+		f.users,
+		f.apiKeys,
+		nil,
+		jobs.NewQueue(backend, f.registry, jobs.QueueOptions{}),
+		f.deadLetters,
+		f.orgs,
+		NewMemoryStore(),
+		audit.NewRecorder(audit.RecorderOptions{}, f.audit),
+		clock.System{},
+	)
+	return f
+}
+
+// run runs args as operator "ops" and decodes stdout into out
+func (f *fixture) run(t *testing.T, out any, args ...string) error {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := f.cli.Run(context.Background(), args, "ops", &stdout, &stderr)
+	if out != nil && stdout.Len() > 0 {
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), out), stdout.String())
+	}
+	return err
+}
+
+func (f *fixture) events(t *testing.T) []audit.Event {
+	t.Helper()
+	page, err := f.audit.Query(context.Background(), audit.Filter{Limit: 100})
+	require.NoError(t, err)
+	return page.Events
+}
+
+func TestCLI_Users(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	var byEmail, disabled, claims User
+
+	// Act
+	getErr := f.run(t, &byEmail, "users", "get", "bob@example.com")
+	disableErr := f.run(t, &disabled, "users", "disable", "bob")
+	claimsErr := f.run(t, &claims, "users", "claims", "-merge", "alice", `{"roles":null,"plan":"pro"}`)
+	missingErr := f.run(t, nil, "users", "get", "carol")
+	badClaimsErr := f.run(t, nil, "users", "claims", "alice", `["admin"]`)
+
+	// Assert
+	require.NoError(t, getErr)
+	assert.Equal(t, "bob", byEmail.UID)
+	require.NoError(t, disableErr)
+	assert.True(t, disabled.Disabled)
+	require.NoError(t, claimsErr)
+	assert.Equal(t, map[string]any{"plan": "pro"}, claims.CustomClaims)
+	assert.ErrorIs(t, missingErr, ErrUserNotFound)
+	assert.ErrorIs(t, badClaimsErr, ErrUsage)
+
+	events := f.events(t)
+	require.Len(t, events, 2)
+	actions := []audit.Action{events[0].Action, events[1].Action}
+	assert.ElementsMatch(t, []audit.Action{"admin.users.disable", "admin.users.claims"}, actions)
+	for _, e := range events {
+		assert.Equal(t, "cli:ops", e.Actor.ID)
+		assert.NotEmpty(t, e.Changes)
+	}
+}
+
+func TestCLI_APIKeys(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	var created apikeys.CreatedKey
+	var listed []apikeys.Key
+	var revoked apikeys.Key
+
+	// Act
+	createErr := f.run(t, &created, "-as", "carol", "apikeys", "create", "-owner", "alice", "-name", "ci")
+	listErr := f.run(t, &listed, "apikeys", "list", "-owner", "alice")
+	revokeErr := f.run(t, &revoked, "apikeys", "revoke", created.ID)
+	unknownOwnerErr := f.run(t, nil, "apikeys", "create", "-owner", "nobody", "-name", "ci")
+
+	// Assert
+	require.NoError(t, createErr)
+	assert.Contains(t, created.Token, apikeys.TokenPrefix)
+	assert.Equal(t, "cli:carol", created.CreatedBy)
+	require.NoError(t, listErr)
+	require.Len(t, listed, 1)
+	assert.Equal(t, created.ID, listed[0].ID)
+	require.NoError(t, revokeErr)
+	assert.NotNil(t, revoked.RevokedAt)
+	assert.ErrorIs(t, unknownOwnerErr, ErrUserNotFound)
+}
+
+func TestCLI_UsersDisable_RevokesAPIKeys(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	ctx := context.Background()
+	bobs, err := f.apiKeys.Create(ctx, "bob", "ci", "ops")
+	require.NoError(t, err)
+	alices, err := f.apiKeys.Create(ctx, "alice", "ci", "ops")
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Middleware(f.apiKeys.Verifier(auth.StaticVerifier{})))
+	status := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, status(bobs.Token))
+
+	// Act
+	disableErr := f.run(t, nil, "users", "disable", "bob")
+	enableErr := f.run(t, nil, "users", "enable", "bob")
+
+	// Assert
+	require.NoError(t, disableErr)
+	require.NoError(t, enableErr)
+	assert.Equal(t, http.StatusUnauthorized, status(bobs.Token), "enabling does not restore keys")
+	assert.Equal(t, http.StatusOK, status(alices.Token))
+}
+
+type pingJob struct {
+	Name string `json:"name"`
+}
+
+func (pingJob) JobType() string { return "ping" }
+
+func TestCLI_JobsReplay(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	ran := make(chan string, 1)
+	jobs.Handle(f.registry, 1, func(_ context.Context, job pingJob) error {
+		ran <- job.Name
+		return nil
+	})
+	ctx := context.Background()
+	require.NoError(t, f.deadLetters.AddDeadLetter(ctx, jobs.DeadLetter{
+		Type: "ping", Payload: `{"name":"alice"}`, Attempts: 5, Error: "timeout", FailedAt: time.Now(),
+	}))
+	var dead, replayed []jobs.DeadLetter
+
+	// Act
+	deadErr := f.run(t, &dead, "jobs", "dead")
+	require.NoError(t, deadErr)
+	require.Len(t, dead, 1)
+	replayErr := f.run(t, &replayed, "jobs", "replay", dead[0].ID)
+	missingErr := f.run(t, nil, "jobs", "replay", dead[0].ID)
+	remaining, listErr := f.deadLetters.ListDeadLetters(ctx, 0)
+
+	// Assert
+	require.NoError(t, replayErr)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "alice", <-ran)
+	require.Error(t, missingErr)
+	require.NoError(t, listErr)
+	assert.Empty(t, remaining)
+}
+
+func TestCLI_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"users", "delete", "alice"}},
+		{name: "missing argument", args: []string{"users", "get"}},
+		{name: "unknown flag", args: []string{"jobs", "dead", "-all"}},
+		{name: "unknown backfill", args: []string{"backfill", "run", "nope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			f := newFixture(t)
+
+			// Act
+			err := f.run(t, nil, tt.args...)
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrUsage), "got %v", err)
+		})
+	}
+}
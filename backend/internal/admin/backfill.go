@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Backfill rewrites existing data in batches, such as filling in a field
+// added after documents were written. Batches resume from a cursor, so an
+// interrupted run picks up where it stopped; they must be idempotent, since
+// a batch that failed halfway runs again.
+type Backfill struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Batch processes up to size items after cursor, "" for the first
+	// batch. It returns the cursor of the last item scanned. With dryRun
+	// it only reports what it would change.
+	Batch func(ctx context.Context, cursor string, size int, dryRun bool) (BatchResult, error) `json:"-"`
+}
+
+// BatchResult is the outcome of one batch
+type BatchResult struct {
+	Cursor  string
+	Scanned int
+	Changed int
+}
+
+// Checkpoint is the progress of a backfill, saved after every batch
+type Checkpoint struct {
+	Name      string    `firestore:"-" json:"name"`
+	Cursor    string    `firestore:"cursor" json:"cursor"`
+	Scanned   int       `firestore:"scanned" json:"scanned"`
+	Changed   int       `firestore:"changed" json:"changed"`
+	Done      bool      `firestore:"done" json:"done"`
+	StartedAt time.Time `firestore:"startedAt" json:"startedAt"`
+	UpdatedAt time.Time `firestore:"updatedAt" json:"updatedAt"`
+	// UpdatedBy is the operator who ran the last batch
+	UpdatedBy string `firestore:"updatedBy" json:"updatedBy"`
+}
+
+// BackfillOptions controls a backfill run
+type BackfillOptions struct {
+	// BatchSize is the number of items per batch
+	BatchSize int
+	// MaxBatches stops the run after this many batches; zero runs to the end
+	MaxBatches int
+	// DryRun reports changes without making them or saving the checkpoint
+	DryRun bool
+	// Restart ignores the saved checkpoint and starts from the beginning
+	Restart bool
+	// Operator is recorded on the checkpoint
+	Operator string
+	// Progress receives a line per batch; nil for none
+	Progress io.Writer
+}
+
+// BackfillReport is the outcome of a run. Scanned and Changed count this
+// run only; the checkpoint holds the totals.
+type BackfillReport struct {
+	Name       string     `json:"name"`
+	DryRun     bool       `json:"dryRun"`
+	Batches    int        `json:"batches"`
+	Scanned    int        `json:"scanned"`
+	Changed    int        `json:"changed"`
+	Checkpoint Checkpoint `json:"checkpoint"`
+}
+
+// RunBackfill runs b from its checkpoint in st until it is done, MaxBatches
+// is reached or ctx is canceled. The checkpoint is saved after every batch,
+// so a failed or interrupted run resumes after the last finished batch.
+// Dry runs also start from the checkpoint but never save it.
+func RunBackfill(ctx context.Context, st Store, b Backfill, opts BackfillOptions, now func() time.Time) (*BackfillReport, error) {
+	if opts.BatchSize <= 0 {
+		return nil, errors.New("admin: batch size must be positive")
+	}
+
+	cp, err := st.GetCheckpoint(ctx, b.Name)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && opts.Restart) {
+		cp, err = &Checkpoint{Name: b.Name, StartedAt: now().UTC()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BackfillReport{Name: b.Name, DryRun: opts.DryRun}
+	for !cp.Done && (opts.MaxBatches == 0 || report.Batches < opts.MaxBatches) {
+		if err := ctx.Err(); err != nil {
+			report.Checkpoint = *cp
+			return report, err
+		}
+
+		res, err := b.Batch(ctx, cp.Cursor, opts.BatchSize, opts.DryRun)
+		if err != nil {
+			report.Checkpoint = *cp
+			return report, fmt.Errorf("admin: backfill %s after %q: %w", b.Name, cp.Cursor, err)
+		}
+
+		report.Batches++
+		report.Scanned += res.Scanned
+		report.Changed += res.Changed
+		cp.Scanned += res.Scanned
+		cp.Changed += res.Changed
+		if res.Scanned > 0 {
+			cp.Cursor = res.Cursor
+		}
+		cp.Done = res.Scanned < opts.BatchSize
+		cp.UpdatedAt = now().UTC()
+		cp.UpdatedBy = opts.Operator
+
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "%s: batch %d scanned %d changed %d cursor %q\n",
+				b.Name, report.Batches, res.Scanned, res.Changed, cp.Cursor)
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := st.SaveCheckpoint(ctx, *cp); err != nil {
+			report.Checkpoint = *cp
+			return report, err
+		}
+	}
+
+	report.Checkpoint = *cp
+	return report, nil
+}
@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+func seedMembers(t *testing.T, f *fixture) {
+	t.Helper()
+	ctx := context.Background()
+	for _, m := range []orgs.Member{
+		{OrgID: "acme", UserID: "alice", Role: orgs.RoleOwner},
+		{OrgID: "acme", UserID: "bob", Email: "bob@example.com", Role: orgs.RoleMember},
+		{OrgID: "acme", UserID: "gone", Role: orgs.RoleMember},
+		{OrgID: "globex", UserID: "bob", Role: orgs.RoleOwner},
+	} {
+		require.NoError(t, f.orgs.SaveMember(ctx, m))
+	}
+}
+
+func (f *fixture) email(t *testing.T, orgID, userID string) string {
+	t.Helper()
+	m, err := f.orgs.GetMember(context.Background(), orgID, userID)
+	require.NoError(t, err)
+	return m.Email
+}
+
+func TestCLI_BackfillDryRun(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	seedMembers(t, f)
+	var report BackfillReport
+	var status Checkpoint
+
+	// Act
+	runErr := f.run(t, &report, "backfill", "run", "-dry-run", "-batch", "3", "orgs.member-emails")
+	statusErr := f.run(t, &status, "backfill", "status", "orgs.member-emails")
+
+	// Assert
+	require.NoError(t, runErr)
+	assert.Equal(t, 2, report.Batches)
+	assert.Equal(t, 4, report.Scanned)
+	assert.Equal(t, 2, report.Changed)
+	assert.True(t, report.Checkpoint.Done)
+	assert.Empty(t, f.email(t, "acme", "alice"))
+	require.NoError(t, statusErr)
+	assert.Empty(t, status.Cursor, "dry runs must not save the checkpoint")
+	assert.Empty(t, f.events(t))
+}
+
+func TestCLI_BackfillResume(t *testing.T) {
+	// Arrange
+	f := newFixture(t)
+	seedMembers(t, f)
+	var first, second, third BackfillReport
+
+	// Act
+	firstErr := f.run(t, &first, "backfill", "run", "-batch", "2", "-max-batches", "1", "orgs.member-emails")
+	aliceAfterFirst := f.email(t, "acme", "alice")
+	globexAfterFirst := f.email(t, "globex", "bob")
+	secondErr := f.run(t, &second, "backfill", "run", "-batch", "2", "orgs.member-emails")
+	thirdErr := f.run(t, &third, "backfill", "run", "-batch", "2", "orgs.member-emails")
+
+	// Assert
+	require.NoError(t, firstErr)
+	assert.Equal(t, 1, first.Batches)
+	assert.Equal(t, "acme/bob", first.Checkpoint.Cursor)
+	assert.False(t, first.Checkpoint.Done)
+	assert.Equal(t, "alice@example.com", aliceAfterFirst)
+	assert.Empty(t, globexAfterFirst)
+
+	require.NoError(t, secondErr)
+	assert.Equal(t, 2, second.Scanned)
+	assert.Equal(t, 1, second.Changed)
+	assert.True(t, second.Checkpoint.Done)
+	assert.Equal(t, 4, second.Checkpoint.Scanned)
+	assert.Equal(t, 2, second.Checkpoint.Changed)
+	assert.Equal(t, "cli:ops", second.Checkpoint.UpdatedBy)
+	assert.Equal(t, "bob@example.com", f.email(t, "globex", "bob"))
+	assert.Empty(t, f.email(t, "acme", "gone"))
+
+	require.NoError(t, thirdErr)
+	assert.Zero(t, third.Batches, "a finished backfill does nothing until restarted")
+	assert.Len(t, f.events(t), 2)
+}
+
+func TestRunBackfill_Restart(t *testing.T) {
+	// Arrange
+	st := NewMemoryStore()
+	ctx := context.Background()
+	require.NoError(t, st.SaveCheckpoint(ctx, Checkpoint{Name: "count", Cursor: "9", Done: true}))
+	var cursors []string
+	b := Backfill{Name: "count", Batch: func(_ context.Context, cursor string, size int, _ bool) (BatchResult, error) {
+		cursors = append(cursors, cursor)
+		return BatchResult{Cursor: "1", Scanned: 1}, nil
+	}}
+
+	// Act
+	report, err := RunBackfill(ctx, st, b, BackfillOptions{BatchSize: 10, Restart: true}, time.Now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, cursors)
+	assert.Equal(t, 1, report.Checkpoint.Scanned)
+	assert.True(t, report.Checkpoint.Done)
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// Backfills lists the backfills the admin command can run. Add new ones
+// here; keep names stable, since checkpoints are saved under them.
+func Backfills(orgStore orgs.Store, users Users) []Backfill {
+	return []Backfill{
+		MemberEmails(orgStore, users),
+	}
+}
+
+// MemberEmails fills in the email of organization members who joined
+// without one on their token, such as phone sign-ins that later added an
+// address, from their sign-in account. Members whose account is gone or
+// still has no email are left alone.
+func MemberEmails(orgStore orgs.Store, users Users) Backfill {
+	return Backfill{
+		Name:        "orgs.member-emails",
+		Description: "Fill in missing organization member emails from Firebase Auth",
+		Batch: func(ctx context.Context, cursor string, size int, dryRun bool) (BatchResult, error) {
+			afterOrg, afterUser, _ := strings.Cut(cursor, "/")
+			members, err := orgStore.ScanMembers(ctx, afterOrg, afterUser, size)
+			if err != nil {
+				return BatchResult{}, err
+			}
+
+			res := BatchResult{Scanned: len(members)}
+			for _, m := range members {
+				res.Cursor = m.OrgID + "/" + m.UserID
+				if m.Email != "" {
+					continue
+				}
+				u, err := users.Get(ctx, m.UserID)
+				if errors.Is(err, ErrUserNotFound) {
+					continue
+				}
+				if err != nil {
+					return res, err
+				}
+				if u.Email == "" {
+					continue
+				}
+
+				res.Changed++
+				if dryRun {
+					continue
+				}
+				m.Email = u.Email
+				if err := orgStore.SaveMember(ctx, m); err != nil {
+					return res, err
+				}
+			}
+			return res, nil
+		},
+	}
+}
@@ -0,0 +1,369 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/store"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+func (inv *invocation) usersGet(args []string) error {
+	rest, err := inv.args(inv.flags("users get"), args, 1)
+	if err != nil {
+		return err
+	}
+
+	var u *User
+	if strings.Contains(rest[0], "@") {
+		u, err = inv.users.GetByEmail(inv.ctx, rest[0])
+	} else {
+		u, err = inv.users.Get(inv.ctx, rest[0])
+	}
+	if err != nil {
+		return err
+	}
+	return inv.write(u)
+}
+
+func (inv *invocation) usersDisable(args []string) error {
+	return inv.setDisabled("users disable", args, true)
+}
+
+func (inv *invocation) usersEnable(args []string) error {
+	return inv.setDisabled("users enable", args, false)
+}
+
+func (inv *invocation) setDisabled(name string, args []string, disabled bool) error {
+	rest, err := inv.args(inv.flags(name), args, 1)
+	if err != nil {
+		return err
+	}
+	uid := rest[0]
+
+	before, err := inv.users.Get(inv.ctx, uid)
+	if err != nil {
+		return err
+	}
+	if err := inv.users.SetDisabled(inv.ctx, uid, disabled); err != nil {
+		return err
+	}
+
+	action := audit.Action("admin.users.enable")
+	if disabled {
+		action = "admin.users.disable"
+	}
+	changes, _ := audit.Diff(map[string]any{"disabled": before.Disabled}, map[string]any{"disabled": disabled})
+	inv.audit(action, "user", uid, changes)
+
+	// API keys do not go through the identity provider, so they are revoked
+	// with the account; enabling it again does not bring them back
+	if disabled {
+		revoked, err := inv.apiKeys.RevokeAll(inv.ctx, uid)
+		if err != nil {
+			return err
+		}
+		if revoked > 0 {
+			fmt.Fprintf(inv.stderr, "admin: revoked %d API keys of %s\n", revoked, uid)
+		}
+	}
+
+	after, err := inv.users.Get(inv.ctx, uid)
+	if err != nil {
+		return err
+	}
+	return inv.write(after)
+}
+
+func (inv *invocation) usersClaims(args []string) error {
+	fs := inv.flags("users claims")
+	merge := fs.Bool("merge", false, "merge into the current claims")
+	rest, err := inv.args(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	uid := rest[0]
+
+	var claims map[string]any
+	if err := json.Unmarshal([]byte(rest[1]), &claims); err != nil || claims == nil {
+		return inv.usage("users claims: claims must be a JSON object")
+	}
+
+	before, err := inv.users.Get(inv.ctx, uid)
+	if err != nil {
+		return err
+	}
+	if *merge {
+		merged := maps.Clone(before.CustomClaims)
+		if merged == nil {
+			merged = map[string]any{}
+		}
+		for k, v := range claims {
+			if v == nil {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+		claims = merged
+	}
+	if err := inv.users.SetClaims(inv.ctx, uid, claims); err != nil {
+		return err
+	}
+
+	changes, _ := audit.Diff(
+		map[string]any{"customClaims": before.CustomClaims},
+		map[string]any{"customClaims": claims},
+	)
+	inv.audit("admin.users.claims", "user", uid, changes)
+
+	after, err := inv.users.Get(inv.ctx, uid)
+	if err != nil {
+		return err
+	}
+	return inv.write(after)
+}
+
+func (inv *invocation) apiKeysCreate(args []string) error {
+	fs := inv.flags("apikeys create")
+	owner := fs.String("owner", "", "UID the key acts as")
+	name := fs.String("name", "", "what the key is for")
+	if _, err := inv.args(fs, args, 0); err != nil {
+		return err
+	}
+	if *owner == "" {
+		return inv.usage("apikeys create: -owner is required")
+	}
+
+	// Catch typos before issuing a key that acts as nobody
+	if _, err := inv.users.Get(inv.ctx, *owner); err != nil {
+		return err
+	}
+	created, err := inv.apiKeys.Create(inv.ctx, *owner, *name, "cli:"+inv.operator)
+	if err != nil {
+		return err
+	}
+	inv.audit("admin.apikeys.create", "apikey", created.ID, nil)
+	return inv.write(created)
+}
+
+func (inv *invocation) apiKeysList(args []string) error {
+	fs := inv.flags("apikeys list")
+	owner := fs.String("owner", "", "UID whose keys to list")
+	if _, err := inv.args(fs, args, 0); err != nil {
+		return err
+	}
+	if *owner == "" {
+		return inv.usage("apikeys list: -owner is required")
+	}
+
+	keys, err := inv.apiKeys.List(inv.ctx, *owner)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		keys = []apikeys.Key{}
+	}
+	return inv.write(keys)
+}
+
+func (inv *invocation) apiKeysRevoke(args []string) error {
+	rest, err := inv.args(inv.flags("apikeys revoke"), args, 1)
+	if err != nil {
+		return err
+	}
+
+	key, err := inv.apiKeys.Revoke(inv.ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	inv.audit("admin.apikeys.revoke", "apikey", key.ID, nil)
+	return inv.write(key)
+}
+
+// deadDelivery shows the owner, which the API leaves out since callers
+// only see their own deliveries
+type deadDelivery struct {
+	OwnerID string `json:"ownerId"`
+	webhooks.Delivery
+}
+
+func (inv *invocation) webhooksDead(args []string) error {
+	fs := inv.flags("webhooks dead")
+	limit := fs.Int("limit", 50, "maximum number of deliveries")
+	if _, err := inv.args(fs, args, 0); err != nil {
+		return err
+	}
+
+	deliveries, err := inv.webhooks.DeadDeliveries(inv.ctx, *limit)
+	if err != nil {
+		return err
+	}
+	out := make([]deadDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		out = append(out, deadDelivery{OwnerID: d.OwnerID, Delivery: d})
+	}
+	return inv.write(out)
+}
+
+func (inv *invocation) webhooksReplay(args []string) error {
+	fs := inv.flags("webhooks replay")
+	owner := fs.String("owner", "", "UID owning the endpoint")
+	endpoint := fs.String("endpoint", "", "endpoint ID")
+	ids, err := inv.args(fs, args, -1)
+	if err != nil {
+		return err
+	}
+	if *owner == "" || *endpoint == "" {
+		return inv.usage("webhooks replay: -owner and -endpoint are required")
+	}
+
+	// Report the replays that were scheduled even if a later one fails
+	out := make([]deadDelivery, 0, len(ids))
+	for _, id := range ids {
+		var d *webhooks.Delivery
+		d, err = inv.webhooks.Replay(inv.ctx, *owner, *endpoint, id)
+		if err != nil {
+			err = fmt.Errorf("admin: replay delivery %s: %w", id, err)
+			break
+		}
+		inv.audit("admin.webhooks.replay", "delivery", id, nil)
+		out = append(out, deadDelivery{OwnerID: *owner, Delivery: *d})
+	}
+	inv.drain()
+	if writeErr := inv.write(out); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func (inv *invocation) jobsDead(args []string) error {
+	fs := inv.flags("jobs dead")
+	limit := fs.Int("limit", 50, "maximum number of dead letters")
+	if _, err := inv.args(fs, args, 0); err != nil {
+		return err
+	}
+
+	letters, err := inv.deadLetters.ListDeadLetters(inv.ctx, *limit)
+	if err != nil {
+		return err
+	}
+	if letters == nil {
+		letters = []jobs.DeadLetter{}
+	}
+	return inv.write(letters)
+}
+
+func (inv *invocation) jobsReplay(args []string) error {
+	ids, err := inv.args(inv.flags("jobs replay"), args, -1)
+	if err != nil {
+		return err
+	}
+
+	// Report the jobs that were enqueued even if a later one fails
+	out := make([]jobs.DeadLetter, 0, len(ids))
+	for _, id := range ids {
+		var letter *jobs.DeadLetter
+		letter, err = inv.replayJob(id)
+		if err != nil {
+			break
+		}
+		inv.audit("admin.jobs.replay", "job", id, nil)
+		out = append(out, *letter)
+	}
+	inv.drain()
+	if writeErr := inv.write(out); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// replayJob enqueues a dead-lettered job again and drops the dead letter.
+// A replay that fails again records a new one.
+func (inv *invocation) replayJob(id string) (*jobs.DeadLetter, error) {
+	letter, err := inv.deadLetters.GetDeadLetter(inv.ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("admin: dead letter %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := inv.queue.Replay(inv.ctx, *letter); err != nil {
+		return nil, fmt.Errorf("admin: replay job %s: %w", id, err)
+	}
+	if err := inv.deadLetters.DeleteDeadLetter(inv.ctx, id); err != nil {
+		return nil, err
+	}
+	return letter, nil
+}
+
+func (inv *invocation) backfillList(args []string) error {
+	if _, err := inv.args(inv.flags("backfill list"), args, 0); err != nil {
+		return err
+	}
+	return inv.write(inv.backfills)
+}
+
+func (inv *invocation) backfillStatus(args []string) error {
+	rest, err := inv.args(inv.flags("backfill status"), args, 1)
+	if err != nil {
+		return err
+	}
+	b, err := inv.backfill(rest[0])
+	if err != nil {
+		return err
+	}
+
+	cp, err := inv.checkpoints.GetCheckpoint(inv.ctx, b.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		cp, err = &Checkpoint{Name: b.Name}, nil
+	}
+	if err != nil {
+		return err
+	}
+	return inv.write(cp)
+}
+
+func (inv *invocation) backfillRun(args []string) error {
+	fs := inv.flags("backfill run")
+	opts := BackfillOptions{Operator: "cli:" + inv.operator, Progress: inv.stderr}
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report changes without making them")
+	fs.BoolVar(&opts.Restart, "restart", false, "ignore the checkpoint and start over")
+	fs.IntVar(&opts.BatchSize, "batch", 100, "items per batch")
+	fs.IntVar(&opts.MaxBatches, "max-batches", 0, "stop after this many batches; 0 runs to the end")
+	rest, err := inv.args(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	b, err := inv.backfill(rest[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := RunBackfill(inv.ctx, inv.checkpoints, b, opts, inv.now)
+	if report == nil {
+		return err
+	}
+	if !opts.DryRun && report.Batches > 0 {
+		inv.audit("admin.backfill.run", "backfill", b.Name, nil)
+	}
+	if writeErr := inv.write(report); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func (inv *invocation) backfill(name string) (Backfill, error) {
+	for _, b := range inv.backfills {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return Backfill{}, inv.usage(fmt.Sprintf("unknown backfill %q; see backfill list", name))
+}
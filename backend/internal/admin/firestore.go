@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// checkpointsCollection holds one checkpoint per backfill, keyed by name
+const checkpointsCollection = "adminBackfills"
+
+// FirestoreStore keeps checkpoints in adminBackfills/{name}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+// GetCheckpoint implements Store
+func (s *FirestoreStore) GetCheckpoint(ctx context.Context, name string) (*Checkpoint, error) {
+	snap, err := s.client.Collection(checkpointsCollection).Doc(name).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("admin: get checkpoint %s: %w", name, err)
+	}
+
+	var cp Checkpoint
+	if err := snap.DataTo(&cp); err != nil {
+		return nil, err
+	}
+	cp.Name = snap.Ref.ID
+	return &cp, nil
+}
+
+// SaveCheckpoint implements Store
+func (s *FirestoreStore) SaveCheckpoint(ctx context.Context, cp Checkpoint) error {
+	if _, err := s.client.Collection(checkpointsCollection).Doc(cp.Name).Set(ctx, cp); err != nil {
+		return fmt.Errorf("admin: save checkpoint %s: %w", cp.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/config"
+)
+
+// Module adds the command line to the API's fx graph. Unlike the API, it
+// needs Firebase Auth to be set up, the emulator included.
+func Module() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			NewUsers,
+			NewCLI,
+		),
+		fx.Decorate(QuietLogger),
+	)
+}
+
+// NewUsers manages the Firebase Auth accounts of the configured project
+func NewUsers(cfg *config.Config) (Users, error) {
+	client, err := auth.NewFirebaseAuthClient(context.Background(), cfg.Auth.FirebaseProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return NewFirebaseUsers(client), nil
+}
+
+// QuietLogger keeps the API's startup logging off the terminal; warnings
+// and errors, such as jobs failing during a replay, still show on stderr
+func QuietLogger(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists backfill checkpoints
+type Store interface {
+	// GetCheckpoint returns store.ErrNotFound for backfills never run
+	GetCheckpoint(ctx context.Context, name string) (*Checkpoint, error)
+	SaveCheckpoint(ctx context.Context, cp Checkpoint) error
+}
+
+// MemoryStore is an in-process Store for tests
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+// GetCheckpoint implements Store
+func (s *MemoryStore) GetCheckpoint(_ context.Context, name string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint implements Store
+func (s *MemoryStore) SaveCheckpoint(_ context.Context, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[cp.Name] = cp
+	return nil
+}
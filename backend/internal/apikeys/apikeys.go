@@ -0,0 +1,234 @@
+// Package apikeys issues API keys, long-lived bearer tokens for scripts and
+// server-to-server integrations acting as a user.
+//
+// A key is shown once, when it is created; only the SHA-256 of it is
+// stored, as the key's ID. Service is an auth.Verifier for keys and passes
+// other tokens on, so routes accept either a Firebase ID token or a key.
+// Keys carry no custom claims, so they never hold a role such as admin.
+package apikeys
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/store"
+)
+
+// TokenPrefix starts every key, telling keys apart from ID tokens
+const TokenPrefix = "ak_"
+
+// ClaimKeyID is the claim holding the key ID on principals authenticated by a key
+const ClaimKeyID = "apiKeyId"
+
+// maxNameLength caps key names, in characters
+const maxNameLength = 100
+
+// maxKeys caps the active keys a single owner may hold
+const maxKeys = 20
+
+var (
+	// ErrNotFound is returned for unknown keys
+	ErrNotFound = errors.New("apikeys: not found")
+	// ErrInvalidName is returned for empty or overlong key names
+	ErrInvalidName = errors.New("apikeys: name must be 1 to 100 characters")
+	// ErrTooManyKeys is returned when an owner reaches the key limit
+	ErrTooManyKeys = errors.New("apikeys: key limit reached")
+)
+
+// Key is an issued API key
+type Key struct {
+	// ID is the SHA-256 of the key, hex encoded
+	ID        string     `firestore:"-" json:"id"`
+	OwnerID   string     `firestore:"ownerId" json:"ownerId"`
+	Name      string     `firestore:"name" json:"name"`
+	CreatedBy string     `firestore:"createdBy" json:"createdBy"`
+	CreatedAt time.Time  `firestore:"createdAt" json:"createdAt"`
+	RevokedAt *time.Time `firestore:"revokedAt" json:"revokedAt,omitempty"`
+}
+
+// Revoked reports whether the key stopped working
+func (k Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// CreatedKey is returned once on creation; the key is not shown again
+type CreatedKey struct {
+	Key
+	Token string `json:"token"`
+}
+
+// Options tunes the service
+type Options struct {
+	// Clock and IDs default to the wall clock and crypto/rand
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// Service issues, revokes and verifies API keys
+type Service struct {
+	store  Store
+	opts   Options
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates an API key service
+func NewService(st Store, opts Options, logger *zap.Logger) *Service {
+	opts.IDs = ids.OrRandom(opts.IDs)
+	return &Service{store: st, opts: opts, logger: logger, now: clock.OrSystem(opts.Clock).Now}
+}
+
+// Create issues a key acting as ownerID. createdBy names who asked for it.
+// The key is only returned here.
+func (s *Service) Create(ctx context.Context, ownerID, name, createdBy string) (*CreatedKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, ErrInvalidName
+	}
+
+	existing, err := s.store.List(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, k := range existing {
+		if !k.Revoked() {
+			active++
+		}
+	}
+	if active >= maxKeys {
+		return nil, ErrTooManyKeys
+	}
+
+	token := TokenPrefix + s.opts.IDs.NewID(32)
+	k := Key{
+		ID:        keyID(token),
+		OwnerID:   ownerID,
+		Name:      name,
+		CreatedBy: createdBy,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.store.Create(ctx, k); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("api key created", zap.String("key_id", k.ID), zap.String("owner_id", ownerID), zap.String("by", createdBy))
+	return &CreatedKey{Key: k, Token: token}, nil
+}
+
+// Get returns a key
+func (s *Service) Get(ctx context.Context, id string) (*Key, error) {
+	k, err := s.store.Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return k, err
+}
+
+// List returns the owner's keys, including revoked ones, oldest first
+func (s *Service) List(ctx context.Context, ownerID string) ([]Key, error) {
+	return s.store.List(ctx, ownerID)
+}
+
+// Revoke stops a key from working. Revoking a revoked key keeps its
+// original revocation time.
+func (s *Service) Revoke(ctx context.Context, id string) (*Key, error) {
+	k, err := s.Get(ctx, id)
+	if err != nil || k.Revoked() {
+		return k, err
+	}
+
+	now := s.now().UTC()
+	k.RevokedAt = &now
+	if err := s.store.Update(ctx, *k); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("api key revoked", zap.String("key_id", k.ID), zap.String("owner_id", k.OwnerID))
+	return k, nil
+}
+
+// RevokeAll stops every key of the owner from working and reports how many
+// were revoked. It is called when the owner is disabled or deleted: keys
+// are checked without asking the identity provider, so they would outlive
+// the owner's sign-in otherwise. Enabling the owner again does not restore
+// them.
+func (s *Service) RevokeAll(ctx context.Context, ownerID string) (int, error) {
+	keys, err := s.store.List(ctx, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, k := range keys {
+		if k.Revoked() {
+			continue
+		}
+		if _, err := s.Revoke(ctx, k.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// DeleteAll erases the owner's keys, revoked ones included, and reports
+// how many were deleted
+func (s *Service) DeleteAll(ctx context.Context, ownerID string) (int, error) {
+	keys, err := s.store.List(ctx, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	for i, k := range keys {
+		if err := s.store.Delete(ctx, k.ID); err != nil {
+			return i, err
+		}
+	}
+	if len(keys) > 0 {
+		s.logger.Info("api keys deleted", zap.String("owner_id", ownerID), zap.Int("count", len(keys)))
+	}
+	return len(keys), nil
+}
+
+// Verify implements auth.Verifier for API keys
+func (s *Service) Verify(ctx context.Context, token string) (*auth.Principal, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, auth.ErrInvalidToken
+	}
+	k, err := s.store.Get(ctx, keyID(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if k.Revoked() {
+		return nil, auth.ErrInvalidToken
+	}
+	return &auth.Principal{Subject: k.OwnerID, Claims: map[string]any{ClaimKeyID: k.ID}}, nil
+}
+
+// Verifier checks API keys with s and every other token with next
+func (s *Service) Verifier(next auth.Verifier) auth.Verifier {
+	return auth.VerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		if strings.HasPrefix(token, TokenPrefix) {
+			return s.Verify(ctx, token)
+		}
+		return next.Verify(ctx, token)
+	})
+}
+
+// keyID derives the stored ID of a key from the key
+func keyID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+package apikeys
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+func setupService(t *testing.T) (*Service, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := NewService(NewMemoryStore(), Options{}, zap.NewNop())
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestService_Create(t *testing.T) {
+	// Arrange
+	s, _ := setupService(t)
+	ctx := context.Background()
+
+	// Act
+	created, err := s.Create(ctx, "alice", " CI deploys ", "ops@example.com")
+	require.NoError(t, err)
+	listed, err := s.List(ctx, "alice")
+	require.NoError(t, err)
+	_, invalid := s.Create(ctx, "alice", "  ", "ops@example.com")
+
+	// Assert
+	assert.True(t, strings.HasPrefix(created.Token, TokenPrefix))
+	assert.Equal(t, keyID(created.Token), created.ID)
+	assert.NotContains(t, created.ID, created.Token)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "CI deploys", listed[0].Name)
+	assert.Equal(t, "ops@example.com", listed[0].CreatedBy)
+	assert.ErrorIs(t, invalid, ErrInvalidName)
+}
+
+func TestService_CreateLimit(t *testing.T) {
+	// Arrange
+	s, _ := setupService(t)
+	ctx := context.Background()
+	var first *CreatedKey
+	for i := range maxKeys {
+		k, err := s.Create(ctx, "alice", "key", "ops")
+		require.NoError(t, err)
+		if i == 0 {
+			first = k
+		}
+	}
+
+	// Act
+	_, full := s.Create(ctx, "alice", "one too many", "ops")
+	_, err := s.Revoke(ctx, first.ID)
+	require.NoError(t, err)
+	_, afterRevoke := s.Create(ctx, "alice", "replacement", "ops")
+
+	// Assert
+	assert.ErrorIs(t, full, ErrTooManyKeys)
+	assert.NoError(t, afterRevoke)
+}
+
+func TestService_Verify(t *testing.T) {
+	// Arrange
+	s, now := setupService(t)
+	ctx := context.Background()
+	created, err := s.Create(ctx, "alice", "CI", "ops")
+	require.NoError(t, err)
+	firebase := auth.StaticVerifier{"id-token": {Subject: "bob"}}
+	v := s.Verifier(firebase)
+
+	// Act
+	principal, err := v.Verify(ctx, created.Token)
+	require.NoError(t, err)
+	idToken, err := v.Verify(ctx, "id-token")
+	require.NoError(t, err)
+	_, unknown := v.Verify(ctx, TokenPrefix+"0000")
+	revokedAt := now.Add(time.Hour)
+	*now = revokedAt
+	revoked, err := s.Revoke(ctx, created.ID)
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+	again, err := s.Revoke(ctx, created.ID)
+	require.NoError(t, err)
+	_, afterRevoke := v.Verify(ctx, created.Token)
+
+	// Assert
+	assert.Equal(t, "alice", principal.Subject)
+	assert.Equal(t, created.ID, principal.Claims[ClaimKeyID])
+	assert.False(t, auth.HasRole(principal, "admin"))
+	assert.Equal(t, "bob", idToken.Subject)
+	assert.ErrorIs(t, unknown, auth.ErrInvalidToken)
+	assert.Equal(t, revokedAt, *revoked.RevokedAt)
+	assert.Equal(t, revokedAt, *again.RevokedAt)
+	assert.ErrorIs(t, afterRevoke, auth.ErrInvalidToken)
+}
+
+func TestService_DeleteAll(t *testing.T) {
+	// Arrange
+	s, _ := setupService(t)
+	ctx := context.Background()
+	active, err := s.Create(ctx, "alice", "CI", "ops")
+	require.NoError(t, err)
+	revoked, err := s.Create(ctx, "alice", "old", "ops")
+	require.NoError(t, err)
+	_, err = s.Revoke(ctx, revoked.ID)
+	require.NoError(t, err)
+	_, err = s.Create(ctx, "bob", "CI", "ops")
+	require.NoError(t, err)
+
+	// Act
+	deleted, err := s.DeleteAll(ctx, "alice")
+	require.NoError(t, err)
+	again, err := s.DeleteAll(ctx, "alice")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 2, deleted)
+	assert.Zero(t, again)
+	_, err = s.Get(ctx, active.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Verify(ctx, active.Token)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	bobs, err := s.List(ctx, "bob")
+	require.NoError(t, err)
+	assert.Len(t, bobs, 1)
+}
+
+func TestService_RevokeAll(t *testing.T) {
+	// Arrange
+	s, now := setupService(t)
+	ctx := context.Background()
+	active, err := s.Create(ctx, "alice", "CI", "ops")
+	require.NoError(t, err)
+	old, err := s.Create(ctx, "alice", "old", "ops")
+	require.NoError(t, err)
+	earlier := *now
+	_, err = s.Revoke(ctx, old.ID)
+	require.NoError(t, err)
+	bobs, err := s.Create(ctx, "bob", "CI", "ops")
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+
+	// Act
+	revoked, err := s.RevokeAll(ctx, "alice")
+	require.NoError(t, err)
+	again, err := s.RevokeAll(ctx, "alice")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, revoked)
+	assert.Zero(t, again)
+	_, err = s.Verify(ctx, active.Token)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	k, err := s.Get(ctx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, earlier, *k.RevokedAt, "revoked keys keep their revocation time")
+	_, err = s.Verify(ctx, bobs.Token)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,96 @@
+package apikeys
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// collection holds one document per key, keyed by the key's hash. It is
+// backend-only: firestore.rules denies clients access.
+const collection = "apiKeys"
+
+// FirestoreStore keeps keys in apiKeys/{keyId}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+// Create implements Store
+func (s *FirestoreStore) Create(ctx context.Context, k Key) error {
+	if _, err := s.client.Collection(collection).Doc(k.ID).Create(ctx, k); err != nil {
+		return fmt.Errorf("apikeys: create %s: %w", k.ID, err)
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *FirestoreStore) Get(ctx context.Context, id string) (*Key, error) {
+	snap, err := s.client.Collection(collection).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apikeys: get %s: %w", id, err)
+	}
+
+	var k Key
+	if err := snap.DataTo(&k); err != nil {
+		return nil, err
+	}
+	k.ID = snap.Ref.ID
+	return &k, nil
+}
+
+// Update implements Store
+func (s *FirestoreStore) Update(ctx context.Context, k Key) error {
+	_, err := s.client.Collection(collection).Doc(k.ID).Update(ctx, []firestore.Update{
+		{Path: "name", Value: k.Name},
+		{Path: "revokedAt", Value: k.RevokedAt},
+	})
+	if store.IsNotFound(err) {
+		return store.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("apikeys: update %s: %w", k.ID, err)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *FirestoreStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.client.Collection(collection).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("apikeys: delete %s: %w", id, err)
+	}
+	return nil
+}
+
+// List implements Store. It relies on the (ownerId, createdAt) composite
+// index declared in firestore.indexes.json.
+func (s *FirestoreStore) List(ctx context.Context, ownerID string) ([]Key, error) {
+	docs, err := s.client.Collection(collection).
+		Where("ownerId", "==", ownerID).
+		OrderBy("createdAt", firestore.Asc).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("apikeys: list: %w", err)
+	}
+
+	out := make([]Key, 0, len(docs))
+	for _, doc := range docs {
+		var k Key
+		if err := doc.DataTo(&k); err != nil {
+			return nil, err
+		}
+		k.ID = doc.Ref.ID
+		out = append(out, k)
+	}
+	return out, nil
+}
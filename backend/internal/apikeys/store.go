@@ -0,0 +1,86 @@
+package apikeys
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists API keys
+type Store interface {
+	Create(ctx context.Context, k Key) error
+	// Get returns store.ErrNotFound for unknown keys
+	Get(ctx context.Context, id string) (*Key, error)
+	// Update returns store.ErrNotFound for unknown keys
+	Update(ctx context.Context, k Key) error
+	// Delete removes a key; unknown keys are not an error
+	Delete(ctx context.Context, id string) error
+	// List returns the owner's keys, oldest first
+	List(ctx context.Context, ownerID string) ([]Key, error)
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]Key
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]Key)}
+}
+
+// Create implements Store
+func (s *MemoryStore) Create(_ context.Context, k Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[k.ID] = k
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, id string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &k, nil
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(_ context.Context, k Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[k.ID]; !ok {
+		return store.ErrNotFound
+	}
+	s.keys[k.ID] = k
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, id)
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, ownerID string) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Key
+	for _, k := range s.keys {
+		if k.OwnerID == ownerID {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
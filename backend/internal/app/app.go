@@ -0,0 +1,789 @@
+// Package app wires the API together with fx. cmd/api runs it; testutil
+// builds the same graph for tests, so they exercise the production
+// middleware and routes.
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/cache"
+	"github.com/your-org/your-app/internal/clientinfo"
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/config"
+	"github.com/your-org/your-app/internal/cron"
+	"github.com/your-org/your-app/internal/events"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/flags"
+	"github.com/your-org/your-app/internal/handlers"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/images"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/maintenance"
+	"github.com/your-org/your-app/internal/metrics"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/server"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+// Version is reported by GET /api/v1/health; release builds set it with
+// -ldflags "-X github.com/your-org/your-app/internal/app.Version=..."
+var Version = "1.0.0"
+
+// Module is the API: every store, service, handler and route, wired from
+// cfg. Config is passed up front because it decides which stores are wired
+// in. main adds StartServer; tests replace what they fake with fx.Replace
+// or fx.Decorate.
+func Module(cfg *config.Config) fx.Option {
+	return fx.Options(
+		fx.Supply(cfg),
+		StoreModule(cfg),
+		fx.Provide(
+			NewClock,
+			NewIDGenerator,
+			NewLogger,
+			NewMeterProvider,
+			NewCache,
+			NewResponseCache,
+			NewAuditRecorder,
+			handlers.NewAuditHandler,
+			NewEchoServer,
+			auth.NewInternal,
+			jobs.NewRegistry,
+			NewJobQueue,
+			NewCronRegistry,
+			NewCronRunner,
+			handlers.NewTaskHandler,
+			handlers.NewCronHandler,
+			NewAPIKeyService,
+			NewUserVerifier,
+			NewFileStorage,
+			NewFilesService,
+			handlers.NewFilesHandler,
+			NewImagePipeline,
+			handlers.NewImagesHandler,
+			NewAccountIdentity,
+			NewAccountService,
+			handlers.NewAccountHandler,
+			NewOrgsService,
+			handlers.NewOrgsHandler,
+			NewWebhookService,
+			handlers.NewWebhooksHandler,
+			handlers.NewOrgWebhooksHandler,
+			NewEventBroker,
+			NewStreamOptions,
+			handlers.NewEventsHandler,
+			NewFlagsService,
+			handlers.NewFlagsHandler,
+			NewMaintenanceController,
+			handlers.NewMaintenanceHandler,
+			NewHealthHandler,
+			handlers.NewHelloHandler,
+		),
+		fx.Invoke(RegisterRoutes),
+	)
+}
+
+// NewLogger creates a production-ready zap logger
+func NewLogger(cfg *config.Config) (*zap.Logger, error) {
+	if cfg.IsProduction() {
+		return zap.NewProduction()
+	}
+	return zap.NewDevelopment()
+}
+
+// NewClock is the wall clock; tests replace it with a fake
+func NewClock() clock.Clock {
+	return clock.System{}
+}
+
+// NewIDGenerator draws IDs from crypto/rand; tests replace it with a
+// predictable sequence
+func NewIDGenerator() ids.Generator {
+	return ids.Random{}
+}
+
+// NewEchoServer creates and configures the Echo server with middleware
+// Production middleware stack: Recover, Security Headers, CORS, RequestID, Logging, Maintenance, Client version, Audit, Compression, ETags
+func NewEchoServer(
+	cfg *config.Config,
+	logger *zap.Logger,
+	meterProvider metric.MeterProvider,
+	maintenanceController *maintenance.Controller,
+	userVerifier auth.Verifier,
+	auditRecorder *audit.Recorder,
+	clk clock.Clock,
+	idGen ids.Generator,
+) (*echo.Echo, error) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	// Client IPs come from the address appended by the load balancer, never
+	// from X-Forwarded-For entries the client wrote itself
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+
+	isProduction := cfg.IsProduction()
+
+	// 1. Panic recovery - prevents server crash on panic
+	e.Use(middleware.Recover())
+
+	// 2. Request logging
+	e.Use(middleware.Logger())
+
+	// 3. Security headers (OWASP A05:2021 - Security Misconfiguration), on
+	// every response including CORS preflights
+	e.Use(server.SecurityHeaders(isProduction))
+
+	// 4. CORS - Cross-Origin Resource Sharing
+	allowedOrigins := cfg.CORSAllowedOrigins
+	if allowedOrigins == "" {
+		if isProduction {
+			allowedOrigins = "https://yourapp.com"
+			logger.Warn("CORS_ALLOWED_ORIGINS not set, using default. Set this in production!")
+		} else {
+			allowedOrigins = "http://localhost:3000,http://localhost:8080"
+		}
+	}
+
+	e.Use(server.CORS(strings.Split(allowedOrigins, ",")))
+
+	// 5. Request ID - for tracing requests across services
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator: func() string { return idGen.NewID(16) },
+	}))
+
+	// 6. Request logging with context (structured logging)
+	e.Use(server.RequestLogger(logger, clk))
+
+	// 7. Maintenance mode - 503 for everything but health checks, or only
+	// writes when read-only; admins and allow-listed IPs get through
+	bypassNets, err := maintenance.ParseNets(cfg.Maintenance.BypassIPs)
+	if err != nil {
+		return nil, err
+	}
+	e.Use(maintenance.Middleware(maintenanceController, maintenance.MiddlewareOptions{
+		Exempt:     []string{"/health", "/api/v1/health"},
+		BypassNets: bypassNets,
+		BypassRole: cfg.Auth.AdminRole,
+		Verifier:   userVerifier,
+		RetryAfter: cfg.Maintenance.RetryAfter,
+	}))
+
+	// 8. Client version - 426 for app builds below the platform minimum,
+	// a hint header below the recommended version
+	requireVersion, err := clientinfo.RequireVersion(clientinfo.VersionOptions{
+		Requirements: map[string]clientinfo.Requirement{
+			clientinfo.PlatformIOS:     clientinfo.Requirement(cfg.Clients.IOS),
+			clientinfo.PlatformAndroid: clientinfo.Requirement(cfg.Clients.Android),
+		},
+		Prefix: "/api/v1",
+		Exempt: []string{"/api/v1/health"},
+		Meter:  meterProvider.Meter("github.com/your-org/your-app/internal/clientinfo"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	e.Use(requireVersion)
+
+	// 9. Audit - an event for every mutating API request, named by the
+	// route's audit.Annotate
+	e.Use(audit.Middleware(auditRecorder, audit.MiddlewareOptions{Prefix: "/api/v1"}, logger))
+
+	// 10. Compression - zstd, br or gzip responses as negotiated, and
+	// compressed request bodies decoded within a size limit
+	e.Use(server.Decompress(server.DecompressOptions{MaxSize: cfg.Compression.MaxRequestSize}))
+	if cfg.Compression.Enabled {
+		e.Use(server.Compress(server.CompressOptions{
+			MinSize: cfg.Compression.MinSize,
+			Types:   cfg.Compression.Types,
+		}))
+	}
+
+	// 11. Conditional requests - strong ETags on JSON GET responses and
+	// 304 for a matching If-None-Match; updates check If-Match themselves
+	e.Use(server.ETags())
+
+	return e, nil
+}
+
+// orgCacheTag files cached organization responses under their organization
+const orgCacheTag = "org:{orgId}"
+
+// RegisterRoutes sets up all API routes
+func RegisterRoutes(
+	e *echo.Echo,
+	cfg *config.Config,
+	logger *zap.Logger,
+	internalAuth auth.Internal,
+	tasks *handlers.TaskHandler,
+	crons *handlers.CronHandler,
+	userVerifier auth.Verifier,
+	fileStorage files.Storage,
+	filesHandler *handlers.FilesHandler,
+	imagesHandler *handlers.ImagesHandler,
+	accountHandler *handlers.AccountHandler,
+	orgsService *orgs.Service,
+	orgsHandler *handlers.OrgsHandler,
+	webhooksHandler *handlers.WebhooksHandler,
+	orgWebhooksHandler *handlers.OrgWebhooksHandler,
+	eventsHandler *handlers.EventsHandler,
+	flagsHandler *handlers.FlagsHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	auditHandler *handlers.AuditHandler,
+	responseCache *cache.ResponseCache,
+	healthHandler *handlers.HealthHandler,
+	helloHandler *handlers.HelloHandler,
+) {
+	// Health check (required for Cloud Run / Kubernetes)
+	e.GET("/health", healthHandler.Health)
+
+	// API v1 routes
+	api := e.Group("/api/v1")
+
+	api.GET("/health", healthHandler.HealthWithVersion)
+
+	// Example: Hello endpoint
+	api.GET("/hello", helloHandler.Hello)
+
+	// Files: signed upload and download URLs scoped to the caller
+	userFiles := api.Group("/files", auth.Middleware(userVerifier))
+	userFiles.POST("/uploads", filesHandler.CreateUpload, audit.Annotate("files.upload", "file", ""))
+	userFiles.GET("", filesHandler.List)
+	userFiles.GET("/:id", filesHandler.Get)
+	userFiles.GET("/:id/download", filesHandler.Download)
+	userFiles.DELETE("/:id", filesHandler.Delete, audit.Annotate("files.delete", "file", "id"))
+	userFiles.GET("/:id/thumbnails/:size", imagesHandler.Thumbnail)
+
+	// Account: data export and deletion of the caller's account
+	me := api.Group("/users/me", auth.Middleware(userVerifier))
+	me.POST("/export", accountHandler.RequestExport, audit.Annotate("account.export", "export", ""))
+	me.GET("/exports/:id", accountHandler.GetExport)
+	me.POST("/deletion", accountHandler.RequestDeletion, audit.Annotate("account.delete", "user", ""))
+	me.GET("/deletion", accountHandler.GetDeletion)
+
+	// Organizations: creating and joining need no membership; everything
+	// below /orgs/{orgId} is scoped to an organization the caller belongs to
+	userOrgs := api.Group("/orgs", auth.Middleware(userVerifier))
+	userOrgs.POST("", orgsHandler.Create, audit.Annotate("orgs.create", "org", ""))
+	userOrgs.GET("", orgsHandler.List)
+	userOrgs.POST("/:orgId/invitations/accept", orgsHandler.AcceptInvitation, audit.Annotate("orgs.invitation.accept", "org", "orgId"),
+		responseCache.Invalidates(orgCacheTag))
+
+	// Membership is checked before the cache, so removed members lose
+	// access at once; cached responses are dropped by the writes below
+	org := userOrgs.Group("/:orgId", orgs.Middleware(orgsService, "orgId"))
+	org.GET("", orgsHandler.Get, responseCache.Route(cfg.Cache.TTL, orgCacheTag))
+	org.GET("/members", orgsHandler.Members, responseCache.Route(cfg.Cache.TTL, orgCacheTag))
+	org.PUT("/members/:userId", orgsHandler.UpdateMember, audit.Annotate("orgs.member.update", "member", "userId"),
+		responseCache.Invalidates(orgCacheTag))
+	org.DELETE("/members/:userId", orgsHandler.RemoveMember, audit.Annotate("orgs.member.remove", "member", "userId"),
+		responseCache.Invalidates(orgCacheTag))
+	org.POST("/invitations", orgsHandler.CreateInvitation, audit.Annotate("orgs.invitation.create", "invitation", ""),
+		responseCache.Invalidates(orgCacheTag))
+	org.GET("/invitations", orgsHandler.Invitations, responseCache.Route(cfg.Cache.TTL, orgCacheTag))
+	org.DELETE("/invitations/:id", orgsHandler.RevokeInvitation, audit.Annotate("orgs.invitation.revoke", "invitation", "id"),
+		responseCache.Invalidates(orgCacheTag))
+
+	// Webhooks of the organization, managed by its admins and owners
+	orgWebhooks := org.Group("/webhooks", orgs.RequireRole(orgs.RoleAdmin))
+	orgWebhooks.GET("/events", orgWebhooksHandler.Events)
+	orgWebhooks.POST("", orgWebhooksHandler.Create, audit.Annotate("orgs.webhooks.create", "webhook", ""))
+	orgWebhooks.GET("", orgWebhooksHandler.List)
+	orgWebhooks.GET("/:id", orgWebhooksHandler.Get)
+	orgWebhooks.DELETE("/:id", orgWebhooksHandler.Delete, audit.Annotate("orgs.webhooks.delete", "webhook", "id"))
+	orgWebhooks.POST("/:id/ping", orgWebhooksHandler.Ping, audit.Annotate("orgs.webhooks.ping", "webhook", "id"))
+	orgWebhooks.GET("/:id/deliveries", orgWebhooksHandler.Deliveries)
+	orgWebhooks.POST("/:id/deliveries/:deliveryId/replay", orgWebhooksHandler.Replay,
+		audit.Annotate("orgs.webhooks.replay", "delivery", "deliveryId"))
+
+	// Feature flags evaluated for the caller; works before sign-in too
+	api.GET("/flags", flagsHandler.List, auth.Optional(userVerifier))
+
+	// Events: Server-Sent Events stream of the caller's updates
+	api.GET("/events/stream", eventsHandler.Stream, auth.Middleware(userVerifier))
+
+	// Webhooks: outgoing event subscriptions owned by the caller
+	userWebhooks := api.Group("/webhooks", auth.Middleware(userVerifier))
+	userWebhooks.GET("/events", webhooksHandler.Events)
+	userWebhooks.POST("", webhooksHandler.Create, audit.Annotate("webhooks.create", "webhook", ""))
+	userWebhooks.GET("", webhooksHandler.List)
+	userWebhooks.GET("/:id", webhooksHandler.Get)
+	userWebhooks.DELETE("/:id", webhooksHandler.Delete, audit.Annotate("webhooks.delete", "webhook", "id"))
+	userWebhooks.POST("/:id/ping", webhooksHandler.Ping, audit.Annotate("webhooks.ping", "webhook", "id"))
+	userWebhooks.GET("/:id/deliveries", webhooksHandler.Deliveries)
+	userWebhooks.POST("/:id/deliveries/:deliveryId/replay", webhooksHandler.Replay, audit.Annotate("webhooks.replay", "delivery", "deliveryId"))
+
+	// Admin: operational controls for holders of the admin role
+	admin := api.Group("/admin", auth.Middleware(userVerifier), auth.RequireRole(cfg.Auth.AdminRole))
+	admin.GET("/maintenance", maintenanceHandler.Get)
+	admin.PUT("/maintenance", maintenanceHandler.Update, audit.Annotate("maintenance.update", "maintenance", ""))
+	admin.GET("/audit", auditHandler.List)
+
+	// The local file backend serves its own signed URLs; the signature is the only auth
+	if local, ok := fileStorage.(*files.LocalStorage); ok {
+		e.Any(files.LocalRoutePrefix+"*", echo.WrapHandler(http.StripPrefix(files.LocalRoutePrefix, local)))
+	}
+
+	// Internal routes, called by Cloud Tasks, Cloud Scheduler and Pub/Sub with an OIDC token
+	internal := e.Group("/internal", echo.MiddlewareFunc(internalAuth))
+	internal.POST("/tasks/:type", tasks.Run)
+	internal.POST("/cron/:job", crons.Run)
+	internal.GET("/cron/:job/executions", crons.History)
+	internal.POST("/storage/events", filesHandler.StorageEvent)
+
+	logger.Info("routes registered")
+}
+
+// StartServer starts the HTTP server with lifecycle management. It serves
+// e.Listener when one is set, as tests running on a real listener do, and
+// listens on PORT otherwise.
+func StartServer(lc fx.Lifecycle, cfg *config.Config, e *echo.Echo, logger *zap.Logger) {
+	port := cfg.Port
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Info("starting server", zap.String("port", port))
+			go func() {
+				if err := e.Start(":" + port); err != nil && err != http.ErrServerClosed {
+					logger.Fatal("server error", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Info("shutting down server")
+			return e.Shutdown(ctx)
+		},
+	})
+}
+
+// NewHealthHandler reports Version from the versioned health check
+func NewHealthHandler() *handlers.HealthHandler {
+	return handlers.NewHealthHandler(Version)
+}
+
+// NewMeterProvider exports custom metrics to Cloud Monitoring, or drops
+// them when no exporter is configured
+func NewMeterProvider(lc fx.Lifecycle, cfg *config.Config) (metric.MeterProvider, error) {
+	if cfg.Metrics.Exporter != config.MetricsExporterGCP {
+		return noop.NewMeterProvider(), nil
+	}
+
+	provider, err := metrics.NewCloudMonitoringProvider(context.Background(), cfg.ProjectID, cfg.Metrics.Interval)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: provider.Shutdown,
+	})
+	return provider, nil
+}
+
+// NewCache creates the cache on the configured backend
+func NewCache(lc fx.Lifecycle, cfg *config.Config, clk clock.Clock) (cache.Cache, error) {
+	if cfg.Cache.Backend != config.CacheBackendRedis {
+		return cache.NewMemoryCache(cache.MemoryOptions{MaxEntries: cfg.Cache.MaxEntries, Clock: clk}), nil
+	}
+
+	opts, err := redis.ParseURL(cfg.Cache.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid CACHE_REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+	return cache.NewRedisCache(client, "api:"), nil
+}
+
+// NewResponseCache caches the responses of read-heavy routes
+func NewResponseCache(c cache.Cache, meterProvider metric.MeterProvider, logger *zap.Logger) (*cache.ResponseCache, error) {
+	return cache.NewResponseCache(c, meterProvider.Meter("github.com/your-org/your-app/internal/cache"), logger.Named("cache"))
+}
+
+// NewJobQueue creates the background job queue on the configured backend
+func NewJobQueue(lc fx.Lifecycle, cfg *config.Config, registry *jobs.Registry, deadLetters jobs.Store, clk clock.Clock, logger *zap.Logger) (*jobs.Queue, error) {
+	var backend jobs.Backend
+	switch cfg.Jobs.Backend {
+	case config.JobsBackendCloudTasks:
+		b, err := jobs.NewCloudTasksBackend(context.Background(), jobs.CloudTasksConfig{
+			ProjectID:      cfg.ProjectID,
+			Location:       cfg.Jobs.Location,
+			Queue:          cfg.Jobs.Queue,
+			ServiceURL:     cfg.ServiceURL,
+			ServiceAccount: cfg.Jobs.ServiceAccount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		backend = b
+	default:
+		backend = jobs.NewLocalBackend(registry, logger, jobs.LocalOptions{
+			Workers:     cfg.Jobs.LocalWorkers,
+			MinBackoff:  cfg.Jobs.LocalMinBackoff,
+			MaxBackoff:  cfg.Jobs.LocalMaxBackoff,
+			DeadLetters: deadLetters,
+			Clock:       clk,
+		})
+	}
+
+	queue := jobs.NewQueue(backend, registry, jobs.QueueOptions{Clock: clk})
+	lc.Append(fx.Hook{
+		OnStop: queue.Close,
+	})
+
+	logger.Info("job queue ready", zap.String("backend", cfg.Jobs.Backend))
+	return queue, nil
+}
+
+// NewCronRegistry binds a handler to every job declared in cron.Definitions
+func NewCronRegistry(cfg *config.Config, store cron.Store, accountService *account.Service, clk clock.Clock, logger *zap.Logger) (*cron.Registry, error) {
+	registry, err := cron.NewRegistry(cron.Definitions())
+	if err != nil {
+		return nil, err
+	}
+
+	registry.Register(cron.PruneHistory, cron.PruneHistoryHandler(store, cfg.Cron.HistoryRetention, clk, logger))
+	registry.Register(cron.PurgeDeletedAccounts, accountService.Purge)
+
+	if err := registry.Validate(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// NewCronRunner creates the runner of scheduled jobs
+func NewCronRunner(registry *cron.Registry, store cron.Store, clk clock.Clock, idGen ids.Generator, logger *zap.Logger) *cron.Runner {
+	return cron.NewRunner(registry, store, cron.RunnerOptions{Clock: clk, IDs: idGen}, logger)
+}
+
+// NewAPIKeyService creates the service of API keys, issued with the admin
+// command
+func NewAPIKeyService(store apikeys.Store, clk clock.Clock, idGen ids.Generator, logger *zap.Logger) *apikeys.Service {
+	return apikeys.NewService(store, apikeys.Options{Clock: clk, IDs: idGen}, logger)
+}
+
+// NewUserVerifier verifies API keys and end-user Firebase Auth ID tokens.
+// Outside production a missing Firebase setup is not fatal: every ID token
+// is rejected, so the rest of the API stays usable for local development.
+func NewUserVerifier(cfg *config.Config, keys *apikeys.Service, logger *zap.Logger) (auth.Verifier, error) {
+	client, err := auth.NewFirebaseAuthClient(context.Background(), cfg.Auth.FirebaseProjectID)
+	if err != nil {
+		if cfg.IsProduction() {
+			return nil, err
+		}
+		logger.Warn("firebase auth unavailable, rejecting all user tokens", zap.Error(err))
+		return keys.Verifier(auth.VerifierFunc(func(context.Context, string) (*auth.Principal, error) {
+			return nil, auth.ErrInvalidToken
+		})), nil
+	}
+	return keys.Verifier(auth.NewFirebaseVerifier(client)), nil
+}
+
+// NewFileStorage creates the object storage on the configured backend
+func NewFileStorage(lc fx.Lifecycle, cfg *config.Config, clk clock.Clock, logger *zap.Logger) (files.Storage, error) {
+	if cfg.Files.Backend == config.FilesBackendGCS {
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return client.Close()
+			},
+		})
+		return files.NewGCSStorage(client, cfg.Files.Bucket, files.StorageOptions{Clock: clk}), nil
+	}
+
+	key := []byte(cfg.Files.LocalSigningKey)
+	if len(key) == 0 {
+		// URLs signed before a restart stop working, which is fine locally
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	baseURL := cfg.ServiceURL
+	if baseURL == "" {
+		baseURL = "http://localhost:" + cfg.Port
+	}
+
+	logger.Info("storing files locally", zap.String("dir", cfg.Files.LocalDir))
+	return files.NewLocalStorage(cfg.Files.LocalDir, baseURL, key, files.StorageOptions{Clock: clk})
+}
+
+// NewFilesService creates the file service. With the local backend, uploads
+// are finalized directly instead of through a storage notification.
+func NewFilesService(
+	cfg *config.Config,
+	fileStorage files.Storage,
+	store files.Store,
+	clk clock.Clock,
+	idGen ids.Generator,
+	logger *zap.Logger,
+) *files.Service {
+	service := files.NewService(fileStorage, store, files.Options{
+		Limits: files.Limits{
+			MaxSize:      cfg.Files.MaxSize,
+			AllowedTypes: cfg.Files.AllowedTypes,
+		},
+		URLExpiry: cfg.Files.URLExpiry,
+		Clock:     clk,
+		IDs:       idGen,
+	}, logger)
+
+	if local, ok := fileStorage.(*files.LocalStorage); ok {
+		local.OnFinalize(func(ctx context.Context, attrs files.ObjectAttrs) {
+			if err := service.Finalize(ctx, attrs); err != nil {
+				logger.Error("finalizing upload failed", zap.String("object", attrs.Name), zap.Error(err))
+			}
+		})
+	}
+	return service
+}
+
+// NewImagePipeline creates the image pipeline and makes it the processor of
+// uploads, so images are only ready once stripped and thumbnailed
+func NewImagePipeline(
+	cfg *config.Config,
+	filesService *files.Service,
+	fileStorage files.Storage,
+	store images.Store,
+	queue *jobs.Queue,
+	registry *jobs.Registry,
+	clk clock.Clock,
+	logger *zap.Logger,
+) *images.Pipeline {
+	pipeline := images.NewPipeline(filesService, fileStorage, store, queue, registry, images.Options{
+		Sizes:     cfg.Images.ThumbnailSizes,
+		MaxPixels: cfg.Images.MaxPixels,
+		Quality:   cfg.Images.JPEGQuality,
+		URLExpiry: cfg.Files.URLExpiry,
+		Clock:     clk,
+	}, logger)
+
+	filesService.SetProcessor(pipeline)
+	filesService.OnEvent(pipeline.OnEvent)
+	return pipeline
+}
+
+// NewAccountIdentity disables and deletes Firebase Auth accounts. Like
+// NewUserVerifier, it tolerates a missing Firebase setup outside production.
+func NewAccountIdentity(cfg *config.Config, logger *zap.Logger) (account.Identity, error) {
+	client, err := auth.NewFirebaseAuthClient(context.Background(), cfg.Auth.FirebaseProjectID)
+	if err != nil {
+		if cfg.IsProduction() {
+			return nil, err
+		}
+		logger.Warn("firebase auth unavailable, account deletion leaves sign-in accounts alone", zap.Error(err))
+		return account.NopIdentity{}, nil
+	}
+	return account.NewFirebaseIdentity(client), nil
+}
+
+// NewAccountService creates the data export and account deletion service,
+// which also covers the user's organization memberships
+func NewAccountService(
+	cfg *config.Config,
+	store account.Store,
+	fileStorage files.Storage,
+	identity account.Identity,
+	queue *jobs.Queue,
+	registry *jobs.Registry,
+	orgsService *orgs.Service,
+	keys *apikeys.Service,
+	clk clock.Clock,
+	idGen ids.Generator,
+	logger *zap.Logger,
+) *account.Service {
+	service := account.NewService(store, fileStorage, identity, queue, registry, account.Options{
+		GracePeriod: cfg.Account.DeletionGracePeriod,
+		ExportTTL:   cfg.Account.ExportTTL,
+		URLExpiry:   cfg.Files.URLExpiry,
+		Clock:       clk,
+		IDs:         idGen,
+	}, logger)
+	service.AddSource(orgsSource{service: orgsService})
+	service.AddSource(apiKeysSource{service: keys})
+	return service
+}
+
+// NewAuditRecorder writes audit events to the store and the structured log
+func NewAuditRecorder(store audit.Store, clk clock.Clock, idGen ids.Generator, logger *zap.Logger) *audit.Recorder {
+	return audit.NewRecorder(audit.RecorderOptions{Clock: clk, IDs: idGen}, store, audit.NewLogSink(logger.Named("audit")))
+}
+
+// NewOrgsService creates the organization service, which reads
+// organizations through the cache
+func NewOrgsService(
+	cfg *config.Config,
+	store orgs.Store,
+	c cache.Cache,
+	meterProvider metric.MeterProvider,
+	clk clock.Clock,
+	idGen ids.Generator,
+	logger *zap.Logger,
+) (*orgs.Service, error) {
+	loader, err := cache.NewLoader(c, "orgs", meterProvider.Meter("github.com/your-org/your-app/internal/cache"), logger.Named("cache"))
+	if err != nil {
+		return nil, err
+	}
+	return orgs.NewService(orgs.NewCachedStore(store, loader, cfg.Cache.TTL), orgs.Options{
+		InvitationTTL: cfg.Orgs.InvitationTTL,
+		Clock:         clk,
+		IDs:           idGen,
+	}, logger), nil
+}
+
+// NewWebhookService creates the webhook service and subscribes it to file
+// and organization events, so neither package has to know about webhooks
+func NewWebhookService(
+	cfg *config.Config,
+	store webhooks.Store,
+	queue *jobs.Queue,
+	registry *jobs.Registry,
+	filesService *files.Service,
+	orgsService *orgs.Service,
+	clk clock.Clock,
+	idGen ids.Generator,
+	logger *zap.Logger,
+) *webhooks.Service {
+	guard := &webhooks.Guard{
+		AllowPrivate: cfg.Webhooks.AllowPrivate,
+		RequireHTTPS: cfg.IsProduction(),
+	}
+	service := webhooks.NewService(store, queue, registry, guard, webhooks.Options{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		MinBackoff:  cfg.Webhooks.MinBackoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		Timeout:     cfg.Webhooks.Timeout,
+		Clock:       clk,
+		IDs:         idGen,
+	}, logger)
+
+	filesService.OnEvent(func(ctx context.Context, event string, f files.File) {
+		if err := service.Publish(ctx, f.OwnerID, event, f); err != nil {
+			logger.Error("publishing webhook event failed", zap.String("event", event), zap.String("file_id", f.ID), zap.Error(err))
+		}
+	})
+	orgsService.OnEvent(func(ctx context.Context, event, orgID string, data any) {
+		if err := service.Publish(ctx, webhooks.OrgOwner(orgID), event, data); err != nil {
+			logger.Error("publishing webhook event failed", zap.String("event", event), zap.String("org_id", orgID), zap.Error(err))
+		}
+	})
+	return service
+}
+
+// NewEventBroker creates the in-process event broker, publishes file events
+// to their owner's topic and ends open streams when the server shuts down
+func NewEventBroker(cfg *config.Config, e *echo.Echo, filesService *files.Service, clk clock.Clock, logger *zap.Logger) *events.Broker {
+	broker := events.NewBroker(events.Options{ReplaySize: cfg.Events.ReplaySize, Clock: clk})
+	e.Server.RegisterOnShutdown(broker.Close)
+
+	filesService.OnEvent(func(_ context.Context, event string, f files.File) {
+		if _, err := broker.Publish(events.UserTopic(f.OwnerID), event, f); err != nil && !errors.Is(err, events.ErrClosed) {
+			logger.Error("publishing stream event failed", zap.String("event", event), zap.String("file_id", f.ID), zap.Error(err))
+		}
+	})
+	return broker
+}
+
+// NewStreamOptions tunes event streams from the config
+func NewStreamOptions(cfg *config.Config) handlers.StreamOptions {
+	return handlers.StreamOptions{
+		Heartbeat:   cfg.Events.Heartbeat,
+		MaxDuration: cfg.Events.MaxDuration,
+	}
+}
+
+// NewFlagsService creates the feature flag service on the configured
+// provider and refreshes it in the background. Flags keep their defaults
+// if the provider cannot be read at startup.
+func NewFlagsService(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger) (*flags.Service, error) {
+	var provider flags.Provider = flags.StaticProvider(nil)
+	switch cfg.Flags.Provider {
+	case config.FlagsProviderFile:
+		provider = flags.NewFileProvider(cfg.Flags.File)
+	case config.FlagsProviderRemoteConfig:
+		p, err := flags.NewRemoteConfigProvider(context.Background(), cfg.Auth.FirebaseProjectID, logger)
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	}
+	service := flags.NewService(provider, flags.Catalogue, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			if err := service.Refresh(startCtx); err != nil {
+				logger.Warn("loading feature flags failed, using defaults", zap.Error(err))
+			}
+			go service.Run(ctx, cfg.Flags.Refresh)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return service, nil
+}
+
+// NewMaintenanceController combines the config, admin override and flag
+// sources of maintenance mode and reloads the override in the background
+func NewMaintenanceController(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	store maintenance.Store,
+	flagsService *flags.Service,
+	clk clock.Clock,
+	logger *zap.Logger,
+) *maintenance.Controller {
+	controller := maintenance.NewController(store, maintenance.Options{
+		Mode:    maintenance.Mode(cfg.Maintenance.Mode),
+		Message: cfg.Maintenance.Message,
+		Clock:   clk,
+		// Only the untargeted value counts; per-platform rules are for the apps
+		Flag: func() bool {
+			return flags.Get(flagsService, flags.MaintenanceMode, flags.Target{})
+		},
+	}, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			if err := controller.Refresh(startCtx); err != nil {
+				logger.Warn("loading maintenance state failed", zap.Error(err))
+			}
+			go controller.Run(ctx, cfg.Maintenance.Refresh)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return controller
+}
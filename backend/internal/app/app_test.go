@@ -0,0 +1,36 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/your-org/your-app/internal/config"
+)
+
+func TestModule_ResolvesOnEveryStoreBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+	}{
+		{name: "memory", backend: config.StoreBackendMemory},
+		{name: "firestore", backend: config.StoreBackendFirestore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			cfg.Store.Backend = tt.backend
+
+			// Act
+			err = fx.ValidateApp(Module(cfg), fx.Invoke(StartServer))
+
+			// Assert
+			assert.NoError(t, err)
+		})
+	}
+}
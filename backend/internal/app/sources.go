@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// orgsSource exports and purges a user's organization memberships with
+// their account. The member documents hold the user's email address.
+type orgsSource struct {
+	service *orgs.Service
+}
+
+// Documents implements account.Source
+func (s orgsSource) Documents(ctx context.Context, userID string) ([]account.Document, error) {
+	members, err := s.service.Memberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]account.Document, 0, len(members))
+	for _, m := range members {
+		out = append(out, account.Document{
+			Path: "orgs/" + m.OrgID + "/members/" + m.UserID,
+			Data: map[string]any{
+				"orgId":    m.OrgID,
+				"userId":   m.UserID,
+				"email":    m.Email,
+				"role":     string(m.Role),
+				"joinedAt": m.JoinedAt,
+			},
+		})
+	}
+	return out, nil
+}
+
+// CheckDeletion implements account.Source. Sole owners hand over their
+// organizations before deleting their account.
+func (s orgsSource) CheckDeletion(ctx context.Context, userID string) error {
+	err := s.service.CheckRemoveUser(ctx, userID)
+	if errors.Is(err, orgs.ErrSoleOwner) {
+		return fmt.Errorf("%w: %w", account.ErrDeletionBlocked, err)
+	}
+	return err
+}
+
+// SoftDelete implements account.Source. Memberships stay until the purge,
+// so the organizations keep working during the grace period.
+func (orgsSource) SoftDelete(context.Context, string) error {
+	return nil
+}
+
+// DeleteDocuments implements account.Source
+func (s orgsSource) DeleteDocuments(ctx context.Context, userID string) (int, error) {
+	return s.service.RemoveUser(ctx, userID)
+}
+
+// apiKeysSource exports and purges the API keys acting as a user. Keys live
+// in the top-level apiKeys collection; the export holds their hashes, never
+// the keys themselves.
+type apiKeysSource struct {
+	service *apikeys.Service
+}
+
+// Documents implements account.Source
+func (s apiKeysSource) Documents(ctx context.Context, userID string) ([]account.Document, error) {
+	keys, err := s.service.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]account.Document, 0, len(keys))
+	for _, k := range keys {
+		data := map[string]any{
+			"ownerId":   k.OwnerID,
+			"name":      k.Name,
+			"createdBy": k.CreatedBy,
+			"createdAt": k.CreatedAt,
+		}
+		if k.RevokedAt != nil {
+			data["revokedAt"] = *k.RevokedAt
+		}
+		out = append(out, account.Document{Path: "apiKeys/" + k.ID, Data: data})
+	}
+	return out, nil
+}
+
+// CheckDeletion implements account.Source. Keys never block a deletion.
+func (apiKeysSource) CheckDeletion(context.Context, string) error {
+	return nil
+}
+
+// SoftDelete implements account.Source. Keys are checked without the
+// identity provider, so disabling the sign-in account does not stop them.
+func (s apiKeysSource) SoftDelete(ctx context.Context, userID string) error {
+	_, err := s.service.RevokeAll(ctx, userID)
+	return err
+}
+
+// DeleteDocuments implements account.Source
+func (s apiKeysSource) DeleteDocuments(ctx context.Context, userID string) (int, error) {
+	return s.service.DeleteAll(ctx, userID)
+}
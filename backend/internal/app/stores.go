@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"go.uber.org/fx"
+
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/admin"
+	"github.com/your-org/your-app/internal/apikeys"
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/config"
+	"github.com/your-org/your-app/internal/cron"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/images"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/maintenance"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/store"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+// StoreModule provides every subsystem store on the configured backend
+func StoreModule(cfg *config.Config) fx.Option {
+	if cfg.Store.Backend == config.StoreBackendMemory {
+		return fx.Provide(
+			fx.Annotate(account.NewMemoryStore, fx.As(new(account.Store))),
+			fx.Annotate(admin.NewMemoryStore, fx.As(new(admin.Store))),
+			fx.Annotate(apikeys.NewMemoryStore, fx.As(new(apikeys.Store))),
+			fx.Annotate(audit.NewMemoryStore, fx.As(new(audit.Store))),
+			fx.Annotate(cron.NewMemoryStore, fx.As(new(cron.Store))),
+			fx.Annotate(files.NewMemoryStore, fx.As(new(files.Store))),
+			fx.Annotate(images.NewMemoryStore, fx.As(new(images.Store))),
+			fx.Annotate(jobs.NewMemoryStore, fx.As(new(jobs.Store))),
+			fx.Annotate(maintenance.NewMemoryStore, fx.As(new(maintenance.Store))),
+			fx.Annotate(orgs.NewMemoryStore, fx.As(new(orgs.Store))),
+			orgs.NewMemoryBackend,
+			fx.Annotate(webhooks.NewMemoryStore, fx.As(new(webhooks.Store))),
+		)
+	}
+
+	return fx.Provide(
+		NewFirestoreClient,
+		fx.Annotate(account.NewFirestoreStore, fx.As(new(account.Store))),
+		fx.Annotate(admin.NewFirestoreStore, fx.As(new(admin.Store))),
+		fx.Annotate(apikeys.NewFirestoreStore, fx.As(new(apikeys.Store))),
+		fx.Annotate(audit.NewFirestoreStore, fx.As(new(audit.Store))),
+		fx.Annotate(cron.NewFirestoreStore, fx.As(new(cron.Store))),
+		fx.Annotate(files.NewFirestoreStore, fx.As(new(files.Store))),
+		fx.Annotate(images.NewFirestoreStore, fx.As(new(images.Store))),
+		fx.Annotate(jobs.NewFirestoreStore, fx.As(new(jobs.Store))),
+		fx.Annotate(maintenance.NewFirestoreStore, fx.As(new(maintenance.Store))),
+		fx.Annotate(orgs.NewFirestoreStore, fx.As(new(orgs.Store))),
+		orgs.NewFirestoreBackend,
+		fx.Annotate(webhooks.NewFirestoreStore, fx.As(new(webhooks.Store))),
+	)
+}
+
+// NewFirestoreClient connects to Firestore and closes the client on shutdown
+func NewFirestoreClient(lc fx.Lifecycle, cfg *config.Config) (*firestore.Client, error) {
+	client, err := store.NewFirestoreClient(context.Background(), cfg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+	return client, nil
+}
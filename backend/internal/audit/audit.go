@@ -0,0 +1,238 @@
+// Package audit records who changed what.
+//
+// An Event names the actor, the action, its target, the fields it changed
+// and the request it came from. A Recorder writes every event to a set of
+// sinks: the Store, an append-only collection admins can query, and the
+// structured log, so events survive a Store outage. Middleware records an
+// event for every mutating API request; routes name their action with
+// Annotate, and handlers add what only they know with SetTarget and
+// SetChanges.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/ids"
+)
+
+// redacted replaces the values of secret fields in changes
+const redacted = "[redacted]"
+
+// secretFields are lower-case substrings of field names whose values never
+// reach the audit log
+var secretFields = []string{"secret", "token", "password"}
+
+// Action names what happened, such as "files.delete"
+type Action string
+
+// Outcome tells whether the action took effect
+type Outcome string
+
+// Outcomes of an action
+const (
+	// OutcomeSuccess is an action that took effect
+	OutcomeSuccess Outcome = "success"
+	// OutcomeDenied is an action refused for missing or insufficient credentials
+	OutcomeDenied Outcome = "denied"
+	// OutcomeFailure is an action that failed for any other reason
+	OutcomeFailure Outcome = "failure"
+)
+
+// Valid reports whether o is a known outcome
+func (o Outcome) Valid() bool {
+	return o == OutcomeSuccess || o == OutcomeDenied || o == OutcomeFailure
+}
+
+// Actor is who performed the action. A zero Actor is an anonymous caller.
+type Actor struct {
+	ID    string `firestore:"id" json:"id,omitempty"`
+	Email string `firestore:"email,omitempty" json:"email,omitempty"`
+}
+
+// Target is what the action was performed on
+type Target struct {
+	// Type is the kind of resource, such as "file"
+	Type string `firestore:"type" json:"type,omitempty"`
+	ID   string `firestore:"id" json:"id,omitempty"`
+}
+
+// Change is one field changed by the action. Nested fields are joined
+// with dots; values of secret fields are redacted.
+type Change struct {
+	Field  string `firestore:"field" json:"field"`
+	Before any    `firestore:"before" json:"before"`
+	After  any    `firestore:"after" json:"after"`
+}
+
+// Request is the HTTP request an action came from
+type Request struct {
+	ID     string `firestore:"id" json:"id,omitempty"`
+	IP     string `firestore:"ip" json:"ip,omitempty"`
+	Method string `firestore:"method" json:"method,omitempty"`
+	// Route is the matched route pattern, such as "/api/v1/files/:id"
+	Route     string `firestore:"route" json:"route,omitempty"`
+	Status    int    `firestore:"status" json:"status,omitempty"`
+	UserAgent string `firestore:"userAgent" json:"userAgent,omitempty"`
+}
+
+// Event is one recorded action
+type Event struct {
+	ID     string    `firestore:"-" json:"id"`
+	Time   time.Time `firestore:"time" json:"time"`
+	Action Action    `firestore:"action" json:"action"`
+	Actor  Actor     `firestore:"actor" json:"actor"`
+	Target Target    `firestore:"target" json:"target"`
+	// OrgID is the organization the action was performed in, if any
+	OrgID   string   `firestore:"orgId" json:"orgId,omitempty"`
+	Outcome Outcome  `firestore:"outcome" json:"outcome"`
+	Changes []Change `firestore:"changes" json:"changes,omitempty"`
+	Request Request  `firestore:"request" json:"request"`
+}
+
+// Sink receives recorded events
+type Sink interface {
+	Write(ctx context.Context, e Event) error
+}
+
+// RecorderOptions configures a Recorder
+type RecorderOptions struct {
+	// Clock and IDs default to the wall clock and crypto/rand; events are
+	// stamped by Clock and named by IDs
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// Recorder stamps events and writes them to every sink
+type Recorder struct {
+	sinks []Sink
+	now   func() time.Time
+	ids   ids.Generator
+}
+
+// NewRecorder creates a recorder writing to sinks, in order
+func NewRecorder(opts RecorderOptions, sinks ...Sink) *Recorder {
+	return &Recorder{sinks: sinks, now: clock.OrSystem(opts.Clock).Now, ids: ids.OrRandom(opts.IDs)}
+}
+
+// Record assigns e an ID and time, unless set, and writes it to every sink.
+// A failing sink does not stop the others; their errors are joined.
+func (r *Recorder) Record(ctx context.Context, e Event) error {
+	if e.Action == "" {
+		return errors.New("audit: event without action")
+	}
+	if e.ID == "" {
+		e.ID = r.ids.NewID(10)
+	}
+	if e.Time.IsZero() {
+		e.Time = r.now().UTC()
+	}
+	if e.Outcome == "" {
+		e.Outcome = OutcomeSuccess
+	}
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Write(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Diff returns the fields that differ between before and after, compared
+// through their JSON encoding. Either side may be nil for a created or
+// deleted resource.
+func Diff(before, after any) ([]Change, error) {
+	b, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]struct{}, len(b)+len(a))
+	for f := range b {
+		fields[f] = struct{}{}
+	}
+	for f := range a {
+		fields[f] = struct{}{}
+	}
+
+	var changes []Change
+	for f := range fields {
+		if reflect.DeepEqual(b[f], a[f]) {
+			continue
+		}
+		c := Change{Field: f, Before: b[f], After: a[f]}
+		if secret(f) {
+			c.Before, c.After = redactValue(c.Before), redactValue(c.After)
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// flatten encodes v as JSON and maps every leaf to its dotted path
+func flatten(v any) (map[string]any, error) {
+	out := make(map[string]any)
+	if v == nil {
+		return out, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("audit: diff: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("audit: diff: %w", err)
+	}
+
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		m, ok := v.(map[string]any)
+		if !ok || len(m) == 0 {
+			if prefix != "" {
+				out[prefix] = v
+			}
+			return
+		}
+		for k, child := range m {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			walk(k, child)
+		}
+	}
+	walk("", decoded)
+	return out, nil
+}
+
+func secret(field string) bool {
+	field = strings.ToLower(field)
+	for _, s := range secretFields {
+		if strings.Contains(field, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue hides a secret but keeps whether it was set
+func redactValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write(context.Context, Event) error {
+	return errors.New("unavailable")
+}
+
+func TestRecorder_Record(t *testing.T) {
+	// Arrange
+	st := NewMemoryStore()
+	r := NewRecorder(RecorderOptions{}, failingSink{}, st)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	// Act
+	err := r.Record(context.Background(), Event{Action: "files.delete", Actor: Actor{ID: "alice"}})
+
+	// Assert
+	require.Error(t, err, "the failing sink is reported")
+	page, qerr := st.Query(context.Background(), Filter{})
+	require.NoError(t, qerr)
+	require.Len(t, page.Events, 1, "the other sinks still receive the event")
+	got := page.Events[0]
+	assert.NotEmpty(t, got.ID)
+	assert.Equal(t, now, got.Time)
+	assert.Equal(t, OutcomeSuccess, got.Outcome)
+}
+
+func TestRecorder_Record_RequiresAction(t *testing.T) {
+	// Act
+	err := NewRecorder(RecorderOptions{}, NewMemoryStore()).Record(context.Background(), Event{})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	type limits struct {
+		Max int `json:"max"`
+	}
+	type settings struct {
+		Name   string  `json:"name"`
+		Secret string  `json:"signingSecret,omitempty"`
+		Limits limits  `json:"limits"`
+		Until  *string `json:"until"`
+	}
+
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   []Change
+	}{
+		{
+			name:   "unchanged",
+			before: settings{Name: "a"},
+			after:  settings{Name: "a"},
+		},
+		{
+			name:   "nested field",
+			before: settings{Name: "a", Limits: limits{Max: 1}},
+			after:  settings{Name: "b", Limits: limits{Max: 2}},
+			want: []Change{
+				{Field: "limits.max", Before: float64(1), After: float64(2)},
+				{Field: "name", Before: "a", After: "b"},
+			},
+		},
+		{
+			name:   "secret redacted",
+			before: settings{Secret: "old"},
+			after:  settings{Secret: "new"},
+			want:   []Change{{Field: "signingSecret", Before: redacted, After: redacted}},
+		},
+		{
+			name:  "created",
+			after: map[string]any{"role": "admin"},
+			want:  []Change{{Field: "role", Before: nil, After: "admin"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := Diff(tt.before, tt.after)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryStore_Query(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	st := NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	seed := []Event{
+		{ID: "e1", Time: base, Action: "files.delete", Actor: Actor{ID: "alice"}, Outcome: OutcomeSuccess},
+		{ID: "e2", Time: base.Add(time.Minute), Action: "orgs.create", Actor: Actor{ID: "bob"}, Outcome: OutcomeSuccess},
+		{ID: "e3", Time: base.Add(2 * time.Minute), Action: "files.delete", Actor: Actor{ID: "alice"}, Outcome: OutcomeDenied},
+		{ID: "e4", Time: base.Add(2 * time.Minute), Action: "orgs.member.update", OrgID: "acme", Target: Target{Type: "member", ID: "bob"}},
+	}
+	for _, e := range seed {
+		require.NoError(t, st.Write(ctx, e))
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "all, newest first", filter: Filter{}, want: []string{"e4", "e3", "e2", "e1"}},
+		{name: "by actor", filter: Filter{ActorID: "alice"}, want: []string{"e3", "e1"}},
+		{name: "by action and outcome", filter: Filter{Action: "files.delete", Outcome: OutcomeDenied}, want: []string{"e3"}},
+		{name: "by target", filter: Filter{TargetType: "member", TargetID: "bob"}, want: []string{"e4"}},
+		{name: "by organization", filter: Filter{OrgID: "acme"}, want: []string{"e4"}},
+		{name: "time range", filter: Filter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, want: []string{"e2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			page, err := st.Query(ctx, tt.filter)
+
+			// Assert
+			require.NoError(t, err)
+			var ids []string
+			for _, e := range page.Events {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Empty(t, page.NextCursor)
+		})
+	}
+}
+
+func TestMemoryStore_Query_Pages(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	st := NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		// Two events share each time so the ID breaks ties
+		require.NoError(t, st.Write(ctx, Event{ID: id, Time: base.Add(time.Duration(i/2) * time.Second), Action: "x"}))
+	}
+
+	// Act
+	var ids []string
+	cursor := ""
+	pages := 0
+	for {
+		page, err := st.Query(ctx, Filter{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		pages++
+		for _, e := range page.Events {
+			ids = append(ids, e.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// Assert
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, ids)
+	assert.Equal(t, 3, pages)
+}
+
+func TestMemoryStore_Query_InvalidCursor(t *testing.T) {
+	// Act
+	_, err := NewMemoryStore().Query(context.Background(), Filter{Cursor: "not a cursor"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
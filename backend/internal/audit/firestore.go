@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+)
+
+// collection holds one document per event. Clients cannot read it; see
+// firestore.rules.
+const collection = "auditEvents"
+
+// FirestoreStore keeps events in auditEvents/{eventId}. Documents are only
+// ever created, never updated or deleted.
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+// Write implements Sink. Create fails rather than overwrite an existing event.
+func (s *FirestoreStore) Write(ctx context.Context, e Event) error {
+	if _, err := s.client.Collection(collection).Doc(e.ID).Create(ctx, e); err != nil {
+		return fmt.Errorf("audit: write event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// Query implements Store. Each combination of equality filters needs a
+// composite index with time descending; see firestore.indexes.json.
+func (s *FirestoreStore) Query(ctx context.Context, f Filter) (*Page, error) {
+	after, err := decodeCursor(f.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	events := s.client.Collection(collection)
+	query := events.Query
+	for _, eq := range []struct {
+		path  string
+		value string
+	}{
+		{"actor.id", f.ActorID},
+		{"action", string(f.Action)},
+		{"target.type", f.TargetType},
+		{"target.id", f.TargetID},
+		{"orgId", f.OrgID},
+		{"outcome", string(f.Outcome)},
+	} {
+		if eq.value != "" {
+			query = query.Where(eq.path, "==", eq.value)
+		}
+	}
+	if !f.Since.IsZero() {
+		query = query.Where("time", ">=", f.Since)
+	}
+	if !f.Until.IsZero() {
+		query = query.Where("time", "<", f.Until)
+	}
+	query = query.OrderBy("time", firestore.Desc).OrderBy(firestore.DocumentID, firestore.Desc)
+	if after != nil {
+		query = query.StartAfter(after.time, events.Doc(after.id))
+	}
+	if f.Limit > 0 {
+		// One extra event tells whether another page follows
+		query = query.Limit(f.Limit + 1)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("audit: query events: %w", err)
+	}
+
+	page := &Page{Events: make([]Event, 0, len(docs))}
+	for _, doc := range docs {
+		var e Event
+		if err := doc.DataTo(&e); err != nil {
+			return nil, err
+		}
+		e.ID = doc.Ref.ID
+		page.Events = append(page.Events, e)
+	}
+	if f.Limit > 0 && len(page.Events) > f.Limit {
+		page.Events = page.Events[:f.Limit]
+		page.NextCursor = encodeCursor(page.Events[f.Limit-1])
+	}
+	return page, nil
+}
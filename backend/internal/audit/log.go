@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogSink writes events to the structured log, where they reach Cloud
+// Logging independently of the Store
+type LogSink struct {
+	logger *zap.Logger
+}
+
+// NewLogSink creates a sink logging to logger
+func NewLogSink(logger *zap.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Write implements Sink
+func (s *LogSink) Write(_ context.Context, e Event) error {
+	s.logger.Info("audit event",
+		zap.String("audit_id", e.ID),
+		zap.Time("time", e.Time),
+		zap.String("action", string(e.Action)),
+		zap.String("actor_id", e.Actor.ID),
+		zap.String("actor_email", e.Actor.Email),
+		zap.String("target_type", e.Target.Type),
+		zap.String("target_id", e.Target.ID),
+		zap.String("org_id", e.OrgID),
+		zap.String("outcome", string(e.Outcome)),
+		zap.Any("changes", e.Changes),
+		zap.String("request_id", e.Request.ID),
+		zap.String("remote_ip", e.Request.IP),
+		zap.String("method", e.Request.Method),
+		zap.String("route", e.Request.Route),
+		zap.Int("status", e.Request.Status),
+	)
+	return nil
+}
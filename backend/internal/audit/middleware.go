@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// contextKey stores the request's pending event details on the echo context
+const contextKey = "audit.entry"
+
+// writeTimeout bounds recording an event after the response was sent
+const writeTimeout = 5 * time.Second
+
+// maxClientValue bounds the client-supplied strings kept on an event, such
+// as the User-Agent, so a large header cannot push it past a store's limit
+const maxClientValue = 512
+
+// entry collects what routes and handlers know about the request's event
+type entry struct {
+	action      Action
+	targetType  string
+	targetParam string
+	targetID    string
+	changes     []Change
+}
+
+// MiddlewareOptions configures which requests are recorded
+type MiddlewareOptions struct {
+	// Prefix limits recording to paths under it, such as "/api/v1"
+	Prefix string
+}
+
+// Middleware records an event for every POST, PUT, PATCH and DELETE request
+// matching a route under the prefix, whatever its outcome. Requests no
+// route matches are not recorded. The action is
+// the one annotated on the route, or "<METHOD> <route>" for requests
+// rejected before reaching the annotation, such as by auth middleware on
+// the route's group. Recording failures are logged, never returned; the
+// response has been written by then.
+func Middleware(r *Recorder, opts MiddlewareOptions, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !mutating(req.Method) || !strings.HasPrefix(req.URL.Path, opts.Prefix) || c.Path() == "" {
+				return next(c)
+			}
+
+			pending := &entry{}
+			c.Set(contextKey, pending)
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status == http.StatusMethodNotAllowed || pending.action == "" && strings.HasSuffix(c.Path(), "/*") {
+				// No route for the request, only the catch-all echo adds to
+				// groups with middleware: nothing was acted on
+				return nil
+			}
+
+			e := Event{
+				Action:  pending.action,
+				Target:  Target{Type: pending.targetType, ID: pending.targetID},
+				Outcome: outcome(status),
+				Changes: pending.changes,
+				Request: Request{
+					ID:        c.Response().Header().Get(echo.HeaderXRequestID),
+					IP:        clientValue(c.RealIP()),
+					Method:    req.Method,
+					Route:     c.Path(),
+					Status:    status,
+					UserAgent: clientValue(req.UserAgent()),
+				},
+			}
+			if e.Action == "" {
+				e.Action = Action(req.Method + " " + c.Path())
+			}
+			if e.Target.ID == "" && pending.targetParam != "" {
+				e.Target.ID = c.Param(pending.targetParam)
+			}
+			e.Target.ID = clientValue(e.Target.ID)
+			if p := auth.PrincipalFrom(c); p != nil {
+				e.Actor = Actor{ID: p.Subject, Email: p.Email}
+			}
+			if t, ok := orgs.TenantFrom(c); ok {
+				e.OrgID = t.OrgID()
+			}
+
+			// The client may be gone already; the event is still recorded
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), writeTimeout)
+			defer cancel()
+			if err := r.Record(ctx, e); err != nil {
+				logger.Error("audit event not recorded",
+					zap.String("action", string(e.Action)),
+					zap.String("request_id", e.Request.ID),
+					zap.Error(err),
+				)
+			}
+			return nil
+		}
+	}
+}
+
+// Annotate names the action of a route. targetType names the kind of
+// resource it acts on and targetParam the path parameter holding its ID;
+// either may be empty.
+func Annotate(action Action, targetType, targetParam string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if pending := pendingEntry(c); pending != nil {
+				pending.action = action
+				pending.targetType = targetType
+				pending.targetParam = targetParam
+			}
+			return next(c)
+		}
+	}
+}
+
+// SetTarget sets the ID of the request's target, for routes whose target
+// is not in the path, such as creations
+func SetTarget(c echo.Context, id string) {
+	if pending := pendingEntry(c); pending != nil {
+		pending.targetID = id
+	}
+}
+
+// SetChanges records the difference between the target before and after
+// the request. Failing to compute it is not an error for the request; the
+// event is recorded without changes.
+func SetChanges(c echo.Context, before, after any) {
+	pending := pendingEntry(c)
+	if pending == nil {
+		return
+	}
+	changes, err := Diff(before, after)
+	if err != nil {
+		return
+	}
+	pending.changes = changes
+}
+
+func pendingEntry(c echo.Context) *entry {
+	pending, _ := c.Get(contextKey).(*entry)
+	return pending
+}
+
+// clientValue makes a string from the client storable: valid UTF-8, which
+// Firestore requires, and at most maxClientValue bytes
+func clientValue(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if len(s) <= maxClientValue {
+		return s
+	}
+	s = s[:maxClientValue]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func outcome(status int) Outcome {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+func TestMiddleware(t *testing.T) {
+	type settings struct {
+		Mode string `json:"mode"`
+	}
+
+	// Arrange
+	st := NewMemoryStore()
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	e.Use(Middleware(NewRecorder(RecorderOptions{}, st), MiddlewareOptions{Prefix: "/api"}, zap.NewNop()))
+	api := e.Group("/api", auth.Middleware(auth.StaticVerifier{
+		"alice-token": {Subject: "alice", Email: "alice@example.com"},
+	}))
+	api.DELETE("/files/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, Annotate("files.delete", "file", "id"))
+	api.POST("/files", func(c echo.Context) error {
+		SetTarget(c, "f2")
+		return echo.NewHTTPError(http.StatusConflict, "exists")
+	}, Annotate("files.upload", "file", ""))
+	api.PUT("/settings", func(c echo.Context) error {
+		SetChanges(c, settings{Mode: "off"}, settings{Mode: "read_only"})
+		return c.NoContent(http.StatusOK)
+	})
+	api.GET("/files/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, Annotate("files.get", "file", "id"))
+	e.POST("/internal/tasks", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		want   *Event
+	}{
+		{
+			name:   "annotated route",
+			method: http.MethodDelete, target: "/api/files/f1", token: "alice-token",
+			want: &Event{
+				Action:  "files.delete",
+				Actor:   Actor{ID: "alice", Email: "alice@example.com"},
+				Target:  Target{Type: "file", ID: "f1"},
+				Outcome: OutcomeSuccess,
+				Request: Request{Method: http.MethodDelete, Route: "/api/files/:id", Status: http.StatusNoContent},
+			},
+		},
+		{
+			name:   "target set by handler on failure",
+			method: http.MethodPost, target: "/api/files", token: "alice-token",
+			want: &Event{
+				Action:  "files.upload",
+				Actor:   Actor{ID: "alice", Email: "alice@example.com"},
+				Target:  Target{Type: "file", ID: "f2"},
+				Outcome: OutcomeFailure,
+				Request: Request{Method: http.MethodPost, Route: "/api/files", Status: http.StatusConflict},
+			},
+		},
+		{
+			name:   "unannotated route with changes",
+			method: http.MethodPut, target: "/api/settings", token: "alice-token",
+			want: &Event{
+				Action:  "PUT /api/settings",
+				Actor:   Actor{ID: "alice", Email: "alice@example.com"},
+				Outcome: OutcomeSuccess,
+				Changes: []Change{{Field: "mode", Before: "off", After: "read_only"}},
+				Request: Request{Method: http.MethodPut, Route: "/api/settings", Status: http.StatusOK},
+			},
+		},
+		{
+			name:   "rejected before the annotation",
+			method: http.MethodDelete, target: "/api/files/f1", token: "wrong-token",
+			want: &Event{
+				Action:  "DELETE /api/files/:id",
+				Outcome: OutcomeDenied,
+				Request: Request{Method: http.MethodDelete, Route: "/api/files/:id", Status: http.StatusUnauthorized},
+			},
+		},
+		{name: "read", method: http.MethodGet, target: "/api/files/f1", token: "alice-token"},
+		{name: "outside the prefix", method: http.MethodPost, target: "/internal/tasks"},
+		{name: "unknown route", method: http.MethodPost, target: "/api/nothing", token: "alice-token"},
+		{name: "method not allowed", method: http.MethodPatch, target: "/api/files/f1", token: "alice-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := st.Query(context.Background(), Filter{})
+			require.NoError(t, err)
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			page, err := st.Query(context.Background(), Filter{})
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Len(t, page.Events, len(before.Events))
+				return
+			}
+			require.Len(t, page.Events, len(before.Events)+1)
+			got := page.Events[0]
+			assert.NotEmpty(t, got.ID)
+			assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), got.Request.ID)
+			assert.NotEmpty(t, got.Request.IP)
+			got.ID, got.Time, got.Request.ID, got.Request.IP = "", tt.want.Time, "", ""
+			assert.Equal(t, *tt.want, got)
+		})
+	}
+}
+
+func TestClientValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "short", value: "curl/8.0", expected: "curl/8.0"},
+		{name: "invalid UTF-8", value: "a\xffb", expected: "a\uFFFDb"},
+		{name: "too long", value: strings.Repeat("a", maxClientValue+1), expected: strings.Repeat("a", maxClientValue)},
+		{
+			// 171 three-byte characters end at byte 513; the one split by
+			// the limit is dropped whole
+			name:     "multibyte character split at the limit",
+			value:    strings.Repeat("\u4e16", 171),
+			expected: strings.Repeat("\u4e16", 170),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, clientValue(tt.value))
+		})
+	}
+}
+
+// FuzzMiddleware checks that events keep client-supplied values storable,
+// whatever the request path and User-Agent hold
+func FuzzMiddleware(f *testing.F) {
+	f.Add(http.MethodDelete, "f1", "curl/8.0", uint16(1))
+	f.Add(http.MethodDelete, "%ff%fe", "\xffagent", uint16(1000))
+
+	f.Fuzz(func(t *testing.T, method, id, userAgent string, repeat uint16) {
+		// Arrange
+		st := NewMemoryStore()
+		e := echo.New()
+		e.Use(Middleware(NewRecorder(RecorderOptions{}, st), MiddlewareOptions{Prefix: "/api"}, zap.NewNop()))
+		e.Any("/api/files/:id", func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		}, Annotate("files.delete", "file", "id"))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Method = method
+		req.URL.Path = "/api/files/" + id
+		req.Header.Set("User-Agent", strings.Repeat(userAgent, int(repeat)))
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		page, err := st.Query(context.Background(), Filter{})
+		require.NoError(t, err)
+		if len(page.Events) == 0 {
+			return
+		}
+		require.Len(t, page.Events, 1)
+		got := page.Events[0]
+		assert.True(t, mutating(method))
+		for _, s := range []string{got.Target.ID, got.Request.UserAgent, got.Request.IP} {
+			assert.True(t, utf8.ValidString(s), "invalid UTF-8 in %q", s)
+			assert.LessOrEqual(t, len(s), maxClientValue)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidCursor is returned for cursors not issued by a previous query
+var ErrInvalidCursor = errors.New("audit: invalid cursor")
+
+// Filter selects events. Empty fields match everything.
+type Filter struct {
+	ActorID    string
+	Action     Action
+	TargetType string
+	TargetID   string
+	OrgID      string
+	Outcome    Outcome
+	// Since and Until bound the event time, inclusive and exclusive
+	Since time.Time
+	Until time.Time
+	// Limit is the page size
+	Limit int
+	// Cursor continues after the last event of a previous page
+	Cursor string
+}
+
+// Page is one page of events, newest first
+type Page struct {
+	Events []Event `json:"events"`
+	// NextCursor fetches the following page; empty on the last one
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Store is an append-only event log that can be queried. Events are never
+// updated or deleted through it.
+type Store interface {
+	Sink
+	// Query returns the events matching f, newest first
+	Query(ctx context.Context, f Filter) (*Page, error)
+}
+
+// cursor is the position after an event in time-then-ID order
+type cursor struct {
+	time time.Time
+	id   string
+}
+
+func encodeCursor(e Event) string {
+	raw := strconv.FormatInt(e.Time.UnixNano(), 10) + ":" + e.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor{time: time.Unix(0, n).UTC(), id: id}, nil
+}
+
+// newer orders events newest first, breaking ties by descending ID
+func newer(a, b Event) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.After(b.Time)
+	}
+	return a.ID > b.ID
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Write implements Sink
+func (s *MemoryStore) Write(_ context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+// Query implements Store
+func (s *MemoryStore) Query(_ context.Context, f Filter) (*Page, error) {
+	after, err := decodeCursor(f.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var matched []Event
+	for _, e := range s.events {
+		if matches(e, f) && (after == nil || newer(Event{Time: after.time, ID: after.id}, e)) {
+			matched = append(matched, e)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(matched, func(i, j int) bool { return newer(matched[i], matched[j]) })
+
+	page := &Page{Events: matched}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		page.Events = matched[:f.Limit]
+		page.NextCursor = encodeCursor(page.Events[f.Limit-1])
+	}
+	return page, nil
+}
+
+func matches(e Event, f Filter) bool {
+	switch {
+	case f.ActorID != "" && e.Actor.ID != f.ActorID,
+		f.Action != "" && e.Action != f.Action,
+		f.TargetType != "" && e.Target.Type != f.TargetType,
+		f.TargetID != "" && e.Target.ID != f.TargetID,
+		f.OrgID != "" && e.OrgID != f.OrgID,
+		f.Outcome != "" && e.Outcome != f.Outcome,
+		!f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+// Package auth authenticates bearer tokens on incoming requests
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrInvalidToken is returned by verifiers for missing, malformed or rejected tokens
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+// contextKey is the echo context key holding the authenticated principal
+const contextKey = "auth.principal"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject is the stable identifier of the caller (Firebase UID or service account ID)
+	Subject string
+	// Email is the verified email address of the caller, if any
+	Email string
+	// Claims holds the remaining token claims
+	Claims map[string]any
+}
+
+// Verifier validates a bearer token and returns its principal
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// VerifierFunc adapts a function to the Verifier interface
+type VerifierFunc func(ctx context.Context, token string) (*Principal, error)
+
+// Verify calls f(ctx, token)
+func (f VerifierFunc) Verify(ctx context.Context, token string) (*Principal, error) {
+	return f(ctx, token)
+}
+
+// StaticVerifier accepts a fixed set of tokens. It backs the shared internal
+// token used in local development and the fake tokens used in tests.
+type StaticVerifier map[string]*Principal
+
+// Verify looks the token up in the map
+func (v StaticVerifier) Verify(_ context.Context, token string) (*Principal, error) {
+	p, ok := v[token]
+	if !ok || token == "" {
+		return nil, ErrInvalidToken
+	}
+	return p, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer" header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Middleware rejects requests without a valid bearer token and stores the
+// principal on the context for handlers
+func Middleware(v Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := BearerToken(c.Request())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+
+			principal, err := v.Verify(c.Request().Context(), token)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid bearer token")
+			}
+
+			c.Set(contextKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// Optional authenticates the request when it carries a bearer token and lets
+// anonymous requests through. An invalid token is still rejected.
+func Optional(v Verifier) echo.MiddlewareFunc {
+	required := Middleware(v)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := required(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
+// PrincipalFrom returns the principal stored by Middleware, or nil
+func PrincipalFrom(c echo.Context) *Principal {
+	p, _ := c.Get(contextKey).(*Principal)
+	return p
+}
+
+// HasRole reports whether p holds role through a Firebase custom claim:
+// {"<role>": true}, {"role": "<role>"} or {"roles": ["<role>", ...]}
+func HasRole(p *Principal, role string) bool {
+	if p == nil || role == "" {
+		return false
+	}
+	if v, _ := p.Claims[role].(bool); v {
+		return true
+	}
+	if v, _ := p.Claims["role"].(string); v == role {
+		return true
+	}
+	roles, _ := p.Claims["roles"].([]any)
+	for _, r := range roles {
+		if v, _ := r.(string); v == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole rejects principals without role. Place it after Middleware.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasRole(PrincipalFrom(c), role) {
+				return echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
+			}
+			return next(c)
+		}
+	}
+}
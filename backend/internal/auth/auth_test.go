@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/config"
+)
+
+func TestMiddleware(t *testing.T) {
+	verifier := StaticVerifier{
+		"good-token": {Subject: "user-1", Email: "user@example.com"},
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+		expectedUser   string
+	}{
+		{
+			name:           "accepts known token",
+			header:         "Bearer good-token",
+			expectedStatus: http.StatusOK,
+			expectedUser:   "user-1",
+		},
+		{
+			name:           "accepts lowercase scheme",
+			header:         "bearer good-token",
+			expectedStatus: http.StatusOK,
+			expectedUser:   "user-1",
+		},
+		{
+			name:           "rejects missing header",
+			header:         "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects other schemes",
+			header:         "Basic good-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects unknown token",
+			header:         "Bearer bad-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			var user string
+			e.GET("/", func(c echo.Context) error {
+				user = PrincipalFrom(c).Subject
+				return c.NoContent(http.StatusOK)
+			}, Middleware(verifier))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedUser, user)
+		})
+	}
+}
+
+func TestOptional(t *testing.T) {
+	verifier := StaticVerifier{
+		"good-token": {Subject: "user-1"},
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+		expectedUser   string
+	}{
+		{
+			name:           "authenticates known token",
+			header:         "Bearer good-token",
+			expectedStatus: http.StatusOK,
+			expectedUser:   "user-1",
+		},
+		{
+			name:           "allows anonymous request",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects invalid token",
+			header:         "Bearer bad-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			var user string
+			e.GET("/", func(c echo.Context) error {
+				if p := PrincipalFrom(c); p != nil {
+					user = p.Subject
+				}
+				return c.NoContent(http.StatusOK)
+			}, Optional(verifier))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedUser, user)
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		expected  bool
+	}{
+		{name: "nil principal", principal: nil, expected: false},
+		{name: "no claims", principal: &Principal{Subject: "u"}, expected: false},
+		{name: "boolean claim", principal: &Principal{Claims: map[string]any{"admin": true}}, expected: true},
+		{name: "false boolean claim", principal: &Principal{Claims: map[string]any{"admin": false}}, expected: false},
+		{name: "role claim", principal: &Principal{Claims: map[string]any{"role": "admin"}}, expected: true},
+		{name: "other role", principal: &Principal{Claims: map[string]any{"role": "support"}}, expected: false},
+		{name: "roles claim", principal: &Principal{Claims: map[string]any{"roles": []any{"support", "admin"}}}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := HasRole(tt.principal, "admin")
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNewInternal_StaticToken(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{InternalAuth: config.InternalAuthConfig{Token: "dev-secret"}}
+	guard, err := NewInternal(cfg)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.POST("/internal/ping", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, echo.MiddlewareFunc(guard))
+
+	// Act
+	allowed := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/internal/ping", nil)
+	req.Header.Set("Authorization", "Bearer dev-secret")
+	e.ServeHTTP(allowed, req)
+
+	denied := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/internal/ping", nil)
+	req.Header.Set("Authorization", "Bearer guess")
+	e.ServeHTTP(denied, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, allowed.Code)
+	assert.Equal(t, http.StatusUnauthorized, denied.Code)
+}
+
+func TestNewInternal_RejectsAllWhenUnconfigured(t *testing.T) {
+	// Arrange
+	guard, err := NewInternal(&config.Config{})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.POST("/internal/ping", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, echo.MiddlewareFunc(guard))
+
+	req := httptest.NewRequest(http.MethodPost, "/internal/ping", nil)
+	req.Header.Set("Authorization", "Bearer anything")
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
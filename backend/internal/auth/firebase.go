@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	firebase "firebase.google.com/go/v4"
+	firebaseauth "firebase.google.com/go/v4/auth"
+)
+
+// FirebaseVerifier accepts Firebase Auth ID tokens issued to end users. When
+// FIREBASE_AUTH_EMULATOR_HOST is set, the SDK accepts emulator tokens instead.
+type FirebaseVerifier struct {
+	client *firebaseauth.Client
+}
+
+// NewFirebaseAuthClient creates a Firebase Auth admin client for projectID
+func NewFirebaseAuthClient(ctx context.Context, projectID string) (*firebaseauth.Client, error) {
+	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: projectID})
+	if err != nil {
+		return nil, fmt.Errorf("auth: create firebase app: %w", err)
+	}
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth: create firebase auth client: %w", err)
+	}
+	return client, nil
+}
+
+// NewFirebaseVerifier creates a verifier using client
+func NewFirebaseVerifier(client *firebaseauth.Client) *FirebaseVerifier {
+	return &FirebaseVerifier{client: client}
+}
+
+// Verify checks the token signature, expiry and audience. Revocation is not
+// checked here since it costs a round trip to Firebase per request. The
+// email address is only passed on once the user has verified it.
+func (v *FirebaseVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	t, err := v.client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{Subject: t.UID, Email: verifiedEmail(t.Claims), Claims: t.Claims}, nil
+}
+
+// verifiedEmail returns the email claim if email_verified is true. Anyone
+// can sign up with an address they do not own, and invitations are matched
+// by address.
+func verifiedEmail(claims map[string]any) string {
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return ""
+	}
+	email, _ := claims["email"].(string)
+	return email
+}
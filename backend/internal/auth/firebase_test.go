@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifiedEmail(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   string
+	}{
+		{name: "verified", claims: map[string]any{"email": "user@example.com", "email_verified": true}, want: "user@example.com"},
+		{name: "unverified", claims: map[string]any{"email": "user@example.com", "email_verified": false}},
+		{name: "verification missing", claims: map[string]any{"email": "user@example.com"}},
+		{name: "verification not a bool", claims: map[string]any{"email": "user@example.com", "email_verified": "true"}},
+		{name: "no email", claims: map[string]any{"email_verified": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, verifiedEmail(tt.claims))
+		})
+	}
+}
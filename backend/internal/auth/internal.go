@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/api/idtoken"
+
+	"github.com/your-org/your-app/internal/config"
+)
+
+// Internal is the middleware guarding /internal routes. It is a distinct type
+// so fx can tell it apart from end-user authentication.
+type Internal echo.MiddlewareFunc
+
+// NewInternal builds the /internal route guard. Google-signed OIDC tokens from
+// the configured service accounts are accepted when an audience is set; the
+// static token, if configured, is accepted as well. With neither configured
+// every request is rejected.
+func NewInternal(cfg *config.Config) (Internal, error) {
+	var verifiers []Verifier
+
+	if cfg.InternalAuth.Audience != "" {
+		validator, err := idtoken.NewValidator(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("auth: create OIDC validator: %w", err)
+		}
+		verifiers = append(verifiers, &OIDCVerifier{
+			Validator:       validator,
+			Audience:        cfg.InternalAuth.Audience,
+			ServiceAccounts: cfg.InternalAuth.ServiceAccounts,
+		})
+	}
+
+	if cfg.InternalAuth.Token != "" {
+		verifiers = append(verifiers, StaticVerifier{
+			cfg.InternalAuth.Token: {Subject: "internal"},
+		})
+	}
+
+	return Internal(Middleware(firstOf(verifiers))), nil
+}
+
+// OIDCVerifier accepts Google-signed ID tokens minted for an allow-listed
+// service account, as attached by Cloud Tasks and Cloud Scheduler
+type OIDCVerifier struct {
+	Validator       *idtoken.Validator
+	Audience        string
+	ServiceAccounts []string
+}
+
+// Verify validates the token signature, audience and caller identity
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	payload, err := v.Validator.Validate(ctx, token, v.Audience)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	email, _ := payload.Claims["email"].(string)
+	verified, _ := payload.Claims["email_verified"].(bool)
+	if !verified || !slices.Contains(v.ServiceAccounts, email) {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{Subject: payload.Subject, Email: email, Claims: payload.Claims}, nil
+}
+
+// firstOf returns a verifier that accepts a token if any of vs accepts it
+func firstOf(vs []Verifier) Verifier {
+	return VerifierFunc(func(ctx context.Context, token string) (*Principal, error) {
+		for _, v := range vs {
+			if p, err := v.Verify(ctx, token); err == nil {
+				return p, nil
+			}
+		}
+		return nil, ErrInvalidToken
+	})
+}
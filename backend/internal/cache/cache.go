@@ -0,0 +1,60 @@
+// Package cache keeps computed values and API responses for a while.
+//
+// A Cache stores byte values under keys with a TTL and files them under
+// tags, so a write can invalidate everything derived from what it changed
+// at once. MemoryCache is an LRU private to the instance; RedisCache is
+// shared by every instance through a Redis-protocol server such as
+// Memorystore. A Loader coalesces concurrent loads of the same key, and a
+// ResponseCache caches GET responses of the routes that opt in.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrMiss is returned by Get for keys that are not cached
+var ErrMiss = errors.New("cache: miss")
+
+// Cache stores values with a TTL. Implementations are safe for concurrent
+// use.
+type Cache interface {
+	// Get returns the value under key, or ErrMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl and files it under tags. A
+	// non-positive ttl stores nothing.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// Delete removes keys
+	Delete(ctx context.Context, keys ...string) error
+	// Invalidate removes every key filed under one of tags
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Lookup results recorded by the cache.requests metric
+const (
+	resultHit   = "hit"
+	resultMiss  = "miss"
+	resultError = "error"
+)
+
+// newRequestCounter creates the counter of cache lookups by result
+func newRequestCounter(meter metric.Meter) (metric.Int64Counter, error) {
+	if meter == nil {
+		return nil, nil
+	}
+	return meter.Int64Counter("cache.requests",
+		metric.WithDescription("Cache lookups by cache, route and result (hit, miss or error)"),
+		metric.WithUnit("{request}"),
+	)
+}
+
+func record(ctx context.Context, counter metric.Int64Counter, result string, attrs ...attribute.KeyValue) {
+	if counter == nil {
+		return
+	}
+	counter.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("result", result))...))
+}
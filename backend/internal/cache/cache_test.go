@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCaches returns each Cache implementation, the Redis one against a
+// local in-process server
+func newTestCaches(t *testing.T) map[string]Cache {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return map[string]Cache{
+		"memory": NewMemoryCache(MemoryOptions{MaxEntries: 100}),
+		"redis":  NewRedisCache(client, "test:"),
+	}
+}
+
+func TestCache_SetGetDelete(t *testing.T) {
+	for name, c := range newTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+
+			// Act
+			_, missErr := c.Get(ctx, "k")
+			require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute))
+			got, getErr := c.Get(ctx, "k")
+			require.NoError(t, c.Delete(ctx, "k"))
+			_, deletedErr := c.Get(ctx, "k")
+
+			// Assert
+			assert.ErrorIs(t, missErr, ErrMiss)
+			require.NoError(t, getErr)
+			assert.Equal(t, []byte("v"), got)
+			assert.ErrorIs(t, deletedErr, ErrMiss)
+		})
+	}
+}
+
+func TestCache_NonPositiveTTLStoresNothing(t *testing.T) {
+	for name, c := range newTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+
+			// Act
+			require.NoError(t, c.Set(ctx, "k", []byte("v"), 0))
+			_, err := c.Get(ctx, "k")
+
+			// Assert
+			assert.ErrorIs(t, err, ErrMiss)
+		})
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	for name, c := range newTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, c.Set(ctx, "org1/members", []byte("a"), time.Minute, "org:1"))
+			require.NoError(t, c.Set(ctx, "org1/summary", []byte("b"), time.Minute, "org:1", "summaries"))
+			require.NoError(t, c.Set(ctx, "org2/members", []byte("c"), time.Minute, "org:2"))
+
+			// Act
+			err := c.Invalidate(ctx, "org:1")
+
+			// Assert
+			require.NoError(t, err)
+			_, err = c.Get(ctx, "org1/members")
+			assert.ErrorIs(t, err, ErrMiss)
+			_, err = c.Get(ctx, "org1/summary")
+			assert.ErrorIs(t, err, ErrMiss)
+			got, err := c.Get(ctx, "org2/members")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("c"), got)
+		})
+	}
+}
+
+func TestCache_KeysAndTagsDoNotCollide(t *testing.T) {
+	for name, c := range newTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, c.Set(ctx, "a", []byte("tagged"), time.Minute, "x"))
+			require.NoError(t, c.Set(ctx, "x", []byte("named like the tag"), time.Minute))
+
+			// Act
+			err := c.Invalidate(ctx, "x")
+
+			// Assert
+			require.NoError(t, err)
+			got, err := c.Get(ctx, "x")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("named like the tag"), got)
+		})
+	}
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemoryCache(MemoryOptions{MaxEntries: 10})
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute, "t"))
+
+	// Act
+	_, fresh := c.Get(ctx, "k")
+	now = now.Add(time.Minute)
+	_, expired := c.Get(ctx, "k")
+
+	// Assert
+	assert.NoError(t, fresh)
+	assert.ErrorIs(t, expired, ErrMiss)
+	assert.Empty(t, c.tags, "expired entries leave their tags")
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewMemoryCache(MemoryOptions{MaxEntries: 2})
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+	// Assert
+	_, err = c.Get(ctx, "a")
+	assert.NoError(t, err)
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = c.Get(ctx, "c")
+	assert.NoError(t, err)
+}
+
+func TestRedisCache_Expiry(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+	c := NewRedisCache(client, "test:")
+	require.NoError(t, c.Set(ctx, "short", []byte("v"), time.Minute, "t"))
+	require.NoError(t, c.Set(ctx, "long", []byte("v"), time.Hour, "t"))
+
+	// Act
+	srv.FastForward(2 * time.Minute)
+
+	// Assert
+	_, err := c.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = c.Get(ctx, "long")
+	assert.NoError(t, err)
+	assert.Greater(t, srv.TTL("test:t:t"), 50*time.Minute, "the tag set outlives its longest entry")
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Loader reads values through a Cache, loading and storing them on a miss.
+// Concurrent misses of a key share one load, so an expiring hot key does
+// not send every waiting request to the datastore. Cache failures are
+// logged and fall back to loading, so an outage of the cache only costs
+// latency.
+type Loader struct {
+	cache    Cache
+	name     string
+	group    singleflight.Group
+	requests metric.Int64Counter
+	logger   *zap.Logger
+}
+
+// NewLoader creates a loader on c. name labels its metrics; meter may be nil.
+func NewLoader(c Cache, name string, meter metric.Meter, logger *zap.Logger) (*Loader, error) {
+	requests, err := newRequestCounter(meter)
+	if err != nil {
+		return nil, err
+	}
+	return &Loader{cache: c, name: name, requests: requests, logger: logger}, nil
+}
+
+// Load returns the value under key, or calls load and caches its result
+// for ttl under tags. load runs without the caller's cancellation, since
+// other callers may be waiting for it.
+func (l *Loader) Load(ctx context.Context, key string, ttl time.Duration, tags []string, load func(context.Context) ([]byte, error)) ([]byte, error) {
+	attrs := attribute.String("cache", l.name)
+	value, err := l.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		record(ctx, l.requests, resultHit, attrs)
+		return value, nil
+	case errors.Is(err, ErrMiss):
+		record(ctx, l.requests, resultMiss, attrs)
+	default:
+		record(ctx, l.requests, resultError, attrs)
+		l.logger.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+	}
+
+	detached := context.WithoutCancel(ctx)
+	ch := l.group.DoChan(key, func() (any, error) {
+		value, err := load(detached)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.cache.Set(detached, key, value, ttl, tags...); err != nil {
+			l.logger.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+		}
+		return value, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate drops the values filed under tags. A failure is logged and
+// leaves the values to expire with their TTL.
+func (l *Loader) Invalidate(ctx context.Context, tags ...string) {
+	if err := l.cache.Invalidate(ctx, tags...); err != nil {
+		l.logger.Warn("cache invalidation failed", zap.Strings("tags", tags), zap.Error(err))
+	}
+}
+
+// Fetch is Load for values cached as JSON
+func Fetch[T any](ctx context.Context, l *Loader, key string, ttl time.Duration, tags []string, load func(context.Context) (T, error)) (T, error) {
+	var v T
+	data, err := l.Load(ctx, key, ttl, tags, func(ctx context.Context) ([]byte, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(loaded)
+	})
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("cache: decode %s: %w", key, err)
+	}
+	return v, nil
+}
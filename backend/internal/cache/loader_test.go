@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// collectResults returns the cache.requests counts by result
+func collectResults(t *testing.T, reader sdkmetric.Reader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			for _, dp := range sum.DataPoints {
+				result, _ := dp.Attributes.Value(attribute.Key("result"))
+				counts[result.AsString()] += dp.Value
+			}
+		}
+	}
+	return counts
+}
+
+func TestLoader_Load(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	l, err := NewLoader(NewMemoryCache(MemoryOptions{MaxEntries: 10}), "test", meter, zap.NewNop())
+	require.NoError(t, err)
+	loads := 0
+	load := func(context.Context) ([]byte, error) {
+		loads++
+		return []byte("value"), nil
+	}
+
+	// Act
+	first, err1 := l.Load(ctx, "k", time.Minute, nil, load)
+	second, err2 := l.Load(ctx, "k", time.Minute, nil, load)
+
+	// Assert
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, []byte("value"), first)
+	assert.Equal(t, []byte("value"), second)
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, map[string]int64{"miss": 1, "hit": 1}, collectResults(t, reader))
+}
+
+func TestLoader_CoalescesConcurrentMisses(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	l, err := NewLoader(NewMemoryCache(MemoryOptions{MaxEntries: 10}), "test", nil, zap.NewNop())
+	require.NoError(t, err)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) ([]byte, error) {
+		loads.Add(1)
+		<-release
+		return []byte("value"), nil
+	}
+
+	// Act
+	var wg sync.WaitGroup
+	results := make([][]byte, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = l.Load(ctx, "k", time.Minute, nil, load)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), loads.Load())
+	for _, r := range results {
+		assert.Equal(t, []byte("value"), r)
+	}
+}
+
+func TestLoader_LoadErrorIsNotCached(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	l, err := NewLoader(NewMemoryCache(MemoryOptions{MaxEntries: 10}), "test", nil, zap.NewNop())
+	require.NoError(t, err)
+	errUnavailable := errors.New("unavailable")
+
+	// Act
+	_, firstErr := l.Load(ctx, "k", time.Minute, nil, func(context.Context) ([]byte, error) {
+		return nil, errUnavailable
+	})
+	second, secondErr := l.Load(ctx, "k", time.Minute, nil, func(context.Context) ([]byte, error) {
+		return []byte("value"), nil
+	})
+
+	// Assert
+	assert.ErrorIs(t, firstErr, errUnavailable)
+	require.NoError(t, secondErr)
+	assert.Equal(t, []byte("value"), second)
+}
+
+func TestFetch(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewMemoryCache(MemoryOptions{MaxEntries: 10})
+	l, err := NewLoader(c, "test", nil, zap.NewNop())
+	require.NoError(t, err)
+	type org struct {
+		Name string `json:"name"`
+	}
+
+	// Act
+	got, err := Fetch(ctx, l, "org:1", time.Minute, []string{"org:1"}, func(context.Context) (org, error) {
+		return org{Name: "Acme"}, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Invalidate(ctx, "org:1"))
+	_, missErr := c.Get(ctx, "org:1")
+
+	// Assert
+	assert.Equal(t, org{Name: "Acme"}, got)
+	assert.ErrorIs(t, missErr, ErrMiss)
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// MemoryCache is a Cache private to the process that evicts the least
+// recently used entry once it holds maxEntries. Invalidations do not reach
+// other instances.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	lru        *list.List
+	entries    map[string]*list.Element
+	tags       map[string]map[string]struct{}
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// MemoryOptions configures a MemoryCache
+type MemoryOptions struct {
+	// MaxEntries bounds the number of values held
+	MaxEntries int
+	// Clock, the wall clock by default, is what entries expire by
+	Clock clock.Clock
+}
+
+// NewMemoryCache creates a cache holding at most opts.MaxEntries values
+func NewMemoryCache(opts MemoryOptions) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: opts.MaxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		now:        clock.OrSystem(opts.Clock).Now,
+	}
+}
+
+// Get implements Cache
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	e := el.Value.(*memoryEntry)
+	if !m.now().Before(e.expires) {
+		m.remove(el)
+		return nil, ErrMiss
+	}
+	m.lru.MoveToFront(el)
+	return e.value, nil
+}
+
+// Set implements Cache
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl <= 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[key]; ok {
+		m.remove(el)
+	}
+	e := &memoryEntry{key: key, value: value, expires: m.now().Add(ttl), tags: tags}
+	m.entries[key] = m.lru.PushFront(e)
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+	for m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+// Delete implements Cache
+func (m *MemoryCache) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := m.entries[key]; ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// Invalidate implements Cache
+func (m *MemoryCache) Invalidate(_ context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			m.remove(m.entries[key])
+		}
+	}
+	return nil
+}
+
+// remove drops an entry and its tag links; callers hold mu
+func (m *MemoryCache) remove(el *list.Element) {
+	e := m.lru.Remove(el).(*memoryEntry)
+	delete(m.entries, e.key)
+	for _, tag := range e.tags {
+		delete(m.tags[tag], e.key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
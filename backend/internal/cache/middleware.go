@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/server"
+)
+
+// HeaderCache tells whether a response came from the cache: "HIT" or "MISS"
+const HeaderCache = "X-Cache"
+
+// maxResponseSize is the largest response body cached, in bytes
+const maxResponseSize = 1 << 20
+
+// placeholder matches the "{param}" parts of a tag
+var placeholder = regexp.MustCompile(`\{[^{}]+\}`)
+
+// response is a cached response
+type response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCache caches GET responses of the routes that opt in with Route
+// and drops them when a route marked with Invalidates succeeds
+type ResponseCache struct {
+	cache    Cache
+	group    singleflight.Group
+	requests metric.Int64Counter
+	logger   *zap.Logger
+}
+
+// NewResponseCache creates a response cache on c; meter may be nil
+func NewResponseCache(c Cache, meter metric.Meter, logger *zap.Logger) (*ResponseCache, error) {
+	requests, err := newRequestCounter(meter)
+	if err != nil {
+		return nil, err
+	}
+	return &ResponseCache{cache: c, requests: requests, logger: logger}, nil
+}
+
+// Route caches the route's 200 responses to GET requests for ttl under
+// tags. "{param}" in a tag is replaced by that path parameter, so
+// "org:{orgId}" files the response under its organization.
+//
+// Requests with an Authorization header get the "Cache-Control: private"
+// response SecurityHeaders sets for them, and are cached per principal:
+// one caller's entry is never served to another. Anonymous responses are
+// shared unless the handler marks them private or no-store. The key is the
+// path and query, so routes whose response depends on other request
+// headers must not be cached. Concurrent misses of a key run the handler
+// once.
+func (rc *ResponseCache) Route(ttl time.Duration, tags ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Method != http.MethodGet || server.IsEventStream(c) {
+				return next(c)
+			}
+			scope := "public"
+			if req.Header.Get(echo.HeaderAuthorization) != "" {
+				principal := auth.PrincipalFrom(c)
+				if principal == nil {
+					return next(c)
+				}
+				scope = "user:" + principal.Subject
+			}
+			key := "resp:" + scope + ":" + req.URL.Path + "?" + req.URL.Query().Encode()
+
+			ctx := req.Context()
+			attrs := []attribute.KeyValue{attribute.String("cache", "responses"), attribute.String("route", c.Path())}
+			data, err := rc.cache.Get(ctx, key)
+			if err == nil {
+				var res response
+				if err := json.Unmarshal(data, &res); err == nil {
+					record(ctx, rc.requests, resultHit, attrs...)
+					return replay(c, &res)
+				}
+				err = errors.New("undecodable entry")
+			}
+			if errors.Is(err, ErrMiss) {
+				record(ctx, rc.requests, resultMiss, attrs...)
+			} else {
+				record(ctx, rc.requests, resultError, attrs...)
+				rc.logger.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+			}
+
+			// Only the first request's function runs; the others wait for it
+			leader := false
+			v, err, _ := rc.group.Do(key, func() (any, error) {
+				leader = true
+				return rc.fill(c, next, key, ttl, expandTags(c, tags), scope == "public")
+			})
+			switch {
+			case leader:
+				return err
+			case errors.Is(err, context.Canceled), err == nil && v.(*response) == nil:
+				// Nothing to share: the first client left, or its response
+				// was not cacheable
+				return next(c)
+			case err != nil:
+				return err
+			}
+			return replay(c, v.(*response))
+		}
+	}
+}
+
+// Invalidates drops the entries under tags when the route succeeds. It
+// runs before the response is sent, so a client that reads after its
+// write never gets the old state. Tags are expanded as in Route.
+func (rc *ResponseCache) Invalidates(tags ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			res.Before(func() {
+				if res.Status >= http.StatusBadRequest {
+					return
+				}
+				expanded := expandTags(c, tags)
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), 5*time.Second)
+				defer cancel()
+				if err := rc.cache.Invalidate(ctx, expanded...); err != nil {
+					rc.logger.Error("cache invalidation failed", zap.Strings("tags", expanded), zap.Error(err))
+				}
+			})
+			return next(c)
+		}
+	}
+}
+
+// fill runs the handler, capturing its response, and caches it if it may
+// be. It returns the response to share with waiting requests, or nil.
+func (rc *ResponseCache) fill(c echo.Context, next echo.HandlerFunc, key string, ttl time.Duration, tags []string, public bool) (*response, error) {
+	res := c.Response()
+	res.Header().Set(HeaderCache, "MISS")
+	w := &captureWriter{ResponseWriter: res.Writer}
+	res.Writer = w
+	err := next(c)
+	res.Writer = w.ResponseWriter
+	if err != nil {
+		return nil, err
+	}
+
+	h := res.Header()
+	cacheControl := h.Get(echo.HeaderCacheControl)
+	if w.status != http.StatusOK || w.overflow || strings.HasPrefix(h.Get(echo.HeaderContentType), server.MIMEEventStream) ||
+		public && (strings.Contains(cacheControl, "private") || strings.Contains(cacheControl, "no-store")) {
+		return nil, nil
+	}
+
+	entry := &response{
+		Status:      w.status,
+		ContentType: h.Get(echo.HeaderContentType),
+		ETag:        h.Get(server.HeaderETag),
+		Body:        w.buf.Bytes(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := rc.cache.Set(context.WithoutCancel(c.Request().Context()), key, data, ttl, tags...); err != nil {
+		rc.logger.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+	}
+	return entry, nil
+}
+
+// replay writes a cached response
+func replay(c echo.Context, res *response) error {
+	h := c.Response().Header()
+	h.Set(HeaderCache, "HIT")
+	if res.ETag != "" {
+		h.Set(server.HeaderETag, res.ETag)
+	}
+	return c.Blob(res.Status, res.ContentType, res.Body)
+}
+
+// expandTags replaces the "{param}" parts of tags with path parameters
+func expandTags(c echo.Context, tags []string) []string {
+	expanded := make([]string, len(tags))
+	for i, tag := range tags {
+		expanded[i] = placeholder.ReplaceAllStringFunc(tag, func(m string) string {
+			return c.Param(m[1 : len(m)-1])
+		})
+	}
+	return expanded
+}
+
+// captureWriter copies a response on its way to the client
+type captureWriter struct {
+	http.ResponseWriter
+	buf      bytes.Buffer
+	status   int
+	overflow bool
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.overflow {
+		if w.buf.Len()+len(b) > maxResponseSize {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
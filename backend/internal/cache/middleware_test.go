@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/server"
+)
+
+// testServer serves an organization's members from a counter, so tests
+// can tell cached responses from fresh ones
+type testServer struct {
+	e     *echo.Echo
+	calls atomic.Int32
+}
+
+func newTestServer(t *testing.T, handler echo.HandlerFunc) *testServer {
+	t.Helper()
+	rc, err := NewResponseCache(NewMemoryCache(MemoryOptions{MaxEntries: 100}), nil, zap.NewNop())
+	require.NoError(t, err)
+	verifier := auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+		"bob-token":   {Subject: "bob"},
+	}
+
+	s := &testServer{e: echo.New()}
+	counted := func(c echo.Context) error {
+		s.calls.Add(1)
+		return handler(c)
+	}
+	s.e.Use(server.SecurityHeaders(false))
+	s.e.Use(server.ETags())
+	s.e.GET("/public/:orgId", counted, rc.Route(time.Minute, "org:{orgId}"))
+	users := s.e.Group("", auth.Optional(verifier))
+	users.GET("/orgs/:orgId/members", counted, rc.Route(time.Minute, "org:{orgId}"))
+	users.PUT("/orgs/:orgId/members", func(c echo.Context) error {
+		if c.QueryParam("fail") != "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+		}
+		return c.NoContent(http.StatusNoContent)
+	}, rc.Invalidates("org:{orgId}"))
+	return s
+}
+
+func (s *testServer) do(method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func countingJSON(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"org": c.Param("orgId")})
+}
+
+func TestResponseCache_Route(t *testing.T) {
+	// Arrange
+	s := newTestServer(t, countingJSON)
+
+	// Act
+	first := s.do(http.MethodGet, "/public/1", "")
+	second := s.do(http.MethodGet, "/public/1", "")
+	other := s.do(http.MethodGet, "/public/1?page=2", "")
+
+	// Assert
+	assert.Equal(t, "MISS", first.Header().Get(HeaderCache))
+	assert.Equal(t, "HIT", second.Header().Get(HeaderCache))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get(server.HeaderETag), second.Header().Get(server.HeaderETag))
+	assert.Equal(t, "MISS", other.Header().Get(HeaderCache), "the query is part of the key")
+	assert.Equal(t, int32(2), s.calls.Load())
+}
+
+func TestResponseCache_PrivateResponsesArePerCaller(t *testing.T) {
+	// Arrange
+	s := newTestServer(t, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"caller": auth.PrincipalFrom(c).Subject})
+	})
+
+	// Act
+	alice := s.do(http.MethodGet, "/orgs/1/members", "alice-token")
+	bob := s.do(http.MethodGet, "/orgs/1/members", "bob-token")
+	aliceAgain := s.do(http.MethodGet, "/orgs/1/members", "alice-token")
+	invalid := s.do(http.MethodGet, "/orgs/1/members", "forged-token")
+
+	// Assert
+	assert.Contains(t, alice.Header().Get(echo.HeaderCacheControl), "private")
+	assert.Contains(t, bob.Body.String(), `"caller":"bob"`)
+	assert.Equal(t, "MISS", bob.Header().Get(HeaderCache))
+	assert.Equal(t, "HIT", aliceAgain.Header().Get(HeaderCache))
+	assert.Contains(t, aliceAgain.Body.String(), `"caller":"alice"`)
+	assert.Empty(t, invalid.Header().Get(HeaderCache), "unverified callers bypass the cache")
+}
+
+func TestResponseCache_SkipsUncacheableResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+	}{
+		{
+			name:    "error",
+			handler: func(echo.Context) error { return echo.NewHTTPError(http.StatusNotFound, "missing") },
+		},
+		{
+			name:    "non-200 status",
+			handler: func(c echo.Context) error { return c.JSON(http.StatusAccepted, map[string]string{}) },
+		},
+		{
+			name: "marked private",
+			handler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "private")
+				return countingJSON(c)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newTestServer(t, tt.handler)
+
+			// Act
+			s.do(http.MethodGet, "/public/1", "")
+			second := s.do(http.MethodGet, "/public/1", "")
+
+			// Assert
+			assert.NotEqual(t, "HIT", second.Header().Get(HeaderCache))
+			assert.Equal(t, int32(2), s.calls.Load())
+		})
+	}
+}
+
+func TestResponseCache_Invalidates(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		expectedRefresh bool
+	}{
+		{name: "successful write", path: "/orgs/1/members", expectedRefresh: true},
+		{name: "failed write", path: "/orgs/1/members?fail=1"},
+		{name: "other organization", path: "/orgs/2/members"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newTestServer(t, countingJSON)
+			s.do(http.MethodGet, "/orgs/1/members", "alice-token")
+			s.do(http.MethodGet, "/orgs/1/members", "bob-token")
+
+			// Act
+			s.do(http.MethodPut, tt.path, "alice-token")
+			alice := s.do(http.MethodGet, "/orgs/1/members", "alice-token")
+			bob := s.do(http.MethodGet, "/orgs/1/members", "bob-token")
+
+			// Assert
+			expected := "HIT"
+			if tt.expectedRefresh {
+				expected = "MISS"
+			}
+			assert.Equal(t, expected, alice.Header().Get(HeaderCache))
+			assert.Equal(t, expected, bob.Header().Get(HeaderCache))
+		})
+	}
+}
+
+func TestResponseCache_CoalescesConcurrentMisses(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	s := newTestServer(t, func(c echo.Context) error {
+		<-release
+		return countingJSON(c)
+	})
+
+	// Act
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bodies[i] = s.do(http.MethodGet, "/public/1", "").Body.String()
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), s.calls.Load())
+	for _, body := range bodies {
+		assert.JSONEq(t, `{"org":"1"}`, body)
+	}
+}
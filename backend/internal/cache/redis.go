@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// setScript stores KEYS[1] and adds it to the tag sets in KEYS[2..],
+// extending a set's expiry to the entry's so it outlives its members
+var setScript = redis.NewScript(`
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+for i = 2, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if redis.call('PTTL', KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call('PEXPIRE', KEYS[i], ARGV[2])
+	end
+end
+return 1
+`)
+
+// invalidateScript deletes the members of the tag sets in KEYS and the
+// sets themselves, atomically so no entry is tagged in between
+var invalidateScript = redis.NewScript(`
+local n = 0
+for _, tag in ipairs(KEYS) do
+	for _, key in ipairs(redis.call('SMEMBERS', tag)) do
+		n = n + redis.call('DEL', key)
+	end
+	redis.call('DEL', tag)
+end
+return n
+`)
+
+// RedisCache is a Cache shared by every instance, on a Redis-protocol
+// server. Keys are prefixed so several services can share one server. Tag
+// invalidation touches keys a script does not declare, which Redis Cluster
+// rejects; use a standalone server such as Memorystore's basic tier.
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisCache creates a cache on client with keys starting with prefix
+func NewRedisCache(client redis.UniversalClient, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+// Get implements Cache
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache: get: %w", err)
+	}
+	return value, nil
+}
+
+// Set implements Cache
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl <= 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, r.key(key))
+	for _, tag := range tags {
+		keys = append(keys, r.tagKey(tag))
+	}
+	if err := setScript.Run(ctx, r.client, keys, value, ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("cache: set: %w", err)
+	}
+	return nil
+}
+
+// Delete implements Cache
+func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.key(key)
+	}
+	if err := r.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("cache: delete: %w", err)
+	}
+	return nil
+}
+
+// Invalidate implements Cache
+func (r *RedisCache) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = r.tagKey(tag)
+	}
+	if err := invalidateScript.Run(ctx, r.client, keys).Err(); err != nil {
+		return fmt.Errorf("cache: invalidate: %w", err)
+	}
+	return nil
+}
+
+// key and tagKey keep entries and tag sets apart whatever their names
+func (r *RedisCache) key(key string) string {
+	return r.prefix + "k:" + key
+}
+
+func (r *RedisCache) tagKey(tag string) string {
+	return r.prefix + "t:" + tag
+}
@@ -0,0 +1,135 @@
+// Package clientinfo identifies the app build making a request
+package clientinfo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Headers sent by the mobile and web clients
+const (
+	HeaderPlatform = "X-Client-Platform"
+	HeaderVersion  = "X-Client-Version"
+)
+
+// Known platforms
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// Info describes the calling client. Fields are empty when not sent.
+type Info struct {
+	Platform string
+	Version  string
+}
+
+// FromRequest reads the client headers, falling back to an app User-Agent
+// such as "YourApp/2.4.1 (iOS 17.2; iPhone15,2)". Unknown platforms and
+// malformed versions are dropped so they never match targeting rules by
+// accident.
+func FromRequest(r *http.Request) Info {
+	var info Info
+	switch p := strings.ToLower(strings.TrimSpace(r.Header.Get(HeaderPlatform))); p {
+	case PlatformIOS, PlatformAndroid, PlatformWeb:
+		info.Platform = p
+	}
+	if v := strings.TrimSpace(r.Header.Get(HeaderVersion)); ValidVersion(v) {
+		info.Version = v
+	}
+
+	if info.Platform == "" || info.Version == "" {
+		ua := ParseUserAgent(r.UserAgent())
+		if info.Platform == "" {
+			info.Platform = ua.Platform
+		}
+		if info.Version == "" && ua.Platform == info.Platform {
+			info.Version = ua.Version
+		}
+	}
+	return info
+}
+
+// ParseUserAgent reads an app User-Agent of the form
+// "<app>/<version> (<os> <os version>; ...)". Only comments starting with
+// iOS, iPadOS or Android are recognised, which excludes browsers: their
+// comments start with the device or "Linux".
+func ParseUserAgent(ua string) Info {
+	product, comment, ok := strings.Cut(ua, " (")
+	if !ok {
+		return Info{}
+	}
+	_, version, ok := strings.Cut(product, "/")
+	if !ok || !ValidVersion(version) {
+		return Info{}
+	}
+
+	osName, _, _ := strings.Cut(comment, ";")
+	osName, _, _ = strings.Cut(strings.TrimSuffix(osName, ")"), " ")
+	switch strings.ToLower(osName) {
+	case "ios", "ipados":
+		return Info{Platform: PlatformIOS, Version: version}
+	case "android":
+		return Info{Platform: PlatformAndroid, Version: version}
+	default:
+		return Info{}
+	}
+}
+
+// ValidVersion reports whether v is a dotted numeric version like "1.4.2"
+func ValidVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// CompareVersions compares dotted numeric versions, treating missing parts
+// as zero: it returns -1 if a < b, 0 if equal and +1 if a > b. Malformed
+// versions sort before every valid one.
+func CompareVersions(a, b string) int {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) ([]int, bool) {
+	if v == "" || len(v) > 32 {
+		return nil, false
+	}
+	parts := strings.Split(v, ".")
+	out := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		// Atoi also takes signs, such as "+1" and "-0"
+		if err != nil || p[0] < '0' || p[0] > '9' {
+			return nil, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
@@ -0,0 +1,148 @@
+package clientinfo
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "1.2.3", b: "1.2.3", expected: 0},
+		{a: "1.2", b: "1.2.0", expected: 0},
+		{a: "1.10.0", b: "1.9.9", expected: 1},
+		{a: "1.2.3", b: "1.3", expected: -1},
+		{a: "2", b: "10", expected: -1},
+		{a: "garbage", b: "0.0.1", expected: -1},
+		{a: "1.0", b: "1.x", expected: 1},
+		{a: "", b: "", expected: 0},
+		{a: "-0", b: "0", expected: -1},
+		{a: "1.+2", b: "0", expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected []int
+	}{
+		{version: "1.2.3", expected: []int{1, 2, 3}},
+		{version: "01.0", expected: []int{1, 0}},
+		{version: "-0"},
+		{version: "+1"},
+		{version: "1.-2"},
+		{version: "1..2"},
+		{version: "1.2."},
+		{version: "\u0661.0"},
+		{version: "99999999999999999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := parseVersion(tt.version)
+			assert.Equal(t, tt.expected != nil, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		platform  string
+		version   string
+		userAgent string
+		expected  Info
+	}{
+		{name: "known client", platform: "iOS", version: "2.4.1", expected: Info{Platform: PlatformIOS, Version: "2.4.1"}},
+		{name: "no headers", expected: Info{}},
+		{name: "unknown platform", platform: "symbian", version: "1.0", expected: Info{Version: "1.0"}},
+		{name: "malformed version", platform: "android", version: "1.0-beta", expected: Info{Platform: PlatformAndroid}},
+		{name: "signed version part", platform: "web", version: "+1.0", expected: Info{Platform: PlatformWeb}},
+		{name: "app user agent", userAgent: "YourApp/2.4.1 (iOS 17.2; iPhone15,2)", expected: Info{Platform: PlatformIOS, Version: "2.4.1"}},
+		{name: "headers win over user agent", platform: "android", version: "3.0", userAgent: "YourApp/2.4.1 (Android 14; Pixel 8)", expected: Info{Platform: PlatformAndroid, Version: "3.0"}},
+		{name: "user agent of another platform", platform: "web", userAgent: "YourApp/2.4.1 (Android 14)", expected: Info{Platform: PlatformWeb}},
+		{name: "browser user agent", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15", expected: Info{}},
+		{name: "android browser", userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) Chrome/120.0", expected: Info{}},
+		{name: "http library", userAgent: "okhttp/4.12.0", expected: Info{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.platform != "" {
+				req.Header.Set(HeaderPlatform, tt.platform)
+			}
+			if tt.version != "" {
+				req.Header.Set(HeaderVersion, tt.version)
+			}
+			if tt.userAgent != "" {
+				req.Header.Set("User-Agent", tt.userAgent)
+			}
+
+			// Act
+			info := FromRequest(req)
+
+			// Assert
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+var versionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// FuzzCompareVersions checks that versions order consistently and that only
+// dotted numbers are valid
+func FuzzCompareVersions(f *testing.F) {
+	f.Add("1.2.3", "1.10")
+	f.Add("-0", "+1.0")
+
+	f.Fuzz(func(t *testing.T, a, b string) {
+		// Act
+		ab := CompareVersions(a, b)
+		ba := CompareVersions(b, a)
+
+		// Assert
+		assert.Equal(t, -ab, ba)
+		assert.Zero(t, CompareVersions(a, a))
+		if ValidVersion(a) {
+			assert.Regexp(t, versionPattern, a)
+		}
+	})
+}
+
+// FuzzFromRequest checks that whatever the client headers hold, only known
+// platforms and valid versions are read from them
+func FuzzFromRequest(f *testing.F) {
+	f.Add("iOS", "2.4.1", "")
+	f.Add("", "", "YourApp/2.4.1 (Android 14; Pixel 8)")
+
+	f.Fuzz(func(t *testing.T, platform, version, userAgent string) {
+		// Arrange
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(HeaderPlatform, platform)
+		req.Header.Set(HeaderVersion, version)
+		req.Header.Set("User-Agent", userAgent)
+
+		// Act
+		info := FromRequest(req)
+
+		// Assert
+		assert.Contains(t, []string{"", PlatformIOS, PlatformAndroid, PlatformWeb}, info.Platform)
+		if info.Version != "" {
+			assert.True(t, ValidVersion(info.Version), info.Version)
+		}
+	})
+}
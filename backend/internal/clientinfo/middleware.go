@@ -0,0 +1,137 @@
+package clientinfo
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// HeaderRecommendedVersion is set on responses to clients below the
+// recommended version, so the apps can suggest an update without blocking
+const HeaderRecommendedVersion = "X-Client-Recommended-Version"
+
+// Version checks recorded in the client.requests metric
+const (
+	checkCurrent     = "current"
+	checkRecommended = "upgrade_recommended"
+	checkRequired    = "upgrade_required"
+	checkUnknown     = "unknown"
+)
+
+// Requirement is the version policy of one platform. Empty versions
+// disable the corresponding check.
+type Requirement struct {
+	// MinVersion is the oldest version allowed to call the API
+	MinVersion string
+	// RecommendedVersion is the version older clients are nudged towards
+	RecommendedVersion string
+	// UpdateURL is the store page returned to clients that must upgrade
+	UpdateURL string
+}
+
+// UpgradeResponse is the body of a request from a client that is too old
+type UpgradeResponse struct {
+	Message string `json:"message"`
+	// Code is always "upgrade_required"
+	Code               string `json:"code"`
+	Platform           string `json:"platform"`
+	CurrentVersion     string `json:"currentVersion"`
+	MinVersion         string `json:"minVersion"`
+	RecommendedVersion string `json:"recommendedVersion,omitempty"`
+	UpdateURL          string `json:"updateUrl,omitempty"`
+}
+
+// VersionOptions configures which requests are checked
+type VersionOptions struct {
+	// Requirements maps a platform to its policy; platforms without one are
+	// never blocked
+	Requirements map[string]Requirement
+	// Prefix limits checks to paths under it, such as "/api/v1"
+	Prefix string
+	// Exempt lists paths that are always served, such as health checks
+	Exempt []string
+	// Meter records the version distribution; nil disables it
+	Meter metric.Meter
+}
+
+// RequireVersion answers 426 Upgrade Required to clients older than their
+// platform's minimum and sets HeaderRecommendedVersion for those older than
+// the recommended one. Requests that do not identify their platform and
+// version are served, since browsers and scripts never send them.
+func RequireVersion(opts VersionOptions) (echo.MiddlewareFunc, error) {
+	exempt := make(map[string]bool, len(opts.Exempt))
+	for _, p := range opts.Exempt {
+		exempt[p] = true
+	}
+
+	var requests metric.Int64Counter
+	if opts.Meter != nil {
+		var err error
+		requests, err = opts.Meter.Int64Counter("client.requests",
+			metric.WithDescription("API requests by client platform, version and version check"),
+			metric.WithUnit("{request}"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if exempt[path] || !strings.HasPrefix(path, opts.Prefix) {
+				return next(c)
+			}
+
+			info := FromRequest(c.Request())
+			req, ok := opts.Requirements[info.Platform]
+			check := checkUnknown
+			switch {
+			case info.Platform == "" || info.Version == "":
+			case !ok:
+				check = checkCurrent
+			case req.MinVersion != "" && CompareVersions(info.Version, req.MinVersion) < 0:
+				check = checkRequired
+			case req.RecommendedVersion != "" && CompareVersions(info.Version, req.RecommendedVersion) < 0:
+				check = checkRecommended
+			default:
+				check = checkCurrent
+			}
+
+			if requests != nil {
+				requests.Add(c.Request().Context(), 1, metric.WithAttributes(
+					attribute.String("platform", orUnknown(info.Platform)),
+					attribute.String("version", orUnknown(info.Version)),
+					attribute.String("check", check),
+				))
+			}
+
+			switch check {
+			case checkRequired:
+				c.Response().Header().Set("Cache-Control", "no-store")
+				return c.JSON(http.StatusUpgradeRequired, UpgradeResponse{
+					Message:            "This version of the app is no longer supported. Please update to continue.",
+					Code:               "upgrade_required",
+					Platform:           info.Platform,
+					CurrentVersion:     info.Version,
+					MinVersion:         req.MinVersion,
+					RecommendedVersion: req.RecommendedVersion,
+					UpdateURL:          req.UpdateURL,
+				})
+			case checkRecommended:
+				c.Response().Header().Set(HeaderRecommendedVersion, req.RecommendedVersion)
+			}
+			return next(c)
+		}
+	}, nil
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return checkUnknown
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+package clientinfo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTestServer(t testing.TB, reader sdkmetric.Reader) *echo.Echo {
+	t.Helper()
+	opts := VersionOptions{
+		Requirements: map[string]Requirement{
+			PlatformIOS:     {MinVersion: "2.0", RecommendedVersion: "2.4.0", UpdateURL: "https://apps.apple.com/app/id1"},
+			PlatformAndroid: {RecommendedVersion: "3.0"},
+		},
+		Prefix: "/api/v1",
+		Exempt: []string{"/api/v1/health"},
+	}
+	if reader != nil {
+		opts.Meter = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	}
+	mw, err := RequireVersion(opts)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(mw)
+	handler := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e.GET("/health", handler)
+	e.GET("/api/v1/health", handler)
+	e.GET("/api/v1/things", handler)
+	return e
+}
+
+func TestRequireVersion(t *testing.T) {
+	tests := []struct {
+		name                string
+		path                string
+		platform            string
+		version             string
+		expectedStatus      int
+		expectedRecommended string
+	}{
+		{name: "current", path: "/api/v1/things", platform: "ios", version: "2.4.0", expectedStatus: http.StatusNoContent},
+		{name: "below recommended", path: "/api/v1/things", platform: "ios", version: "2.3.9", expectedStatus: http.StatusNoContent, expectedRecommended: "2.4.0"},
+		{name: "below minimum", path: "/api/v1/things", platform: "ios", version: "1.9", expectedStatus: http.StatusUpgradeRequired},
+		{name: "recommended only", path: "/api/v1/things", platform: "android", version: "1.0", expectedStatus: http.StatusNoContent, expectedRecommended: "3.0"},
+		{name: "platform without policy", path: "/api/v1/things", platform: "web", version: "0.1", expectedStatus: http.StatusNoContent},
+		{name: "unidentified client", path: "/api/v1/things", expectedStatus: http.StatusNoContent},
+		{name: "version without platform", path: "/api/v1/things", version: "0.1", expectedStatus: http.StatusNoContent},
+		{name: "exempt path", path: "/api/v1/health", platform: "ios", version: "1.0", expectedStatus: http.StatusNoContent},
+		{name: "outside prefix", path: "/health", platform: "ios", version: "1.0", expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := newTestServer(t, nil)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.platform != "" {
+				req.Header.Set(HeaderPlatform, tt.platform)
+			}
+			if tt.version != "" {
+				req.Header.Set(HeaderVersion, tt.version)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedRecommended, rec.Header().Get(HeaderRecommendedVersion))
+		})
+	}
+}
+
+func TestRequireVersion_UpgradePayload(t *testing.T) {
+	// Arrange
+	e := newTestServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/things", nil)
+	req.Header.Set("User-Agent", "YourApp/1.2.0 (iOS 16.0; iPhone14,2)")
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusUpgradeRequired, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	var body UpgradeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "upgrade_required", body.Code)
+	assert.Equal(t, PlatformIOS, body.Platform)
+	assert.Equal(t, "1.2.0", body.CurrentVersion)
+	assert.Equal(t, "2.0", body.MinVersion)
+	assert.Equal(t, "2.4.0", body.RecommendedVersion)
+	assert.Equal(t, "https://apps.apple.com/app/id1", body.UpdateURL)
+	assert.NotEmpty(t, body.Message)
+}
+
+func TestRequireVersion_RecordsDistribution(t *testing.T) {
+	// Arrange
+	reader := sdkmetric.NewManualReader()
+	e := newTestServer(t, reader)
+	send := func(platform, version string) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/things", nil)
+		req.Header.Set(HeaderPlatform, platform)
+		req.Header.Set(HeaderVersion, version)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Act
+	send("ios", "2.4.0")
+	send("ios", "2.4.0")
+	send("ios", "1.0")
+	send("", "")
+
+	// Assert
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	counts := map[string]int64{}
+	for _, dp := range sum.DataPoints {
+		platform, _ := dp.Attributes.Value(attribute.Key("platform"))
+		version, _ := dp.Attributes.Value(attribute.Key("version"))
+		check, _ := dp.Attributes.Value(attribute.Key("check"))
+		counts[platform.AsString()+" "+version.AsString()+" "+check.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{
+		"ios 2.4.0 current":        2,
+		"ios 1.0 upgrade_required": 1,
+		"unknown unknown unknown":  1,
+	}, counts)
+}
+
+// FuzzRequireVersion checks that only iOS builds below the minimum are
+// turned away, whatever the request identifies itself as
+func FuzzRequireVersion(f *testing.F) {
+	f.Add("/api/v1/things", "ios", "1.9", "")
+	f.Add("/api/v1/things", "", "", "YourApp/1.0 (iPadOS 17.2; iPad)")
+	e := newTestServer(f, nil)
+
+	f.Fuzz(func(t *testing.T, path, platform, version, userAgent string) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = path
+		req.Header.Set(HeaderPlatform, platform)
+		req.Header.Set(HeaderVersion, version)
+		req.Header.Set("User-Agent", userAgent)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Less(t, rec.Code, http.StatusInternalServerError)
+		if rec.Code == http.StatusUpgradeRequired {
+			info := FromRequest(req)
+			assert.Equal(t, PlatformIOS, info.Platform)
+			assert.Negative(t, CompareVersions(info.Version, "2.0"))
+		}
+	})
+}
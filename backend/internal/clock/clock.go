@@ -0,0 +1,26 @@
+// Package clock abstracts the current time, so code that stamps records or
+// decides expiry can be driven by a fake clock in tests
+package clock
+
+import "time"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the wall clock
+type System struct{}
+
+// Now returns time.Now()
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// OrSystem returns c, or System when c is nil, for optional Clock fields
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System{}
+	}
+	return c
+}
@@ -0,0 +1,689 @@
+// Package config loads service configuration from environment variables
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+// Job queue backends
+const (
+	JobsBackendLocal      = "local"
+	JobsBackendCloudTasks = "cloudtasks"
+)
+
+// Store backends
+const (
+	StoreBackendMemory    = "memory"
+	StoreBackendFirestore = "firestore"
+)
+
+// File storage backends
+const (
+	FilesBackendLocal = "local"
+	FilesBackendGCS   = "gcs"
+)
+
+// Feature flag providers
+const (
+	FlagsProviderNone         = "none"
+	FlagsProviderFile         = "file"
+	FlagsProviderRemoteConfig = "remoteconfig"
+)
+
+// Cache backends
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+)
+
+// Metrics exporters
+const (
+	MetricsExporterNone = "none"
+	MetricsExporterGCP  = "gcp"
+)
+
+// Config holds the service configuration
+type Config struct {
+	Env                string
+	Port               string
+	ProjectID          string
+	Region             string
+	ServiceURL         string
+	CORSAllowedOrigins string
+
+	Store        StoreConfig
+	Jobs         JobsConfig
+	Cron         CronConfig
+	InternalAuth InternalAuthConfig
+	Auth         AuthConfig
+	Files        FilesConfig
+	Images       ImagesConfig
+	Account      AccountConfig
+	Orgs         OrgsConfig
+	Webhooks     WebhooksConfig
+	Events       EventsConfig
+	Flags        FlagsConfig
+	Maintenance  MaintenanceConfig
+	Clients      ClientsConfig
+	Metrics      MetricsConfig
+	Compression  CompressionConfig
+	Cache        CacheConfig
+}
+
+// AuthConfig configures end-user authentication
+type AuthConfig struct {
+	// FirebaseProjectID is the project whose Firebase Auth ID tokens are accepted
+	FirebaseProjectID string
+	// AdminRole is the custom claim role that grants admin endpoints
+	AdminRole string
+}
+
+// FilesConfig configures file uploads and downloads
+type FilesConfig struct {
+	// Backend is either "local" (filesystem, URLs served by this service) or "gcs"
+	Backend string
+	// Bucket is the Cloud Storage bucket of the gcs backend
+	Bucket string
+	// LocalDir is where the local backend keeps objects
+	LocalDir string
+	// LocalSigningKey signs local URLs; a random key is used when empty
+	LocalSigningKey string
+	// MaxSize is the largest accepted upload in bytes
+	MaxSize int64
+	// AllowedTypes lists accepted content types, e.g. "image/*"
+	AllowedTypes []string
+	// URLExpiry is how long signed URLs stay valid
+	URLExpiry time.Duration
+}
+
+// ImagesConfig configures processing of uploaded images
+type ImagesConfig struct {
+	// ThumbnailSizes are the bounding boxes of generated thumbnails, in pixels
+	ThumbnailSizes []int
+	// MaxPixels rejects images whose width times height exceeds it
+	MaxPixels int
+	// JPEGQuality is the quality of re-encoded JPEGs, 1-100
+	JPEGQuality int
+}
+
+// AccountConfig configures data exports and account deletion
+type AccountConfig struct {
+	// DeletionGracePeriod is how long a deleted account is kept before it is purged
+	DeletionGracePeriod time.Duration
+	// ExportTTL is how long a data export can be downloaded
+	ExportTTL time.Duration
+}
+
+// OrgsConfig configures organizations
+type OrgsConfig struct {
+	// InvitationTTL is how long an invitation can be accepted
+	InvitationTTL time.Duration
+}
+
+// WebhooksConfig configures outgoing webhook delivery
+type WebhooksConfig struct {
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered
+	MaxAttempts int
+	// MinBackoff is the delay after the first failed attempt; it doubles per attempt
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Timeout bounds a single attempt
+	Timeout time.Duration
+	// AllowPrivate permits endpoints on private addresses, for local development only
+	AllowPrivate bool
+}
+
+// EventsConfig configures the Server-Sent Events stream
+type EventsConfig struct {
+	// Heartbeat is the interval of keep-alive comments on idle streams
+	Heartbeat time.Duration
+	// MaxDuration ends a stream so clients reconnect before the platform's
+	// request timeout cuts them off
+	MaxDuration time.Duration
+	// ReplaySize is how many recent events are kept for Last-Event-ID resume
+	ReplaySize int
+}
+
+// FlagsConfig configures feature flags
+type FlagsConfig struct {
+	// Provider is "none" (defaults only), "file" or "remoteconfig"
+	Provider string
+	// File is the JSON snapshot read by the file provider
+	File string
+	// Refresh is how often flags are reloaded
+	Refresh time.Duration
+}
+
+// MaintenanceConfig configures maintenance mode
+type MaintenanceConfig struct {
+	// Mode is "off", "read_only" or "full". Anything but off holds until the
+	// next deploy, whatever the admin override and flag say.
+	Mode string
+	// Message is shown to blocked clients
+	Message string
+	// RetryAfter is advertised to blocked clients when no end time is set
+	RetryAfter time.Duration
+	// BypassIPs lists addresses and CIDRs that are never blocked
+	BypassIPs []string
+	// Refresh is how often the admin override is reloaded from the store
+	Refresh time.Duration
+}
+
+// ClientsConfig sets the app versions allowed to call the API
+type ClientsConfig struct {
+	IOS     ClientVersionConfig
+	Android ClientVersionConfig
+}
+
+// ClientVersionConfig is the version policy of one mobile platform
+type ClientVersionConfig struct {
+	// MinVersion is the oldest version served; older ones get 426
+	MinVersion string
+	// RecommendedVersion is the version older clients are asked to update to
+	RecommendedVersion string
+	// UpdateURL is the app's store page
+	UpdateURL string
+}
+
+// MetricsConfig configures custom metrics
+type MetricsConfig struct {
+	// Exporter is "none" or "gcp" (Cloud Monitoring)
+	Exporter string
+	// Interval is how often metrics are exported
+	Interval time.Duration
+}
+
+// CompressionConfig configures HTTP response compression and compressed
+// request bodies
+type CompressionConfig struct {
+	// Enabled turns response compression on
+	Enabled bool
+	// MinSize is the smallest response body in bytes worth compressing
+	MinSize int
+	// Types lists compressed content types, e.g. "text/*"
+	Types []string
+	// MaxRequestSize caps request bodies in bytes once decompressed
+	MaxRequestSize int64
+}
+
+// CacheConfig configures the response cache
+type CacheConfig struct {
+	// Backend is "memory" (per instance) or "redis" (shared by all instances)
+	Backend string
+	// RedisURL is the redis:// or rediss:// URL of the redis backend
+	RedisURL string
+	// MaxEntries bounds the memory backend
+	MaxEntries int
+	// TTL is how long cached responses are served
+	TTL time.Duration
+}
+
+// StoreConfig selects the datastore
+type StoreConfig struct {
+	// Backend is either "memory" (lost on restart) or "firestore"
+	Backend string
+}
+
+// JobsConfig configures the background job queue
+type JobsConfig struct {
+	// Backend is either "local" (in-process) or "cloudtasks"
+	Backend string
+	// Queue is the Cloud Tasks queue ID
+	Queue string
+	// Location is the Cloud Tasks queue region
+	Location string
+	// ServiceAccount is the identity Cloud Tasks uses to sign OIDC tokens
+	ServiceAccount string
+	// LocalWorkers bounds concurrency of the in-process backend
+	LocalWorkers int
+	// LocalMinBackoff is the first retry delay of the in-process backend
+	LocalMinBackoff time.Duration
+	// LocalMaxBackoff caps the retry delay of the in-process backend
+	LocalMaxBackoff time.Duration
+}
+
+// CronConfig configures scheduled jobs
+type CronConfig struct {
+	// HistoryRetention is how long execution records are kept
+	HistoryRetention time.Duration
+}
+
+// InternalAuthConfig configures authentication for /internal routes,
+// which are only called by Google Cloud services (Cloud Tasks, Cloud Scheduler)
+type InternalAuthConfig struct {
+	// Audience is the expected OIDC token audience, usually the service URL
+	Audience string
+	// ServiceAccounts lists the identities allowed to call internal routes
+	ServiceAccounts []string
+	// Token is a static bearer token accepted in non-production environments
+	Token string
+}
+
+// Load reads configuration from the environment
+func Load() (*Config, error) {
+	cfg := &Config{
+		Env:                getenv("ENV", "development"),
+		Port:               getenv("PORT", "8080"),
+		ProjectID:          getenv("GCP_PROJECT", os.Getenv("GCP_PROJECT_ID")),
+		Region:             getenv("GCP_REGION", "us-central1"),
+		ServiceURL:         strings.TrimSuffix(os.Getenv("SERVICE_URL"), "/"),
+		CORSAllowedOrigins: os.Getenv("CORS_ALLOWED_ORIGINS"),
+	}
+
+	cfg.Store = StoreConfig{
+		Backend: getenv("STORE_BACKEND", StoreBackendMemory),
+	}
+
+	var err error
+	cfg.Jobs = JobsConfig{
+		Backend:        getenv("JOBS_BACKEND", JobsBackendLocal),
+		Queue:          os.Getenv("TASKS_QUEUE"),
+		Location:       getenv("TASKS_LOCATION", cfg.Region),
+		ServiceAccount: os.Getenv("TASKS_SERVICE_ACCOUNT"),
+	}
+	if cfg.Jobs.LocalWorkers, err = getenvInt("JOBS_LOCAL_WORKERS", 4); err != nil {
+		return nil, err
+	}
+	if cfg.Jobs.LocalMinBackoff, err = getenvDuration("JOBS_LOCAL_MIN_BACKOFF", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Jobs.LocalMaxBackoff, err = getenvDuration("JOBS_LOCAL_MAX_BACKOFF", time.Minute); err != nil {
+		return nil, err
+	}
+
+	if cfg.Cron.HistoryRetention, err = getenvDuration("CRON_HISTORY_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	cfg.InternalAuth = InternalAuthConfig{
+		Audience:        getenv("INTERNAL_AUTH_AUDIENCE", cfg.ServiceURL),
+		ServiceAccounts: splitList(getenv("INTERNAL_AUTH_SERVICE_ACCOUNTS", cfg.Jobs.ServiceAccount)),
+		Token:           os.Getenv("INTERNAL_AUTH_TOKEN"),
+	}
+
+	cfg.Auth = AuthConfig{
+		FirebaseProjectID: getenv("FIREBASE_PROJECT_ID", cfg.ProjectID),
+		AdminRole:         getenv("ADMIN_ROLE", "admin"),
+	}
+
+	cfg.Files = FilesConfig{
+		Backend:         getenv("FILES_BACKEND", FilesBackendLocal),
+		Bucket:          os.Getenv("FILES_BUCKET"),
+		LocalDir:        getenv("FILES_LOCAL_DIR", filepath.Join(os.TempDir(), "your-app-files")),
+		LocalSigningKey: os.Getenv("FILES_LOCAL_SIGNING_KEY"),
+		AllowedTypes:    splitList(getenv("FILES_ALLOWED_TYPES", "image/*")),
+	}
+	// Matches the "< 10MB" upload limit in storage.rules
+	if cfg.Files.MaxSize, err = getenvInt64("FILES_MAX_SIZE", 10*1024*1024-1); err != nil {
+		return nil, err
+	}
+	if cfg.Files.URLExpiry, err = getenvDuration("FILES_URL_EXPIRY", 15*time.Minute); err != nil {
+		return nil, err
+	}
+
+	if cfg.Images.ThumbnailSizes, err = getenvIntList("IMAGES_THUMBNAIL_SIZES", "128,512"); err != nil {
+		return nil, err
+	}
+	// 24 megapixels decode to about 96MB of RGBA
+	if cfg.Images.MaxPixels, err = getenvInt("IMAGES_MAX_PIXELS", 24_000_000); err != nil {
+		return nil, err
+	}
+	if cfg.Images.JPEGQuality, err = getenvInt("IMAGES_JPEG_QUALITY", 85); err != nil {
+		return nil, err
+	}
+
+	if cfg.Account.DeletionGracePeriod, err = getenvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Account.ExportTTL, err = getenvDuration("ACCOUNT_EXPORT_TTL", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.Orgs.InvitationTTL, err = getenvDuration("ORGS_INVITATION_TTL", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.Webhooks.MaxAttempts, err = getenvInt("WEBHOOKS_MAX_ATTEMPTS", 8); err != nil {
+		return nil, err
+	}
+	if cfg.Webhooks.MinBackoff, err = getenvDuration("WEBHOOKS_MIN_BACKOFF", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Webhooks.MaxBackoff, err = getenvDuration("WEBHOOKS_MAX_BACKOFF", 6*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Webhooks.Timeout, err = getenvDuration("WEBHOOKS_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Webhooks.AllowPrivate, err = getenvBool("WEBHOOKS_ALLOW_PRIVATE", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.Events.Heartbeat, err = getenvDuration("EVENTS_HEARTBEAT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	// Cloud Run's request timeout is set to 60 minutes by Pulumi
+	if cfg.Events.MaxDuration, err = getenvDuration("EVENTS_MAX_DURATION", 55*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Events.ReplaySize, err = getenvInt("EVENTS_REPLAY_SIZE", 1000); err != nil {
+		return nil, err
+	}
+
+	cfg.Flags.Provider = getenv("FLAGS_PROVIDER", FlagsProviderNone)
+	cfg.Flags.File = os.Getenv("FLAGS_FILE")
+	if cfg.Flags.Refresh, err = getenvDuration("FLAGS_REFRESH", time.Minute); err != nil {
+		return nil, err
+	}
+
+	cfg.Maintenance = MaintenanceConfig{
+		Mode:      getenv("MAINTENANCE_MODE", "off"),
+		Message:   os.Getenv("MAINTENANCE_MESSAGE"),
+		BypassIPs: splitList(os.Getenv("MAINTENANCE_BYPASS_IPS")),
+	}
+	if cfg.Maintenance.RetryAfter, err = getenvDuration("MAINTENANCE_RETRY_AFTER", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Maintenance.Refresh, err = getenvDuration("MAINTENANCE_REFRESH", 15*time.Second); err != nil {
+		return nil, err
+	}
+
+	cfg.Clients = ClientsConfig{
+		IOS: ClientVersionConfig{
+			MinVersion:         os.Getenv("CLIENT_IOS_MIN_VERSION"),
+			RecommendedVersion: os.Getenv("CLIENT_IOS_RECOMMENDED_VERSION"),
+			UpdateURL:          os.Getenv("CLIENT_IOS_UPDATE_URL"),
+		},
+		Android: ClientVersionConfig{
+			MinVersion:         os.Getenv("CLIENT_ANDROID_MIN_VERSION"),
+			RecommendedVersion: os.Getenv("CLIENT_ANDROID_RECOMMENDED_VERSION"),
+			UpdateURL:          os.Getenv("CLIENT_ANDROID_UPDATE_URL"),
+		},
+	}
+
+	cfg.Metrics.Exporter = getenv("METRICS_EXPORTER", MetricsExporterNone)
+	// Cloud Monitoring rejects custom metric points written less than 5s apart
+	if cfg.Metrics.Interval, err = getenvDuration("METRICS_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+
+	cfg.Compression.Types = splitList(getenv("COMPRESSION_TYPES", "application/json,application/problem+json,text/*,image/svg+xml"))
+	if cfg.Compression.Enabled, err = getenvBool("COMPRESSION_ENABLED", true); err != nil {
+		return nil, err
+	}
+	// Bodies under a kilobyte fit a single packet; compressing them saves nothing
+	if cfg.Compression.MinSize, err = getenvInt("COMPRESSION_MIN_SIZE", 1024); err != nil {
+		return nil, err
+	}
+	if cfg.Compression.MaxRequestSize, err = getenvInt64("COMPRESSION_MAX_REQUEST_SIZE", 1024*1024); err != nil {
+		return nil, err
+	}
+
+	cfg.Cache.Backend = getenv("CACHE_BACKEND", CacheBackendMemory)
+	cfg.Cache.RedisURL = os.Getenv("CACHE_REDIS_URL")
+	if cfg.Cache.MaxEntries, err = getenvInt("CACHE_MAX_ENTRIES", 10000); err != nil {
+		return nil, err
+	}
+	if cfg.Cache.TTL, err = getenvDuration("CACHE_TTL", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// IsProduction reports whether the service runs in production
+func (c *Config) IsProduction() bool {
+	return c.Env == "production"
+}
+
+func (c *Config) validate() error {
+	switch c.Store.Backend {
+	case StoreBackendMemory, StoreBackendFirestore:
+	default:
+		return fmt.Errorf("config: unknown STORE_BACKEND %q", c.Store.Backend)
+	}
+
+	switch c.Jobs.Backend {
+	case JobsBackendLocal:
+	case JobsBackendCloudTasks:
+		if c.ProjectID == "" || c.Jobs.Queue == "" || c.ServiceURL == "" || c.Jobs.ServiceAccount == "" {
+			return fmt.Errorf("config: JOBS_BACKEND=cloudtasks requires GCP_PROJECT, TASKS_QUEUE, SERVICE_URL and TASKS_SERVICE_ACCOUNT")
+		}
+	default:
+		return fmt.Errorf("config: unknown JOBS_BACKEND %q", c.Jobs.Backend)
+	}
+
+	switch c.Files.Backend {
+	case FilesBackendLocal:
+	case FilesBackendGCS:
+		if c.Files.Bucket == "" {
+			return fmt.Errorf("config: FILES_BACKEND=gcs requires FILES_BUCKET")
+		}
+	default:
+		return fmt.Errorf("config: unknown FILES_BACKEND %q", c.Files.Backend)
+	}
+	if c.Files.MaxSize <= 0 {
+		return fmt.Errorf("config: FILES_MAX_SIZE must be positive")
+	}
+	if c.Files.URLExpiry <= 0 || c.Files.URLExpiry > 7*24*time.Hour {
+		return fmt.Errorf("config: FILES_URL_EXPIRY must be between 0 and 168h")
+	}
+
+	for _, size := range c.Images.ThumbnailSizes {
+		if size < 16 || size > 4096 {
+			return fmt.Errorf("config: IMAGES_THUMBNAIL_SIZES must be between 16 and 4096")
+		}
+	}
+	if c.Images.MaxPixels <= 0 {
+		return fmt.Errorf("config: IMAGES_MAX_PIXELS must be positive")
+	}
+	if c.Images.JPEGQuality < 1 || c.Images.JPEGQuality > 100 {
+		return fmt.Errorf("config: IMAGES_JPEG_QUALITY must be between 1 and 100")
+	}
+
+	if c.Account.DeletionGracePeriod < 0 {
+		return fmt.Errorf("config: ACCOUNT_DELETION_GRACE_PERIOD must not be negative")
+	}
+	// The files bucket's lifecycle rule deletes exports after seven days
+	if c.Account.ExportTTL <= 0 || c.Account.ExportTTL > 7*24*time.Hour {
+		return fmt.Errorf("config: ACCOUNT_EXPORT_TTL must be between 0 and 168h")
+	}
+
+	if c.Orgs.InvitationTTL <= 0 {
+		return fmt.Errorf("config: ORGS_INVITATION_TTL must be positive")
+	}
+
+	if c.Webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("config: WEBHOOKS_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhooks.Timeout <= 0 {
+		return fmt.Errorf("config: WEBHOOKS_TIMEOUT must be positive")
+	}
+
+	if c.Events.Heartbeat <= 0 || c.Events.MaxDuration <= 0 {
+		return fmt.Errorf("config: EVENTS_HEARTBEAT and EVENTS_MAX_DURATION must be positive")
+	}
+	if c.Events.ReplaySize < 1 {
+		return fmt.Errorf("config: EVENTS_REPLAY_SIZE must be at least 1")
+	}
+
+	switch c.Flags.Provider {
+	case FlagsProviderNone:
+	case FlagsProviderFile:
+		if c.Flags.File == "" {
+			return fmt.Errorf("config: FLAGS_PROVIDER=file requires FLAGS_FILE")
+		}
+	case FlagsProviderRemoteConfig:
+		if c.Auth.FirebaseProjectID == "" {
+			return fmt.Errorf("config: FLAGS_PROVIDER=remoteconfig requires FIREBASE_PROJECT_ID or GCP_PROJECT")
+		}
+	default:
+		return fmt.Errorf("config: unknown FLAGS_PROVIDER %q", c.Flags.Provider)
+	}
+	if c.Flags.Refresh <= 0 {
+		return fmt.Errorf("config: FLAGS_REFRESH must be positive")
+	}
+
+	switch c.Maintenance.Mode {
+	case "off", "read_only", "full":
+	default:
+		return fmt.Errorf("config: unknown MAINTENANCE_MODE %q", c.Maintenance.Mode)
+	}
+	if c.Maintenance.RetryAfter <= 0 || c.Maintenance.Refresh <= 0 {
+		return fmt.Errorf("config: MAINTENANCE_RETRY_AFTER and MAINTENANCE_REFRESH must be positive")
+	}
+
+	if c.Compression.MinSize < 0 {
+		return fmt.Errorf("config: COMPRESSION_MIN_SIZE must not be negative")
+	}
+	if c.Compression.MaxRequestSize <= 0 {
+		return fmt.Errorf("config: COMPRESSION_MAX_REQUEST_SIZE must be positive")
+	}
+
+	switch c.Cache.Backend {
+	case CacheBackendMemory:
+	case CacheBackendRedis:
+		if c.Cache.RedisURL == "" {
+			return fmt.Errorf("config: CACHE_BACKEND=redis requires CACHE_REDIS_URL")
+		}
+	default:
+		return fmt.Errorf("config: unknown CACHE_BACKEND %q", c.Cache.Backend)
+	}
+	if c.Cache.MaxEntries < 1 {
+		return fmt.Errorf("config: CACHE_MAX_ENTRIES must be at least 1")
+	}
+	if c.Cache.TTL <= 0 {
+		return fmt.Errorf("config: CACHE_TTL must be positive")
+	}
+
+	if err := c.Clients.IOS.validate("IOS"); err != nil {
+		return err
+	}
+	if err := c.Clients.Android.validate("ANDROID"); err != nil {
+		return err
+	}
+
+	switch c.Metrics.Exporter {
+	case MetricsExporterNone:
+	case MetricsExporterGCP:
+		if c.ProjectID == "" {
+			return fmt.Errorf("config: METRICS_EXPORTER=gcp requires GCP_PROJECT")
+		}
+		if c.Metrics.Interval < 5*time.Second {
+			return fmt.Errorf("config: METRICS_INTERVAL must be at least 5s")
+		}
+	default:
+		return fmt.Errorf("config: unknown METRICS_EXPORTER %q", c.Metrics.Exporter)
+	}
+
+	if c.IsProduction() && c.InternalAuth.Token != "" {
+		return fmt.Errorf("config: INTERNAL_AUTH_TOKEN must not be set in production")
+	}
+	if c.IsProduction() && c.Webhooks.AllowPrivate {
+		return fmt.Errorf("config: WEBHOOKS_ALLOW_PRIVATE must not be set in production")
+	}
+	return nil
+}
+
+func (v ClientVersionConfig) validate(platform string) error {
+	if v.MinVersion != "" && !clientinfo.ValidVersion(v.MinVersion) {
+		return fmt.Errorf("config: CLIENT_%s_MIN_VERSION %q is not a dotted numeric version", platform, v.MinVersion)
+	}
+	if v.RecommendedVersion != "" && !clientinfo.ValidVersion(v.RecommendedVersion) {
+		return fmt.Errorf("config: CLIENT_%s_RECOMMENDED_VERSION %q is not a dotted numeric version", platform, v.RecommendedVersion)
+	}
+	return nil
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getenvInt(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getenvInt64(key string, fallback int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getenvBool(key string, fallback bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func getenvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+// getenvIntList parses a comma-separated list of integers, sorted and
+// without duplicates
+func getenvIntList(key, fallback string) ([]int, error) {
+	var out []int
+	for _, part := range splitList(getenv(key, fallback)) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", key, err)
+		}
+		out = append(out, n)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Defaults(t *testing.T) {
+	// Arrange
+	t.Setenv("ENV", "")
+	t.Setenv("PORT", "")
+	t.Setenv("JOBS_BACKEND", "")
+	t.Setenv("STORE_BACKEND", "")
+	t.Setenv("FILES_BACKEND", "")
+	t.Setenv("FLAGS_PROVIDER", "")
+	t.Setenv("MAINTENANCE_MODE", "")
+	t.Setenv("METRICS_EXPORTER", "")
+	t.Setenv("IMAGES_THUMBNAIL_SIZES", "")
+	t.Setenv("CACHE_BACKEND", "")
+
+	// Act
+	cfg, err := Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "development", cfg.Env)
+	assert.Equal(t, "8080", cfg.Port)
+	assert.False(t, cfg.IsProduction())
+	assert.Equal(t, StoreBackendMemory, cfg.Store.Backend)
+	assert.Equal(t, JobsBackendLocal, cfg.Jobs.Backend)
+	assert.Equal(t, 4, cfg.Jobs.LocalWorkers)
+	assert.Equal(t, time.Second, cfg.Jobs.LocalMinBackoff)
+	assert.Equal(t, FilesBackendLocal, cfg.Files.Backend)
+	assert.Equal(t, int64(10*1024*1024-1), cfg.Files.MaxSize)
+	assert.Equal(t, []string{"image/*"}, cfg.Files.AllowedTypes)
+	assert.Equal(t, 15*time.Minute, cfg.Files.URLExpiry)
+	assert.Equal(t, []int{128, 512}, cfg.Images.ThumbnailSizes)
+	assert.Equal(t, 85, cfg.Images.JPEGQuality)
+	assert.Equal(t, 30*24*time.Hour, cfg.Account.DeletionGracePeriod)
+	assert.Equal(t, 7*24*time.Hour, cfg.Account.ExportTTL)
+	assert.Equal(t, 7*24*time.Hour, cfg.Orgs.InvitationTTL)
+	assert.Equal(t, 8, cfg.Webhooks.MaxAttempts)
+	assert.False(t, cfg.Webhooks.AllowPrivate)
+	assert.Equal(t, 15*time.Second, cfg.Events.Heartbeat)
+	assert.Equal(t, 55*time.Minute, cfg.Events.MaxDuration)
+	assert.Equal(t, 1000, cfg.Events.ReplaySize)
+	assert.Equal(t, FlagsProviderNone, cfg.Flags.Provider)
+	assert.Equal(t, time.Minute, cfg.Flags.Refresh)
+	assert.Equal(t, "admin", cfg.Auth.AdminRole)
+	assert.Equal(t, "off", cfg.Maintenance.Mode)
+	assert.Equal(t, 5*time.Minute, cfg.Maintenance.RetryAfter)
+	assert.Equal(t, ClientVersionConfig{}, cfg.Clients.IOS)
+	assert.Equal(t, MetricsExporterNone, cfg.Metrics.Exporter)
+	assert.Equal(t, time.Minute, cfg.Metrics.Interval)
+	assert.True(t, cfg.Compression.Enabled)
+	assert.Equal(t, 1024, cfg.Compression.MinSize)
+	assert.Contains(t, cfg.Compression.Types, "application/json")
+	assert.Equal(t, int64(1024*1024), cfg.Compression.MaxRequestSize)
+	assert.Equal(t, CacheBackendMemory, cfg.Cache.Backend)
+	assert.Equal(t, 10000, cfg.Cache.MaxEntries)
+	assert.Equal(t, 30*time.Second, cfg.Cache.TTL)
+}
+
+func TestLoad_CloudTasks(t *testing.T) {
+	// Arrange
+	t.Setenv("JOBS_BACKEND", JobsBackendCloudTasks)
+	t.Setenv("GCP_PROJECT", "demo")
+	t.Setenv("SERVICE_URL", "https://api.example.com/")
+	t.Setenv("TASKS_QUEUE", "jobs")
+	t.Setenv("TASKS_SERVICE_ACCOUNT", "api@demo.iam.gserviceaccount.com")
+
+	// Act
+	cfg, err := Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com", cfg.ServiceURL)
+	assert.Equal(t, "https://api.example.com", cfg.InternalAuth.Audience)
+	assert.Equal(t, []string{"api@demo.iam.gserviceaccount.com"}, cfg.InternalAuth.ServiceAccounts)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{
+			name: "unknown store backend",
+			env:  map[string]string{"STORE_BACKEND": "postgres"},
+		},
+		{
+			name: "unknown jobs backend",
+			env:  map[string]string{"JOBS_BACKEND": "sqs"},
+		},
+		{
+			name: "cloud tasks without queue",
+			env:  map[string]string{"JOBS_BACKEND": JobsBackendCloudTasks, "GCP_PROJECT": "demo"},
+		},
+		{
+			name: "malformed worker count",
+			env:  map[string]string{"JOBS_LOCAL_WORKERS": "many"},
+		},
+		{
+			name: "gcs files backend without bucket",
+			env:  map[string]string{"FILES_BACKEND": FilesBackendGCS},
+		},
+		{
+			name: "non-positive file size limit",
+			env:  map[string]string{"FILES_MAX_SIZE": "0"},
+		},
+		{
+			name: "signed url expiry above seven days",
+			env:  map[string]string{"FILES_URL_EXPIRY": "200h"},
+		},
+		{
+			name: "malformed thumbnail size",
+			env:  map[string]string{"IMAGES_THUMBNAIL_SIZES": "128,large"},
+		},
+		{
+			name: "thumbnail size too small",
+			env:  map[string]string{"IMAGES_THUMBNAIL_SIZES": "8"},
+		},
+		{
+			name: "jpeg quality above 100",
+			env:  map[string]string{"IMAGES_JPEG_QUALITY": "101"},
+		},
+		{
+			name: "negative deletion grace period",
+			env:  map[string]string{"ACCOUNT_DELETION_GRACE_PERIOD": "-1h"},
+		},
+		{
+			name: "export ttl above seven days",
+			env:  map[string]string{"ACCOUNT_EXPORT_TTL": "200h"},
+		},
+		{
+			name: "zero invitation ttl",
+			env:  map[string]string{"ORGS_INVITATION_TTL": "0s"},
+		},
+		{
+			name: "zero webhook attempts",
+			env:  map[string]string{"WEBHOOKS_MAX_ATTEMPTS": "0"},
+		},
+		{
+			name: "malformed boolean",
+			env:  map[string]string{"WEBHOOKS_ALLOW_PRIVATE": "sometimes"},
+		},
+		{
+			name: "private webhook targets in production",
+			env:  map[string]string{"ENV": "production", "WEBHOOKS_ALLOW_PRIVATE": "true"},
+		},
+		{
+			name: "zero heartbeat",
+			env:  map[string]string{"EVENTS_HEARTBEAT": "0s"},
+		},
+		{
+			name: "empty replay buffer",
+			env:  map[string]string{"EVENTS_REPLAY_SIZE": "0"},
+		},
+		{
+			name: "unknown flags provider",
+			env:  map[string]string{"FLAGS_PROVIDER": "launchdarkly"},
+		},
+		{
+			name: "file flags provider without file",
+			env:  map[string]string{"FLAGS_PROVIDER": FlagsProviderFile},
+		},
+		{
+			name: "unknown maintenance mode",
+			env:  map[string]string{"MAINTENANCE_MODE": "on"},
+		},
+		{
+			name: "zero maintenance retry after",
+			env:  map[string]string{"MAINTENANCE_RETRY_AFTER": "0s"},
+		},
+		{
+			name: "malformed minimum client version",
+			env:  map[string]string{"CLIENT_IOS_MIN_VERSION": "v2"},
+		},
+		{
+			name: "malformed recommended client version",
+			env:  map[string]string{"CLIENT_ANDROID_RECOMMENDED_VERSION": "2.x"},
+		},
+		{
+			name: "unknown metrics exporter",
+			env:  map[string]string{"METRICS_EXPORTER": "prometheus"},
+		},
+		{
+			name: "gcp metrics exporter below the minimum interval",
+			env:  map[string]string{"METRICS_EXPORTER": MetricsExporterGCP, "GCP_PROJECT": "demo", "METRICS_INTERVAL": "1s"},
+		},
+		{
+			name: "negative compression minimum size",
+			env:  map[string]string{"COMPRESSION_MIN_SIZE": "-1"},
+		},
+		{
+			name: "zero decompressed request size",
+			env:  map[string]string{"COMPRESSION_MAX_REQUEST_SIZE": "0"},
+		},
+		{
+			name: "unknown cache backend",
+			env:  map[string]string{"CACHE_BACKEND": "memcached"},
+		},
+		{
+			name: "redis cache without URL",
+			env:  map[string]string{"CACHE_BACKEND": CacheBackendRedis, "CACHE_REDIS_URL": ""},
+		},
+		{
+			name: "zero cache TTL",
+			env:  map[string]string{"CACHE_TTL": "0s"},
+		},
+		{
+			name: "static internal token in production",
+			env:  map[string]string{"ENV": "production", "INTERNAL_AUTH_TOKEN": "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			_, err := Load()
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package contract checks the handlers against api/openapi.yaml. Every
+// operation testutil.SetupTestServer routes is called with a request built
+// from the spec's schemas and examples, and with requests that each break
+// one of its rules. Every response, status, headers and body, must be
+// declared by the operation.
+package contract
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/client"
+	"github.com/your-org/your-app/internal/testutil"
+)
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// streamFor is how long an event stream is read before the test ends it
+const streamFor = 100 * time.Millisecond
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.PlainBodyDecoder)
+}
+
+func TestContract(t *testing.T) {
+	doc, err := client.GetSwagger()
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()), "api/openapi.yaml is invalid")
+	server, err := url.Parse(doc.Servers[0].URL)
+	require.NoError(t, err)
+	base := strings.TrimSuffix(server.Path, "/")
+
+	routed := map[string]bool{}
+	for _, r := range testutil.SetupTestServer(t).Routes() {
+		routed[r.Method+" "+r.Path] = true
+	}
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Value(path)
+		for method, op := range item.Operations() {
+			t.Run(op.OperationID, func(t *testing.T) {
+				if !routed[method+" "+base+pathParam.ReplaceAllString(path, ":$1")] {
+					t.Skipf("%s %s is not routed by testutil.SetupTestServer", method, path)
+				}
+				route := &routers.Route{Spec: doc, Path: path, PathItem: item, Method: method, Operation: op}
+				params := parameters(item, op)
+				valid := validCall(doc, op, params)
+
+				for _, c := range append([]call{valid}, invalidCalls(doc, op, params, valid)...) {
+					t.Run(c.name, func(t *testing.T) {
+						checkCall(t, route, base, c)
+					})
+				}
+			})
+		}
+	}
+}
+
+// checkCall sends c to a fresh test server and fails unless the spec
+// declares the response
+func checkCall(t *testing.T, route *routers.Route, base string, c call) {
+	ctx := context.Background()
+
+	// Arrange
+	req, err := c.request(base, route.Path, route.Method)
+	require.NoError(t, err)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: c.path,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	specErr := openapi3filter.ValidateRequest(ctx, input)
+	switch {
+	case !c.invalid:
+		require.NoError(t, specErr, "the generated request breaks the spec")
+	case c.token != "" || !secured(route.Spec, route.Operation):
+		require.Error(t, specErr, "the generated request should break the spec")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if streams(route.Operation) {
+		ctx, cancel := context.WithTimeout(ctx, streamFor)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	rec := httptest.NewRecorder()
+
+	// Act
+	testutil.SetupTestServer(t).ServeHTTP(rec, req)
+
+	// Assert
+	responses := route.Operation.Responses
+	if responses.Status(rec.Code) == nil && responses.Default() == nil {
+		t.Fatalf("undocumented %d response: %s", rec.Code, rec.Body.String())
+	}
+	if c.invalid {
+		assert.GreaterOrEqual(t, rec.Code, http.StatusBadRequest, "an invalid request was accepted")
+		assert.Less(t, rec.Code, http.StatusInternalServerError)
+	} else {
+		assert.Less(t, rec.Code, http.StatusInternalServerError, rec.Body.String())
+	}
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	assert.NoError(t, err, "the %d response does not match the spec", rec.Code)
+}
+
+// streams reports whether op answers with Server-Sent Events, which only
+// end when the client goes away
+func streams(op *openapi3.Operation) bool {
+	ok := op.Responses.Status(http.StatusOK)
+	return ok != nil && ok.Value.Content.Get("text/event-stream") != nil
+}
+
+// parameters returns the parameters of op, including those declared on its
+// path that op does not override
+func parameters(item *openapi3.PathItem, op *openapi3.Operation) openapi3.Parameters {
+	params := append(openapi3.Parameters{}, op.Parameters...)
+	for _, ref := range item.Parameters {
+		if op.Parameters.GetByInAndName(ref.Value.In, ref.Value.Name) == nil {
+			params = append(params, ref)
+		}
+	}
+	return params
+}
@@ -0,0 +1,345 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/your-org/your-app/internal/testutil"
+)
+
+// call is one request generated for an operation
+type call struct {
+	name   string
+	path   map[string]string
+	query  url.Values
+	header http.Header
+	body   any
+	// rawBody, when set, is sent instead of body
+	rawBody string
+	token   string
+	// invalid calls break the spec and must be answered with a 4xx
+	invalid bool
+}
+
+func (c call) clone() call {
+	out := c
+	out.path = maps.Clone(c.path)
+	out.query = url.Values{}
+	for k, v := range c.query {
+		out.query[k] = slices.Clone(v)
+	}
+	out.header = c.header.Clone()
+	out.body = deepCopy(c.body)
+	return out
+}
+
+// request builds the HTTP request of c against an operation at path,
+// relative to base
+func (c call) request(base, path, method string) (*http.Request, error) {
+	for name, value := range c.path {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	target := base + path
+	if len(c.query) > 0 {
+		target += "?" + c.query.Encode()
+	}
+
+	var body string
+	switch {
+	case c.rawBody != "":
+		body = c.rawBody
+	case c.body != nil:
+		b, err := json.Marshal(c.body)
+		if err != nil {
+			return nil, err
+		}
+		body = string(b)
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header = c.header.Clone()
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// validCall returns a request to op that satisfies the spec: required
+// parameters, parameters with an example and a JSON body built from the
+// schema's examples
+func validCall(doc *openapi3.T, op *openapi3.Operation, params openapi3.Parameters) call {
+	c := call{name: "valid", path: map[string]string{}, query: url.Values{}, header: http.Header{}}
+	for _, ref := range params {
+		p := ref.Value
+		if !p.Required && p.Example == nil {
+			continue
+		}
+		value := p.Example
+		if value == nil && p.Schema != nil {
+			value = sample(p.Schema.Value)
+		}
+		switch p.In {
+		case openapi3.ParameterInPath:
+			c.path[p.Name] = fmt.Sprint(value)
+		case openapi3.ParameterInQuery:
+			c.query.Set(p.Name, fmt.Sprint(value))
+		case openapi3.ParameterInHeader:
+			c.header.Set(p.Name, fmt.Sprint(value))
+		}
+	}
+
+	if media := jsonBody(op); media != nil {
+		switch {
+		case media.Example != nil:
+			c.body = media.Example
+		case len(media.Examples) > 0:
+			c.body = media.Examples[sortedKeys(media.Examples)[0]].Value.Value
+		default:
+			c.body = sample(media.Schema.Value)
+		}
+	}
+
+	if secured(doc, op) || op.Security != nil {
+		c.token = testutil.AdminToken
+	}
+	return c
+}
+
+// invalidCalls returns variations of valid that each break one rule of the
+// spec
+func invalidCalls(doc *openapi3.T, op *openapi3.Operation, params openapi3.Parameters, valid call) []call {
+	var calls []call
+	for _, ref := range params {
+		p := ref.Value
+		if p.Schema == nil || p.In == openapi3.ParameterInHeader {
+			continue
+		}
+		for _, v := range invalidValues(p.Schema.Value) {
+			s := fmt.Sprint(v.value)
+			if s == "" {
+				// An empty parameter reads as a missing one
+				continue
+			}
+			c := valid.clone()
+			c.name = fmt.Sprintf("%s %s %s", p.In, p.Name, v.reason)
+			c.invalid = true
+			if p.In == openapi3.ParameterInPath {
+				c.path[p.Name] = s
+			} else {
+				c.query.Set(p.Name, s)
+			}
+			calls = append(calls, c)
+		}
+	}
+
+	if media := jsonBody(op); media != nil {
+		c := valid.clone()
+		c.name = "malformed body"
+		c.rawBody = "{"
+		c.invalid = true
+		calls = append(calls, c)
+
+		schema := media.Schema.Value
+		if _, ok := valid.body.(map[string]any); !ok {
+			return append(calls, authCalls(doc, op, valid)...)
+		}
+		for _, prop := range schema.Required {
+			c := valid.clone()
+			c.name = "body without " + prop
+			delete(c.body.(map[string]any), prop)
+			c.invalid = true
+			calls = append(calls, c)
+		}
+		for _, prop := range sortedKeys(schema.Properties) {
+			for _, v := range invalidValues(schema.Properties[prop].Value) {
+				c := valid.clone()
+				c.name = fmt.Sprintf("body %s %s", prop, v.reason)
+				c.body.(map[string]any)[prop] = v.value
+				c.invalid = true
+				calls = append(calls, c)
+			}
+		}
+	}
+
+	return append(calls, authCalls(doc, op, valid)...)
+}
+
+// authCalls returns valid without its token when op requires one
+func authCalls(doc *openapi3.T, op *openapi3.Operation, valid call) []call {
+	if !secured(doc, op) {
+		return nil
+	}
+	c := valid.clone()
+	c.name = "no token"
+	c.token = ""
+	c.invalid = true
+	return []call{c}
+}
+
+// sample returns a value that satisfies schema, preferring its example
+func sample(schema *openapi3.Schema) any {
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := map[string]any{}
+		for _, ref := range schema.AllOf {
+			if m, ok := sample(ref.Value).(map[string]any); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return sample(schema.OneOf[0].Value)
+	case len(schema.AnyOf) > 0:
+		return sample(schema.AnyOf[0].Value)
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject) || len(schema.Properties) > 0:
+		obj := map[string]any{}
+		for _, name := range sortedKeys(schema.Properties) {
+			prop := schema.Properties[name].Value
+			if slices.Contains(schema.Required, name) || prop.Example != nil {
+				obj[name] = sample(prop)
+			}
+		}
+		return obj
+	case schema.Type.Is(openapi3.TypeArray):
+		items := make([]any, max(int(schema.MinItems), 1))
+		for i := range items {
+			items[i] = sample(schema.Items.Value)
+		}
+		return items
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 1
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return true
+	}
+
+	switch schema.Format {
+	case "date-time":
+		return "2026-01-01T00:00:00Z"
+	case "date":
+		return "2026-01-01"
+	case "email":
+		return "alice@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	}
+	return strings.Repeat("a", max(int(schema.MinLength), 1))
+}
+
+// invalidValue is a value schema rejects, and why
+type invalidValue struct {
+	reason string
+	value  any
+}
+
+// invalidValues returns one value per constraint of schema that a request
+// can break
+func invalidValues(schema *openapi3.Schema) []invalidValue {
+	var values []invalidValue
+	if len(schema.Enum) > 0 {
+		values = append(values, invalidValue{"outside enum", "not-a-listed-value"})
+	}
+	switch {
+	case schema.Type.Is(openapi3.TypeString):
+		if schema.MaxLength != nil {
+			values = append(values, invalidValue{"over maxLength", strings.Repeat("a", int(*schema.MaxLength)+1)})
+		}
+		if schema.MinLength > 0 {
+			values = append(values, invalidValue{"under minLength", strings.Repeat("a", int(schema.MinLength)-1)})
+		}
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		values = append(values, invalidValue{"not a number", "abc"})
+		if schema.Min != nil {
+			values = append(values, invalidValue{"under minimum", *schema.Min - 1})
+		}
+		if schema.Max != nil {
+			values = append(values, invalidValue{"over maximum", *schema.Max + 1})
+		}
+	case schema.Type.Is(openapi3.TypeBoolean):
+		values = append(values, invalidValue{"not a boolean", "maybe"})
+	}
+	return values
+}
+
+// jsonBody returns the JSON request body of op, or nil
+func jsonBody(op *openapi3.Operation) *openapi3.MediaType {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return nil
+	}
+	media := op.RequestBody.Value.Content.Get("application/json")
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+	return media
+}
+
+// secured reports whether op requires a bearer token; an empty security
+// requirement lets anonymous callers in
+func secured(doc *openapi3.T, op *openapi3.Operation) bool {
+	requirements := doc.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 {
+		return false
+	}
+	for _, r := range requirements {
+		if len(r) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// deepCopy copies the JSON value v so variations do not share maps
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = deepCopy(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = deepCopy(e)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,191 @@
+// Package cron runs recurring maintenance jobs triggered by Cloud Scheduler.
+//
+// Every job is declared once in definitions.go. The backend binds a handler
+// to each definition at startup, and the same definitions are exported to
+// api/cron.json, from which Pulumi creates one Cloud Scheduler job per entry
+// targeting POST /internal/cron/{job}.
+package cron
+
+//go:generate go run ../../cmd/cronmanifest -o ../../api/cron.json
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeZone is used by definitions that do not set one
+const DefaultTimeZone = "Etc/UTC"
+
+// DefaultTimeout is used by definitions that do not set one
+const DefaultTimeout = 5 * time.Minute
+
+// maxTimeout is the longest attempt deadline Cloud Scheduler allows for HTTP targets
+const maxTimeout = 30 * time.Minute
+
+var (
+	// ErrUnknownJob is returned for job names without a definition
+	ErrUnknownJob = errors.New("cron: unknown job")
+	// ErrNotRegistered is returned for definitions without a handler
+	ErrNotRegistered = errors.New("cron: no handler registered")
+)
+
+// Definition declares a recurring job
+type Definition struct {
+	// Name identifies the job in routes, locks and Cloud Scheduler
+	Name string `json:"name"`
+	// Schedule is a unix-cron expression, e.g. "0 3 * * *"
+	Schedule string `json:"schedule"`
+	// TimeZone is the IANA zone the schedule is evaluated in
+	TimeZone string `json:"timeZone"`
+	// Description is shown in the Cloud Scheduler console
+	Description string `json:"description"`
+	// Timeout bounds a single run; it also sizes the overlap lock and the
+	// Cloud Scheduler attempt deadline
+	Timeout time.Duration `json:"-"`
+}
+
+// timeout returns the definition's timeout or the default
+func (d Definition) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultTimeout
+}
+
+// validate checks the fields Cloud Scheduler would reject
+func (d Definition) validate() error {
+	if d.Name == "" || strings.ContainsAny(d.Name, "/ ") {
+		return fmt.Errorf("cron: invalid job name %q", d.Name)
+	}
+	if len(strings.Fields(d.Schedule)) != 5 {
+		return fmt.Errorf("cron: %s: schedule %q must have five fields", d.Name, d.Schedule)
+	}
+	if _, err := time.LoadLocation(d.TimeZone); d.TimeZone != "" && err != nil {
+		return fmt.Errorf("cron: %s: %w", d.Name, err)
+	}
+	if d.Timeout > maxTimeout {
+		return fmt.Errorf("cron: %s: timeout %s exceeds %s", d.Name, d.Timeout, maxTimeout)
+	}
+	return nil
+}
+
+// Handler runs one execution of a job
+type Handler func(ctx context.Context) error
+
+// Registry binds handlers to the declared definitions
+type Registry struct {
+	mu       sync.RWMutex
+	defs     map[string]Definition
+	handlers map[string]Handler
+}
+
+// NewRegistry creates a registry of the given definitions
+func NewRegistry(defs []Definition) (*Registry, error) {
+	r := &Registry{
+		defs:     make(map[string]Definition, len(defs)),
+		handlers: make(map[string]Handler, len(defs)),
+	}
+	for _, def := range defs {
+		if err := def.validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := r.defs[def.Name]; dup {
+			return nil, fmt.Errorf("cron: job %q declared twice", def.Name)
+		}
+		r.defs[def.Name] = def
+	}
+	return r, nil
+}
+
+// Register binds handler to the declared job def. Binding a job that is not
+// declared, or binding twice, panics as it always indicates a wiring mistake.
+func (r *Registry) Register(def Definition, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.defs[def.Name]; !ok {
+		panic(fmt.Sprintf("cron: job %q is not declared in definitions.go", def.Name))
+	}
+	if _, dup := r.handlers[def.Name]; dup {
+		panic(fmt.Sprintf("cron: handler for %q registered twice", def.Name))
+	}
+	r.handlers[def.Name] = handler
+}
+
+// Validate returns an error naming every declared job without a handler
+func (r *Registry) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var missing []string
+	for name := range r.defs {
+		if _, ok := r.handlers[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s", ErrNotRegistered, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Definitions returns the registered definitions sorted by name
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.defs))
+	for _, def := range r.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+func (r *Registry) lookup(name string) (Definition, Handler, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.defs[name]
+	if !ok {
+		return Definition{}, nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	handler, ok := r.handlers[name]
+	if !ok {
+		return Definition{}, nil, fmt.Errorf("%w: %s", ErrNotRegistered, name)
+	}
+	return def, handler, nil
+}
+
+// manifestEntry is the Pulumi-facing form of a definition
+type manifestEntry struct {
+	Definition
+	AttemptDeadline string `json:"attemptDeadline"`
+}
+
+// WriteManifest writes defs as the JSON manifest consumed by Pulumi
+func WriteManifest(w io.Writer, defs []Definition) error {
+	entries := make([]manifestEntry, 0, len(defs))
+	for _, def := range defs {
+		if def.TimeZone == "" {
+			def.TimeZone = DefaultTimeZone
+		}
+		entries = append(entries, manifestEntry{
+			Definition:      def,
+			AttemptDeadline: fmt.Sprintf("%ds", int(def.timeout().Seconds())),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
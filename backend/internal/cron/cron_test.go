@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry_RejectsInvalidDefinitions(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []Definition
+	}{
+		{
+			name: "empty name",
+			defs: []Definition{{Schedule: "* * * * *"}},
+		},
+		{
+			name: "name with slash",
+			defs: []Definition{{Name: "a/b", Schedule: "* * * * *"}},
+		},
+		{
+			name: "six field schedule",
+			defs: []Definition{{Name: "job", Schedule: "0 * * * * *"}},
+		},
+		{
+			name: "unknown time zone",
+			defs: []Definition{{Name: "job", Schedule: "* * * * *", TimeZone: "Mars/Olympus"}},
+		},
+		{
+			name: "timeout beyond scheduler limit",
+			defs: []Definition{{Name: "job", Schedule: "* * * * *", Timeout: time.Hour}},
+		},
+		{
+			name: "duplicate name",
+			defs: []Definition{{Name: "job", Schedule: "* * * * *"}, {Name: "job", Schedule: "0 * * * *"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := NewRegistry(tt.defs)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	// Arrange
+	a := Definition{Name: "a", Schedule: "* * * * *"}
+	b := Definition{Name: "b", Schedule: "* * * * *"}
+	registry, err := NewRegistry([]Definition{a, b})
+	require.NoError(t, err)
+	registry.Register(a, func(context.Context) error { return nil })
+
+	// Act
+	missing := registry.Validate()
+	registry.Register(b, func(context.Context) error { return nil })
+	complete := registry.Validate()
+
+	// Assert
+	assert.ErrorIs(t, missing, ErrNotRegistered)
+	assert.Contains(t, missing.Error(), "b")
+	assert.NoError(t, complete)
+}
+
+func TestRegistry_Register_PanicsOnUndeclaredJob(t *testing.T) {
+	// Arrange
+	registry, err := NewRegistry(nil)
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.Panics(t, func() {
+		registry.Register(Definition{Name: "ghost"}, func(context.Context) error { return nil })
+	})
+}
+
+func TestDefinitions_AreValid(t *testing.T) {
+	// Act
+	_, err := NewRegistry(Definitions())
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+// TestManifest_UpToDate fails when definitions.go changes without
+// regenerating the manifest Pulumi deploys from
+func TestManifest_UpToDate(t *testing.T) {
+	// Arrange
+	committed, err := os.ReadFile("../../api/cron.json")
+	require.NoError(t, err)
+
+	// Act
+	var generated bytes.Buffer
+	err = WriteManifest(&generated, Definitions())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), generated.String(),
+		"api/cron.json is stale, run: go generate ./internal/cron")
+}
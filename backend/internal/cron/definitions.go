@@ -0,0 +1,29 @@
+package cron
+
+import "time"
+
+// PruneHistory deletes execution records older than the history retention
+var PruneHistory = Definition{
+	Name:        "prune-cron-history",
+	Schedule:    "30 3 * * *",
+	Description: "Delete cron execution records past their retention period",
+	Timeout:     10 * time.Minute,
+}
+
+// PurgeDeletedAccounts hard-deletes accounts whose deletion grace period is over
+var PurgeDeletedAccounts = Definition{
+	Name:        "purge-deleted-accounts",
+	Schedule:    "15 * * * *",
+	Description: "Hard-delete accounts whose deletion grace period has ended",
+	Timeout:     30 * time.Minute,
+}
+
+// Definitions returns every declared job. Add new jobs here, bind their
+// handlers at startup, then run `go generate ./internal/cron` to refresh the
+// manifest Pulumi reads.
+func Definitions() []Definition {
+	return []Definition{
+		PruneHistory,
+		PurgeDeletedAccounts,
+	}
+}
@@ -0,0 +1,156 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Firestore collections used by the cron store
+const (
+	locksCollection      = "cronLocks"
+	executionsCollection = "cronExecutions"
+)
+
+type lockDoc struct {
+	Holder    string    `firestore:"holder"`
+	ExpiresAt time.Time `firestore:"expiresAt"`
+}
+
+// FirestoreStore keeps locks and history in Firestore. Locks are taken in a
+// transaction so concurrent triggers across instances see a consistent view.
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+// Acquire implements Store
+func (s *FirestoreStore) Acquire(ctx context.Context, job, holder string, now, expiresAt time.Time) (bool, error) {
+	ref := s.client.Collection(locksCollection).Doc(job)
+	acquired := false
+
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		acquired = false
+
+		snap, err := tx.Get(ref)
+		if err != nil && !store.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			var lock lockDoc
+			if err := snap.DataTo(&lock); err != nil {
+				return err
+			}
+			if lock.Holder != holder && now.Before(lock.ExpiresAt) {
+				return nil
+			}
+		}
+
+		acquired = true
+		return tx.Set(ref, lockDoc{Holder: holder, ExpiresAt: expiresAt})
+	})
+	if err != nil {
+		return false, fmt.Errorf("cron: acquire lock %s: %w", job, err)
+	}
+	return acquired, nil
+}
+
+// Release implements Store
+func (s *FirestoreStore) Release(ctx context.Context, job, holder string) error {
+	ref := s.client.Collection(locksCollection).Doc(job)
+
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if store.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var lock lockDoc
+		if err := snap.DataTo(&lock); err != nil {
+			return err
+		}
+		if lock.Holder != holder {
+			return nil
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return fmt.Errorf("cron: release lock %s: %w", job, err)
+	}
+	return nil
+}
+
+// Record implements Store
+func (s *FirestoreStore) Record(ctx context.Context, exec Execution) error {
+	if _, _, err := s.client.Collection(executionsCollection).Add(ctx, exec); err != nil {
+		return fmt.Errorf("cron: record execution %s: %w", exec.Job, err)
+	}
+	return nil
+}
+
+// History implements Store. It relies on the (job, startedAt desc)
+// composite index declared in firestore.indexes.json.
+func (s *FirestoreStore) History(ctx context.Context, job string, limit int) ([]Execution, error) {
+	query := s.client.Collection(executionsCollection).
+		Where("job", "==", job).
+		OrderBy("startedAt", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("cron: list executions %s: %w", job, err)
+	}
+
+	out := make([]Execution, 0, len(docs))
+	for _, doc := range docs {
+		var exec Execution
+		if err := doc.DataTo(&exec); err != nil {
+			return nil, err
+		}
+		exec.ID = doc.Ref.ID
+		out = append(out, exec)
+	}
+	return out, nil
+}
+
+// Prune implements Store
+func (s *FirestoreStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	iter := s.client.Collection(executionsCollection).
+		Where("startedAt", "<", cutoff).
+		Documents(ctx)
+	defer iter.Stop()
+
+	writer := s.client.BulkWriter(ctx)
+	pruned := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			writer.End()
+			return pruned, fmt.Errorf("cron: prune executions: %w", err)
+		}
+		if _, err := writer.Delete(doc.Ref); err != nil {
+			writer.End()
+			return pruned, fmt.Errorf("cron: prune executions: %w", err)
+		}
+		pruned++
+	}
+	writer.End()
+	return pruned, nil
+}
@@ -0,0 +1,133 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/ids"
+)
+
+// lockGrace keeps the lock a little past the job timeout so a run that is
+// finishing up is not overlapped by the next trigger
+const lockGrace = 30 * time.Second
+
+// RunnerOptions configures a Runner
+type RunnerOptions struct {
+	// Clock and IDs default to the wall clock and crypto/rand; executions
+	// and locks are timed by Clock, lock holders are named by IDs
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// Runner executes jobs with an overlap lock and records their history
+type Runner struct {
+	registry *Registry
+	store    Store
+	logger   *zap.Logger
+	now      func() time.Time
+	ids      ids.Generator
+}
+
+// NewRunner creates a runner for the jobs in registry
+func NewRunner(registry *Registry, store Store, opts RunnerOptions, logger *zap.Logger) *Runner {
+	return &Runner{
+		registry: registry,
+		store:    store,
+		logger:   logger,
+		now:      clock.OrSystem(opts.Clock).Now,
+		ids:      ids.OrRandom(opts.IDs),
+	}
+}
+
+// Run executes the named job unless another execution holds its lock, and
+// records the outcome. Job failures are reported through the returned
+// execution's status; the error is reserved for unknown jobs and store
+// failures.
+func (r *Runner) Run(ctx context.Context, name string) (Execution, error) {
+	def, handler, err := r.registry.lookup(name)
+	if err != nil {
+		return Execution{}, err
+	}
+
+	holder := r.ids.NewID(8)
+	start := r.now()
+	exec := Execution{Job: name, StartedAt: start}
+
+	acquired, err := r.store.Acquire(ctx, name, holder, start, start.Add(def.timeout()+lockGrace))
+	if err != nil {
+		return Execution{}, err
+	}
+
+	log := r.logger.With(zap.String("cron_job", name))
+
+	if !acquired {
+		exec.Status = StatusSkipped
+		exec.FinishedAt = start
+		log.Info("cron job already running, skipped")
+		return exec, r.record(ctx, &exec)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, def.timeout())
+	runErr := safeRun(runCtx, handler)
+	cancel()
+
+	exec.FinishedAt = r.now()
+	exec.DurationMS = exec.FinishedAt.Sub(start).Milliseconds()
+	exec.Status = StatusSucceeded
+	if runErr != nil {
+		exec.Status = StatusFailed
+		exec.Error = runErr.Error()
+		log.Error("cron job failed", zap.Int64("duration_ms", exec.DurationMS), zap.Error(runErr))
+	} else {
+		log.Info("cron job succeeded", zap.Int64("duration_ms", exec.DurationMS))
+	}
+
+	// Bookkeeping must happen even if the trigger's request was cancelled
+	bg := context.WithoutCancel(ctx)
+	if err := r.store.Release(bg, name, holder); err != nil {
+		log.Warn("failed to release cron lock", zap.Error(err))
+	}
+	return exec, r.record(bg, &exec)
+}
+
+// History returns the latest executions of the named job
+func (r *Runner) History(ctx context.Context, name string, limit int) ([]Execution, error) {
+	if _, _, err := r.registry.lookup(name); err != nil {
+		return nil, err
+	}
+	return r.store.History(ctx, name, limit)
+}
+
+func (r *Runner) record(ctx context.Context, exec *Execution) error {
+	if err := r.store.Record(ctx, *exec); err != nil {
+		return fmt.Errorf("cron: record %s: %w", exec.Job, err)
+	}
+	return nil
+}
+
+// safeRun turns a handler panic into an error so the lock is still released
+func safeRun(ctx context.Context, handler Handler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return handler(ctx)
+}
+
+// PruneHistoryHandler deletes execution records older than retention, as
+// told by clk
+func PruneHistoryHandler(store Store, retention time.Duration, clk clock.Clock, logger *zap.Logger) Handler {
+	return func(ctx context.Context) error {
+		pruned, err := store.Prune(ctx, clk.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		logger.Info("pruned cron history", zap.Int("records", pruned))
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+var testJob = Definition{Name: "test-job", Schedule: "*/5 * * * *", Timeout: time.Minute}
+
+func newTestRunner(t *testing.T, handler Handler) (*Runner, *MemoryStore) {
+	t.Helper()
+
+	registry, err := NewRegistry([]Definition{testJob})
+	require.NoError(t, err)
+	registry.Register(testJob, handler)
+
+	store := NewMemoryStore()
+	return NewRunner(registry, store, RunnerOptions{}, zap.NewNop()), store
+}
+
+func TestRunner_Run(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        Handler
+		expectedStatus Status
+		expectedError  string
+	}{
+		{
+			name:           "records success",
+			handler:        func(context.Context) error { return nil },
+			expectedStatus: StatusSucceeded,
+		},
+		{
+			name:           "records failure",
+			handler:        func(context.Context) error { return errors.New("disk full") },
+			expectedStatus: StatusFailed,
+			expectedError:  "disk full",
+		},
+		{
+			name:           "records panic as failure",
+			handler:        func(context.Context) error { panic("nil map") },
+			expectedStatus: StatusFailed,
+			expectedError:  "panic: nil map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			runner, store := newTestRunner(t, tt.handler)
+
+			// Act
+			exec, err := runner.Run(context.Background(), testJob.Name)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, exec.Status)
+			assert.Equal(t, tt.expectedError, exec.Error)
+
+			history, err := store.History(context.Background(), testJob.Name, 10)
+			require.NoError(t, err)
+			require.Len(t, history, 1)
+			assert.Equal(t, tt.expectedStatus, history[0].Status)
+
+			// Lock is released whatever the outcome
+			acquired, err := store.Acquire(context.Background(), testJob.Name, "next", time.Now(), time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			assert.True(t, acquired)
+		})
+	}
+}
+
+func TestRunner_Run_SkipsOverlappingExecution(t *testing.T) {
+	// Arrange
+	ran := false
+	runner, store := newTestRunner(t, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	_, err := store.Acquire(context.Background(), testJob.Name, "other-instance", time.Now(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// Act
+	exec, err := runner.Run(context.Background(), testJob.Name)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, StatusSkipped, exec.Status)
+	assert.False(t, ran)
+}
+
+func TestRunner_Run_TakesOverExpiredLock(t *testing.T) {
+	// Arrange
+	ran := false
+	runner, store := newTestRunner(t, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	_, err := store.Acquire(context.Background(), testJob.Name, "crashed-instance", time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	// Act
+	exec, err := runner.Run(context.Background(), testJob.Name)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, exec.Status)
+	assert.True(t, ran)
+}
+
+// fixedClock is a clock.Clock that never moves
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+// fixedIDs hands out the same ID every time
+type fixedIDs string
+
+func (g fixedIDs) NewID(int) string { return string(g) }
+
+func TestRunner_Run_UsesClockAndIDs(t *testing.T) {
+	// Arrange
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	registry, err := NewRegistry([]Definition{testJob})
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	var holder string
+	registry.Register(testJob, func(context.Context) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		holder = store.locks[testJob.Name].holder
+		return nil
+	})
+	runner := NewRunner(registry, store, RunnerOptions{Clock: fixedClock(start), IDs: fixedIDs("holder-1")}, zap.NewNop())
+
+	// Act
+	exec, err := runner.Run(context.Background(), testJob.Name)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, exec.Status)
+	assert.Equal(t, start, exec.StartedAt)
+	assert.Equal(t, "holder-1", holder)
+}
+
+func TestRunner_Run_AppliesTimeout(t *testing.T) {
+	// Arrange
+	var deadline time.Time
+	runner, _ := newTestRunner(t, func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	})
+
+	// Act
+	_, err := runner.Run(context.Background(), testJob.Name)
+
+	// Assert
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(testJob.Timeout), deadline, 5*time.Second)
+}
+
+func TestRunner_Run_UnknownJob(t *testing.T) {
+	// Arrange
+	runner, _ := newTestRunner(t, func(context.Context) error { return nil })
+
+	// Act
+	_, err := runner.Run(context.Background(), "nope")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnknownJob)
+}
+
+func TestPruneHistoryHandler(t *testing.T) {
+	// Arrange
+	store := NewMemoryStore()
+	ctx := context.Background()
+	require.NoError(t, store.Record(ctx, Execution{Job: "a", StartedAt: time.Now().Add(-48 * time.Hour)}))
+	require.NoError(t, store.Record(ctx, Execution{Job: "a", StartedAt: time.Now()}))
+
+	// Act
+	err := PruneHistoryHandler(store, 24*time.Hour, clock.System{}, zap.NewNop())(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	history, err := store.History(ctx, "a", 0)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of an execution
+type Status string
+
+// Execution outcomes
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	// StatusSkipped means another execution of the job held the lock
+	StatusSkipped Status = "skipped"
+)
+
+// Execution records one trigger of a job
+type Execution struct {
+	ID         string    `firestore:"-" json:"id"`
+	Job        string    `firestore:"job" json:"job"`
+	Status     Status    `firestore:"status" json:"status"`
+	StartedAt  time.Time `firestore:"startedAt" json:"startedAt"`
+	FinishedAt time.Time `firestore:"finishedAt" json:"finishedAt"`
+	DurationMS int64     `firestore:"durationMs" json:"durationMs"`
+	Error      string    `firestore:"error,omitempty" json:"error,omitempty"`
+}
+
+// Store persists locks and execution history
+type Store interface {
+	// Acquire takes the lock for job on behalf of holder until expiresAt. It
+	// returns false if another holder's lock has not expired yet.
+	Acquire(ctx context.Context, job, holder string, now, expiresAt time.Time) (bool, error)
+	// Release drops the lock if holder still owns it
+	Release(ctx context.Context, job, holder string) error
+	// Record appends an execution to the history
+	Record(ctx context.Context, exec Execution) error
+	// History returns the latest executions of job, newest first
+	History(ctx context.Context, job string, limit int) ([]Execution, error)
+	// Prune deletes executions started before cutoff and returns how many
+	Prune(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+type memoryLock struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu         sync.Mutex
+	locks      map[string]memoryLock
+	executions []Execution
+	nextID     int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{locks: make(map[string]memoryLock)}
+}
+
+// Acquire implements Store
+func (s *MemoryStore) Acquire(_ context.Context, job, holder string, now, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, held := s.locks[job]; held && lock.holder != holder && now.Before(lock.expiresAt) {
+		return false, nil
+	}
+	s.locks[job] = memoryLock{holder: holder, expiresAt: expiresAt}
+	return true, nil
+}
+
+// Release implements Store
+func (s *MemoryStore) Release(_ context.Context, job, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.locks[job].holder == holder {
+		delete(s.locks, job)
+	}
+	return nil
+}
+
+// Record implements Store
+func (s *MemoryStore) Record(_ context.Context, exec Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	exec.ID = strconv.Itoa(s.nextID)
+	s.executions = append(s.executions, exec)
+	return nil
+}
+
+// History implements Store
+func (s *MemoryStore) History(_ context.Context, job string, limit int) ([]Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Execution
+	for _, exec := range s.executions {
+		if exec.Job == job {
+			out = append(out, exec)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// Prune implements Store
+func (s *MemoryStore) Prune(_ context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.executions[:0]
+	for _, exec := range s.executions {
+		if !exec.StartedAt.Before(cutoff) {
+			kept = append(kept, exec)
+		}
+	}
+	pruned := len(s.executions) - len(kept)
+	s.executions = kept
+	return pruned, nil
+}
@@ -0,0 +1,240 @@
+// Package events is an in-process publish/subscribe broker for pushing
+// real-time updates to connected clients
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// TopicBroadcast reaches every subscriber
+const TopicBroadcast = "broadcast"
+
+// Broker defaults
+const (
+	DefaultReplaySize       = 1000
+	DefaultSubscriberBuffer = 64
+)
+
+// ErrClosed is returned once the broker has shut down
+var ErrClosed = errors.New("events: broker closed")
+
+// UserTopic is the topic of events meant for a single user
+func UserTopic(uid string) string {
+	return "users/" + uid
+}
+
+// Event is a published message. IDs increase monotonically within a broker.
+type Event struct {
+	ID        string          `json:"id"`
+	Topic     string          `json:"-"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Options tunes a Broker
+type Options struct {
+	// ReplaySize is how many recent events are kept for Last-Event-ID resume
+	ReplaySize int
+	// SubscriberBuffer is how many events a subscriber may fall behind before
+	// it is dropped
+	SubscriberBuffer int
+	// Clock defaults to the wall clock; the epoch of event IDs is its time
+	// at NewBroker
+	Clock clock.Clock
+}
+
+// Broker fans published events out to subscribers of their topic and keeps a
+// bounded buffer of recent events so reconnecting clients can catch up
+type Broker struct {
+	opts Options
+	// epoch distinguishes IDs of this process from those of a previous one
+	epoch string
+
+	mu     sync.Mutex
+	seq    uint64
+	replay []Event
+	next   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker creates a broker
+func NewBroker(opts Options) *Broker {
+	if opts.ReplaySize <= 0 {
+		opts.ReplaySize = DefaultReplaySize
+	}
+	if opts.SubscriberBuffer <= 0 {
+		opts.SubscriberBuffer = DefaultSubscriberBuffer
+	}
+	opts.Clock = clock.OrSystem(opts.Clock)
+	return &Broker{
+		opts:   opts,
+		epoch:  strconv.FormatInt(opts.Clock.Now().UnixNano(), 36),
+		replay: make([]Event, 0, opts.ReplaySize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends an event to every subscriber of topic. Subscribers that
+// cannot keep up are dropped rather than blocking the publisher.
+func (b *Broker) Publish(topic, eventType string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("events: encoding %s: %w", eventType, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return Event{}, ErrClosed
+	}
+
+	b.seq++
+	event := Event{
+		ID:        b.epoch + "-" + strconv.FormatUint(b.seq, 10),
+		Topic:     topic,
+		Type:      eventType,
+		Data:      raw,
+		CreatedAt: b.opts.Clock.Now().UTC(),
+	}
+
+	if len(b.replay) < b.opts.ReplaySize {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[b.next] = event
+		b.next = (b.next + 1) % b.opts.ReplaySize
+	}
+
+	for sub := range b.subs {
+		if !sub.wants(topic) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+	return event, nil
+}
+
+// Subscribe registers for events on topics. When lastEventID is set, events
+// published after it are returned in Replay; Gap reports that some of them
+// were no longer buffered, so the client should refetch its state.
+func (b *Broker) Subscribe(topics []string, lastEventID string) (*Subscription, error) {
+	sub := &Subscription{
+		broker: b,
+		topics: make(map[string]bool, len(topics)),
+		ch:     make(chan Event, b.opts.SubscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	for _, t := range topics {
+		sub.topics[t] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	if lastEventID != "" {
+		sub.Replay, sub.Gap = b.since(lastEventID, sub)
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// since returns the buffered events after id on the subscriber's topics.
+// Callers hold b.mu.
+func (b *Broker) since(id string, sub *Subscription) ([]Event, bool) {
+	epoch, raw, ok := strings.Cut(id, "-")
+	seq, err := strconv.ParseUint(raw, 10, 64)
+	if !ok || err != nil || epoch != b.epoch || seq > b.seq {
+		// Unknown or from another process: everything may have been missed
+		return nil, true
+	}
+
+	var events []Event
+	oldest := b.seq + 1
+	for i := range b.replay {
+		event := b.replay[(b.next+i)%len(b.replay)]
+		eventSeq := b.seq - uint64(len(b.replay)-1-i)
+		if i == 0 {
+			oldest = eventSeq
+		}
+		if eventSeq > seq && sub.wants(event.Topic) {
+			events = append(events, event)
+		}
+	}
+	return events, seq+1 < oldest
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription and rejects further use
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// drop ends a subscription. Callers hold b.mu.
+func (b *Broker) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.done)
+	}
+}
+
+// Subscription receives events for a set of topics
+type Subscription struct {
+	// Replay holds missed events requested with a Last-Event-ID
+	Replay []Event
+	// Gap reports that events after the Last-Event-ID were lost
+	Gap bool
+
+	broker *Broker
+	topics map[string]bool
+	ch     chan Event
+	done   chan struct{}
+}
+
+// Events delivers live events in publish order
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Done is closed when the subscription ends: it was closed, fell too far
+// behind, or the broker shut down
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s)
+}
+
+func (s *Subscription) wants(topic string) bool {
+	return topic == TopicBroadcast || s.topics[topic]
+}
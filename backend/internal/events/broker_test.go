@@ -0,0 +1,155 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, sub *Subscription) []string {
+	t.Helper()
+	var types []string
+	for {
+		select {
+		case e := <-sub.Events():
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func types(events []Event) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Type)
+	}
+	return out
+}
+
+func TestBroker_TopicFiltering(t *testing.T) {
+	// Arrange
+	b := NewBroker(Options{})
+	alice, err := b.Subscribe([]string{UserTopic("alice")}, "")
+	require.NoError(t, err)
+	bob, err := b.Subscribe([]string{UserTopic("bob")}, "")
+	require.NoError(t, err)
+
+	// Act
+	_, err = b.Publish(UserTopic("alice"), "a.1", nil)
+	require.NoError(t, err)
+	_, err = b.Publish(UserTopic("bob"), "b.1", nil)
+	require.NoError(t, err)
+	_, err = b.Publish(TopicBroadcast, "all", map[string]string{"k": "v"})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []string{"a.1", "all"}, receive(t, alice))
+	assert.Equal(t, []string{"b.1", "all"}, receive(t, bob))
+}
+
+func TestBroker_Replay(t *testing.T) {
+	tests := []struct {
+		name           string
+		lastEventID    func(ids []string) string
+		expectedTypes  []string
+		expectedGapped bool
+	}{
+		{
+			name:          "resumes after the given event",
+			lastEventID:   func(ids []string) string { return ids[2] },
+			expectedTypes: []string{"e3", "e4"},
+		},
+		{
+			name:          "nothing missed",
+			lastEventID:   func(ids []string) string { return ids[4] },
+			expectedTypes: nil,
+		},
+		{
+			name:           "resume point evicted from buffer",
+			lastEventID:    func(ids []string) string { return ids[0] },
+			expectedTypes:  []string{"e2", "e3", "e4"},
+			expectedGapped: true,
+		},
+		{
+			name:           "oldest buffered event is contiguous",
+			lastEventID:    func(ids []string) string { return ids[1] },
+			expectedTypes:  []string{"e2", "e3", "e4"},
+			expectedGapped: false,
+		},
+		{
+			name:           "id from another process",
+			lastEventID:    func([]string) string { return "otherepoch-3" },
+			expectedGapped: true,
+		},
+		{
+			name:           "malformed id",
+			lastEventID:    func([]string) string { return "garbage" },
+			expectedGapped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			b := NewBroker(Options{ReplaySize: 3})
+			_, err := b.Publish(UserTopic("bob"), "other", nil)
+			require.NoError(t, err)
+			var ids []string
+			for _, typ := range []string{"e0", "e1", "e2", "e3", "e4"} {
+				e, err := b.Publish(UserTopic("alice"), typ, nil)
+				require.NoError(t, err)
+				ids = append(ids, e.ID)
+			}
+
+			// Act
+			sub, err := b.Subscribe([]string{UserTopic("alice")}, tt.lastEventID(ids))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTypes, types(sub.Replay))
+			assert.Equal(t, tt.expectedGapped, sub.Gap)
+		})
+	}
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	// Arrange
+	b := NewBroker(Options{SubscriberBuffer: 2})
+	slow, err := b.Subscribe([]string{UserTopic("alice")}, "")
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, err := b.Publish(UserTopic("alice"), "e", nil)
+		require.NoError(t, err)
+	}
+
+	// Assert
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow subscriber was not dropped")
+	}
+	assert.Equal(t, 0, b.Subscribers())
+}
+
+func TestBroker_Close(t *testing.T) {
+	// Arrange
+	b := NewBroker(Options{})
+	sub, err := b.Subscribe([]string{UserTopic("alice")}, "")
+	require.NoError(t, err)
+
+	// Act
+	b.Close()
+	sub.Close()
+	_, publishErr := b.Publish(UserTopic("alice"), "e", nil)
+	_, subscribeErr := b.Subscribe(nil, "")
+
+	// Assert
+	_, open := <-sub.Done()
+	assert.False(t, open)
+	assert.ErrorIs(t, publishErr, ErrClosed)
+	assert.ErrorIs(t, subscribeErr, ErrClosed)
+}
@@ -0,0 +1,431 @@
+// Package files lets clients upload and download files through signed URLs.
+//
+// The API never proxies file bytes. A client asks for an upload, receives a
+// short-lived signed PUT URL scoped to an object under its own
+// users/{userId}/ prefix, and uploads directly to storage. When storage
+// reports the object as finalized, the service checks it against the limits
+// that were signed into the URL and marks the file ready, after the
+// Processor is done with it if one accepts the file.
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/store"
+)
+
+var (
+	// ErrContentType is returned for content types outside the allow-list
+	ErrContentType = errors.New("files: content type not allowed")
+	// ErrTooLarge is returned for files above the size limit
+	ErrTooLarge = errors.New("files: file too large")
+	// ErrInvalidSize is returned for non-positive declared sizes
+	ErrInvalidSize = errors.New("files: size must be positive")
+	// ErrNotFound is returned for unknown files or files of another owner
+	ErrNotFound = errors.New("files: file not found")
+	// ErrNotReady is returned when downloading a file whose upload has not completed
+	ErrNotReady = errors.New("files: file not ready")
+)
+
+// Status is the lifecycle state of a file
+type Status string
+
+// File states
+const (
+	// StatusPending files have an upload URL but no finalized object yet
+	StatusPending Status = "pending"
+	// StatusProcessing files passed validation and wait for the Processor
+	StatusProcessing Status = "processing"
+	// StatusReady files have been uploaded and validated
+	StatusReady Status = "ready"
+	// StatusRejected files were uploaded but failed validation and were deleted
+	StatusRejected Status = "rejected"
+)
+
+// Events emitted to the listeners registered with Service.OnEvent
+const (
+	// EventReady is emitted when an upload has been validated
+	EventReady = "file.ready"
+	// EventRejected is emitted when an upload failed validation and was deleted
+	EventRejected = "file.rejected"
+	// EventDeleted is emitted when the owner deleted a file
+	EventDeleted = "file.deleted"
+)
+
+// Listener is notified after a file changed state. It runs synchronously and
+// cannot fail the change, so it should only hand the event off.
+type Listener func(ctx context.Context, event string, f File)
+
+// Processor post-processes validated uploads. Files it accepts stay
+// processing, and cannot be downloaded, until it calls Service.Complete or
+// Service.Reject.
+type Processor interface {
+	// Accepts reports whether f needs processing
+	Accepts(f File) bool
+	// Process hands f off, usually to the job queue
+	Process(ctx context.Context, f File) error
+}
+
+// File is the metadata of an uploaded file
+type File struct {
+	ID          string    `firestore:"-" json:"id"`
+	OwnerID     string    `firestore:"ownerId" json:"ownerId"`
+	Name        string    `firestore:"name" json:"name"`
+	Object      string    `firestore:"object" json:"-"`
+	ContentType string    `firestore:"contentType" json:"contentType"`
+	Size        int64     `firestore:"size" json:"size"`
+	Status      Status    `firestore:"status" json:"status"`
+	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time `firestore:"updatedAt" json:"updatedAt"`
+}
+
+// Limits restricts what may be uploaded
+type Limits struct {
+	// MaxSize is the largest accepted file in bytes, inclusive
+	MaxSize int64
+	// AllowedTypes lists accepted content types; "image/*" matches any image type
+	AllowedTypes []string
+}
+
+// Allows reports whether contentType is on the allow-list
+func (l Limits) Allows(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" || strings.ContainsAny(contentType, ";,") {
+		return false
+	}
+	for _, allowed := range l.AllowedTypes {
+		allowed = strings.ToLower(allowed)
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadRequest describes a file a client wants to upload
+type UploadRequest struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// Upload is a pending file together with the URL to upload it to
+type Upload struct {
+	File   File      `json:"file"`
+	Upload SignedURL `json:"upload"`
+}
+
+// Service issues signed URLs and tracks file metadata
+type Service struct {
+	storage Storage
+	store   Store
+	limits  Limits
+	ttl     time.Duration
+	logger  *zap.Logger
+	now     func() time.Time
+	ids     ids.Generator
+
+	mu        sync.RWMutex
+	listeners []Listener
+	processor Processor
+}
+
+// Options tunes the service
+type Options struct {
+	// Limits restrict the uploads the service signs URLs for
+	Limits Limits
+	// URLExpiry is how long signed URLs are valid
+	URLExpiry time.Duration
+	// Clock and IDs default to the wall clock and crypto/rand
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// NewService creates a file service
+func NewService(storage Storage, store Store, opts Options, logger *zap.Logger) *Service {
+	return &Service{
+		storage: storage,
+		store:   store,
+		limits:  opts.Limits,
+		ttl:     opts.URLExpiry,
+		logger:  logger,
+		now:     clock.OrSystem(opts.Clock).Now,
+		ids:     ids.OrRandom(opts.IDs),
+	}
+}
+
+// OnEvent adds a listener for file events. Listeners run in the order
+// they were added.
+func (s *Service) OnEvent(l Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// SetProcessor sets the processor of validated uploads, replacing any
+// previous one
+func (s *Service) SetProcessor(p Processor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processor = p
+}
+
+func (s *Service) emit(ctx context.Context, event string, f File) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		l(ctx, event, f)
+	}
+}
+
+// CreateUpload records a pending file for ownerID and returns a URL that
+// accepts exactly the declared content type, up to the size limit
+func (s *Service) CreateUpload(ctx context.Context, ownerID string, req UploadRequest) (*Upload, error) {
+	if err := s.check(req.ContentType, req.Size); err != nil {
+		return nil, err
+	}
+
+	id := s.ids.NewID(10)
+
+	now := s.now()
+	name := sanitizeName(req.Name)
+	f := File{
+		ID:          id,
+		OwnerID:     ownerID,
+		Name:        name,
+		Object:      ObjectName(ownerID, id, name),
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := Authorize(f.writeAccess(f.ContentType, f.Size)); err != nil {
+		return nil, err
+	}
+
+	signed, err := s.storage.SignUpload(ctx, f.Object, UploadConstraints{
+		ContentType: f.ContentType,
+		MaxSize:     s.limits.MaxSize,
+	}, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(ctx, f); err != nil {
+		return nil, err
+	}
+	return &Upload{File: f, Upload: signed}, nil
+}
+
+// Get returns the owner's file
+func (s *Service) Get(ctx context.Context, ownerID, id string) (*File, error) {
+	f, err := s.store.Get(ctx, ownerID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// List returns the owner's files, newest first
+func (s *Service) List(ctx context.Context, ownerID string, limit int) ([]File, error) {
+	return s.store.List(ctx, ownerID, limit)
+}
+
+// Download returns a signed URL for a ready file of the owner
+func (s *Service) Download(ctx context.Context, ownerID, id string) (SignedURL, error) {
+	f, err := s.Get(ctx, ownerID, id)
+	if err != nil {
+		return SignedURL{}, err
+	}
+	if f.Status != StatusReady {
+		return SignedURL{}, ErrNotReady
+	}
+	return s.storage.SignDownload(ctx, f.Object, s.ttl)
+}
+
+// Delete removes the owner's file and its object
+func (s *Service) Delete(ctx context.Context, ownerID, id string) error {
+	f, err := s.Get(ctx, ownerID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.Delete(ctx, f.Object); err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, ownerID, id); err != nil {
+		return err
+	}
+	s.emit(ctx, EventDeleted, *f)
+	return nil
+}
+
+// Finalize handles a finalized object reported by storage. Objects outside
+// the managed users/{userId}/files/ layout are ignored. Uploads that do not
+// match their metadata, the limits or the storage policy are deleted and
+// marked rejected, since the signed URL only constrains well-behaved
+// clients. Finalize is idempotent, as notifications may be delivered more
+// than once.
+func (s *Service) Finalize(ctx context.Context, attrs ObjectAttrs) error {
+	ownerID, id, ok := ParseObjectName(attrs.Name)
+	if !ok {
+		return nil
+	}
+
+	log := s.logger.With(zap.String("object", attrs.Name))
+
+	f, err := s.store.Get(ctx, ownerID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		log.Warn("finalized object has no file record")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if f.Object != attrs.Name || f.Status != StatusPending {
+		return nil
+	}
+
+	f.UpdatedAt = s.now()
+	err = s.check(attrs.ContentType, attrs.Size)
+	if err == nil {
+		err = Authorize(f.writeAccess(attrs.ContentType, attrs.Size))
+	}
+	if err != nil || attrs.ContentType != f.ContentType {
+		log.Warn("rejecting upload",
+			zap.String("content_type", attrs.ContentType),
+			zap.Int64("size", attrs.Size),
+			zap.Error(err),
+		)
+		if err := s.storage.Delete(ctx, attrs.Name); err != nil {
+			return err
+		}
+		f.Status = StatusRejected
+		return s.update(ctx, EventRejected, *f)
+	}
+
+	f.Size = attrs.Size
+
+	s.mu.RLock()
+	processor := s.processor
+	s.mu.RUnlock()
+	if processor == nil || !processor.Accepts(*f) {
+		f.Status = StatusReady
+		return s.update(ctx, EventReady, *f)
+	}
+
+	f.Status = StatusProcessing
+	if err := s.store.Update(ctx, *f); err != nil {
+		return err
+	}
+	if err := processor.Process(ctx, *f); err != nil {
+		// Back to pending, so the redelivered notification tries again
+		f.Status = StatusPending
+		return errors.Join(err, s.store.Update(ctx, *f))
+	}
+	return nil
+}
+
+// Complete marks a processing file ready once its Processor is done. size
+// replaces the uploaded size when processing rewrote the object.
+func (s *Service) Complete(ctx context.Context, ownerID, id string, size int64) error {
+	f, err := s.Get(ctx, ownerID, id)
+	if err != nil || f.Status != StatusProcessing {
+		return err
+	}
+	f.Size = size
+	f.Status = StatusReady
+	f.UpdatedAt = s.now()
+	return s.update(ctx, EventReady, *f)
+}
+
+// Reject deletes a processing file's object and marks it rejected, for
+// uploads its Processor found invalid
+func (s *Service) Reject(ctx context.Context, ownerID, id string, reason error) error {
+	f, err := s.Get(ctx, ownerID, id)
+	if err != nil || f.Status != StatusProcessing {
+		return err
+	}
+	s.logger.Warn("rejecting processed upload", zap.String("object", f.Object), zap.Error(reason))
+	if err := s.storage.Delete(ctx, f.Object); err != nil {
+		return err
+	}
+	f.Status = StatusRejected
+	f.UpdatedAt = s.now()
+	return s.update(ctx, EventRejected, *f)
+}
+
+// writeAccess is the owner's write of the file's object. The backend signs
+// uploads for the owner only, so the owner is the writer storage.rules sees.
+func (f File) writeAccess(contentType string, size int64) Access {
+	return Access{Op: OpWrite, Object: f.Object, UID: f.OwnerID, ContentType: contentType, Size: size}
+}
+
+func (s *Service) update(ctx context.Context, event string, f File) error {
+	if err := s.store.Update(ctx, f); err != nil {
+		return err
+	}
+	s.emit(ctx, event, f)
+	return nil
+}
+
+func (s *Service) check(contentType string, size int64) error {
+	if !s.limits.Allows(contentType) {
+		return ErrContentType
+	}
+	if size <= 0 {
+		return ErrInvalidSize
+	}
+	if size > s.limits.MaxSize {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// ObjectName returns the storage object holding a file
+func ObjectName(ownerID, id, name string) string {
+	return fmt.Sprintf("users/%s/files/%s/%s", ownerID, id, name)
+}
+
+// ParseObjectName extracts owner and file ID from an object created by ObjectName
+func ParseObjectName(object string) (ownerID, id string, ok bool) {
+	parts := strings.Split(object, "/")
+	if len(parts) != 5 || parts[0] != "users" || parts[2] != "files" ||
+		parts[1] == "" || parts[3] == "" || parts[4] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeName reduces a client-supplied filename to a safe object name segment
+func sanitizeName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "_"), "._")
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
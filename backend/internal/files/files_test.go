@@ -0,0 +1,434 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testLimits = Limits{MaxSize: 1024, AllowedTypes: []string{"image/*", "application/pdf"}}
+
+type testEnv struct {
+	service *Service
+	storage *LocalStorage
+	store   *MemoryStore
+	server  *httptest.Server
+}
+
+func setupService(t *testing.T) *testEnv {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	storage, err := NewLocalStorage(t.TempDir(), server.URL, []byte("test-key"), StorageOptions{})
+	require.NoError(t, err)
+	mux.Handle(LocalRoutePrefix, http.StripPrefix(LocalRoutePrefix, storage))
+
+	store := NewMemoryStore()
+	service := NewService(storage, store, Options{Limits: testLimits, URLExpiry: time.Minute}, zap.NewNop())
+	storage.OnFinalize(func(ctx context.Context, attrs ObjectAttrs) {
+		require.NoError(t, service.Finalize(ctx, attrs))
+	})
+
+	return &testEnv{service: service, storage: storage, store: store, server: server}
+}
+
+func put(t *testing.T, signed SignedURL, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(signed.Method, signed.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range signed.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestService_UploadAndDownload(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	content := []byte("\x89PNG fake image")
+
+	// Act
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{
+		Name: "../../avatar me.png", ContentType: "image/png", Size: int64(len(content)),
+	})
+	require.NoError(t, err)
+	putResp := put(t, upload.Upload, content)
+
+	file, getErr := env.service.Get(ctx, "user-1", upload.File.ID)
+	download, downloadErr := env.service.Download(ctx, "user-1", upload.File.ID)
+
+	// Assert
+	assert.Equal(t, StatusPending, upload.File.Status)
+	assert.Equal(t, "users/user-1/files/"+upload.File.ID+"/avatar_me.png", upload.File.Object)
+	assert.Equal(t, http.StatusOK, putResp.StatusCode)
+
+	require.NoError(t, getErr)
+	assert.Equal(t, StatusReady, file.Status)
+	assert.Equal(t, int64(len(content)), file.Size)
+
+	require.NoError(t, downloadErr)
+	resp, err := http.Get(download.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Equal(t, content, body)
+}
+
+func TestService_CreateUpload_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         UploadRequest
+		expectedErr error
+	}{
+		{
+			name:        "rejects disallowed content type",
+			req:         UploadRequest{Name: "a.html", ContentType: "text/html", Size: 10},
+			expectedErr: ErrContentType,
+		},
+		{
+			name:        "rejects content type with parameters",
+			req:         UploadRequest{Name: "a.png", ContentType: "image/png; charset=utf-8", Size: 10},
+			expectedErr: ErrContentType,
+		},
+		{
+			name:        "rejects oversized file",
+			req:         UploadRequest{Name: "a.png", ContentType: "image/png", Size: 1025},
+			expectedErr: ErrTooLarge,
+		},
+		{
+			name:        "rejects allowed type outside the storage policy",
+			req:         UploadRequest{Name: "a.pdf", ContentType: "application/pdf", Size: 10},
+			expectedErr: ErrForbidden,
+		},
+		{
+			name:        "rejects type the storage policy matches case-sensitively",
+			req:         UploadRequest{Name: "a.png", ContentType: "IMAGE/PNG", Size: 10},
+			expectedErr: ErrForbidden,
+		},
+		{
+			name:        "rejects empty file",
+			req:         UploadRequest{Name: "a.png", ContentType: "image/png", Size: 0},
+			expectedErr: ErrInvalidSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupService(t)
+
+			// Act
+			_, err := env.service.CreateUpload(context.Background(), "user-1", tt.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestService_Get_ScopedToOwner(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	// Act
+	_, getErr := env.service.Get(ctx, "user-2", upload.File.ID)
+	_, downloadErr := env.service.Download(ctx, "user-2", upload.File.ID)
+	deleteErr := env.service.Delete(ctx, "user-2", upload.File.ID)
+
+	// Assert
+	assert.ErrorIs(t, getErr, ErrNotFound)
+	assert.ErrorIs(t, downloadErr, ErrNotFound)
+	assert.ErrorIs(t, deleteErr, ErrNotFound)
+}
+
+func TestService_Download_NotReady(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	// Act
+	_, err = env.service.Download(ctx, "user-1", upload.File.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotReady)
+}
+
+func TestService_Finalize_RejectsMismatchedUpload(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	// Simulate a client bypassing the signed URL constraints
+	w, err := env.storage.Create(ctx, upload.File.Object, "text/html")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("<script>"))
+	require.NoError(t, w.Close())
+
+	// Act
+	err = env.service.Finalize(ctx, ObjectAttrs{Name: upload.File.Object, ContentType: "text/html", Size: 8})
+
+	// Assert
+	require.NoError(t, err)
+	file, err := env.service.Get(ctx, "user-1", upload.File.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, file.Status)
+
+	_, err = env.storage.Attrs(ctx, upload.File.Object)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestService_Finalize_IgnoresUnmanagedObjects(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+
+	// Act
+	errs := []error{
+		env.service.Finalize(context.Background(), ObjectAttrs{Name: "public/logo.png"}),
+		env.service.Finalize(context.Background(), ObjectAttrs{Name: "users/u/files/unknown/a.png"}),
+	}
+
+	// Assert
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+	put(t, upload.Upload, []byte("png"))
+
+	// Act
+	err = env.service.Delete(ctx, "user-1", upload.File.ID)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = env.service.Get(ctx, "user-1", upload.File.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = env.storage.Attrs(ctx, upload.File.Object)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestService_Events(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var events []string
+	env.service.OnEvent(func(_ context.Context, event string, f File) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event+":"+f.Name)
+	})
+
+	ready, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+	rejected, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "b.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	// Act
+	put(t, ready.Upload, []byte("png"))
+	require.NoError(t, env.service.Finalize(ctx, ObjectAttrs{Name: rejected.File.Object, ContentType: "text/html", Size: 3}))
+	require.NoError(t, env.service.Delete(ctx, "user-1", ready.File.ID))
+
+	// Assert
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{EventReady + ":a.png", EventRejected + ":b.png", EventDeleted + ":a.png"}, events)
+}
+
+func TestLimits_Allows(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    bool
+	}{
+		{contentType: "image/png", expected: true},
+		{contentType: "IMAGE/JPEG", expected: true},
+		{contentType: "application/pdf", expected: true},
+		{contentType: "application/pdfx", expected: false},
+		{contentType: "imagex/png", expected: false},
+		{contentType: "image/", expected: true},
+		{contentType: "", expected: false},
+		{contentType: "text/plain", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, testLimits.Allows(tt.contentType))
+		})
+	}
+}
+
+func TestParseObjectName(t *testing.T) {
+	// Act
+	owner, id, ok := ParseObjectName(ObjectName("user-1", "abc", "a.png"))
+	_, _, extra := ParseObjectName("users/user-1/files/abc/a/b.png")
+	_, _, other := ParseObjectName("users/user-1/avatar.png")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", owner)
+	assert.Equal(t, "abc", id)
+	assert.False(t, extra)
+	assert.False(t, other)
+}
+
+func TestService_Events_NotifiesEveryListener(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var calls []string
+	for _, name := range []string{"first", "second"} {
+		env.service.OnEvent(func(_ context.Context, event string, _ File) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, name+":"+event)
+		})
+	}
+	upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	// Act
+	put(t, upload.Upload, []byte("png"))
+
+	// Assert
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"first:" + EventReady, "second:" + EventReady}, calls)
+}
+
+// fakeProcessor accepts PNGs and records what it was handed
+type fakeProcessor struct {
+	mu        sync.Mutex
+	processed []string
+	err       error
+}
+
+func (p *fakeProcessor) Accepts(f File) bool { return f.ContentType == "image/png" }
+
+func (p *fakeProcessor) Process(_ context.Context, f File) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed = append(p.processed, f.ID)
+	return p.err
+}
+
+func TestService_Processor(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		processErr  error
+		finish      func(s *Service, f File) error
+		wantStatus  Status
+		wantSize    int64
+	}{
+		{
+			name:        "not accepted",
+			contentType: "image/gif",
+			wantStatus:  StatusReady,
+			wantSize:    3,
+		},
+		{
+			name:        "accepted and still processing",
+			contentType: "image/png",
+			wantStatus:  StatusProcessing,
+			wantSize:    3,
+		},
+		{
+			name:        "completed with a rewritten object",
+			contentType: "image/png",
+			finish: func(s *Service, f File) error {
+				return s.Complete(context.Background(), f.OwnerID, f.ID, 2)
+			},
+			wantStatus: StatusReady,
+			wantSize:   2,
+		},
+		{
+			name:        "rejected by the processor",
+			contentType: "image/png",
+			finish: func(s *Service, f File) error {
+				return s.Reject(context.Background(), f.OwnerID, f.ID, errors.New("not an image"))
+			},
+			wantStatus: StatusRejected,
+			wantSize:   3,
+		},
+		{
+			name:        "hand-off failed",
+			contentType: "image/png",
+			processErr:  errors.New("queue unavailable"),
+			wantStatus:  StatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupService(t)
+			ctx := context.Background()
+			processor := &fakeProcessor{err: tt.processErr}
+			env.service.SetProcessor(processor)
+			upload, err := env.service.CreateUpload(ctx, "user-1", UploadRequest{Name: "a", ContentType: tt.contentType, Size: 3})
+			require.NoError(t, err)
+			w, err := env.storage.Create(ctx, upload.File.Object, tt.contentType)
+			require.NoError(t, err)
+			_, err = w.Write([]byte("abc"))
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			// Act
+			err = env.service.Finalize(ctx, ObjectAttrs{Name: upload.File.Object, ContentType: tt.contentType, Size: 3})
+			if tt.processErr != nil {
+				require.ErrorIs(t, err, tt.processErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.finish != nil {
+				require.NoError(t, tt.finish(env.service, upload.File))
+			}
+
+			// Assert
+			f, err := env.service.Get(ctx, "user-1", upload.File.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, f.Status)
+			if tt.wantSize > 0 {
+				assert.Equal(t, tt.wantSize, f.Size)
+			}
+			if tt.wantStatus == StatusRejected {
+				_, err := env.storage.Attrs(ctx, upload.File.Object)
+				assert.ErrorIs(t, err, ErrObjectNotFound)
+			}
+		})
+	}
+}
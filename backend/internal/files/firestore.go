@@ -0,0 +1,91 @@
+package files
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// FirestoreStore keeps file metadata in users/{userId}/files/{fileId}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) collection(ownerID string) *firestore.CollectionRef {
+	return s.client.Collection("users").Doc(ownerID).Collection("files")
+}
+
+// Create implements Store
+func (s *FirestoreStore) Create(ctx context.Context, f File) error {
+	if _, err := s.collection(f.OwnerID).Doc(f.ID).Create(ctx, f); err != nil {
+		return fmt.Errorf("files: create %s: %w", f.ID, err)
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *FirestoreStore) Get(ctx context.Context, ownerID, id string) (*File, error) {
+	snap, err := s.collection(ownerID).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("files: get %s: %w", id, err)
+	}
+
+	var f File
+	if err := snap.DataTo(&f); err != nil {
+		return nil, err
+	}
+	f.ID = snap.Ref.ID
+	return &f, nil
+}
+
+// Update implements Store
+func (s *FirestoreStore) Update(ctx context.Context, f File) error {
+	ref := s.collection(f.OwnerID).Doc(f.ID)
+	if _, err := ref.Set(ctx, f); err != nil {
+		return fmt.Errorf("files: update %s: %w", f.ID, err)
+	}
+	return nil
+}
+
+// List implements Store
+func (s *FirestoreStore) List(ctx context.Context, ownerID string, limit int) ([]File, error) {
+	query := s.collection(ownerID).OrderBy("createdAt", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("files: list: %w", err)
+	}
+
+	out := make([]File, 0, len(docs))
+	for _, doc := range docs {
+		var f File
+		if err := doc.DataTo(&f); err != nil {
+			return nil, err
+		}
+		f.ID = doc.Ref.ID
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Delete implements Store
+func (s *FirestoreStore) Delete(ctx context.Context, ownerID, id string) error {
+	if _, err := s.collection(ownerID).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("files: delete %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package files
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// LocalRoutePrefix is where LocalStorage serves its signed URLs
+const LocalRoutePrefix = "/files/local/"
+
+// Query parameters of a local signed URL
+const (
+	localParamMethod      = "method"
+	localParamExpires     = "expires"
+	localParamContentType = "contentType"
+	localParamMaxSize     = "maxSize"
+	localParamSignature   = "signature"
+)
+
+// FinalizeFunc is notified when an upload through a signed URL completes,
+// playing the role of the Cloud Storage finalize notification
+type FinalizeFunc func(ctx context.Context, attrs ObjectAttrs)
+
+// LocalStorage keeps objects on the local filesystem and serves HMAC-signed
+// URLs itself, so the signed upload flow works end to end without GCP.
+type LocalStorage struct {
+	root    string
+	baseURL string
+	key     []byte
+	now     func() time.Time
+
+	mu         sync.RWMutex
+	onFinalize FinalizeFunc
+}
+
+// localMeta is stored next to each object
+type localMeta struct {
+	ContentType string `json:"contentType"`
+}
+
+// NewLocalStorage stores objects under root and signs URLs relative to
+// baseURL (the public base URL of this service) with key
+func NewLocalStorage(root, baseURL string, key []byte, opts StorageOptions) (*LocalStorage, error) {
+	if len(key) == 0 {
+		return nil, errors.New("files: local storage needs a signing key")
+	}
+	for _, dir := range []string{"objects", "meta"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o750); err != nil {
+			return nil, fmt.Errorf("files: create local storage: %w", err)
+		}
+	}
+	return &LocalStorage{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		key:     key,
+		now:     clock.OrSystem(opts.Clock).Now,
+	}, nil
+}
+
+// OnFinalize registers fn to be called after each completed upload
+func (s *LocalStorage) OnFinalize(fn FinalizeFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onFinalize = fn
+}
+
+// SignUpload implements Storage
+func (s *LocalStorage) SignUpload(_ context.Context, object string, c UploadConstraints, ttl time.Duration) (SignedURL, error) {
+	if err := checkObjectName(object); err != nil {
+		return SignedURL{}, err
+	}
+	expires := s.now().Add(ttl)
+	q := url.Values{
+		localParamMethod:      {http.MethodPut},
+		localParamExpires:     {strconv.FormatInt(expires.Unix(), 10)},
+		localParamContentType: {c.ContentType},
+		localParamMaxSize:     {strconv.FormatInt(c.MaxSize, 10)},
+	}
+	return SignedURL{
+		URL:       s.signedURL(object, q),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": c.ContentType},
+		ExpiresAt: expires,
+	}, nil
+}
+
+// SignDownload implements Storage
+func (s *LocalStorage) SignDownload(_ context.Context, object string, ttl time.Duration) (SignedURL, error) {
+	if err := checkObjectName(object); err != nil {
+		return SignedURL{}, err
+	}
+	expires := s.now().Add(ttl)
+	q := url.Values{
+		localParamMethod:  {http.MethodGet},
+		localParamExpires: {strconv.FormatInt(expires.Unix(), 10)},
+	}
+	return SignedURL{URL: s.signedURL(object, q), Method: http.MethodGet, ExpiresAt: expires}, nil
+}
+
+// Attrs implements Storage
+func (s *LocalStorage) Attrs(_ context.Context, object string) (ObjectAttrs, error) {
+	if err := checkObjectName(object); err != nil {
+		return ObjectAttrs{}, err
+	}
+	info, err := os.Stat(s.objectPath(object))
+	if errors.Is(err, os.ErrNotExist) {
+		return ObjectAttrs{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectAttrs{}, err
+	}
+
+	var meta localMeta
+	if data, err := os.ReadFile(s.metaPath(object)); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+	return ObjectAttrs{Name: object, ContentType: meta.ContentType, Size: info.Size()}, nil
+}
+
+// Open implements Storage
+func (s *LocalStorage) Open(_ context.Context, object string) (io.ReadCloser, error) {
+	if err := checkObjectName(object); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.objectPath(object))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Create implements Storage. Content is written to a temporary file and
+// renamed into place on Close, so readers never see partial objects.
+func (s *LocalStorage) Create(_ context.Context, object, contentType string) (io.WriteCloser, error) {
+	if err := checkObjectName(object); err != nil {
+		return nil, err
+	}
+	dst := s.objectPath(object)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	return &localWriter{File: tmp, storage: s, object: object, contentType: contentType}, nil
+}
+
+// Delete implements Storage
+func (s *LocalStorage) Delete(_ context.Context, object string) error {
+	if err := checkObjectName(object); err != nil {
+		return err
+	}
+	for _, p := range []string{s.objectPath(object), s.metaPath(object)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List implements Storage
+func (s *LocalStorage) List(_ context.Context, prefix string) ([]string, error) {
+	root := filepath.Join(s.root, "objects")
+	var names []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		// Uploads in flight are not objects yet
+		if strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("files: list %s: %w", prefix, err)
+	}
+	return names, nil
+}
+
+// ServeHTTP serves the signed URLs issued by SignUpload and SignDownload.
+// Mount it under LocalRoutePrefix with the prefix stripped.
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	object := strings.TrimPrefix(r.URL.Path, "/")
+	q := r.URL.Query()
+
+	if err := s.verify(object, r.Method, q); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.serveDownload(w, r, object)
+	case http.MethodPut:
+		s.serveUpload(w, r, object, q)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *LocalStorage) serveDownload(w http.ResponseWriter, r *http.Request, object string) {
+	attrs, err := s.Attrs(r.Context(), object)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if attrs.ContentType != "" {
+		w.Header().Set("Content-Type", attrs.ContentType)
+	}
+	http.ServeFile(w, r, s.objectPath(object))
+}
+
+func (s *LocalStorage) serveUpload(w http.ResponseWriter, r *http.Request, object string, q url.Values) {
+	contentType := q.Get(localParamContentType)
+	if r.Header.Get("Content-Type") != contentType {
+		http.Error(w, "content type does not match signed URL", http.StatusForbidden)
+		return
+	}
+	maxSize, _ := strconv.ParseInt(q.Get(localParamMaxSize), 10, 64)
+
+	wc, err := s.Create(r.Context(), object, contentType)
+	if err != nil {
+		http.Error(w, "storage error", http.StatusInternalServerError)
+		return
+	}
+	n, err := io.Copy(wc, io.LimitReader(r.Body, maxSize+1))
+	if err == nil && n > maxSize {
+		err = errTooLargeUpload
+	}
+	if err != nil {
+		wc.(*localWriter).abort()
+		status := http.StatusInternalServerError
+		if errors.Is(err, errTooLargeUpload) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err := wc.Close(); err != nil {
+		http.Error(w, "storage error", http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.RLock()
+	onFinalize := s.onFinalize
+	s.mu.RUnlock()
+	if onFinalize != nil {
+		onFinalize(r.Context(), ObjectAttrs{Name: object, ContentType: contentType, Size: n})
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+var errTooLargeUpload = errors.New("upload exceeds signed size limit")
+
+func (s *LocalStorage) signedURL(object string, q url.Values) string {
+	q.Set(localParamSignature, s.sign(object, q))
+	return s.baseURL + LocalRoutePrefix + (&url.URL{Path: object}).EscapedPath() + "?" + q.Encode()
+}
+
+func (s *LocalStorage) verify(object, method string, q url.Values) error {
+	if checkObjectName(object) != nil {
+		return errors.New("invalid object name")
+	}
+	if q.Get(localParamMethod) != method {
+		return errors.New("method does not match signed URL")
+	}
+	expires, err := strconv.ParseInt(q.Get(localParamExpires), 10, 64)
+	if err != nil || s.now().Unix() > expires {
+		return errors.New("signed URL expired")
+	}
+	expected := s.sign(object, q)
+	if !hmac.Equal([]byte(expected), []byte(q.Get(localParamSignature))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (s *LocalStorage) sign(object string, q url.Values) string {
+	mac := hmac.New(sha256.New, s.key)
+	for _, part := range []string{
+		q.Get(localParamMethod),
+		object,
+		q.Get(localParamExpires),
+		q.Get(localParamContentType),
+		q.Get(localParamMaxSize),
+	} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) objectPath(object string) string {
+	return filepath.Join(s.root, "objects", filepath.FromSlash(object))
+}
+
+func (s *LocalStorage) metaPath(object string) string {
+	return filepath.Join(s.root, "meta", filepath.FromSlash(object)+".json")
+}
+
+// localWriter renames its temporary file into place on Close
+type localWriter struct {
+	*os.File
+	storage     *LocalStorage
+	object      string
+	contentType string
+}
+
+func (w *localWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		_ = os.Remove(w.Name())
+		return err
+	}
+
+	meta, _ := json.Marshal(localMeta{ContentType: w.contentType})
+	metaPath := w.storage.metaPath(w.object)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(metaPath, meta, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(w.Name(), w.storage.objectPath(w.object))
+}
+
+func (w *localWriter) abort() {
+	_ = w.File.Close()
+	_ = os.Remove(w.Name())
+}
+
+// checkObjectName rejects names that could escape the storage root
+func checkObjectName(object string) error {
+	if object == "" || strings.HasPrefix(object, "/") || path.Clean(object) != object ||
+		object == ".." || strings.HasPrefix(object, "../") {
+		return fmt.Errorf("files: invalid object name %q", object)
+	}
+	return nil
+}
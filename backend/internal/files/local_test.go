@@ -0,0 +1,139 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_SignedUpload(t *testing.T) {
+	constraints := UploadConstraints{ContentType: "image/png", MaxSize: 8}
+
+	tests := []struct {
+		name           string
+		mutate         func(u *url.URL, h http.Header)
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "accepts matching upload",
+			body:           "12345678",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects body above signed size",
+			body:           "123456789",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "rejects other content type",
+			body:           "1234",
+			mutate:         func(_ *url.URL, h http.Header) { h.Set("Content-Type", "text/html") },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "rejects tampered size limit",
+			body: "1234",
+			mutate: func(u *url.URL, _ http.Header) {
+				q := u.Query()
+				q.Set(localParamMaxSize, "999999")
+				u.RawQuery = q.Encode()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "rejects other object",
+			body: "1234",
+			mutate: func(u *url.URL, _ http.Header) {
+				u.Path = strings.Replace(u.Path, "user-1", "user-2", 1)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupService(t)
+			signed, err := env.storage.SignUpload(context.Background(), "users/user-1/a.png", constraints, time.Minute)
+			require.NoError(t, err)
+
+			u, err := url.Parse(signed.URL)
+			require.NoError(t, err)
+			header := http.Header{"Content-Type": {signed.Headers["Content-Type"]}}
+			if tt.mutate != nil {
+				tt.mutate(u, header)
+			}
+
+			req, err := http.NewRequest(http.MethodPut, u.String(), strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header = header
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestLocalStorage_SignedURLExpires(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	signed, err := env.storage.SignDownload(context.Background(), "users/user-1/a.png", time.Minute)
+	require.NoError(t, err)
+	env.storage.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+	// Act
+	resp, err := http.Get(signed.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestLocalStorage_RejectsTraversal(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+
+	// Act & Assert
+	for _, object := range []string{"../etc/passwd", "/abs", "a/../../b", ""} {
+		_, err := env.storage.SignUpload(ctx, object, UploadConstraints{}, time.Minute)
+		assert.Error(t, err, object)
+		_, err = env.storage.Create(ctx, object, "text/plain")
+		assert.Error(t, err, object)
+	}
+}
+
+func TestLocalStorage_List(t *testing.T) {
+	// Arrange
+	env := setupService(t)
+	ctx := context.Background()
+	for _, object := range []string{"users/bob/a.png", "users/alice/files/2/b.png", "users/alice/files/1/a.png", "users/alicex/a.png"} {
+		w, err := env.storage.Create(ctx, object, "image/png")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	// An upload still being written is not listed
+	pending, err := env.storage.Create(ctx, "users/alice/c.png", "image/png")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pending.Close() })
+
+	// Act
+	names, err := env.storage.List(ctx, "users/alice/")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users/alice/files/1/a.png", "users/alice/files/2/b.png"}, names)
+}
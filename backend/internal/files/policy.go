@@ -0,0 +1,79 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrForbidden is returned for accesses storage.rules would deny
+var ErrForbidden = errors.New("files: forbidden by storage policy")
+
+// PolicyMaxSize is the size every write must stay below, as in storage.rules
+const PolicyMaxSize = 10 * 1024 * 1024
+
+// policyContentType is the matches() pattern of storage.rules. Rules
+// patterns must match the whole string and are case-sensitive.
+var policyContentType = regexp.MustCompile(`\Aimage/.*\z`)
+
+// Operation is a storage.rules operation
+type Operation string
+
+// Operations checked by Authorize
+const (
+	OpRead  Operation = "read"
+	OpWrite Operation = "write"
+)
+
+// Access is an operation on an object, described the way storage.rules
+// sees it
+type Access struct {
+	Op     Operation
+	Object string
+	// UID is the caller's user ID (request.auth.uid), empty when signed out
+	UID string
+	// ContentType and Size describe the object being written
+	// (request.resource); they are ignored for reads
+	ContentType string
+	Size        int64
+}
+
+// Authorize applies storage.rules to an access. The backend writes with a
+// service account that bypasses the rules, so every upload it mediates must
+// pass here first. Keep both in sync: TestAuthorize_StorageRulesParity runs
+// the same scenarios against the Storage emulator.
+func Authorize(a Access) error {
+	segments := strings.Split(a.Object, "/")
+	switch {
+	// match /users/{userId}/{allPaths=**}
+	case len(segments) >= 2 && segments[0] == "users" && segments[1] != "":
+		if a.Op == OpRead {
+			if a.UID == "" {
+				return fmt.Errorf("%w: sign-in required to read %s", ErrForbidden, a.Object)
+			}
+			return nil
+		}
+		if a.UID == "" || a.UID != segments[1] {
+			return fmt.Errorf("%w: only the owner may write %s", ErrForbidden, a.Object)
+		}
+		if !policyContentType.MatchString(a.ContentType) {
+			return fmt.Errorf("%w: content type %q is not an image", ErrForbidden, a.ContentType)
+		}
+		if a.Size >= PolicyMaxSize {
+			return fmt.Errorf("%w: %d bytes is not below the %d byte limit", ErrForbidden, a.Size, PolicyMaxSize)
+		}
+		return nil
+
+	// match /public/{allPaths=**}
+	case len(segments) >= 1 && segments[0] == "public":
+		if a.Op == OpRead {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is read-only", ErrForbidden, a.Object)
+
+	// match /{allPaths=**}
+	default:
+		return fmt.Errorf("%w: %s", ErrForbidden, a.Object)
+	}
+}
@@ -0,0 +1,176 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/rulestest"
+)
+
+// policyScenarios run against both Authorize and the storage.rules loaded
+// in the Storage emulator, so a change to one without the other fails
+var policyScenarios = []struct {
+	name    string
+	access  Access
+	allowed bool
+}{
+	{name: "owner writes image", access: Access{Op: OpWrite, Object: "users/alice/files/1/a.png", UID: "alice", ContentType: "image/png", Size: 1024}, allowed: true},
+	{name: "owner writes outside files", access: Access{Op: OpWrite, Object: "users/alice/avatar.jpg", UID: "alice", ContentType: "image/jpeg", Size: 10}, allowed: true},
+	{name: "owner writes just below the limit", access: Access{Op: OpWrite, Object: "users/alice/big.png", UID: "alice", ContentType: "image/png", Size: PolicyMaxSize - 1}, allowed: true},
+	{name: "owner writes at the limit", access: Access{Op: OpWrite, Object: "users/alice/big.png", UID: "alice", ContentType: "image/png", Size: PolicyMaxSize}},
+	{name: "owner writes non-image", access: Access{Op: OpWrite, Object: "users/alice/a.pdf", UID: "alice", ContentType: "application/pdf", Size: 10}},
+	{name: "owner writes uppercase type", access: Access{Op: OpWrite, Object: "users/alice/a.png", UID: "alice", ContentType: "IMAGE/PNG", Size: 10}},
+	{name: "owner writes type merely containing image", access: Access{Op: OpWrite, Object: "users/alice/a.png", UID: "alice", ContentType: "text/image/png", Size: 10}},
+	{name: "other user writes", access: Access{Op: OpWrite, Object: "users/alice/a.png", UID: "bob", ContentType: "image/png", Size: 10}},
+	{name: "signed-out write", access: Access{Op: OpWrite, Object: "users/alice/a.png", ContentType: "image/png", Size: 10}},
+	{name: "other user reads", access: Access{Op: OpRead, Object: "users/alice/a.png", UID: "bob"}, allowed: true},
+	{name: "signed-out read", access: Access{Op: OpRead, Object: "users/alice/a.png"}},
+	{name: "public read", access: Access{Op: OpRead, Object: "public/logo.png"}, allowed: true},
+	{name: "public write", access: Access{Op: OpWrite, Object: "public/logo.png", UID: "alice", ContentType: "image/png", Size: 10}},
+	{name: "unmatched read", access: Access{Op: OpRead, Object: "exports/alice.zip", UID: "alice"}},
+	{name: "unmatched write", access: Access{Op: OpWrite, Object: "alice/a.png", UID: "alice", ContentType: "image/png", Size: 10}},
+	{name: "prefix lookalike", access: Access{Op: OpWrite, Object: "usersx/alice/a.png", UID: "alice", ContentType: "image/png", Size: 10}},
+}
+
+func TestAuthorize(t *testing.T) {
+	for _, tt := range policyScenarios {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Authorize(tt.access)
+
+			// Assert
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+// TestAuthorize_StorageRulesParity replays policyScenarios against the
+// Storage emulator. From the repository root:
+//
+//	firebase emulators:exec --only storage --project demo-rules-test \
+//	  'cd backend && go test -run StorageRulesParity ./internal/files'
+func TestAuthorize_StorageRulesParity(t *testing.T) {
+	host := os.Getenv("FIREBASE_STORAGE_EMULATOR_HOST")
+	if host == "" {
+		t.Skip("FIREBASE_STORAGE_EMULATOR_HOST not set")
+	}
+	bucket := os.Getenv("FIREBASE_STORAGE_BUCKET")
+	if bucket == "" {
+		bucket = rulestest.ProjectID + ".appspot.com"
+	}
+	emu := &rulesEmulator{base: "http://" + host, bucket: bucket}
+
+	for _, tt := range policyScenarios {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			if tt.access.Op == OpRead {
+				emu.seed(t, tt.access.Object)
+			}
+
+			// Act
+			allowed := emu.allows(t, tt.access)
+
+			// Assert
+			assert.Equal(t, tt.allowed, allowed, "storage.rules")
+			assert.Equal(t, tt.allowed, Authorize(tt.access) == nil, "Authorize")
+		})
+	}
+}
+
+// rulesEmulator talks to the Storage emulator: through the Firebase API,
+// which applies storage.rules, and the Cloud Storage API, which does not
+type rulesEmulator struct {
+	base   string
+	bucket string
+}
+
+// seed creates object as an admin, so reads are judged by the rules and not
+// by whether the object exists
+func (e *rulesEmulator) seed(t *testing.T, object string) {
+	t.Helper()
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", e.base, e.bucket, url.QueryEscape(object))
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader([]byte("seed")))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "image/png")
+	req.Header.Set("Authorization", "Bearer owner")
+	resp := e.do(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "seeding %s", object)
+}
+
+// allows performs the access as a client SDK would and reports whether the
+// rules let it through
+func (e *rulesEmulator) allows(t *testing.T, a Access) bool {
+	t.Helper()
+	var req *http.Request
+	var err error
+	if a.Op == OpRead {
+		u := fmt.Sprintf("%s/v0/b/%s/o/%s", e.base, e.bucket, url.PathEscape(a.Object))
+		req, err = http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+	} else {
+		body, contentType := multipartUpload(t, a)
+		u := fmt.Sprintf("%s/v0/b/%s/o?name=%s", e.base, e.bucket, url.QueryEscape(a.Object))
+		req, err = http.NewRequest(http.MethodPost, u, body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Goog-Upload-Protocol", "multipart")
+	}
+	if a.UID != "" {
+		req.Header.Set("Authorization", "Firebase "+rulestest.IDToken(rulestest.User(a.UID)))
+	}
+
+	resp := e.do(t, req)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return false
+	default:
+		t.Fatalf("unexpected emulator status %d for %s %s", resp.StatusCode, a.Op, a.Object)
+		return false
+	}
+}
+
+func (e *rulesEmulator) do(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return resp
+}
+
+// multipartUpload builds the metadata and content body the Firebase SDKs
+// send, with a payload of exactly a.Size bytes
+func multipartUpload(t *testing.T, a Access) (io.Reader, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	meta, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=utf-8"}})
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(meta).Encode(map[string]string{"name": a.Object, "contentType": a.ContentType}))
+
+	content, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {a.ContentType}})
+	require.NoError(t, err)
+	_, err = content.Write(make([]byte, a.Size))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return &buf, "multipart/related; boundary=" + w.Boundary()
+}
@@ -0,0 +1,61 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// ErrObjectNotFound is returned by storages for missing objects
+var ErrObjectNotFound = errors.New("files: object not found")
+
+// ObjectAttrs describes a stored object
+type ObjectAttrs struct {
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// SignedURL is a time-limited URL granting one operation on one object.
+// Clients must send Headers verbatim with the request.
+type SignedURL struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// UploadConstraints restrict what a signed upload URL accepts
+type UploadConstraints struct {
+	ContentType string
+	// MaxSize is the largest accepted body in bytes, inclusive
+	MaxSize int64
+}
+
+// StorageOptions configures GCSStorage and LocalStorage
+type StorageOptions struct {
+	// Clock, the wall clock by default, is what signed URLs expire by
+	Clock clock.Clock
+}
+
+// Storage is an object store able to hand out signed URLs. GCSStorage backs
+// production; LocalStorage keeps objects on disk for development and tests.
+type Storage interface {
+	// SignUpload returns a URL accepting a single PUT of object
+	SignUpload(ctx context.Context, object string, c UploadConstraints, ttl time.Duration) (SignedURL, error)
+	// SignDownload returns a URL serving a GET of object
+	SignDownload(ctx context.Context, object string, ttl time.Duration) (SignedURL, error)
+	// Attrs returns the attributes of object
+	Attrs(ctx context.Context, object string) (ObjectAttrs, error)
+	// Open reads object
+	Open(ctx context.Context, object string) (io.ReadCloser, error)
+	// Create writes object, replacing any previous content, once the writer is closed
+	Create(ctx context.Context, object, contentType string) (io.WriteCloser, error)
+	// Delete removes object; deleting a missing object is not an error
+	Delete(ctx context.Context, object string) error
+	// List returns the names of the objects starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
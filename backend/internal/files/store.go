@@ -0,0 +1,92 @@
+package files
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists file metadata, scoped by owner
+type Store interface {
+	Create(ctx context.Context, f File) error
+	// Get returns store.ErrNotFound if ownerID has no file id
+	Get(ctx context.Context, ownerID, id string) (*File, error)
+	Update(ctx context.Context, f File) error
+	// List returns the owner's files, newest first
+	List(ctx context.Context, ownerID string, limit int) ([]File, error)
+	Delete(ctx context.Context, ownerID, id string) error
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu    sync.Mutex
+	files map[string]File
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{files: make(map[string]File)}
+}
+
+func memoryKey(ownerID, id string) string {
+	return ownerID + "/" + id
+}
+
+// Create implements Store
+func (s *MemoryStore) Create(_ context.Context, f File) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[memoryKey(f.OwnerID, f.ID)] = f
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, ownerID, id string) (*File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[memoryKey(ownerID, id)]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &f, nil
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(_ context.Context, f File) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryKey(f.OwnerID, f.ID)
+	if _, ok := s.files[key]; !ok {
+		return store.ErrNotFound
+	}
+	s.files[key] = f
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, ownerID string, limit int) ([]File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []File
+	for _, f := range s.files {
+		if f.OwnerID == ownerID {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, ownerID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, memoryKey(ownerID, id))
+	return nil
+}
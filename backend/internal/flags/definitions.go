@@ -0,0 +1,22 @@
+package flags
+
+// Flags shared with the mobile apps. Keys must match FeatureFlagManager.swift
+// and RemoteConfigManager.kt; defaults match their in-app defaults.
+var (
+	NewFeatureEnabled = Flag[bool]{
+		Key:         "new_feature_enabled",
+		Default:     false,
+		Description: "Example feature gate",
+	}
+	MaintenanceMode = Flag[bool]{
+		Key:         "maintenance_mode",
+		Default:     false,
+		Description: "Apps show a maintenance screen",
+	}
+)
+
+// Catalogue lists every flag the backend knows about
+var Catalogue = []Definition{
+	NewFeatureEnabled.Definition(),
+	MaintenanceMode.Definition(),
+}
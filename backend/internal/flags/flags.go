@@ -0,0 +1,287 @@
+// Package flags evaluates feature flags shared with the mobile apps' Remote
+// Config keys, with percentage and attribute targeting
+package flags
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+// Value is a type a flag can hold
+type Value interface {
+	bool | string | int | float64
+}
+
+// Flag is a typed flag definition. The key matches the Remote Config key the
+// apps read, and Default applies when no provider sets a value.
+type Flag[T Value] struct {
+	Key         string
+	Default     T
+	Description string
+}
+
+// Definition describes a flag independent of its type
+type Definition struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     any    `json:"default"`
+	Description string `json:"description"`
+}
+
+// Definition returns the untyped description of the flag
+func (f Flag[T]) Definition() Definition {
+	return Definition{Key: f.Key, Type: typeName(f.Default), Default: f.Default, Description: f.Description}
+}
+
+// Target is who a flag is evaluated for. Empty fields never match rules
+// that target them.
+type Target struct {
+	UserID     string
+	Platform   string
+	AppVersion string
+}
+
+// Service evaluates flags against the latest snapshot from a provider
+type Service struct {
+	provider Provider
+	defs     map[string]Definition
+	order    []string
+	snapshot atomic.Pointer[Snapshot]
+	logger   *zap.Logger
+}
+
+// NewService creates a service for the given definitions. Until the first
+// successful Refresh every flag has its default.
+func NewService(provider Provider, defs []Definition, logger *zap.Logger) *Service {
+	s := &Service{
+		provider: provider,
+		defs:     make(map[string]Definition, len(defs)),
+		logger:   logger,
+	}
+	for _, d := range defs {
+		s.defs[d.Key] = d
+		s.order = append(s.order, d.Key)
+	}
+	s.snapshot.Store(&Snapshot{})
+	return s
+}
+
+// Refresh loads a new snapshot. On error the previous one stays in use.
+func (s *Service) Refresh(ctx context.Context) error {
+	snap, err := s.provider.Load(ctx)
+	if err != nil {
+		return err
+	}
+	for key := range snap {
+		if _, ok := s.defs[key]; !ok {
+			s.logger.Debug("ignoring unknown flag", zap.String("flag", key))
+		}
+	}
+	s.snapshot.Store(&snap)
+	return nil
+}
+
+// Run refreshes the snapshot every interval until ctx is done
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warn("refreshing feature flags failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Definitions returns the known flags in definition order
+func (s *Service) Definitions() []Definition {
+	defs := make([]Definition, 0, len(s.order))
+	for _, key := range s.order {
+		defs = append(defs, s.defs[key])
+	}
+	return defs
+}
+
+// Evaluate returns the value of every known flag for t
+func (s *Service) Evaluate(t Target) map[string]any {
+	out := make(map[string]any, len(s.defs))
+	for key, def := range s.defs {
+		out[key] = s.value(def, t)
+	}
+	return out
+}
+
+// Get evaluates a typed flag for t
+func Get[T Value](s *Service, f Flag[T], t Target) T {
+	v, ok := coerce[T](s.value(f.Definition(), t))
+	if !ok {
+		return f.Default
+	}
+	return v
+}
+
+// value picks the first matching rule's value, then the configured value,
+// then the default. Values that do not convert to the flag's type are
+// skipped so a typo in a provider never changes a flag's type.
+func (s *Service) value(def Definition, t Target) any {
+	cfg, ok := (*s.snapshot.Load())[def.Key]
+	if !ok {
+		return def.Default
+	}
+
+	for _, rule := range cfg.Rules {
+		if !rule.matches(def.Key, t) {
+			continue
+		}
+		if rule.Value == nil {
+			return def.Default
+		}
+		if v, ok := convert(def, rule.Value); ok {
+			return v
+		}
+		s.logger.Warn("flag rule value has the wrong type", zap.String("flag", def.Key))
+	}
+	if cfg.Value != nil {
+		if v, ok := convert(def, cfg.Value); ok {
+			return v
+		}
+		s.logger.Warn("flag value has the wrong type", zap.String("flag", def.Key))
+	}
+	return def.Default
+}
+
+func (r Rule) matches(key string, t Target) bool {
+	if len(r.Users) > 0 && !contains(r.Users, t.UserID) {
+		return false
+	}
+	if len(r.Platforms) > 0 && !contains(r.Platforms, t.Platform) {
+		return false
+	}
+	if r.MinVersion != "" && (t.AppVersion == "" || clientinfo.CompareVersions(t.AppVersion, r.MinVersion) < 0) {
+		return false
+	}
+	if r.MaxVersion != "" && (t.AppVersion == "" || clientinfo.CompareVersions(t.AppVersion, r.MaxVersion) > 0) {
+		return false
+	}
+	if r.Percentage != nil && (t.UserID == "" || bucket(key, t.UserID) >= *r.Percentage*100) {
+		return false
+	}
+	return true
+}
+
+// bucket places a user in [0, 10000) for a flag. Buckets are stable per
+// user and independent between flags.
+func bucket(key, userID string) float64 {
+	sum := sha256.Sum256([]byte(key + "/" + userID))
+	return float64(binary.BigEndian.Uint32(sum[:4]) % 10000)
+}
+
+func contains(list []string, v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case int:
+		return "integer"
+	case float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// convert coerces a provider value to the definition's type
+func convert(def Definition, raw any) (any, bool) {
+	switch def.Default.(type) {
+	case bool:
+		return coerce[bool](raw)
+	case int:
+		return coerce[int](raw)
+	case float64:
+		return coerce[float64](raw)
+	default:
+		return coerce[string](raw)
+	}
+}
+
+// coerce converts JSON values and Remote Config strings to T
+func coerce[T Value](raw any) (T, bool) {
+	var zero T
+	var out any
+	switch any(zero).(type) {
+	case bool:
+		switch v := raw.(type) {
+		case bool:
+			out = v
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return zero, false
+			}
+			out = b
+		}
+	case int:
+		switch v := raw.(type) {
+		case int:
+			out = v
+		case float64:
+			if v != float64(int(v)) {
+				return zero, false
+			}
+			out = int(v)
+		case string:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return zero, false
+			}
+			out = n
+		}
+	case float64:
+		switch v := raw.(type) {
+		case float64:
+			out = v
+		case int:
+			out = float64(v)
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return zero, false
+			}
+			out = f
+		}
+	case string:
+		switch v := raw.(type) {
+		case string:
+			out = v
+		case bool, float64, int:
+			out = fmt.Sprint(v)
+		}
+	}
+
+	v, ok := out.(T)
+	return v, ok
+}
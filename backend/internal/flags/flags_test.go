@@ -0,0 +1,258 @@
+package flags
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+var (
+	testBool   = Flag[bool]{Key: "test_bool", Default: false}
+	testInt    = Flag[int]{Key: "test_int", Default: 3}
+	testString = Flag[string]{Key: "test_string", Default: "blue"}
+)
+
+func pct(v float64) *float64 { return &v }
+
+func newTestService(t *testing.T, snap Snapshot) *Service {
+	t.Helper()
+	s := NewService(StaticProvider(snap), []Definition{
+		testBool.Definition(), testInt.Definition(), testString.Definition(),
+	}, zap.NewNop())
+	require.NoError(t, s.Refresh(context.Background()))
+	return s
+}
+
+func TestGet_Targeting(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		target   Target
+		expected bool
+	}{
+		{
+			name:     "default when unconfigured",
+			expected: false,
+		},
+		{
+			name:     "configured value",
+			config:   Config{Value: true},
+			expected: true,
+		},
+		{
+			name:     "remote config string value",
+			config:   Config{Value: "true"},
+			expected: true,
+		},
+		{
+			name:     "wrong type falls back to default",
+			config:   Config{Value: "yes please"},
+			expected: false,
+		},
+		{
+			name:     "user rule",
+			config:   Config{Rules: []Rule{{Users: []string{"alice"}, Value: true}}},
+			target:   Target{UserID: "alice"},
+			expected: true,
+		},
+		{
+			name:     "user rule for someone else",
+			config:   Config{Rules: []Rule{{Users: []string{"alice"}, Value: true}}},
+			target:   Target{UserID: "bob"},
+			expected: false,
+		},
+		{
+			name:     "platform rule",
+			config:   Config{Rules: []Rule{{Platforms: []string{"ios"}, Value: true}}},
+			target:   Target{Platform: clientinfo.PlatformIOS},
+			expected: true,
+		},
+		{
+			name:     "version range",
+			config:   Config{Rules: []Rule{{MinVersion: "2.0", MaxVersion: "2.9", Value: true}}},
+			target:   Target{AppVersion: "2.4.1"},
+			expected: true,
+		},
+		{
+			name:     "below min version",
+			config:   Config{Rules: []Rule{{MinVersion: "2.0", Value: true}}},
+			target:   Target{AppVersion: "1.9.9"},
+			expected: false,
+		},
+		{
+			name:     "version rule without version",
+			config:   Config{Rules: []Rule{{MinVersion: "0.0.1", Value: true}}},
+			expected: false,
+		},
+		{
+			name:     "all conditions must hold",
+			config:   Config{Rules: []Rule{{Platforms: []string{"android"}, MinVersion: "3", Value: true}}},
+			target:   Target{Platform: clientinfo.PlatformAndroid, AppVersion: "2.0"},
+			expected: false,
+		},
+		{
+			name: "first matching rule wins",
+			config: Config{Value: true, Rules: []Rule{
+				{Users: []string{"alice"}, Value: false},
+				{Platforms: []string{"ios"}, Value: true},
+			}},
+			target:   Target{UserID: "alice", Platform: clientinfo.PlatformIOS},
+			expected: false,
+		},
+		{
+			name:     "rule with null value uses default",
+			config:   Config{Value: true, Rules: []Rule{{Users: []string{"alice"}}}},
+			target:   Target{UserID: "alice"},
+			expected: false,
+		},
+		{
+			name:     "full percentage",
+			config:   Config{Rules: []Rule{{Percentage: pct(100), Value: true}}},
+			target:   Target{UserID: "alice"},
+			expected: true,
+		},
+		{
+			name:     "percentage needs a user",
+			config:   Config{Rules: []Rule{{Percentage: pct(100), Value: true}}},
+			expected: false,
+		},
+		{
+			name:     "zero percentage",
+			config:   Config{Rules: []Rule{{Percentage: pct(0), Value: true}}},
+			target:   Target{UserID: "alice"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newTestService(t, Snapshot{testBool.Key: tt.config})
+
+			// Act
+			got := Get(s, testBool, tt.target)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestGet_PercentageRollout(t *testing.T) {
+	// Arrange
+	s := newTestService(t, Snapshot{testBool.Key: {Rules: []Rule{{Percentage: pct(25), Value: true}}}})
+
+	// Act
+	enabled := 0
+	for i := 0; i < 4000; i++ {
+		user := Target{UserID: "user-" + strconv.Itoa(i)}
+		if Get(s, testBool, user) {
+			enabled++
+		}
+	}
+	first := Get(s, testBool, Target{UserID: "stable-user"})
+	second := Get(s, testBool, Target{UserID: "stable-user"})
+
+	// Assert
+	assert.InDelta(t, 1000, enabled, 120)
+	assert.Equal(t, first, second)
+}
+
+func TestGet_TypedValues(t *testing.T) {
+	// Arrange
+	s := newTestService(t, Snapshot{
+		testInt.Key:    {Value: float64(7)},
+		testString.Key: {Rules: []Rule{{Platforms: []string{"web"}, Value: "green"}}},
+	})
+
+	// Act
+	n := Get(s, testInt, Target{})
+	web := Get(s, testString, Target{Platform: clientinfo.PlatformWeb})
+	ios := Get(s, testString, Target{Platform: clientinfo.PlatformIOS})
+	all := s.Evaluate(Target{Platform: clientinfo.PlatformWeb})
+
+	// Assert
+	assert.Equal(t, 7, n)
+	assert.Equal(t, "green", web)
+	assert.Equal(t, "blue", ios)
+	assert.Equal(t, map[string]any{"test_bool": false, "test_int": 7, "test_string": "green"}, all)
+}
+
+func TestFileProvider(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "flags.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"test_bool": {"value": false, "rules": [{"users": ["alice"], "value": true}]}
+	}`), 0o600))
+	s := NewService(NewFileProvider(path), []Definition{testBool.Definition()}, zap.NewNop())
+
+	// Act
+	err := s.Refresh(context.Background())
+	alice := Get(s, testBool, Target{UserID: "alice"})
+
+	require.NoError(t, os.WriteFile(path, []byte(`{not json`), 0o600))
+	badErr := s.Refresh(context.Background())
+	stillAlice := Get(s, testBool, Target{UserID: "alice"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, alice)
+	assert.Error(t, badErr)
+	assert.True(t, stillAlice, "a failed refresh keeps the previous snapshot")
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		platform       string
+		expectedStatus int
+	}{
+		{name: "enabled for user", token: "alice-token", expectedStatus: http.StatusOK},
+		{name: "enabled for platform", platform: "android", expectedStatus: http.StatusOK},
+		{name: "disabled otherwise", token: "bob-token", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newTestService(t, Snapshot{testBool.Key: {Rules: []Rule{
+				{Users: []string{"alice"}, Value: true},
+				{Platforms: []string{"android"}, Value: true},
+			}}})
+			verifier := auth.StaticVerifier{"alice-token": {Subject: "alice"}, "bob-token": {Subject: "bob"}}
+
+			e := echo.New()
+			e.GET("/beta", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, auth.Optional(verifier), Require(s, testBool))
+
+			req := httptest.NewRequest(http.MethodGet, "/beta", nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			if tt.platform != "" {
+				req.Header.Set(clientinfo.HeaderPlatform, tt.platform)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
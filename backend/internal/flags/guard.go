@@ -0,0 +1,34 @@
+package flags
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+// TargetFrom builds the evaluation target of a request from its principal,
+// if authenticated, and the client headers
+func TargetFrom(c echo.Context) Target {
+	info := clientinfo.FromRequest(c.Request())
+	t := Target{Platform: info.Platform, AppVersion: info.Version}
+	if p := auth.PrincipalFrom(c); p != nil {
+		t.UserID = p.Subject
+	}
+	return t
+}
+
+// Require hides a route with 404 unless the flag is on for the caller. Place
+// it after the auth middleware so user targeting applies.
+func Require(s *Service, f Flag[bool]) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !Get(s, f, TargetFrom(c)) {
+				return echo.NewHTTPError(http.StatusNotFound, "Not Found")
+			}
+			return next(c)
+		}
+	}
+}
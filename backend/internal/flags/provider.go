@@ -0,0 +1,71 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Snapshot is the provider's configuration, keyed by flag
+type Snapshot map[string]Config
+
+// Config sets a flag's value and targeting. Rules are tried in order and the
+// first match wins; Value applies when none match, the default when unset.
+type Config struct {
+	Value any    `json:"value,omitempty"`
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule matches when every condition it sets holds for the target
+type Rule struct {
+	// Users lists user IDs
+	Users []string `json:"users,omitempty"`
+	// Platforms lists client platforms: ios, android or web
+	Platforms []string `json:"platforms,omitempty"`
+	// MinVersion and MaxVersion bound the app version, inclusive
+	MinVersion string `json:"minVersion,omitempty"`
+	MaxVersion string `json:"maxVersion,omitempty"`
+	// Percentage selects a stable share of signed-in users, 0 to 100
+	Percentage *float64 `json:"percentage,omitempty"`
+	// Value is returned when the rule matches; null means the default
+	Value any `json:"value"`
+}
+
+// Provider loads flag configuration
+type Provider interface {
+	Load(ctx context.Context) (Snapshot, error)
+}
+
+// StaticProvider serves a fixed snapshot. It backs deployments without a
+// flag source, where every flag has its default, and tests.
+type StaticProvider Snapshot
+
+// Load returns the snapshot
+func (p StaticProvider) Load(context.Context) (Snapshot, error) {
+	return Snapshot(p), nil
+}
+
+// FileProvider reads a JSON snapshot from disk on every load, so edits are
+// picked up on the next refresh
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider creates a provider reading path
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Load reads and parses the file
+func (p *FileProvider) Load(context.Context) (Snapshot, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("flags: reading %s: %w", p.path, err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("flags: parsing %s: %w", p.path, err)
+	}
+	return snap, nil
+}
@@ -0,0 +1,163 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2/google"
+
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+const (
+	remoteConfigURL   = "https://firebaseremoteconfig.googleapis.com"
+	remoteConfigScope = "https://www.googleapis.com/auth/firebase.remoteconfig"
+)
+
+var (
+	osCondition      = regexp.MustCompile(`^device\.os\s*==\s*'(ios|android)'$`)
+	percentCondition = regexp.MustCompile(`^percent(?:\('[^']*'\))?\s*<=\s*(\d+(?:\.\d+)?)$`)
+)
+
+// RemoteConfigProvider reads the Firebase Remote Config template the apps
+// use, so the backend sees the same keys and values. Conditions are mapped
+// to rules where they can be: "device.os == ..." and "percent <= N" terms
+// joined by "&&". Parameters under other conditions keep their default.
+type RemoteConfigProvider struct {
+	client  *http.Client
+	baseURL string
+	project string
+	logger  *zap.Logger
+}
+
+// NewRemoteConfigProvider creates a provider using Application Default
+// Credentials, which need the Remote Config Viewer role
+func NewRemoteConfigProvider(ctx context.Context, projectID string, logger *zap.Logger) (*RemoteConfigProvider, error) {
+	client, err := google.DefaultClient(ctx, remoteConfigScope)
+	if err != nil {
+		return nil, fmt.Errorf("flags: remote config credentials: %w", err)
+	}
+	return &RemoteConfigProvider{client: client, baseURL: remoteConfigURL, project: projectID, logger: logger}, nil
+}
+
+type remoteConfigTemplate struct {
+	Conditions []struct {
+		Name       string `json:"name"`
+		Expression string `json:"expression"`
+	} `json:"conditions"`
+	Parameters      map[string]remoteConfigParameter `json:"parameters"`
+	ParameterGroups map[string]struct {
+		Parameters map[string]remoteConfigParameter `json:"parameters"`
+	} `json:"parameterGroups"`
+}
+
+type remoteConfigParameter struct {
+	DefaultValue      *remoteConfigValue           `json:"defaultValue"`
+	ConditionalValues map[string]remoteConfigValue `json:"conditionalValues"`
+}
+
+type remoteConfigValue struct {
+	Value           *string `json:"value"`
+	UseInAppDefault bool    `json:"useInAppDefault"`
+}
+
+// Load fetches the current template
+func (p *RemoteConfigProvider) Load(ctx context.Context) (Snapshot, error) {
+	url := fmt.Sprintf("%s/v1/projects/%s/remoteConfig", p.baseURL, p.project)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("flags: fetching remote config: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("flags: fetching remote config: %s: %s", resp.Status, body)
+	}
+
+	var tmpl remoteConfigTemplate
+	if err := json.NewDecoder(resp.Body).Decode(&tmpl); err != nil {
+		return nil, fmt.Errorf("flags: decoding remote config: %w", err)
+	}
+	return p.snapshot(tmpl), nil
+}
+
+func (p *RemoteConfigProvider) snapshot(tmpl remoteConfigTemplate) Snapshot {
+	params := make(map[string]remoteConfigParameter, len(tmpl.Parameters))
+	for key, param := range tmpl.Parameters {
+		params[key] = param
+	}
+	for _, group := range tmpl.ParameterGroups {
+		for key, param := range group.Parameters {
+			params[key] = param
+		}
+	}
+
+	snap := make(Snapshot, len(params))
+	for key, param := range params {
+		var cfg Config
+		if v := param.DefaultValue; v != nil && !v.UseInAppDefault && v.Value != nil {
+			cfg.Value = *v.Value
+		}
+
+		// Remote Config evaluates conditions in template order
+		for _, cond := range tmpl.Conditions {
+			v, ok := param.ConditionalValues[cond.Name]
+			if !ok {
+				continue
+			}
+			rule, ok := conditionRule(cond.Expression)
+			if !ok {
+				p.logger.Debug("skipping unsupported remote config condition",
+					zap.String("flag", key), zap.String("condition", cond.Name))
+				continue
+			}
+			if !v.UseInAppDefault && v.Value != nil {
+				rule.Value = *v.Value
+			}
+			cfg.Rules = append(cfg.Rules, rule)
+		}
+		snap[key] = cfg
+	}
+	return snap
+}
+
+// conditionRule maps a Remote Config condition expression to a rule
+func conditionRule(expr string) (Rule, bool) {
+	var rule Rule
+	for _, term := range strings.Split(expr, "&&") {
+		term = strings.TrimSpace(term)
+		if m := osCondition.FindStringSubmatch(term); m != nil {
+			platform := clientinfo.PlatformIOS
+			if m[1] == "android" {
+				platform = clientinfo.PlatformAndroid
+			}
+			rule.Platforms = append(rule.Platforms, platform)
+			continue
+		}
+		if m := percentCondition.FindStringSubmatch(term); m != nil {
+			pct, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				return Rule{}, false
+			}
+			rule.Percentage = &pct
+			continue
+		}
+		if term == "true" {
+			continue
+		}
+		return Rule{}, false
+	}
+	return rule, true
+}
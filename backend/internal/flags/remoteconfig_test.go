@@ -0,0 +1,111 @@
+package flags
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clientinfo"
+)
+
+const testTemplate = `{
+  "conditions": [
+    {"name": "iOS beta", "expression": "device.os == 'ios' && percent <= 100"},
+    {"name": "Android", "expression": "device.os == 'android'"},
+    {"name": "Country", "expression": "device.country in ['DE']"}
+  ],
+  "parameters": {
+    "test_bool": {
+      "defaultValue": {"value": "false"},
+      "conditionalValues": {
+        "Country": {"value": "true"},
+        "Android": {"useInAppDefault": true},
+        "iOS beta": {"value": "true"}
+      }
+    }
+  },
+  "parameterGroups": {
+    "tuning": {
+      "parameters": {
+        "test_int": {"defaultValue": {"value": "12"}},
+        "test_string": {"defaultValue": {"useInAppDefault": true}}
+      }
+    }
+  },
+  "version": {"versionNumber": "42"}
+}`
+
+func TestRemoteConfigProvider(t *testing.T) {
+	// Arrange
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testTemplate))
+	}))
+	defer server.Close()
+
+	provider := &RemoteConfigProvider{client: server.Client(), baseURL: server.URL, project: "demo", logger: zap.NewNop()}
+	s := NewService(provider, []Definition{
+		testBool.Definition(), testInt.Definition(), testString.Definition(),
+	}, zap.NewNop())
+
+	// Act
+	err := s.Refresh(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/projects/demo/remoteConfig", path)
+	assert.True(t, Get(s, testBool, Target{UserID: "u", Platform: clientinfo.PlatformIOS}))
+	assert.False(t, Get(s, testBool, Target{Platform: clientinfo.PlatformIOS}), "percent conditions need a user")
+	assert.False(t, Get(s, testBool, Target{Platform: clientinfo.PlatformAndroid}))
+	assert.False(t, Get(s, testBool, Target{}), "unsupported conditions never match")
+	assert.Equal(t, 12, Get(s, testInt, Target{}))
+	assert.Equal(t, "blue", Get(s, testString, Target{}))
+}
+
+func TestRemoteConfigProvider_Error(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"error":{"status":"PERMISSION_DENIED"}}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+	provider := &RemoteConfigProvider{client: server.Client(), baseURL: server.URL, project: "demo", logger: zap.NewNop()}
+
+	// Act
+	_, err := provider.Load(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "PERMISSION_DENIED")
+}
+
+func TestConditionRule(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected Rule
+		ok       bool
+	}{
+		{expr: "device.os == 'ios'", expected: Rule{Platforms: []string{"ios"}}, ok: true},
+		{expr: "percent <= 12.5", expected: Rule{Percentage: pct(12.5)}, ok: true},
+		{expr: "percent('seed') <= 5 && device.os == 'android'", expected: Rule{Percentage: pct(5), Platforms: []string{"android"}}, ok: true},
+		{expr: "true", expected: Rule{}, ok: true},
+		{expr: "device.language in ['en']", ok: false},
+		{expr: "device.os == 'ios' || device.os == 'android'", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			// Act
+			rule, ok := conditionRule(tt.expr)
+
+			// Assert
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, rule)
+		})
+	}
+}
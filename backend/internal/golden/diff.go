@@ -0,0 +1,79 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is how many unchanged lines Diff shows around a change
+const contextLines = 2
+
+// Diff returns the lines of want and got that differ, prefixed with "-"
+// and "+", between a few unchanged lines. It is empty when they are equal.
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]; golden files are short enough for the quadratic table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// Keep the changes and the unchanged lines within contextLines of one
+	show := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for n := max(k-contextLines, 0); n <= min(k+contextLines, len(lines)-1); n++ {
+			show[n] = true
+		}
+	}
+
+	var out strings.Builder
+	for k, l := range lines {
+		if !show[k] {
+			if k == 0 || show[k-1] {
+				out.WriteString("  ...\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&out, "%c %s\n", l.op, l.text)
+	}
+	return out.String()
+}
@@ -0,0 +1,204 @@
+// Package golden snapshots HTTP exchanges into testdata/*.golden files.
+//
+// Assert writes the request line, the status, selected headers and the
+// response body, JSON canonicalised, as text and compares it with the
+// test's golden file. Values that change from run to run, request IDs and
+// timestamps, are scrubbed first. Run the tests with -update to record new
+// or changed golden files, then review them like code:
+//
+//	go test ./internal/handlers -update
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden files with the current responses")
+
+// DefaultHeaders are the response headers every snapshot records when
+// present
+var DefaultHeaders = []string{
+	"Cache-Control",
+	"Content-Type",
+	"ETag",
+	"Location",
+	"Retry-After",
+	"X-Request-Id",
+}
+
+// Timestamp matches RFC 3339 times such as 2026-01-01T00:00:00.123Z
+var Timestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// Option changes what Assert records
+type Option func(*options)
+
+type options struct {
+	headers []string
+	scrubs  []scrub
+}
+
+type scrub struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Headers records these response headers as well as DefaultHeaders
+func Headers(names ...string) Option {
+	return func(o *options) {
+		o.headers = append(o.headers, names...)
+	}
+}
+
+// Scrub replaces every match of re in the snapshot with replacement,
+// e.g. "<id>", before it is compared
+func Scrub(re *regexp.Regexp, replacement string) Option {
+	return func(o *options) {
+		o.scrubs = append(o.scrubs, scrub{re, replacement})
+	}
+}
+
+// Assert fails t unless the exchange of req and res matches
+// testdata/<test name>.golden, or rewrites that file under -update. The
+// request body is recorded when req.GetBody is set, as by http.NewRequest.
+func Assert(t testing.TB, req *http.Request, res *http.Response, opts ...Option) {
+	t.Helper()
+	got, err := Snapshot(req, res, opts...)
+	if err != nil {
+		t.Fatalf("golden: %v", err)
+	}
+	path := filepath.Join("testdata", fileName(t.Name())+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden: %s does not exist; run the test with -update to record it", path)
+	}
+	if err != nil {
+		t.Fatalf("golden: %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("golden: response differs from %s (-want +got); run the test with -update if the change is intended:\n%s", path, Diff(string(want), got))
+	}
+}
+
+// Snapshot returns the scrubbed text Assert compares
+func Snapshot(req *http.Request, res *http.Response, opts ...Option) (string, error) {
+	o := options{headers: slices.Clone(DefaultHeaders)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL.RequestURI())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		if err := writeBody(&b, req.Header, body); err != nil {
+			return "", fmt.Errorf("request body: %w", err)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%d %s\n", res.StatusCode, http.StatusText(res.StatusCode))
+	names := slices.Clone(o.headers)
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(http.CanonicalHeaderKey(a), http.CanonicalHeaderKey(b))
+	})
+	for _, name := range slices.Compact(names) {
+		for _, value := range res.Header.Values(name) {
+			fmt.Fprintf(&b, "%s: %s\n", http.CanonicalHeaderKey(name), value)
+		}
+	}
+	if err := writeBody(&b, res.Header, res.Body); err != nil {
+		return "", fmt.Errorf("response body: %w", err)
+	}
+
+	out := b.String()
+	if id := res.Header.Get("X-Request-Id"); id != "" {
+		out = strings.ReplaceAll(out, id, "<request-id>")
+	}
+	out = Timestamp.ReplaceAllString(out, "<timestamp>")
+	for _, s := range o.scrubs {
+		out = s.re.ReplaceAllString(out, s.replacement)
+	}
+	return out, nil
+}
+
+// writeBody writes body after a blank line, indented with sorted keys when
+// it is JSON. An empty body writes nothing.
+func writeBody(b *strings.Builder, header http.Header, body io.Reader) error {
+	if body == nil {
+		return nil
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+
+	b.WriteString("\n")
+	if strings.Contains(header.Get("Content-Type"), "json") {
+		canonical, err := canonicalJSON(raw)
+		if err != nil {
+			return err
+		}
+		raw = canonical
+	}
+	b.Write(raw)
+	if !bytes.HasSuffix(raw, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	return nil
+}
+
+// canonicalJSON indents raw with object keys sorted, keeping numbers as
+// written
+func canonicalJSON(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName turns a test name such as "TestHello/greets_Alice" into
+// "TestHello_greets_Alice"
+func fileName(testName string) string {
+	return unsafeChars.ReplaceAllString(testName, "_")
+}
@@ -0,0 +1,215 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errFatal ends Assert where a real test would stop at Fatalf
+var errFatal = errors.New("fatal")
+
+// recordingT collects the failures of Assert instead of failing the test
+type recordingT struct {
+	testing.TB
+	name   string
+	errors []string
+	fatal  bool
+}
+
+func (r *recordingT) Name() string { return r.name }
+func (r *recordingT) Helper()      {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	panic(errFatal)
+}
+
+// assert runs Assert, recovering from Fatalf
+func (r *recordingT) assert(req *http.Request, res *http.Response) {
+	defer func() {
+		if v := recover(); v != nil && v != errFatal {
+			panic(v)
+		}
+	}()
+	Assert(r, req, res)
+}
+
+func exchange(body string) (*http.Request, *http.Response) {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orgs?b=2&a=1", strings.NewReader(`{"name":"Acme"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	rec.Header().Set("X-Request-Id", "f033fd6c1a525331")
+	rec.Header().Set("Date", "Mon, 19 Oct 2026 10:00:00 GMT")
+	rec.WriteHeader(http.StatusCreated)
+	_, _ = rec.WriteString(body)
+	return req, rec.Result()
+}
+
+func TestSnapshot(t *testing.T) {
+	// Arrange
+	req, res := exchange(`{"id":"org_1a2b","createdAt":"2026-10-19T10:00:00.123456Z","count":1.50,"requestId":"f033fd6c1a525331","html":"<b>"}`)
+
+	// Act
+	got, err := Snapshot(req, res, Scrub(regexp.MustCompile(`org_[0-9a-f]+`), "<org-id>"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, `POST /api/v1/orgs?b=2&a=1
+
+{
+  "name": "Acme"
+}
+
+201 Created
+Content-Type: application/json; charset=UTF-8
+X-Request-Id: <request-id>
+
+{
+  "count": 1.50,
+  "createdAt": "<timestamp>",
+  "html": "<b>",
+  "id": "<org-id>",
+  "requestId": "<request-id>"
+}
+`, got)
+}
+
+func TestSnapshot_Headers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{name: "default headers", expected: "X-Request-Id: <request-id>\n"},
+		{name: "extra header", opts: []Option{Headers("date")}, expected: "Date: Mon, 19 Oct 2026 10:00:00 GMT\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req, res := exchange("")
+
+			// Act
+			got, err := Snapshot(req, res, tt.opts...)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Contains(t, got, tt.expected)
+			assert.True(t, strings.HasSuffix(got, "X-Request-Id: <request-id>\n"), "an empty body is left out")
+		})
+	}
+}
+
+func TestSnapshot_InvalidJSON(t *testing.T) {
+	// Arrange
+	req, res := exchange(`{"id":`)
+
+	// Act
+	_, err := Snapshot(req, res)
+
+	// Assert
+	assert.ErrorContains(t, err, "response body")
+}
+
+func TestAssert(t *testing.T) {
+	// current stands for the snapshot of the exchange under test
+	const current = "<current>"
+	tests := []struct {
+		name           string
+		golden         string
+		update         bool
+		expectedError  string
+		expectedFatal  bool
+		expectedGolden string
+	}{
+		{name: "matches", golden: current},
+		{name: "differs", golden: "POST /api/v1/orgs?b=2&a=1\n\n200 OK\n", expectedError: "-want +got"},
+		{name: "missing", expectedError: "run the test with -update", expectedFatal: true},
+		{name: "update", golden: "stale\n", update: true, expectedGolden: "201 Created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Chdir(t.TempDir())
+			rt := &recordingT{TB: t, name: "TestOrgs/creates_" + tt.name}
+			path := filepath.Join("testdata", "TestOrgs_creates_"+tt.name+".golden")
+			if tt.golden != "" {
+				want := tt.golden
+				if want == current {
+					req, res := exchange(`{"id":"org_1"}`)
+					snapshot, err := Snapshot(req, res)
+					require.NoError(t, err)
+					want = snapshot
+				}
+				require.NoError(t, os.MkdirAll("testdata", 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(want), 0o644))
+			}
+			*update = tt.update
+			t.Cleanup(func() { *update = false })
+			req, res := exchange(`{"id":"org_1"}`)
+
+			// Act
+			rt.assert(req, res)
+
+			// Assert
+			if tt.expectedError == "" {
+				assert.Empty(t, rt.errors)
+			} else {
+				require.Len(t, rt.errors, 1)
+				assert.Contains(t, rt.errors[0], tt.expectedError)
+			}
+			assert.Equal(t, tt.expectedFatal, rt.fatal)
+			if tt.expectedGolden != "" {
+				written, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.Contains(t, string(written), tt.expectedGolden)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     string
+		got      string
+		expected string
+	}{
+		{name: "equal", want: "a\nb\n", got: "a\nb\n", expected: ""},
+		{
+			name:     "changed line",
+			want:     "1\n2\n3\n4\n5\n6\n7\n",
+			got:      "1\n2\n3\n4\nfive\n6\n7\n",
+			expected: "  ...\n  3\n  4\n- 5\n+ five\n  6\n  7\n  ...\n",
+		},
+		{name: "added line", want: "a\n", got: "a\nb\n", expected: "  a\n+ b\n  \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := Diff(tt.want, tt.got)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
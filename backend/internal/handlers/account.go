@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/audit"
+)
+
+// AccountHandler exports and deletes the caller's data
+type AccountHandler struct {
+	service *account.Service
+	logger  *zap.Logger
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(service *account.Service, logger *zap.Logger) *AccountHandler {
+	return &AccountHandler{service: service, logger: logger}
+}
+
+// RequestExport starts building an archive of the caller's data
+func (h *AccountHandler) RequestExport(c echo.Context) error {
+	e, err := h.service.RequestExport(c.Request().Context(), ownerID(c))
+	if err != nil {
+		return accountError(err)
+	}
+	audit.SetTarget(c, e.ID)
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/users/me/exports/"+e.ID)
+	return c.JSON(http.StatusAccepted, e)
+}
+
+// GetExport returns one of the caller's exports, with a download URL once ready
+func (h *AccountHandler) GetExport(c echo.Context) error {
+	e, err := h.service.GetExport(c.Request().Context(), ownerID(c), c.Param("id"))
+	if err != nil {
+		return accountError(err)
+	}
+	return c.JSON(http.StatusOK, e)
+}
+
+// RequestDeletion soft-deletes the caller's account and schedules the purge
+func (h *AccountHandler) RequestDeletion(c echo.Context) error {
+	d, err := h.service.RequestDeletion(c.Request().Context(), ownerID(c))
+	if err != nil {
+		return accountError(err)
+	}
+	audit.SetTarget(c, ownerID(c))
+	return c.JSON(http.StatusAccepted, d)
+}
+
+// GetDeletion returns the caller's pending deletion
+func (h *AccountHandler) GetDeletion(c echo.Context) error {
+	d, err := h.service.GetDeletion(c.Request().Context(), ownerID(c))
+	if err != nil {
+		return accountError(err)
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+func accountError(err error) error {
+	switch {
+	case errors.Is(err, account.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	case errors.Is(err, account.ErrExportInProgress), errors.Is(err, account.ErrDeletionBlocked):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return err
+	}
+}
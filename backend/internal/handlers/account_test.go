@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+func TestAccountHandler(t *testing.T) {
+	// Arrange
+	storage, err := files.NewLocalStorage(t.TempDir(), "http://files.test", []byte("test-key"), files.StorageOptions{})
+	require.NoError(t, err)
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 1})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+	st := account.NewMemoryStore()
+	require.NoError(t, st.CreateExport(context.Background(), account.Export{ID: "pending", OwnerID: "bob", Status: account.ExportPending}))
+	service := account.NewService(st, storage, account.NopIdentity{}, jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry, account.Options{
+		GracePeriod: time.Hour, ExportTTL: time.Hour, URLExpiry: time.Minute,
+	}, zap.NewNop())
+	h := NewAccountHandler(service, zap.NewNop())
+
+	e := echo.New()
+	me := e.Group("/api/v1/users/me", auth.Middleware(auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+		"bob-token":   {Subject: "bob"},
+	}))
+	me.POST("/export", h.RequestExport)
+	me.GET("/exports/:id", h.GetExport)
+	me.POST("/deletion", h.RequestDeletion)
+	me.GET("/deletion", h.GetDeletion)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		want   int
+	}{
+		{name: "export another user's archive", method: http.MethodGet, target: "/api/v1/users/me/exports/pending", token: "alice-token", want: http.StatusNotFound},
+		{name: "export while one is pending", method: http.MethodPost, target: "/api/v1/users/me/export", token: "bob-token", want: http.StatusConflict},
+		{name: "deletion never requested", method: http.MethodGet, target: "/api/v1/users/me/deletion", token: "alice-token", want: http.StatusNotFound},
+		{name: "request deletion", method: http.MethodPost, target: "/api/v1/users/me/deletion", token: "alice-token", want: http.StatusAccepted},
+		{name: "deletion scheduled", method: http.MethodGet, target: "/api/v1/users/me/deletion", token: "alice-token", want: http.StatusOK},
+		{name: "without token", method: http.MethodPost, target: "/api/v1/users/me/deletion", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/audit"
+)
+
+// maxAuditLimit caps the number of audit events returned at once
+const maxAuditLimit = 200
+
+// AuditHandler lets admins search the audit log
+type AuditHandler struct {
+	store audit.Store
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(store audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// List returns audit events matching the query filters, newest first
+func (h *AuditHandler) List(c echo.Context) error {
+	f := audit.Filter{
+		ActorID:    c.QueryParam("actor"),
+		Action:     audit.Action(c.QueryParam("action")),
+		TargetType: c.QueryParam("targetType"),
+		TargetID:   c.QueryParam("targetId"),
+		OrgID:      c.QueryParam("orgId"),
+		Outcome:    audit.Outcome(c.QueryParam("outcome")),
+		Limit:      50,
+		Cursor:     c.QueryParam("cursor"),
+	}
+	if f.Outcome != "" && !f.Outcome.Valid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "outcome must be success, denied or failure")
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 200")
+		}
+		f.Limit = n
+	}
+	for _, bound := range []struct {
+		param string
+		dst   *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		raw := c.QueryParam(bound.param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, bound.param+" must be an RFC 3339 time")
+		}
+		*bound.dst = t
+	}
+
+	page, err := h.store.Query(c.Request().Context(), f)
+	if err != nil {
+		return auditError(err)
+	}
+	if page.Events == nil {
+		page.Events = []audit.Event{}
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func auditError(err error) error {
+	switch {
+	case errors.Is(err, audit.ErrInvalidCursor):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/audit"
+)
+
+func TestAuditHandler_List(t *testing.T) {
+	// Arrange
+	st := audit.NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []audit.Action{"files.delete", "orgs.create", "files.delete"} {
+		require.NoError(t, st.Write(context.Background(), audit.Event{
+			ID:      string(rune('a' + i)),
+			Time:    base.Add(time.Duration(i) * time.Minute),
+			Action:  action,
+			Actor:   audit.Actor{ID: "alice"},
+			Outcome: audit.OutcomeSuccess,
+		}))
+	}
+	h := NewAuditHandler(st)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+		expectNext     bool
+	}{
+		{name: "all", query: "", expectedStatus: http.StatusOK, expectedIDs: []string{"c", "b", "a"}},
+		{name: "by action", query: "?action=files.delete", expectedStatus: http.StatusOK, expectedIDs: []string{"c", "a"}},
+		{name: "first page", query: "?limit=2", expectedStatus: http.StatusOK, expectedIDs: []string{"c", "b"}, expectNext: true},
+		{name: "since", query: "?since=2026-03-01T12:01:00Z", expectedStatus: http.StatusOK, expectedIDs: []string{"c", "b"}},
+		{name: "no match", query: "?actor=bob", expectedStatus: http.StatusOK, expectedIDs: []string{}},
+		{name: "limit too large", query: "?limit=500", expectedStatus: http.StatusBadRequest},
+		{name: "malformed time", query: "?until=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "unknown outcome", query: "?outcome=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "invalid cursor", query: "?cursor=%21", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			err := h.List(c)
+			if err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var page audit.Page
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+			ids := []string{}
+			for _, ev := range page.Events {
+				ids = append(ids, ev.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectNext, page.NextCursor != "")
+		})
+	}
+}
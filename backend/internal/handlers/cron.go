@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/cron"
+)
+
+// maxHistoryLimit caps the number of executions returned at once
+const maxHistoryLimit = 100
+
+// CronResponse is the execution history of a cron job
+type CronResponse struct {
+	Executions []cron.Execution `json:"executions"`
+}
+
+// CronHandler receives Cloud Scheduler triggers
+type CronHandler struct {
+	runner *cron.Runner
+}
+
+// NewCronHandler creates a new cron handler
+func NewCronHandler(runner *cron.Runner) *CronHandler {
+	return &CronHandler{runner: runner}
+}
+
+// Run executes a job. Skipped runs succeed, since overlap is expected;
+// failed runs return 500 so Cloud Scheduler marks the attempt as failed.
+func (h *CronHandler) Run(c echo.Context) error {
+	exec, err := h.runner.Run(c.Request().Context(), c.Param("job"))
+	if err != nil {
+		return cronError(err)
+	}
+
+	if exec.Status == cron.StatusFailed {
+		return c.JSON(http.StatusInternalServerError, exec)
+	}
+	return c.JSON(http.StatusOK, exec)
+}
+
+// History returns the latest executions of a job, newest first
+func (h *CronHandler) History(c echo.Context) error {
+	limit := 20
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		limit = n
+	}
+
+	executions, err := h.runner.History(c.Request().Context(), c.Param("job"), limit)
+	if err != nil {
+		return cronError(err)
+	}
+	return c.JSON(http.StatusOK, CronResponse{Executions: executions})
+}
+
+func cronError(err error) error {
+	switch {
+	case errors.Is(err, cron.ErrUnknownJob):
+		return echo.NewHTTPError(http.StatusNotFound, "unknown cron job")
+	case errors.Is(err, cron.ErrNotRegistered):
+		return echo.NewHTTPError(http.StatusNotImplemented, "cron job has no handler")
+	default:
+		return err
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/cron"
+)
+
+func setupCronServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	ok := cron.Definition{Name: "ok", Schedule: "* * * * *"}
+	broken := cron.Definition{Name: "broken", Schedule: "* * * * *"}
+	unbound := cron.Definition{Name: "unbound", Schedule: "* * * * *"}
+
+	registry, err := cron.NewRegistry([]cron.Definition{ok, broken, unbound})
+	require.NoError(t, err)
+	registry.Register(ok, func(context.Context) error { return nil })
+	registry.Register(broken, func(context.Context) error { return errors.New("boom") })
+
+	handler := NewCronHandler(cron.NewRunner(registry, cron.NewMemoryStore(), cron.RunnerOptions{}, zap.NewNop()))
+
+	e := echo.New()
+	e.POST("/internal/cron/:job", handler.Run)
+	e.GET("/internal/cron/:job/executions", handler.History)
+	return e
+}
+
+func TestCronHandler_Run(t *testing.T) {
+	tests := []struct {
+		name           string
+		job            string
+		expectedStatus int
+	}{
+		{name: "runs job", job: "ok", expectedStatus: http.StatusOK},
+		{name: "reports failed job", job: "broken", expectedStatus: http.StatusInternalServerError},
+		{name: "rejects unknown job", job: "missing", expectedStatus: http.StatusNotFound},
+		{name: "rejects job without handler", job: "unbound", expectedStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := setupCronServer(t)
+			req := httptest.NewRequest(http.MethodPost, "/internal/cron/"+tt.job, nil)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestCronHandler_History(t *testing.T) {
+	// Arrange
+	e := setupCronServer(t)
+	for range 3 {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/internal/cron/ok", nil))
+	}
+	req := httptest.NewRequest(http.MethodGet, "/internal/cron/ok/executions?limit=2", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response CronResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Len(t, response.Executions, 2)
+	assert.Equal(t, cron.StatusSucceeded, response.Executions[0].Status)
+}
+
+func TestCronHandler_History_RejectsBadLimit(t *testing.T) {
+	// Arrange
+	e := setupCronServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/internal/cron/ok/executions?limit=1000", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
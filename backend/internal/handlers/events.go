@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/events"
+	"github.com/your-org/your-app/internal/server"
+)
+
+// EventReset tells a resuming client that events were lost and it should
+// refetch its state
+const EventReset = "stream.reset"
+
+// retryMillis is the reconnect delay suggested to clients
+const retryMillis = 3000
+
+// StreamOptions tunes event streams
+type StreamOptions struct {
+	// Heartbeat is the interval of keep-alive comments
+	Heartbeat time.Duration
+	// MaxDuration ends streams before the platform's request timeout does, so
+	// clients reconnect cleanly with Last-Event-ID
+	MaxDuration time.Duration
+}
+
+// EventsHandler streams the caller's events as Server-Sent Events
+type EventsHandler struct {
+	broker *events.Broker
+	opts   StreamOptions
+	logger *zap.Logger
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(broker *events.Broker, opts StreamOptions, logger *zap.Logger) *EventsHandler {
+	return &EventsHandler{broker: broker, opts: opts, logger: logger}
+}
+
+// Stream sends events for the caller and broadcasts until the client
+// disconnects, the stream reaches its maximum duration or the server stops
+func (h *EventsHandler) Stream(c echo.Context) error {
+	uid := ownerID(c)
+	sub, err := h.broker.Subscribe(
+		[]string{events.UserTopic(uid)},
+		c.Request().Header.Get("Last-Event-ID"),
+	)
+	if errors.Is(err, events.ErrClosed) {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "server is shutting down")
+	}
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, server.MIMEEventStream)
+	res.Header().Set("Cache-Control", "no-store")
+	// Disables response buffering in nginx-style proxies
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", retryMillis); err != nil {
+		return nil
+	}
+	if sub.Gap {
+		if err := writeEvent(res, events.Event{Type: EventReset, Data: []byte("{}")}); err != nil {
+			return nil
+		}
+	}
+	for _, e := range sub.Replay {
+		if err := writeEvent(res, e); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(h.opts.Heartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(h.opts.MaxDuration)
+	defer deadline.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.Done():
+			// Dropped for falling behind or the server is stopping; the
+			// client resumes from its last event
+			return nil
+		case <-deadline.C:
+			return nil
+		case e := <-sub.Events():
+			if err := writeEvent(res, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// writeEvent writes one SSE frame. JSON data never contains newlines, so it
+// always fits on a single data line.
+func writeEvent(w io.Writer, e events.Event) error {
+	if e.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", e.ID); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, e.Data)
+	return err
+}
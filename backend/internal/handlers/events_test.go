@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/events"
+)
+
+type eventsTestEnv struct {
+	broker *events.Broker
+	server *httptest.Server
+}
+
+func setupEventsServer(t *testing.T, opts StreamOptions) *eventsTestEnv {
+	t.Helper()
+
+	broker := events.NewBroker(events.Options{ReplaySize: 2})
+	handler := NewEventsHandler(broker, opts, zap.NewNop())
+	verifier := auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+	}
+
+	e := echo.New()
+	e.GET("/api/v1/events/stream", handler.Stream, auth.Middleware(verifier))
+
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	t.Cleanup(broker.Close)
+	return &eventsTestEnv{broker: broker, server: server}
+}
+
+// open connects to the stream and waits until the broker has subscribed it
+func (env *eventsTestEnv) open(t *testing.T, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, env.server.URL+"/api/v1/events/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer alice-token")
+	req.Header.Set(echo.HeaderAccept, "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	before := env.broker.Subscribers()
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	if resp.StatusCode == http.StatusOK {
+		require.Eventually(t, func() bool { return env.broker.Subscribers() > before }, time.Second, time.Millisecond)
+	}
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// frame reads lines up to the next blank line
+func frame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestEventsHandler_Stream(t *testing.T) {
+	// Arrange
+	env := setupEventsServer(t, StreamOptions{Heartbeat: time.Hour, MaxDuration: time.Hour})
+	resp, r := env.open(t, "")
+
+	// Act
+	_, err := env.broker.Publish(events.UserTopic("bob"), "file.ready", map[string]string{"id": "b"})
+	require.NoError(t, err)
+	published, err := env.broker.Publish(events.UserTopic("alice"), "file.ready", map[string]string{"id": "a"})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get(echo.HeaderContentType))
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "retry: 3000", frame(t, r))
+	assert.Equal(t, "id: "+published.ID+"\nevent: file.ready\ndata: {\"id\":\"a\"}", frame(t, r))
+}
+
+func TestEventsHandler_Resume(t *testing.T) {
+	tests := []struct {
+		name     string
+		resume   func(ids []string) string
+		expected []string
+	}{
+		{
+			name:     "replays events after the last one seen",
+			resume:   func(ids []string) string { return ids[2] },
+			expected: []string{"event: e3"},
+		},
+		{
+			name:     "resets when events were lost",
+			resume:   func(ids []string) string { return ids[0] },
+			expected: []string{"event: " + EventReset, "event: e2", "event: e3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupEventsServer(t, StreamOptions{Heartbeat: time.Hour, MaxDuration: time.Hour})
+			var ids []string
+			for _, typ := range []string{"e0", "e1", "e2", "e3"} {
+				e, err := env.broker.Publish(events.UserTopic("alice"), typ, nil)
+				require.NoError(t, err)
+				ids = append(ids, e.ID)
+			}
+
+			// Act
+			_, r := env.open(t, tt.resume(ids))
+
+			// Assert
+			assert.Equal(t, "retry: 3000", frame(t, r))
+			for _, want := range tt.expected {
+				assert.Contains(t, frame(t, r), want)
+			}
+		})
+	}
+}
+
+func TestEventsHandler_Heartbeat(t *testing.T) {
+	// Arrange
+	env := setupEventsServer(t, StreamOptions{Heartbeat: 10 * time.Millisecond, MaxDuration: time.Hour})
+
+	// Act
+	_, r := env.open(t, "")
+
+	// Assert
+	assert.Equal(t, "retry: 3000", frame(t, r))
+	assert.Equal(t, ": ping", frame(t, r))
+}
+
+func TestEventsHandler_Ends(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  StreamOptions
+		close bool
+	}{
+		{name: "at max duration", opts: StreamOptions{Heartbeat: time.Hour, MaxDuration: 20 * time.Millisecond}},
+		{name: "on shutdown", opts: StreamOptions{Heartbeat: time.Hour, MaxDuration: time.Hour}, close: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupEventsServer(t, tt.opts)
+			_, r := env.open(t, "")
+			assert.Equal(t, "retry: 3000", frame(t, r))
+
+			// Act
+			if tt.close {
+				env.broker.Close()
+			}
+
+			// Assert
+			_, err := r.ReadString('\n')
+			assert.Error(t, err)
+			assert.Eventually(t, func() bool { return env.broker.Subscribers() == 0 }, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestEventsHandler_RequiresAuth(t *testing.T) {
+	// Arrange
+	env := setupEventsServer(t, StreamOptions{Heartbeat: time.Hour, MaxDuration: time.Hour})
+
+	// Act
+	resp, err := http.Get(env.server.URL + "/api/v1/events/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 0, env.broker.Subscribers())
+}
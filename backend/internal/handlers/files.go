@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/server"
+)
+
+// maxFilesLimit caps the number of files listed at once
+const maxFilesLimit = 100
+
+// FilesResponse is a page of the caller's files
+type FilesResponse struct {
+	Files []files.File `json:"files"`
+}
+
+// FilesHandler issues signed upload and download URLs for the caller's files
+type FilesHandler struct {
+	service *files.Service
+	logger  *zap.Logger
+}
+
+// NewFilesHandler creates a new files handler
+func NewFilesHandler(service *files.Service, logger *zap.Logger) *FilesHandler {
+	return &FilesHandler{service: service, logger: logger}
+}
+
+// CreateUpload records a pending file and returns a signed upload URL
+func (h *FilesHandler) CreateUpload(c echo.Context) error {
+	var req files.UploadRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+
+	upload, err := h.service.CreateUpload(c.Request().Context(), ownerID(c), req)
+	if err != nil {
+		return filesError(err)
+	}
+	audit.SetTarget(c, upload.File.ID)
+	return c.JSON(http.StatusCreated, upload)
+}
+
+// List returns the caller's files, newest first
+func (h *FilesHandler) List(c echo.Context) error {
+	limit := 20
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxFilesLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		limit = n
+	}
+
+	list, err := h.service.List(c.Request().Context(), ownerID(c), limit)
+	if err != nil {
+		return filesError(err)
+	}
+	if list == nil {
+		list = []files.File{}
+	}
+	return c.JSON(http.StatusOK, FilesResponse{Files: list})
+}
+
+// Get returns the metadata of one of the caller's files
+func (h *FilesHandler) Get(c echo.Context) error {
+	f, err := h.service.Get(c.Request().Context(), ownerID(c), c.Param("id"))
+	if err != nil {
+		return filesError(err)
+	}
+	// Every change to a file moves UpdatedAt, so it versions the representation
+	c.Response().Header().Set(server.HeaderETag, server.VersionETag(f.ID, strconv.FormatInt(f.UpdatedAt.UnixNano(), 10)))
+	return c.JSON(http.StatusOK, f)
+}
+
+// Download returns a signed download URL for one of the caller's files
+func (h *FilesHandler) Download(c echo.Context) error {
+	signed, err := h.service.Download(c.Request().Context(), ownerID(c), c.Param("id"))
+	if err != nil {
+		return filesError(err)
+	}
+	return c.JSON(http.StatusOK, signed)
+}
+
+// Delete removes one of the caller's files
+func (h *FilesHandler) Delete(c echo.Context) error {
+	if err := h.service.Delete(c.Request().Context(), ownerID(c), c.Param("id")); err != nil {
+		return filesError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// storageEvent is a Cloud Storage notification delivered by a Pub/Sub push subscription
+type storageEvent struct {
+	Message struct {
+		Attributes map[string]string `json:"attributes"`
+		Data       string            `json:"data"`
+	} `json:"message"`
+}
+
+// storageObject is the JSON_API_V1 payload of a storage notification
+type storageObject struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        string `json:"size"`
+}
+
+// StorageEvent finalizes files when Cloud Storage reports a completed
+// upload. Pub/Sub redelivers any non-2xx response, so only transient
+// failures return an error; malformed and unrelated events are acknowledged.
+func (h *FilesHandler) StorageEvent(c echo.Context) error {
+	var event storageEvent
+	if err := json.NewDecoder(c.Request().Body).Decode(&event); err != nil {
+		h.logger.Warn("malformed storage event", zap.Error(err))
+		return c.NoContent(http.StatusNoContent)
+	}
+	if event.Message.Attributes["eventType"] != "OBJECT_FINALIZE" {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	var obj storageObject
+	data, err := base64.StdEncoding.DecodeString(event.Message.Data)
+	if err == nil {
+		err = json.Unmarshal(data, &obj)
+	}
+	size, sizeErr := strconv.ParseInt(obj.Size, 10, 64)
+	if err != nil || sizeErr != nil {
+		h.logger.Warn("malformed storage object", zap.Error(errors.Join(err, sizeErr)))
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	attrs := files.ObjectAttrs{Name: obj.Name, ContentType: obj.ContentType, Size: size}
+	if err := h.service.Finalize(c.Request().Context(), attrs); err != nil {
+		h.logger.Error("finalizing upload failed", zap.String("object", obj.Name), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "finalize failed")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ownerID is the subject of the authenticated caller
+func ownerID(c echo.Context) string {
+	return auth.PrincipalFrom(c).Subject
+}
+
+func filesError(err error) error {
+	switch {
+	case errors.Is(err, files.ErrContentType), errors.Is(err, files.ErrInvalidSize):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, files.ErrTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, files.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, files.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "file not found")
+	case errors.Is(err, files.ErrNotReady):
+		return echo.NewHTTPError(http.StatusConflict, "file upload not complete")
+	default:
+		return err
+	}
+}
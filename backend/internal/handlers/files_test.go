@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/files"
+)
+
+type filesTestEnv struct {
+	e       *echo.Echo
+	service *files.Service
+	storage *files.LocalStorage
+}
+
+func setupFilesServer(t *testing.T) *filesTestEnv {
+	t.Helper()
+
+	storage, err := files.NewLocalStorage(t.TempDir(), "http://files.test", []byte("test-key"), files.StorageOptions{})
+	require.NoError(t, err)
+	limits := files.Limits{MaxSize: 1024, AllowedTypes: []string{"image/*"}}
+	service := files.NewService(storage, files.NewMemoryStore(), files.Options{Limits: limits, URLExpiry: time.Minute}, zap.NewNop())
+	handler := NewFilesHandler(service, zap.NewNop())
+
+	verifier := auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+		"bob-token":   {Subject: "bob"},
+	}
+
+	e := echo.New()
+	api := e.Group("/api/v1/files", auth.Middleware(verifier))
+	api.POST("/uploads", handler.CreateUpload)
+	api.GET("", handler.List)
+	api.GET("/:id", handler.Get)
+	api.GET("/:id/download", handler.Download)
+	api.DELETE("/:id", handler.Delete)
+	e.POST("/internal/storage/events", handler.StorageEvent)
+
+	return &filesTestEnv{e: e, service: service, storage: storage}
+}
+
+func (env *filesTestEnv) do(method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	env.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (env *filesTestEnv) createUpload(t *testing.T) files.Upload {
+	t.Helper()
+	rec := env.do(http.MethodPost, "/api/v1/files/uploads", "alice-token",
+		`{"name":"cat.png","contentType":"image/png","size":3}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var upload files.Upload
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &upload))
+	return upload
+}
+
+// storageEventBody builds a Pub/Sub push body for a storage notification
+func storageEventBody(eventType, name, contentType, size string) string {
+	data, _ := json.Marshal(map[string]string{"name": name, "contentType": contentType, "size": size})
+	body, _ := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"attributes": map[string]string{"eventType": eventType},
+			"data":       base64.StdEncoding.EncodeToString(data),
+		},
+	})
+	return string(body)
+}
+
+func TestFilesHandler_CreateUpload(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "issues upload url",
+			token:          "alice-token",
+			body:           `{"name":"cat.png","contentType":"image/png","size":3}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "requires authentication",
+			body:           `{"name":"cat.png","contentType":"image/png","size":3}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects disallowed content type",
+			token:          "alice-token",
+			body:           `{"name":"page.html","contentType":"text/html","size":3}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects oversized file",
+			token:          "alice-token",
+			body:           `{"name":"cat.png","contentType":"image/png","size":2048}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "rejects malformed body",
+			token:          "alice-token",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects missing name",
+			token:          "alice-token",
+			body:           `{"contentType":"image/png","size":3}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupFilesServer(t)
+
+			// Act
+			rec := env.do(http.MethodPost, "/api/v1/files/uploads", tt.token, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestFilesHandler_UploadResponse(t *testing.T) {
+	// Arrange
+	env := setupFilesServer(t)
+
+	// Act
+	upload := env.createUpload(t)
+
+	// Assert
+	assert.NotEmpty(t, upload.File.ID)
+	assert.Equal(t, "alice", upload.File.OwnerID)
+	assert.Equal(t, files.StatusPending, upload.File.Status)
+	assert.Equal(t, http.MethodPut, upload.Upload.Method)
+	assert.Equal(t, "image/png", upload.Upload.Headers["Content-Type"])
+	assert.True(t, strings.HasPrefix(upload.Upload.URL, "http://files.test"+files.LocalRoutePrefix+"users/alice/"))
+}
+
+func TestFilesHandler_ScopedToCaller(t *testing.T) {
+	// Arrange
+	env := setupFilesServer(t)
+	upload := env.createUpload(t)
+	path := "/api/v1/files/" + upload.File.ID
+
+	// Act
+	get := env.do(http.MethodGet, path, "bob-token", "")
+	download := env.do(http.MethodGet, path+"/download", "bob-token", "")
+	del := env.do(http.MethodDelete, path, "bob-token", "")
+	list := env.do(http.MethodGet, "/api/v1/files", "bob-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, get.Code)
+	assert.Equal(t, http.StatusNotFound, download.Code)
+	assert.Equal(t, http.StatusNotFound, del.Code)
+	assert.Equal(t, http.StatusOK, list.Code)
+	assert.JSONEq(t, `{"files":[]}`, list.Body.String())
+}
+
+func TestFilesHandler_Download(t *testing.T) {
+	// Arrange
+	env := setupFilesServer(t)
+	upload := env.createUpload(t)
+	path := "/api/v1/files/" + upload.File.ID + "/download"
+	object := files.ObjectName(upload.File.OwnerID, upload.File.ID, upload.File.Name)
+
+	// Act
+	pending := env.do(http.MethodGet, path, "alice-token", "")
+
+	w, err := env.storage.Create(context.Background(), object, "image/png")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("png"))
+	require.NoError(t, w.Close())
+	event := env.do(http.MethodPost, "/internal/storage/events", "",
+		storageEventBody("OBJECT_FINALIZE", object, "image/png", "3"))
+
+	ready := env.do(http.MethodGet, path, "alice-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, pending.Code)
+	assert.Equal(t, http.StatusNoContent, event.Code)
+	assert.Equal(t, http.StatusOK, ready.Code)
+
+	var signed files.SignedURL
+	require.NoError(t, json.Unmarshal(ready.Body.Bytes(), &signed))
+	assert.Equal(t, http.MethodGet, signed.Method)
+	assert.NotEmpty(t, signed.URL)
+}
+
+func TestFilesHandler_StorageEvent_Acknowledges(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed body", body: `{`},
+		{name: "other event type", body: storageEventBody("OBJECT_DELETE", "users/alice/files/x/a.png", "image/png", "3")},
+		{name: "malformed size", body: storageEventBody("OBJECT_FINALIZE", "users/alice/files/x/a.png", "image/png", "big")},
+		{name: "unmanaged object", body: storageEventBody("OBJECT_FINALIZE", "public/logo.png", "image/png", "3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupFilesServer(t)
+
+			// Act
+			rec := env.do(http.MethodPost, "/internal/storage/events", "", tt.body)
+
+			// Assert
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
+
+func TestFilesHandler_List(t *testing.T) {
+	// Arrange
+	env := setupFilesServer(t)
+	upload := env.createUpload(t)
+
+	// Act
+	rec := env.do(http.MethodGet, "/api/v1/files?limit=10", "alice-token", "")
+	invalid := env.do(http.MethodGet, "/api/v1/files?limit=500", "alice-token", "")
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response FilesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Files, 1)
+	assert.Equal(t, upload.File.ID, response.Files[0].ID)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+func TestFilesHandler_Delete(t *testing.T) {
+	// Arrange
+	env := setupFilesServer(t)
+	upload := env.createUpload(t)
+	path := "/api/v1/files/" + upload.File.ID
+
+	// Act
+	del := env.do(http.MethodDelete, path, "alice-token", "")
+	get := env.do(http.MethodGet, path, "alice-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, del.Code)
+	assert.Equal(t, http.StatusNotFound, get.Code)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/flags"
+)
+
+// FlagsResponse holds every known flag evaluated for the caller
+type FlagsResponse struct {
+	Flags map[string]any `json:"flags"`
+}
+
+// FlagsHandler exposes evaluated feature flags
+type FlagsHandler struct {
+	service *flags.Service
+}
+
+// NewFlagsHandler creates a new flags handler
+func NewFlagsHandler(service *flags.Service) *FlagsHandler {
+	return &FlagsHandler{service: service}
+}
+
+// List evaluates every flag for the caller. Anonymous callers get values for
+// their client platform and version only.
+func (h *FlagsHandler) List(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "private, no-store")
+	return c.JSON(http.StatusOK, FlagsResponse{
+		Flags: h.service.Evaluate(flags.TargetFrom(c)),
+	})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clientinfo"
+	"github.com/your-org/your-app/internal/flags"
+)
+
+func TestFlagsHandler_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		platform       string
+		expectedStatus int
+		expectedFlags  map[string]any
+	}{
+		{
+			name:           "anonymous caller gets defaults",
+			expectedStatus: http.StatusOK,
+			expectedFlags:  map[string]any{"new_feature_enabled": false, "maintenance_mode": false},
+		},
+		{
+			name:           "targeted user",
+			token:          "alice-token",
+			expectedStatus: http.StatusOK,
+			expectedFlags:  map[string]any{"new_feature_enabled": true, "maintenance_mode": false},
+		},
+		{
+			name:           "targeted platform",
+			platform:       clientinfo.PlatformAndroid,
+			expectedStatus: http.StatusOK,
+			expectedFlags:  map[string]any{"new_feature_enabled": false, "maintenance_mode": true},
+		},
+		{
+			name:           "invalid token",
+			token:          "bad-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := flags.NewService(flags.StaticProvider{
+				flags.NewFeatureEnabled.Key: {Rules: []flags.Rule{{Users: []string{"alice"}, Value: true}}},
+				flags.MaintenanceMode.Key:   {Rules: []flags.Rule{{Platforms: []string{"android"}, Value: true}}},
+			}, flags.Catalogue, zap.NewNop())
+			require.NoError(t, service.Refresh(context.Background()))
+			handler := NewFlagsHandler(service)
+
+			e := echo.New()
+			e.GET("/api/v1/flags", handler.List, auth.Optional(auth.StaticVerifier{"alice-token": {Subject: "alice"}}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/flags", nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			if tt.platform != "" {
+				req.Header.Set(clientinfo.HeaderPlatform, tt.platform)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedFlags != nil {
+				var response FlagsResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedFlags, response.Flags)
+			}
+		})
+	}
+}
@@ -1,14 +1,14 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/labstack/echo/v4"
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/golden"
 )
 
 func TestHealthHandler_Health(t *testing.T) {
@@ -23,37 +23,27 @@ func TestHealthHandler_Health(t *testing.T) {
 	// Act
 	err := handler.Health(c)
 
-	// Assert
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, rec.Code)
-
-	var response HealthResponse
-	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	// Assert: the version is left out of the basic health check
 	require.NoError(t, err)
-	assert.Equal(t, "ok", response.Status)
-	assert.Empty(t, response.Version) // Version not included in basic health
+	golden.Assert(t, req, rec.Result())
 }
 
 func TestHealthHandler_HealthWithVersion(t *testing.T) {
 	tests := []struct {
-		name            string
-		version         string
-		expectedVersion string
+		name    string
+		version string
 	}{
 		{
-			name:            "returns version 1.0.0",
-			version:         "1.0.0",
-			expectedVersion: "1.0.0",
+			name:    "returns version 1.0.0",
+			version: "1.0.0",
 		},
 		{
-			name:            "returns version 2.1.0",
-			version:         "2.1.0",
-			expectedVersion: "2.1.0",
+			name:    "returns version 2.1.0",
+			version: "2.1.0",
 		},
 		{
-			name:            "handles empty version",
-			version:         "",
-			expectedVersion: "",
+			name:    "handles empty version",
+			version: "",
 		},
 	}
 
@@ -72,13 +62,7 @@ func TestHealthHandler_HealthWithVersion(t *testing.T) {
 
 			// Assert
 			require.NoError(t, err)
-			assert.Equal(t, http.StatusOK, rec.Code)
-
-			var response HealthResponse
-			err = json.Unmarshal(rec.Body.Bytes(), &response)
-			require.NoError(t, err)
-			assert.Equal(t, "ok", response.Status)
-			assert.Equal(t, tt.expectedVersion, response.Version)
+			golden.Assert(t, req, rec.Result())
 		})
 	}
 }
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
 )
 
+// maxNameLength is the longest name greeted, in characters
+const maxNameLength = 100
+
 // HelloResponse represents the hello endpoint response
 type HelloResponse struct {
 	Message string `json:"message"`
@@ -28,8 +32,12 @@ func (h *HelloHandler) Hello(c echo.Context) error {
 		name = "World"
 	}
 
-	// Basic input validation - prevent excessively long names
-	if len(name) > 100 {
+	// Basic input validation - prevent excessively long names. The limit
+	// counts characters, like maxLength in the API spec.
+	if !utf8.ValidString(name) {
+		return echo.NewHTTPError(http.StatusBadRequest, "name must be valid UTF-8")
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
 		return echo.NewHTTPError(http.StatusBadRequest, "name too long")
 	}
 
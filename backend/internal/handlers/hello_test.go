@@ -2,46 +2,45 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/internal/golden"
 )
 
 func TestHelloHandler_Hello(t *testing.T) {
 	tests := []struct {
-		name           string
-		queryName      string
-		expectedMsg    string
-		expectedStatus int
+		name      string
+		queryName string
 	}{
 		{
-			name:           "greets world when no name provided",
-			queryName:      "",
-			expectedMsg:    "Hello, World!",
-			expectedStatus: http.StatusOK,
+			name:      "greets world when no name provided",
+			queryName: "",
+		},
+		{
+			name:      "greets provided name",
+			queryName: "Alice",
 		},
 		{
-			name:           "greets provided name",
-			queryName:      "Alice",
-			expectedMsg:    "Hello, Alice!",
-			expectedStatus: http.StatusOK,
+			name:      "handles special characters",
+			queryName: "O'Brien",
 		},
 		{
-			name:           "handles special characters",
-			queryName:      "O'Brien",
-			expectedMsg:    "Hello, O'Brien!",
-			expectedStatus: http.StatusOK,
+			name:      "handles unicode names",
+			queryName: "世界",
 		},
 		{
-			name:           "handles unicode names",
-			queryName:      "世界",
-			expectedMsg:    "Hello, 世界!",
-			expectedStatus: http.StatusOK,
+			name:      "trims surrounding spaces",
+			queryName: "  Bob  ",
 		},
 	}
 
@@ -51,7 +50,7 @@ func TestHelloHandler_Hello(t *testing.T) {
 			e := echo.New()
 			target := "/api/v1/hello"
 			if tt.queryName != "" {
-				target += "?name=" + tt.queryName
+				target += "?name=" + url.QueryEscape(tt.queryName)
 			}
 			req := httptest.NewRequest(http.MethodGet, target, nil)
 			rec := httptest.NewRecorder()
@@ -64,12 +63,7 @@ func TestHelloHandler_Hello(t *testing.T) {
 
 			// Assert
 			require.NoError(t, err)
-			assert.Equal(t, tt.expectedStatus, rec.Code)
-
-			var response HelloResponse
-			err = json.Unmarshal(rec.Body.Bytes(), &response)
-			require.NoError(t, err)
-			assert.Equal(t, tt.expectedMsg, response.Message)
+			golden.Assert(t, req, rec.Result())
 		})
 	}
 }
@@ -86,10 +80,77 @@ func TestHelloHandler_Hello_RejectsLongName(t *testing.T) {
 
 	// Act
 	err := handler.Hello(c)
+	c.Error(err)
 
 	// Assert
-	require.Error(t, err)
-	httpErr, ok := err.(*echo.HTTPError)
-	require.True(t, ok)
+	var httpErr *echo.HTTPError
+	require.True(t, errors.As(err, &httpErr))
 	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	golden.Assert(t, req, rec.Result())
+}
+
+func TestHelloHandler_Hello_ValidatesName(t *testing.T) {
+	tests := []struct {
+		name           string
+		queryName      string
+		expectedStatus int
+	}{
+		{name: "100 multibyte characters", queryName: strings.Repeat("世", 100), expectedStatus: http.StatusOK},
+		{name: "101 characters", queryName: strings.Repeat("世", 101), expectedStatus: http.StatusBadRequest},
+		{name: "invalid UTF-8", queryName: "Al\xffce", expectedStatus: http.StatusBadRequest},
+		{name: "truncated multibyte character", queryName: "Al\xe4\xb8", expectedStatus: http.StatusBadRequest},
+		{name: "250 bytes in 100 characters", queryName: strings.Repeat("\u00e9\u4e16", 50), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/hello?name="+url.QueryEscape(tt.queryName), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			err := NewHelloHandler().Hello(c)
+			c.Error(err)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+// FuzzHelloHandler_Hello checks that any name is either greeted verbatim
+// or rejected with 400
+func FuzzHelloHandler_Hello(f *testing.F) {
+	f.Add("Alice")
+	f.Add(" 世界\t")
+	e := echo.New()
+	handler := NewHelloHandler()
+
+	f.Fuzz(func(t *testing.T, name string) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/hello?"+url.Values{"name": {name}}.Encode(), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		// Act
+		err := handler.Hello(c)
+
+		// Assert
+		trimmed := strings.TrimSpace(name)
+		if !utf8.ValidString(trimmed) || utf8.RuneCountInString(trimmed) > maxNameLength {
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+			return
+		}
+		require.NoError(t, err)
+		if trimmed == "" {
+			trimmed = "World"
+		}
+		var res HelloResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, "Hello, "+trimmed+"!", res.Message)
+	})
 }
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/images"
+)
+
+// ImagesHandler serves the derived renditions of the caller's images
+type ImagesHandler struct {
+	pipeline *images.Pipeline
+	logger   *zap.Logger
+}
+
+// NewImagesHandler creates a new images handler
+func NewImagesHandler(pipeline *images.Pipeline, logger *zap.Logger) *ImagesHandler {
+	return &ImagesHandler{pipeline: pipeline, logger: logger}
+}
+
+// Thumbnail returns a signed download URL for a thumbnail of one of the
+// caller's images
+func (h *ImagesHandler) Thumbnail(c echo.Context) error {
+	size, err := strconv.Atoi(c.Param("size"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "size must be a number")
+	}
+
+	signed, err := h.pipeline.Thumbnail(c.Request().Context(), ownerID(c), c.Param("id"), size)
+	if errors.Is(err, images.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "thumbnail not found")
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, signed)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/images"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+func TestImagesHandler_Thumbnail(t *testing.T) {
+	// Arrange
+	storage, err := files.NewLocalStorage(t.TempDir(), "http://files.test", []byte("test-key"), files.StorageOptions{})
+	require.NoError(t, err)
+	service := files.NewService(storage, files.NewMemoryStore(), files.Options{
+		Limits:    files.Limits{MaxSize: 1024, AllowedTypes: []string{"image/*"}},
+		URLExpiry: time.Minute,
+	}, zap.NewNop())
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 1})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+	pipeline := images.NewPipeline(service, storage, images.NewMemoryStore(), jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry, images.Options{
+		Sizes: []int{128}, MaxPixels: 1_000_000, Quality: 85, URLExpiry: time.Minute,
+	}, zap.NewNop())
+	upload, err := service.CreateUpload(context.Background(), "alice", files.UploadRequest{Name: "a.png", ContentType: "image/png", Size: 3})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.GET("/api/v1/files/:id/thumbnails/:size", NewImagesHandler(pipeline, zap.NewNop()).Thumbnail,
+		auth.Middleware(auth.StaticVerifier{"alice-token": {Subject: "alice"}}))
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "size is not a number", target: "/api/v1/files/" + upload.File.ID + "/thumbnails/large", want: http.StatusBadRequest},
+		{name: "unknown file", target: "/api/v1/files/missing/thumbnails/128", want: http.StatusNotFound},
+		{name: "file not processed yet", target: "/api/v1/files/" + upload.File.ID + "/thumbnails/128", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer alice-token")
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
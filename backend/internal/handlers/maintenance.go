@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/maintenance"
+	"github.com/your-org/your-app/internal/server"
+)
+
+// MaintenanceResponse is the effective maintenance mode and the admin override
+type MaintenanceResponse struct {
+	Status   maintenance.Status `json:"status"`
+	Override maintenance.State  `json:"override"`
+}
+
+// UpdateMaintenanceRequest sets the admin override
+type UpdateMaintenanceRequest struct {
+	Mode    maintenance.Mode `json:"mode"`
+	Message string           `json:"message"`
+	Until   *time.Time       `json:"until"`
+}
+
+// MaintenanceHandler lets admins toggle maintenance mode at runtime
+type MaintenanceHandler struct {
+	controller *maintenance.Controller
+	logger     *zap.Logger
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(controller *maintenance.Controller, logger *zap.Logger) *MaintenanceHandler {
+	return &MaintenanceHandler{controller: controller, logger: logger}
+}
+
+// Get returns the current maintenance mode
+func (h *MaintenanceHandler) Get(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.response())
+}
+
+// Update replaces the admin override. Modes set by config or the flag stay
+// in force; the response shows the resulting effective mode. With If-Match,
+// the update only applies if the state is still the one Get returned; the
+// check runs inside the store update, so of two racing requests with the
+// same ETag one gets 412.
+func (h *MaintenanceHandler) Update(c echo.Context) error {
+	var req UpdateMaintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Message) > 500 {
+		return echo.NewHTTPError(http.StatusBadRequest, "message too long")
+	}
+
+	var before maintenance.State
+	state, err := h.controller.Update(c.Request().Context(), maintenance.State{
+		Mode:      req.Mode,
+		Message:   req.Message,
+		Until:     req.Until,
+		UpdatedBy: ownerID(c),
+	}, func(current maintenance.State) error {
+		before = current
+		tag, err := server.ETagOf(MaintenanceResponse{Status: h.controller.StatusWith(current), Override: current})
+		if err != nil {
+			return err
+		}
+		return server.CheckIfMatch(c, tag)
+	})
+	if err != nil {
+		return maintenanceError(err)
+	}
+	audit.SetChanges(c, before, state)
+
+	h.logger.Info("maintenance override set",
+		zap.String("mode", string(state.Mode)),
+		zap.String("updated_by", state.UpdatedBy),
+	)
+	return c.JSON(http.StatusOK, MaintenanceResponse{
+		Status:   h.controller.Status(),
+		Override: state,
+	})
+}
+
+func (h *MaintenanceHandler) response() MaintenanceResponse {
+	return MaintenanceResponse{
+		Status:   h.controller.Status(),
+		Override: h.controller.Override(),
+	}
+}
+
+func maintenanceError(err error) error {
+	switch {
+	case errors.Is(err, maintenance.ErrInvalidMode), errors.Is(err, maintenance.ErrInvalidUntil):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/maintenance"
+	"github.com/your-org/your-app/internal/server"
+)
+
+func TestMaintenanceHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		expectedStatus int
+		expectedMode   maintenance.Mode
+	}{
+		{
+			name:           "admin enables read-only mode",
+			token:          "admin-token",
+			body:           `{"mode":"read_only","message":"Migrating"}`,
+			expectedStatus: http.StatusOK,
+			expectedMode:   maintenance.ModeReadOnly,
+		},
+		{
+			name:           "unknown mode",
+			token:          "admin-token",
+			body:           `{"mode":"later"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMode:   maintenance.ModeOff,
+		},
+		{
+			name:           "end in the past",
+			token:          "admin-token",
+			body:           `{"mode":"full","until":"2001-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedMode:   maintenance.ModeOff,
+		},
+		{
+			name:           "regular user",
+			token:          "user-token",
+			body:           `{"mode":"full"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedMode:   maintenance.ModeOff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			controller := maintenance.NewController(maintenance.NewMemoryStore(), maintenance.Options{}, zap.NewNop())
+			handler := NewMaintenanceHandler(controller, zap.NewNop())
+			verifier := auth.StaticVerifier{
+				"admin-token": {Subject: "admin-1", Claims: map[string]any{"role": "admin"}},
+				"user-token":  {Subject: "user-1"},
+			}
+
+			e := echo.New()
+			e.PUT("/api/v1/admin/maintenance", handler.Update, auth.Middleware(verifier), auth.RequireRole("admin"))
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/maintenance", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedMode, controller.Status().Mode)
+			if tt.expectedStatus == http.StatusOK {
+				var response MaintenanceResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedMode, response.Status.Mode)
+				assert.Equal(t, maintenance.SourceAdmin, response.Status.Source)
+				assert.Equal(t, "admin-1", response.Override.UpdatedBy)
+			}
+		})
+	}
+}
+
+func TestMaintenanceHandler_Update_ConcurrentIfMatch(t *testing.T) {
+	// Arrange
+	controller := maintenance.NewController(maintenance.NewMemoryStore(), maintenance.Options{}, zap.NewNop())
+	handler := NewMaintenanceHandler(controller, zap.NewNop())
+	verifier := auth.StaticVerifier{
+		"admin-token": {Subject: "admin-1", Claims: map[string]any{"role": "admin"}},
+	}
+
+	e := echo.New()
+	admin := e.Group("/api/v1/admin", auth.Middleware(verifier), auth.RequireRole("admin"))
+	admin.GET("/maintenance", handler.Get, server.ETags())
+	admin.PUT("/maintenance", handler.Update)
+
+	get := httptest.NewRequest(http.MethodGet, "/api/v1/admin/maintenance", nil)
+	get.Header.Set(echo.HeaderAuthorization, "Bearer admin-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, get)
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get(server.HeaderETag)
+	require.NotEmpty(t, etag)
+
+	const requests = 8
+	codes := make([]int, requests)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Act
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := `{"mode":"read_only","message":"writer ` + strconv.Itoa(i) + `"}`
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/maintenance", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer admin-token")
+			req.Header.Set(server.HeaderIfMatch, etag)
+			rec := httptest.NewRecorder()
+			<-start
+			e.ServeHTTP(rec, req)
+			codes[i] = rec.Code
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// Assert
+	won := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			won++
+			continue
+		}
+		assert.Equal(t, http.StatusPreconditionFailed, code)
+	}
+	assert.Equal(t, 1, won)
+	assert.Equal(t, maintenance.ModeReadOnly, controller.Status().Mode)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// OrgsResponse is the caller's organizations
+type OrgsResponse struct {
+	Orgs []orgs.Membership `json:"orgs"`
+}
+
+// MembersResponse is an organization's members
+type MembersResponse struct {
+	Members []orgs.Member `json:"members"`
+}
+
+// InvitationsResponse is an organization's open invitations
+type InvitationsResponse struct {
+	Invitations []orgs.Invitation `json:"invitations"`
+}
+
+// CreateOrgRequest creates an organization
+type CreateOrgRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateMemberRequest changes a member's role
+type UpdateMemberRequest struct {
+	Role orgs.Role `json:"role"`
+}
+
+// AcceptInvitationRequest joins an organization
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// OrgsHandler manages organizations, their members and invitations. Routes
+// below /orgs/{orgId} run behind orgs.Middleware.
+type OrgsHandler struct {
+	service *orgs.Service
+}
+
+// NewOrgsHandler creates a new organizations handler
+func NewOrgsHandler(service *orgs.Service) *OrgsHandler {
+	return &OrgsHandler{service: service}
+}
+
+// Create creates an organization owned by the caller
+func (h *OrgsHandler) Create(c echo.Context) error {
+	var req CreateOrgRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	p := auth.PrincipalFrom(c)
+	org, err := h.service.CreateOrg(c.Request().Context(), p.Subject, p.Email, req.Name)
+	if err != nil {
+		return orgsError(err)
+	}
+	audit.SetTarget(c, org.ID)
+	return c.JSON(http.StatusCreated, org)
+}
+
+// List returns the organizations the caller belongs to
+func (h *OrgsHandler) List(c echo.Context) error {
+	memberships, err := h.service.ListOrgs(c.Request().Context(), ownerID(c))
+	if err != nil {
+		return orgsError(err)
+	}
+	return c.JSON(http.StatusOK, OrgsResponse{Orgs: memberships})
+}
+
+// Get returns the organization with the caller's role
+func (h *OrgsHandler) Get(c echo.Context) error {
+	org, err := h.service.GetOrg(c.Request().Context(), tenant(c))
+	if err != nil {
+		return orgsError(err)
+	}
+	return c.JSON(http.StatusOK, org)
+}
+
+// Members returns the organization's members
+func (h *OrgsHandler) Members(c echo.Context) error {
+	members, err := h.service.ListMembers(c.Request().Context(), tenant(c))
+	if err != nil {
+		return orgsError(err)
+	}
+	if members == nil {
+		members = []orgs.Member{}
+	}
+	return c.JSON(http.StatusOK, MembersResponse{Members: members})
+}
+
+// UpdateMember changes a member's role
+func (h *OrgsHandler) UpdateMember(c echo.Context) error {
+	var req UpdateMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	m, err := h.service.UpdateMember(c.Request().Context(), tenant(c), c.Param("userId"), req.Role)
+	if err != nil {
+		return orgsError(err)
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// RemoveMember removes a member, or lets the caller leave
+func (h *OrgsHandler) RemoveMember(c echo.Context) error {
+	if err := h.service.RemoveMember(c.Request().Context(), tenant(c), c.Param("userId")); err != nil {
+		return orgsError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateInvitation invites someone. The token is only returned here.
+func (h *OrgsHandler) CreateInvitation(c echo.Context) error {
+	var req orgs.InvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	created, err := h.service.CreateInvitation(c.Request().Context(), tenant(c), req)
+	if err != nil {
+		return orgsError(err)
+	}
+	audit.SetTarget(c, created.ID)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// Invitations returns the organization's open invitations
+func (h *OrgsHandler) Invitations(c echo.Context) error {
+	invitations, err := h.service.ListInvitations(c.Request().Context(), tenant(c))
+	if err != nil {
+		return orgsError(err)
+	}
+	if invitations == nil {
+		invitations = []orgs.Invitation{}
+	}
+	return c.JSON(http.StatusOK, InvitationsResponse{Invitations: invitations})
+}
+
+// RevokeInvitation deletes an invitation
+func (h *OrgsHandler) RevokeInvitation(c echo.Context) error {
+	if err := h.service.RevokeInvitation(c.Request().Context(), tenant(c), c.Param("id")); err != nil {
+		return orgsError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AcceptInvitation adds the caller to the organization of the path. It runs
+// without orgs.Middleware, as the caller is not a member yet.
+func (h *OrgsHandler) AcceptInvitation(c echo.Context) error {
+	var req AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token is required")
+	}
+
+	p := auth.PrincipalFrom(c)
+	m, err := h.service.AcceptInvitation(c.Request().Context(), c.Param("orgId"), p.Subject, p.Email, req.Token)
+	if err != nil {
+		return orgsError(err)
+	}
+	return c.JSON(http.StatusCreated, m)
+}
+
+// tenant returns the tenant resolved by orgs.Middleware
+func tenant(c echo.Context) orgs.Tenant {
+	t, _ := orgs.TenantFrom(c)
+	return t
+}
+
+func orgsError(err error) error {
+	switch {
+	case errors.Is(err, orgs.ErrInvalidName), errors.Is(err, orgs.ErrInvalidRole):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, orgs.ErrForbidden), errors.Is(err, orgs.ErrEmailMismatch):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, orgs.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	case errors.Is(err, orgs.ErrLastOwner), errors.Is(err, orgs.ErrAlreadyMember):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, orgs.ErrInvitationExpired):
+		return echo.NewHTTPError(http.StatusGone, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+func TestOrgsHandler(t *testing.T) {
+	// Arrange
+	service := orgs.NewService(orgs.NewMemoryStore(), orgs.Options{InvitationTTL: time.Hour}, zap.NewNop())
+	h := NewOrgsHandler(service)
+	acme, err := service.CreateOrg(context.Background(), "alice", "alice@example.com", "Acme")
+	require.NoError(t, err)
+
+	e := echo.New()
+	userOrgs := e.Group("/api/v1/orgs", auth.Middleware(auth.StaticVerifier{
+		"alice-token": {Subject: "alice", Email: "alice@example.com"},
+		"bob-token":   {Subject: "bob", Email: "bob@example.com"},
+	}))
+	userOrgs.POST("", h.Create)
+	userOrgs.GET("", h.List)
+	userOrgs.POST("/:orgId/invitations/accept", h.AcceptInvitation)
+	org := userOrgs.Group("/:orgId", orgs.Middleware(service, "orgId"))
+	org.GET("", h.Get)
+	org.GET("/members", h.Members)
+	org.PUT("/members/:userId", h.UpdateMember)
+	org.DELETE("/members/:userId", h.RemoveMember)
+	org.POST("/invitations", h.CreateInvitation)
+	org.GET("/invitations", h.Invitations)
+	org.DELETE("/invitations/:id", h.RevokeInvitation)
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	base := "/api/v1/orgs/" + acme.ID
+
+	// Act: bob cannot see acme before accepting alice's invitation
+	before := do(http.MethodGet, base, "bob-token", "")
+	invited := do(http.MethodPost, base+"/invitations", "alice-token", `{"email":"bob@example.com","role":"member"}`)
+	var created orgs.CreatedInvitation
+	require.NoError(t, json.Unmarshal(invited.Body.Bytes(), &created))
+	accepted := do(http.MethodPost, base+"/invitations/accept", "bob-token", `{"token":"`+created.Token+`"}`)
+	after := do(http.MethodGet, base+"/members", "bob-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, before.Code)
+	assert.Equal(t, http.StatusCreated, invited.Code)
+	assert.Equal(t, http.StatusCreated, accepted.Code, accepted.Body.String())
+	assert.Equal(t, http.StatusOK, after.Code)
+	assert.Contains(t, after.Body.String(), `"userId":"bob"`)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		body   string
+		want   int
+	}{
+		{name: "create organization", method: http.MethodPost, target: "/api/v1/orgs", token: "bob-token", body: `{"name":"Globex"}`, want: http.StatusCreated},
+		{name: "create organization without name", method: http.MethodPost, target: "/api/v1/orgs", token: "bob-token", body: `{}`, want: http.StatusBadRequest},
+		{name: "list organizations", method: http.MethodGet, target: "/api/v1/orgs", token: "bob-token", want: http.StatusOK},
+		{name: "member lists invitations", method: http.MethodGet, target: base + "/invitations", token: "bob-token", want: http.StatusForbidden},
+		{name: "owner lists invitations", method: http.MethodGet, target: base + "/invitations", token: "alice-token", want: http.StatusOK},
+		{name: "member promotes self", method: http.MethodPut, target: base + "/members/bob", token: "bob-token", body: `{"role":"admin"}`, want: http.StatusForbidden},
+		{name: "last owner leaves", method: http.MethodDelete, target: base + "/members/alice", token: "alice-token", want: http.StatusConflict},
+		{name: "accept with unknown token", method: http.MethodPost, target: base + "/invitations/accept", token: "bob-token", body: `{"token":"nope"}`, want: http.StatusNotFound},
+		{name: "revoke unknown invitation", method: http.MethodDelete, target: base + "/invitations/missing", token: "alice-token", want: http.StatusNotFound},
+		{name: "member leaves", method: http.MethodDelete, target: base + "/members/bob", token: "bob-token", want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec := do(tt.method, tt.target, tt.token, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
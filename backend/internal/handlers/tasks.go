@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+// maxTaskBody matches the Cloud Tasks payload limit
+const maxTaskBody = 1 << 20
+
+// TaskHandler receives jobs delivered by Cloud Tasks
+type TaskHandler struct {
+	registry    *jobs.Registry
+	deadLetters jobs.Store
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewTaskHandler creates a new task handler recording jobs that give up in
+// deadLetters
+func NewTaskHandler(registry *jobs.Registry, deadLetters jobs.Store, clk clock.Clock, logger *zap.Logger) *TaskHandler {
+	return &TaskHandler{registry: registry, deadLetters: deadLetters, logger: logger, now: clk.Now}
+}
+
+// Run executes one attempt of a job. Cloud Tasks retries any non-2xx
+// response, so permanent failures and exhausted jobs are acknowledged with
+// 204 after logging and recording a dead letter instead of being reported
+// as errors. If the dead letter cannot be recorded, the attempt is reported
+// as failed, so Cloud Tasks tries once more while it still can.
+func (h *TaskHandler) Run(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxTaskBody))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "unreadable task body")
+	}
+
+	retries, _ := strconv.Atoi(c.Request().Header.Get(jobs.HeaderRetryCount))
+	maxAttempts, _ := strconv.Atoi(c.Request().Header.Get(jobs.HeaderMaxAttempts))
+	delivery := jobs.Delivery{
+		Type:        c.Param("type"),
+		Payload:     body,
+		Attempt:     retries + 1,
+		MaxAttempts: maxAttempts,
+	}
+
+	err = h.registry.Dispatch(c.Request().Context(), delivery)
+	if err == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	log := h.logger.With(
+		zap.String("job_type", delivery.Type),
+		zap.Int("attempt", delivery.Attempt),
+		zap.Error(err),
+	)
+
+	switch {
+	case errors.Is(err, jobs.ErrUnknownType):
+		// Possibly a task for a newer revision during a rollout; let Cloud Tasks retry
+		log.Warn("no handler for job type")
+		return echo.NewHTTPError(http.StatusNotFound, "unknown job type")
+	case jobs.IsPermanent(err) || delivery.Exhausted():
+		log.Error("job failed, giving up")
+		letter := delivery.DeadLetter("", err, h.now())
+		if recordErr := h.deadLetters.AddDeadLetter(c.Request().Context(), letter); recordErr != nil {
+			log.Error("recording dead letter failed", zap.NamedError("record_error", recordErr))
+			return echo.NewHTTPError(http.StatusInternalServerError, "job failed")
+		}
+		return c.NoContent(http.StatusNoContent)
+	default:
+		log.Warn("job failed, retrying")
+		return echo.NewHTTPError(http.StatusInternalServerError, "job failed")
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+type echoJob struct {
+	Fail string `json:"fail"`
+}
+
+func (echoJob) JobType() string { return "echo" }
+
+func TestTaskHandler_Run(t *testing.T) {
+	tests := []struct {
+		name           string
+		jobType        string
+		body           string
+		retryCount     string
+		maxAttempts    string
+		expectedStatus int
+		expectedDead   bool
+	}{
+		{
+			name:           "acknowledges successful job",
+			jobType:        "echo",
+			body:           `{}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "asks for retry on transient failure",
+			jobType:        "echo",
+			body:           `{"fail":"transient"}`,
+			retryCount:     "0",
+			maxAttempts:    "3",
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "gives up on last attempt",
+			jobType:        "echo",
+			body:           `{"fail":"transient"}`,
+			retryCount:     "2",
+			maxAttempts:    "3",
+			expectedStatus: http.StatusNoContent,
+			expectedDead:   true,
+		},
+		{
+			name:           "gives up on permanent failure",
+			jobType:        "echo",
+			body:           `{"fail":"permanent"}`,
+			maxAttempts:    "3",
+			expectedStatus: http.StatusNoContent,
+			expectedDead:   true,
+		},
+		{
+			name:           "gives up on undecodable payload",
+			jobType:        "echo",
+			body:           `not json`,
+			expectedStatus: http.StatusNoContent,
+			expectedDead:   true,
+		},
+		{
+			name:           "rejects unknown job type",
+			jobType:        "missing",
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			registry := jobs.NewRegistry()
+			jobs.Handle(registry, 0, func(_ context.Context, job echoJob) error {
+				switch job.Fail {
+				case "transient":
+					return errors.New("try again")
+				case "permanent":
+					return jobs.Permanent(errors.New("never works"))
+				}
+				return nil
+			})
+
+			deadLetters := jobs.NewMemoryStore()
+			e := echo.New()
+			e.POST("/internal/tasks/:type", NewTaskHandler(registry, deadLetters, clock.System{}, zap.NewNop()).Run)
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/tasks/"+tt.jobType, strings.NewReader(tt.body))
+			if tt.retryCount != "" {
+				req.Header.Set(jobs.HeaderRetryCount, tt.retryCount)
+			}
+			if tt.maxAttempts != "" {
+				req.Header.Set(jobs.HeaderMaxAttempts, tt.maxAttempts)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			dead, err := deadLetters.ListDeadLetters(context.Background(), 0)
+			require.NoError(t, err)
+			if tt.expectedDead {
+				require.Len(t, dead, 1)
+				assert.Equal(t, tt.jobType, dead[0].Type)
+				assert.Equal(t, tt.body, dead[0].Payload)
+			} else {
+				assert.Empty(t, dead)
+			}
+		})
+	}
+}
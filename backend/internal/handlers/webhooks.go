@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/audit"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+// maxDeliveriesLimit caps the number of deliveries listed at once
+const maxDeliveriesLimit = 100
+
+// WebhookEventsResponse is the catalogue of subscribable events
+type WebhookEventsResponse struct {
+	Events []webhooks.EventType `json:"events"`
+}
+
+// WebhookEndpointsResponse is the caller's registered endpoints
+type WebhookEndpointsResponse struct {
+	Endpoints []webhooks.Endpoint `json:"endpoints"`
+}
+
+// WebhookDeliveriesResponse is a page of an endpoint's delivery log
+type WebhookDeliveriesResponse struct {
+	Deliveries []webhooks.Delivery `json:"deliveries"`
+}
+
+// WebhooksHandler manages the caller's webhook endpoints
+type WebhooksHandler struct {
+	service *webhooks.Service
+	// owner returns the owner ID of the endpoints a request manages
+	owner func(c echo.Context) string
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(service *webhooks.Service) *WebhooksHandler {
+	return &WebhooksHandler{service: service, owner: ownerID}
+}
+
+// OrgWebhooksHandler manages an organization's webhook endpoints. Its
+// routes run behind orgs.Middleware and orgs.RequireRole.
+type OrgWebhooksHandler struct {
+	WebhooksHandler
+}
+
+// NewOrgWebhooksHandler creates a new organization webhooks handler
+func NewOrgWebhooksHandler(service *webhooks.Service) *OrgWebhooksHandler {
+	return &OrgWebhooksHandler{WebhooksHandler{service: service, owner: orgOwnerID}}
+}
+
+// orgOwnerID returns the webhook owner ID of the tenant resolved by orgs.Middleware
+func orgOwnerID(c echo.Context) string {
+	t, _ := orgs.TenantFrom(c)
+	return webhooks.OrgOwner(t.OrgID())
+}
+
+// Events returns the catalogue of events the caller's endpoints can subscribe to
+func (h *WebhooksHandler) Events(c echo.Context) error {
+	return c.JSON(http.StatusOK, WebhookEventsResponse{Events: webhooks.CatalogueOf(h.owner(c))})
+}
+
+// Create registers an endpoint. The signing secret is only returned here.
+func (h *WebhooksHandler) Create(c echo.Context) error {
+	var req webhooks.EndpointRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Description) > 200 {
+		return echo.NewHTTPError(http.StatusBadRequest, "description too long")
+	}
+
+	created, err := h.service.CreateEndpoint(c.Request().Context(), h.owner(c), req)
+	if err != nil {
+		return webhooksError(err)
+	}
+	audit.SetTarget(c, created.ID)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// List returns the caller's endpoints
+func (h *WebhooksHandler) List(c echo.Context) error {
+	endpoints, err := h.service.ListEndpoints(c.Request().Context(), h.owner(c))
+	if err != nil {
+		return webhooksError(err)
+	}
+	if endpoints == nil {
+		endpoints = []webhooks.Endpoint{}
+	}
+	return c.JSON(http.StatusOK, WebhookEndpointsResponse{Endpoints: endpoints})
+}
+
+// Get returns one of the caller's endpoints
+func (h *WebhooksHandler) Get(c echo.Context) error {
+	endpoint, err := h.service.GetEndpoint(c.Request().Context(), h.owner(c), c.Param("id"))
+	if err != nil {
+		return webhooksError(err)
+	}
+	return c.JSON(http.StatusOK, endpoint)
+}
+
+// Delete removes one of the caller's endpoints and its delivery log
+func (h *WebhooksHandler) Delete(c echo.Context) error {
+	if err := h.service.DeleteEndpoint(c.Request().Context(), h.owner(c), c.Param("id")); err != nil {
+		return webhooksError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Ping sends a test event to an endpoint
+func (h *WebhooksHandler) Ping(c echo.Context) error {
+	delivery, err := h.service.Ping(c.Request().Context(), h.owner(c), c.Param("id"))
+	if err != nil {
+		return webhooksError(err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// Deliveries returns an endpoint's delivery log, newest first
+func (h *WebhooksHandler) Deliveries(c echo.Context) error {
+	limit := 20
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		limit = n
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request().Context(), h.owner(c), c.Param("id"), limit)
+	if err != nil {
+		return webhooksError(err)
+	}
+	if deliveries == nil {
+		deliveries = []webhooks.Delivery{}
+	}
+	return c.JSON(http.StatusOK, WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// Replay sends the event of a past delivery again
+func (h *WebhooksHandler) Replay(c echo.Context) error {
+	delivery, err := h.service.Replay(c.Request().Context(), h.owner(c), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		return webhooksError(err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+func webhooksError(err error) error {
+	switch {
+	case errors.Is(err, webhooks.ErrNoEvents), errors.Is(err, webhooks.ErrUnknownEvent),
+		errors.Is(err, webhooks.ErrInvalidURL), errors.Is(err, webhooks.ErrPrivateAddress):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, webhooks.ErrTooManyEndpoints):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, webhooks.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+	default:
+		return err
+	}
+}
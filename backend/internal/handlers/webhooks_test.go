@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/webhooks"
+)
+
+type webhooksTestEnv struct {
+	e       *echo.Echo
+	backend *jobs.LocalBackend
+	orgs    *orgs.Service
+	orgID   string
+}
+
+func setupWebhooksServer(t *testing.T) *webhooksTestEnv {
+	t.Helper()
+
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 1})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	service := webhooks.NewService(webhooks.NewMemoryStore(), jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry,
+		&webhooks.Guard{AllowPrivate: true}, webhooks.Options{MaxAttempts: 1, Timeout: time.Second}, zap.NewNop())
+	handler := NewWebhooksHandler(service)
+	orgHandler := NewOrgWebhooksHandler(service)
+
+	// alice owns acme and bob is a member; org events reach acme's endpoints
+	// as they do in internal/app
+	orgStore := orgs.NewMemoryStore()
+	orgsService := orgs.NewService(orgStore, orgs.Options{InvitationTTL: time.Hour}, zap.NewNop())
+	orgsService.OnEvent(func(ctx context.Context, event, orgID string, data any) {
+		require.NoError(t, service.Publish(ctx, webhooks.OrgOwner(orgID), event, data))
+	})
+	acme, err := orgsService.CreateOrg(context.Background(), "alice", "alice@example.com", "Acme")
+	require.NoError(t, err)
+	require.NoError(t, orgStore.SaveMember(context.Background(), orgs.Member{OrgID: acme.ID, UserID: "bob", Role: orgs.RoleMember}))
+
+	verifier := auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+		"bob-token":   {Subject: "bob"},
+		"carol-token": {Subject: "carol"},
+	}
+
+	e := echo.New()
+	api := e.Group("/api/v1/webhooks", auth.Middleware(verifier))
+	api.GET("/events", handler.Events)
+	api.POST("", handler.Create)
+	api.GET("", handler.List)
+	api.GET("/:id", handler.Get)
+	api.DELETE("/:id", handler.Delete)
+	api.POST("/:id/ping", handler.Ping)
+	api.GET("/:id/deliveries", handler.Deliveries)
+	api.POST("/:id/deliveries/:deliveryId/replay", handler.Replay)
+
+	org := e.Group("/api/v1/orgs/:orgId/webhooks", auth.Middleware(verifier), orgs.Middleware(orgsService, "orgId"), orgs.RequireRole(orgs.RoleAdmin))
+	org.GET("/events", orgHandler.Events)
+	org.POST("", orgHandler.Create)
+	org.GET("", orgHandler.List)
+	org.GET("/:id", orgHandler.Get)
+	org.DELETE("/:id", orgHandler.Delete)
+	org.POST("/:id/ping", orgHandler.Ping)
+	org.GET("/:id/deliveries", orgHandler.Deliveries)
+	org.POST("/:id/deliveries/:deliveryId/replay", orgHandler.Replay)
+
+	return &webhooksTestEnv{e: e, backend: backend, orgs: orgsService, orgID: acme.ID}
+}
+
+func (env *webhooksTestEnv) do(method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	env.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (env *webhooksTestEnv) register(t *testing.T, url string) webhooks.CreatedEndpoint {
+	t.Helper()
+	rec := env.do(http.MethodPost, "/api/v1/webhooks", "alice-token",
+		`{"url":"`+url+`","events":["file.ready"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created webhooks.CreatedEndpoint
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	return created
+}
+
+func TestWebhooksHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "registers endpoint",
+			token:          "alice-token",
+			body:           `{"url":"http://127.0.0.1:9/hook","events":["file.ready"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "requires authentication",
+			body:           `{"url":"http://127.0.0.1:9/hook","events":["file.ready"]}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects unknown event",
+			token:          "alice-token",
+			body:           `{"url":"http://127.0.0.1:9/hook","events":["user.created"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects missing events",
+			token:          "alice-token",
+			body:           `{"url":"http://127.0.0.1:9/hook"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects invalid url",
+			token:          "alice-token",
+			body:           `{"url":"gopher://127.0.0.1/hook","events":["file.ready"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects long description",
+			token:          "alice-token",
+			body:           `{"url":"http://127.0.0.1:9/hook","events":["file.ready"],"description":"` + strings.Repeat("x", 201) + `"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupWebhooksServer(t)
+
+			// Act
+			rec := env.do(http.MethodPost, "/api/v1/webhooks", tt.token, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestWebhooksHandler_SecretOnlyOnCreate(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	created := env.register(t, "http://127.0.0.1:9/hook")
+
+	// Act
+	get := env.do(http.MethodGet, "/api/v1/webhooks/"+created.ID, "alice-token", "")
+	list := env.do(http.MethodGet, "/api/v1/webhooks", "alice-token", "")
+
+	// Assert
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.Equal(t, http.StatusOK, get.Code)
+	assert.NotContains(t, get.Body.String(), "whsec_")
+	assert.Equal(t, http.StatusOK, list.Code)
+	assert.NotContains(t, list.Body.String(), "whsec_")
+}
+
+func TestWebhooksHandler_ScopedToCaller(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	created := env.register(t, "http://127.0.0.1:9/hook")
+	path := "/api/v1/webhooks/" + created.ID
+
+	// Act
+	get := env.do(http.MethodGet, path, "bob-token", "")
+	ping := env.do(http.MethodPost, path+"/ping", "bob-token", "")
+	deliveries := env.do(http.MethodGet, path+"/deliveries", "bob-token", "")
+	del := env.do(http.MethodDelete, path, "bob-token", "")
+	list := env.do(http.MethodGet, "/api/v1/webhooks", "bob-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, get.Code)
+	assert.Equal(t, http.StatusNotFound, ping.Code)
+	assert.Equal(t, http.StatusNotFound, deliveries.Code)
+	assert.Equal(t, http.StatusNotFound, del.Code)
+	assert.JSONEq(t, `{"endpoints":[]}`, list.Body.String())
+}
+
+func TestWebhooksHandler_PingAndReplay(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	var received int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	created := env.register(t, receiver.URL)
+	path := "/api/v1/webhooks/" + created.ID
+
+	// Act
+	ping := env.do(http.MethodPost, path+"/ping", "alice-token", "")
+	env.backend.Wait()
+	log := env.do(http.MethodGet, path+"/deliveries?limit=10", "alice-token", "")
+
+	var response WebhookDeliveriesResponse
+	require.NoError(t, json.Unmarshal(log.Body.Bytes(), &response))
+	require.Len(t, response.Deliveries, 1)
+	dead := response.Deliveries[0]
+
+	replay := env.do(http.MethodPost, path+"/deliveries/"+dead.ID+"/replay", "alice-token", "")
+	env.backend.Wait()
+	missing := env.do(http.MethodPost, path+"/deliveries/wd_missing/replay", "alice-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, ping.Code)
+	assert.Equal(t, http.StatusOK, log.Code)
+	assert.Equal(t, webhooks.DeliveryDead, dead.Status)
+	assert.Equal(t, http.StatusInternalServerError, dead.ResponseStatus)
+	assert.Equal(t, http.StatusAccepted, replay.Code)
+	assert.Contains(t, replay.Body.String(), `"replayOf":"`+dead.ID+`"`)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, 2, received)
+}
+
+func TestWebhooksHandler_Delete(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	created := env.register(t, "http://127.0.0.1:9/hook")
+	path := "/api/v1/webhooks/" + created.ID
+
+	// Act
+	del := env.do(http.MethodDelete, path, "alice-token", "")
+	get := env.do(http.MethodGet, path, "alice-token", "")
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, del.Code)
+	assert.Equal(t, http.StatusNotFound, get.Code)
+}
+
+func TestOrgWebhooksHandler_AdminsOnly(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	base := "/api/v1/orgs/" + env.orgID + "/webhooks"
+	body := `{"url":"http://127.0.0.1:9/hook","events":["org.member.joined"]}`
+
+	// Act
+	owner := env.do(http.MethodPost, base, "alice-token", body)
+	member := env.do(http.MethodPost, base, "bob-token", body)
+	memberList := env.do(http.MethodGet, base, "bob-token", "")
+	outsider := env.do(http.MethodGet, base, "carol-token", "")
+	fileEvent := env.do(http.MethodPost, base, "alice-token", `{"url":"http://127.0.0.1:9/hook","events":["file.ready"]}`)
+	events := env.do(http.MethodGet, base+"/events", "alice-token", "")
+	personal := env.do(http.MethodGet, "/api/v1/webhooks", "alice-token", "")
+
+	// Assert
+	require.Equal(t, http.StatusCreated, owner.Code, owner.Body.String())
+	var created webhooks.CreatedEndpoint
+	require.NoError(t, json.Unmarshal(owner.Body.Bytes(), &created))
+	assert.Equal(t, webhooks.OrgOwner(env.orgID), created.OwnerID)
+	assert.Equal(t, http.StatusForbidden, member.Code)
+	assert.Equal(t, http.StatusForbidden, memberList.Code)
+	assert.Equal(t, http.StatusNotFound, outsider.Code)
+	assert.Equal(t, http.StatusBadRequest, fileEvent.Code)
+	assert.Contains(t, events.Body.String(), orgs.EventMemberJoined)
+	assert.NotContains(t, events.Body.String(), files.EventReady)
+	assert.JSONEq(t, `{"endpoints":[]}`, personal.Body.String(), "organization endpoints are not the caller's own")
+}
+
+func TestOrgWebhooksHandler_DeliversOrgEvents(t *testing.T) {
+	// Arrange
+	env := setupWebhooksServer(t)
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(webhooks.HeaderEvent)
+	}))
+	defer receiver.Close()
+	base := "/api/v1/orgs/" + env.orgID + "/webhooks"
+	created := env.do(http.MethodPost, base, "alice-token", `{"url":"`+receiver.URL+`","events":["org.member.updated"]}`)
+	require.Equal(t, http.StatusCreated, created.Code, created.Body.String())
+	alice, err := env.orgs.Resolve(context.Background(), env.orgID, "alice")
+	require.NoError(t, err)
+
+	// Act
+	_, err = env.orgs.UpdateMember(context.Background(), alice, "bob", orgs.RoleAdmin)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, orgs.EventMemberUpdated, <-received)
+}
+
+// TestWebhookCatalogue_MatchesOpenAPI keeps the callbacks declared on the
+// registration operations in sync with the events the service can send.
+// webhook.ping is declared once, on /webhooks, since the generated client
+// needs callback names to be unique.
+func TestWebhookCatalogue_MatchesOpenAPI(t *testing.T) {
+	// Arrange
+	data, err := os.ReadFile("../../api/openapi.yaml")
+	require.NoError(t, err)
+
+	var spec struct {
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+
+	tests := []struct {
+		path      string
+		catalogue []webhooks.EventType
+		ping      bool
+	}{
+		{path: "/webhooks", catalogue: webhooks.Catalogue, ping: true},
+		{path: "/orgs/{orgId}/webhooks", catalogue: webhooks.OrgCatalogue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var operation struct {
+				Callbacks map[string]any `yaml:"callbacks"`
+			}
+			post := spec.Paths[tt.path]["post"]
+			require.NoError(t, post.Decode(&operation))
+
+			// Act
+			var declared []string
+			for name := range operation.Callbacks {
+				declared = append(declared, name)
+			}
+			sort.Strings(declared)
+
+			var expected []string
+			if tt.ping {
+				expected = append(expected, webhooks.EventPing)
+			}
+			for _, event := range tt.catalogue {
+				expected = append(expected, event.Name)
+			}
+			sort.Strings(expected)
+
+			// Assert
+			assert.Equal(t, expected, declared)
+		})
+	}
+}
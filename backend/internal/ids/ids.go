@@ -0,0 +1,33 @@
+// Package ids generates the random part of IDs, secrets and request IDs,
+// so tests can swap it for a predictable sequence
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Generator is the ID generator
+type Generator interface {
+	// NewID returns n random bytes, hex encoded
+	NewID(n int) string
+}
+
+// Random draws IDs from crypto/rand
+type Random struct{}
+
+// NewID returns n bytes of crypto/rand, hex encoded
+func (Random) NewID(n int) string {
+	b := make([]byte, n)
+	// crypto/rand.Read never fails; it crashes the program instead
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// OrRandom returns g, or Random when g is nil, for optional Generator fields
+func OrRandom(g Generator) Generator {
+	if g == nil {
+		return Random{}
+	}
+	return g
+}
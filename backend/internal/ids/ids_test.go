@@ -0,0 +1,21 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandom_NewID(t *testing.T) {
+	// Arrange
+	g := Random{}
+
+	// Act
+	first := g.NewID(10)
+	second := g.NewID(10)
+
+	// Assert
+	assert.Len(t, first, 20)
+	assert.Regexp(t, "^[0-9a-f]+$", first)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,288 @@
+// Package images processes uploaded images.
+//
+// The Pipeline is the files.Processor for image uploads. Once an upload is
+// finalized it runs as a job: it checks the bytes really are the declared
+// image format, rewrites the original without metadata such as EXIF GPS
+// tags, renders thumbnails to users/{userId}/derived/{fileId}/ and records
+// dimensions and thumbnail URLs on the owner's user document. Only then is
+// the file ready; uploads that fail the checks are rejected.
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+)
+
+// ErrNotFound is returned for thumbnails of unknown files, of files that are
+// not processed images, or of sizes that are not configured
+var ErrNotFound = errors.New("images: thumbnail not found")
+
+// maxAttempts is the number of tries at processing an upload before it is
+// rejected
+const maxAttempts = 5
+
+// Options tunes processing
+type Options struct {
+	// Sizes are the bounding boxes of the thumbnails, in pixels
+	Sizes []int
+	// MaxPixels rejects images whose width times height exceeds it, before
+	// they are decoded
+	MaxPixels int
+	// Quality is the JPEG quality of re-encoded originals and thumbnails
+	Quality int
+	// URLExpiry is the lifetime of signed thumbnail URLs
+	URLExpiry time.Duration
+	// Clock defaults to the wall clock
+	Clock clock.Clock
+}
+
+// Image is the processing result recorded for an uploaded image
+type Image struct {
+	FileID      string      `firestore:"-" json:"fileId"`
+	ContentType string      `firestore:"contentType" json:"contentType"`
+	Width       int         `firestore:"width" json:"width"`
+	Height      int         `firestore:"height" json:"height"`
+	Thumbnails  []Thumbnail `firestore:"thumbnails" json:"thumbnails"`
+	ProcessedAt time.Time   `firestore:"processedAt" json:"processedAt"`
+}
+
+// Thumbnail is a derived rendition of an image
+type Thumbnail struct {
+	Size   int    `firestore:"size" json:"size"`
+	Width  int    `firestore:"width" json:"width"`
+	Height int    `firestore:"height" json:"height"`
+	Object string `firestore:"object" json:"-"`
+	// URL is the API path returning a signed URL of the thumbnail
+	URL string `firestore:"url" json:"url"`
+}
+
+// processJob processes one finalized upload
+type processJob struct {
+	OwnerID string `json:"ownerId"`
+	FileID  string `json:"fileId"`
+}
+
+func (processJob) JobType() string { return "images.process" }
+
+// Pipeline processes image uploads and serves their thumbnails
+type Pipeline struct {
+	files   *files.Service
+	storage files.Storage
+	store   Store
+	queue   *jobs.Queue
+	opts    Options
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewPipeline creates a pipeline and registers its job with registry, which
+// must be the registry behind queue. Register it with filesService through
+// SetProcessor and OnEvent.
+func NewPipeline(filesService *files.Service, storage files.Storage, st Store, queue *jobs.Queue, registry *jobs.Registry, opts Options, logger *zap.Logger) *Pipeline {
+	p := &Pipeline{
+		files:   filesService,
+		storage: storage,
+		store:   st,
+		queue:   queue,
+		opts:    opts,
+		logger:  logger,
+		now:     clock.OrSystem(opts.Clock).Now,
+	}
+	jobs.Handle(registry, maxAttempts, p.process)
+	return p
+}
+
+// Accepts implements files.Processor. Every image type is accepted, so
+// formats the pipeline cannot strip are rejected rather than served as is.
+func (p *Pipeline) Accepts(f files.File) bool {
+	return strings.HasPrefix(f.ContentType, "image/")
+}
+
+// Process implements files.Processor
+func (p *Pipeline) Process(ctx context.Context, f files.File) error {
+	return p.queue.Enqueue(ctx, processJob{OwnerID: f.OwnerID, FileID: f.ID}, jobs.Options{Name: "images-" + f.ID})
+}
+
+// OnEvent is a files.Listener that removes the thumbnails and the record of
+// deleted files
+func (p *Pipeline) OnEvent(ctx context.Context, event string, f files.File) {
+	if event != files.EventDeleted || !p.Accepts(f) {
+		return
+	}
+	if err := p.cleanup(ctx, f); err != nil {
+		p.logger.Error("removing thumbnails failed", zap.String("file_id", f.ID), zap.Error(err))
+	}
+}
+
+// Thumbnail returns a signed URL of the owner's thumbnail of file id
+func (p *Pipeline) Thumbnail(ctx context.Context, ownerID, id string, size int) (files.SignedURL, error) {
+	f, err := p.files.Get(ctx, ownerID, id)
+	if errors.Is(err, files.ErrNotFound) {
+		return files.SignedURL{}, ErrNotFound
+	}
+	if err != nil {
+		return files.SignedURL{}, err
+	}
+	if f.Status != files.StatusReady || !p.Accepts(*f) || !slices.Contains(p.opts.Sizes, size) {
+		return files.SignedURL{}, ErrNotFound
+	}
+	return p.storage.SignDownload(ctx, ThumbnailObject(ownerID, id, size, ThumbnailType(f.ContentType)), p.opts.URLExpiry)
+}
+
+// process runs processJob. Invalid images are rejected at once; other
+// failures are retried and reject the upload on the last attempt, so it
+// never stays processing.
+func (p *Pipeline) process(ctx context.Context, job processJob) error {
+	f, err := p.files.Get(ctx, job.OwnerID, job.FileID)
+	if errors.Is(err, files.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Redelivered after completing, or the file was deleted and re-created
+	if f.Status != files.StatusProcessing {
+		return nil
+	}
+
+	size, err := p.run(ctx, *f)
+	switch {
+	case err == nil:
+		return p.files.Complete(ctx, f.OwnerID, f.ID, size)
+	case errors.Is(err, ErrInvalidImage) || errors.Is(err, files.ErrForbidden):
+		return jobs.Permanent(errors.Join(err, p.reject(ctx, *f, err)))
+	case jobs.Attempt(ctx) >= maxAttempts:
+		return errors.Join(err, p.reject(ctx, *f, err))
+	default:
+		return err
+	}
+}
+
+// run processes f and returns the size of the rewritten original
+func (p *Pipeline) run(ctx context.Context, f files.File) (int64, error) {
+	data, err := p.read(ctx, f.Object)
+	if err != nil {
+		return 0, err
+	}
+	out, err := process(data, f.ContentType, p.opts)
+	if err != nil {
+		return 0, err
+	}
+
+	img := Image{
+		FileID:      f.ID,
+		ContentType: f.ContentType,
+		Width:       out.original.width,
+		Height:      out.original.height,
+		ProcessedAt: p.now(),
+	}
+	for _, size := range p.opts.Sizes {
+		thumb := out.thumbnails[size]
+		object := ThumbnailObject(f.OwnerID, f.ID, size, thumb.contentType)
+		if err := p.write(ctx, f.OwnerID, object, thumb); err != nil {
+			return 0, err
+		}
+		img.Thumbnails = append(img.Thumbnails, Thumbnail{
+			Size:   size,
+			Width:  thumb.width,
+			Height: thumb.height,
+			Object: object,
+			URL:    ThumbnailURL(f.ID, size),
+		})
+	}
+
+	// The original goes last: a retry after a failure above must still find
+	// the upload's own bytes
+	if err := p.write(ctx, f.OwnerID, f.Object, out.original); err != nil {
+		return 0, err
+	}
+	if err := p.store.Save(ctx, f.OwnerID, img); err != nil {
+		return 0, err
+	}
+	return int64(len(out.original.data)), nil
+}
+
+func (p *Pipeline) reject(ctx context.Context, f files.File, reason error) error {
+	if err := p.cleanup(ctx, f); err != nil {
+		return err
+	}
+	return p.files.Reject(ctx, f.OwnerID, f.ID, reason)
+}
+
+// cleanup deletes every thumbnail f may have and its record
+func (p *Pipeline) cleanup(ctx context.Context, f files.File) error {
+	for _, size := range p.opts.Sizes {
+		for _, contentType := range []string{TypeJPEG, TypePNG} {
+			if err := p.storage.Delete(ctx, ThumbnailObject(f.OwnerID, f.ID, size, contentType)); err != nil {
+				return err
+			}
+		}
+	}
+	return p.store.Delete(ctx, f.OwnerID, f.ID)
+}
+
+func (p *Pipeline) read(ctx context.Context, object string) ([]byte, error) {
+	r, err := p.storage.Open(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// Uploads are capped by the storage policy; anything larger is not one
+	data, err := io.ReadAll(io.LimitReader(r, files.PolicyMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("images: read %s: %w", object, err)
+	}
+	return data, nil
+}
+
+// write stores r at object, under the same storage policy as client uploads
+func (p *Pipeline) write(ctx context.Context, ownerID, object string, r rendition) error {
+	if err := files.Authorize(files.Access{
+		Op:          files.OpWrite,
+		Object:      object,
+		UID:         ownerID,
+		ContentType: r.contentType,
+		Size:        int64(len(r.data)),
+	}); err != nil {
+		return err
+	}
+
+	w, err := p.storage.Create(ctx, object, r.contentType)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, bytes.NewReader(r.data)); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("images: write %s: %w", object, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("images: write %s: %w", object, err)
+	}
+	return nil
+}
+
+// ThumbnailObject returns the storage object of a thumbnail
+func ThumbnailObject(ownerID, fileID string, size int, contentType string) string {
+	ext := "png"
+	if contentType == TypeJPEG {
+		ext = "jpg"
+	}
+	return fmt.Sprintf("users/%s/derived/%s/thumb_%d.%s", ownerID, fileID, size, ext)
+}
+
+// ThumbnailURL returns the API path of a thumbnail
+func ThumbnailURL(fileID string, size int) string {
+	return fmt.Sprintf("/api/v1/files/%s/thumbnails/%d", fileID, size)
+}
@@ -0,0 +1,173 @@
+package images
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/store"
+)
+
+type testEnv struct {
+	pipeline *Pipeline
+	files    *files.Service
+	storage  *files.LocalStorage
+	store    *MemoryStore
+	backend  *jobs.LocalBackend
+}
+
+func setupPipeline(t *testing.T) *testEnv {
+	t.Helper()
+
+	storage, err := files.NewLocalStorage(t.TempDir(), "http://files.test", []byte("test-key"), files.StorageOptions{})
+	require.NoError(t, err)
+	filesService := files.NewService(storage, files.NewMemoryStore(), files.Options{
+		Limits: files.Limits{
+			MaxSize:      files.PolicyMaxSize - 1,
+			AllowedTypes: []string{"image/*"},
+		},
+		URLExpiry: time.Minute,
+	}, zap.NewNop())
+
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 2})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	st := NewMemoryStore()
+	opts := testOptions
+	opts.URLExpiry = time.Minute
+	pipeline := NewPipeline(filesService, storage, st, jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry, opts, zap.NewNop())
+	filesService.SetProcessor(pipeline)
+	filesService.OnEvent(pipeline.OnEvent)
+
+	return &testEnv{pipeline: pipeline, files: filesService, storage: storage, store: st, backend: backend}
+}
+
+// upload stores data as alice's file and finalizes it, as a storage
+// notification would, then waits for processing
+func (env *testEnv) upload(t *testing.T, contentType string, data []byte) files.File {
+	t.Helper()
+	ctx := context.Background()
+
+	upload, err := env.files.CreateUpload(ctx, "alice", files.UploadRequest{Name: "photo", ContentType: contentType, Size: int64(len(data))})
+	require.NoError(t, err)
+	w, err := env.storage.Create(ctx, upload.File.Object, contentType)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	require.NoError(t, env.files.Finalize(ctx, files.ObjectAttrs{Name: upload.File.Object, ContentType: contentType, Size: int64(len(data))}))
+	env.backend.Wait()
+
+	f, err := env.files.Get(ctx, "alice", upload.File.ID)
+	require.NoError(t, err)
+	return *f
+}
+
+func TestPipeline_ProcessesImage(t *testing.T) {
+	// Arrange
+	env := setupPipeline(t)
+	ctx := context.Background()
+	data := withEXIF(encodeJPEG(t, halves(200, 100)), 1)
+
+	// Act
+	f := env.upload(t, TypeJPEG, data)
+
+	// Assert
+	assert.Equal(t, files.StatusReady, f.Status)
+
+	attrs, err := env.storage.Attrs(ctx, f.Object)
+	require.NoError(t, err)
+	assert.Equal(t, f.Size, attrs.Size, "size of the rewritten original")
+
+	img, err := env.store.Get(ctx, "alice", f.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 200, img.Width)
+	assert.Equal(t, 100, img.Height)
+	require.Len(t, img.Thumbnails, 2)
+	assert.Equal(t, Thumbnail{
+		Size:   64,
+		Width:  64,
+		Height: 32,
+		Object: "users/alice/derived/" + f.ID + "/thumb_64.jpg",
+		URL:    "/api/v1/files/" + f.ID + "/thumbnails/64",
+	}, img.Thumbnails[1])
+	for _, thumb := range img.Thumbnails {
+		_, err := env.storage.Attrs(ctx, thumb.Object)
+		assert.NoError(t, err, thumb.Object)
+	}
+
+	signed, err := env.pipeline.Thumbnail(ctx, "alice", f.ID, 16)
+	require.NoError(t, err)
+	assert.Contains(t, signed.URL, "thumb_16.jpg")
+}
+
+func TestPipeline_RejectsInvalidImage(t *testing.T) {
+	// Arrange
+	env := setupPipeline(t)
+	ctx := context.Background()
+
+	// Act
+	f := env.upload(t, TypePNG, []byte("<html><script>alert(1)</script></html>"))
+
+	// Assert
+	assert.Equal(t, files.StatusRejected, f.Status)
+	_, err := env.storage.Attrs(ctx, f.Object)
+	assert.ErrorIs(t, err, files.ErrObjectNotFound)
+	_, err = env.store.Get(ctx, "alice", f.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestPipeline_Thumbnail_NotFound(t *testing.T) {
+	// Arrange
+	env := setupPipeline(t)
+	f := env.upload(t, TypePNG, encodePNG(t, halves(20, 20)))
+
+	tests := []struct {
+		name    string
+		ownerID string
+		fileID  string
+		size    int
+	}{
+		{name: "unconfigured size", ownerID: "alice", fileID: f.ID, size: 32},
+		{name: "unknown file", ownerID: "alice", fileID: "missing", size: 16},
+		{name: "file of another owner", ownerID: "bob", fileID: f.ID, size: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := env.pipeline.Thumbnail(context.Background(), tt.ownerID, tt.fileID, tt.size)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestPipeline_DeleteRemovesDerivatives(t *testing.T) {
+	// Arrange
+	env := setupPipeline(t)
+	ctx := context.Background()
+	f := env.upload(t, TypePNG, encodePNG(t, halves(100, 100)))
+	require.Equal(t, files.StatusReady, f.Status)
+
+	// Act
+	err := env.files.Delete(ctx, "alice", f.ID)
+
+	// Assert
+	require.NoError(t, err)
+	for _, size := range testOptions.Sizes {
+		_, err := env.storage.Attrs(ctx, ThumbnailObject("alice", f.ID, size, TypePNG))
+		assert.ErrorIs(t, err, files.ErrObjectNotFound)
+	}
+	_, err = env.store.Get(ctx, "alice", f.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
@@ -0,0 +1,254 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrInvalidImage is returned for uploads that are not a supported image of
+// their declared type
+var ErrInvalidImage = errors.New("images: invalid image")
+
+// Supported formats. Other image types are rejected: they cannot be decoded,
+// so their metadata cannot be stripped.
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// rendition is an encoded image
+type rendition struct {
+	data        []byte
+	contentType string
+	width       int
+	height      int
+}
+
+// processed is the outcome of processing an upload
+type processed struct {
+	// original is the upload re-encoded without metadata
+	original   rendition
+	thumbnails map[int]rendition
+}
+
+// process checks that data is an image of contentType by its bytes,
+// re-encodes it without metadata, applying the EXIF orientation first, and
+// renders a thumbnail fitting each size. Images are never upscaled.
+func process(data []byte, contentType string, opts Options) (*processed, error) {
+	sniffed := http.DetectContentType(data)
+	if sniffed != contentType {
+		return nil, fmt.Errorf("%w: declared %s but content is %s", ErrInvalidImage, contentType, sniffed)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidImage, cfg.Width, cfg.Height, opts.MaxPixels)
+	}
+
+	var out processed
+	var img image.Image
+	switch contentType {
+	case TypeJPEG:
+		decoded, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		img = orient(decoded, jpegOrientation(data))
+		out.original, err = encode(img, TypeJPEG, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+	case TypePNG:
+		decoded, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		img = decoded
+		out.original, err = encode(img, TypePNG, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+	case TypeGIF:
+		// Re-encoding keeps the frames and drops comment and application
+		// extensions, where GIF metadata lives
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil || len(anim.Image) == 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, anim); err != nil {
+			return nil, fmt.Errorf("images: encode gif: %w", err)
+		}
+		img = anim.Image[0]
+		out.original = rendition{data: buf.Bytes(), contentType: TypeGIF, width: anim.Config.Width, height: anim.Config.Height}
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrInvalidImage, contentType)
+	}
+
+	// Thumbnails of GIFs show the first frame as a PNG
+	thumbType := ThumbnailType(contentType)
+	out.thumbnails = make(map[int]rendition, len(opts.Sizes))
+	for _, size := range opts.Sizes {
+		thumb, err := encode(fit(img, size), thumbType, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+		out.thumbnails[size] = thumb
+	}
+	return &out, nil
+}
+
+// ThumbnailType is the content type of thumbnails of contentType images
+func ThumbnailType(contentType string) string {
+	if contentType == TypeJPEG {
+		return TypeJPEG
+	}
+	return TypePNG
+}
+
+func encode(img image.Image, contentType string, quality int) (rendition, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == TypeJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return rendition{}, fmt.Errorf("images: encode %s: %w", contentType, err)
+	}
+	b := img.Bounds()
+	return rendition{data: buf.Bytes(), contentType: contentType, width: b.Dx(), height: b.Dy()}, nil
+}
+
+// fit scales img down to fit a size x size box, keeping its aspect ratio
+func fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8), since re-encoding drops the tag
+// that told viewers to rotate
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, or returns 1
+// (upright) when there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Start of scan: no metadata segments follow
+		if marker == 0xDA {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in IFD0 of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:]) == 0x0112 {
+			// A SHORT value sits in the first two bytes of the value field
+			if v := int(order.Uint16(tiff[off+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
@@ -0,0 +1,222 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{Sizes: []int{16, 64}, MaxPixels: 1_000_000, Quality: 90}
+
+// halves is a w x h image, red on the left half and blue on the right
+func halves(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment holding an orientation tag and a
+// GPS-like marker right after the SOI of a JPEG
+func withEXIF(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 52.5200N 13.4050E"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, app1...)
+	return append(out, jpg[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && r < 0x4000 && g < 0x4000
+}
+
+func TestProcess_JPEG_AppliesOrientationAndStripsEXIF(t *testing.T) {
+	// Arrange
+	data := withEXIF(encodeJPEG(t, halves(40, 20)), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	// Act
+	out, err := process(data, TypeJPEG, testOptions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, string(out.original.data), "Exif")
+	assert.NotContains(t, string(out.original.data), "GPS")
+	assert.Equal(t, 1, jpegOrientation(out.original.data))
+
+	// Rotated 90 clockwise: the left half is now on top
+	img, err := jpeg.Decode(bytes.NewReader(out.original.data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(20, 40), img.Bounds().Size())
+	assert.True(t, isRed(img.At(10, 5)), "top is red")
+	assert.True(t, isBlue(img.At(10, 35)), "bottom is blue")
+	assert.Equal(t, 20, out.original.width)
+	assert.Equal(t, 40, out.original.height)
+}
+
+func TestProcess_Thumbnails(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        func(t *testing.T) []byte
+		contentType string
+		wantType    string
+		want        map[int]image.Point
+	}{
+		{
+			name:        "landscape jpeg",
+			data:        func(t *testing.T) []byte { return encodeJPEG(t, halves(200, 100)) },
+			contentType: TypeJPEG,
+			wantType:    TypeJPEG,
+			want:        map[int]image.Point{16: {16, 8}, 64: {64, 32}},
+		},
+		{
+			name:        "portrait png",
+			data:        func(t *testing.T) []byte { return encodePNG(t, halves(50, 100)) },
+			contentType: TypePNG,
+			wantType:    TypePNG,
+			want:        map[int]image.Point{16: {8, 16}, 64: {32, 64}},
+		},
+		{
+			name:        "small image is not upscaled",
+			data:        func(t *testing.T) []byte { return encodePNG(t, halves(30, 20)) },
+			contentType: TypePNG,
+			wantType:    TypePNG,
+			want:        map[int]image.Point{16: {16, 10}, 64: {30, 20}},
+		},
+		{
+			name: "gif first frame as png",
+			data: func(t *testing.T) []byte {
+				frame := image.NewPaletted(image.Rect(0, 0, 128, 128), color.Palette{color.Black, color.White})
+				var buf bytes.Buffer
+				require.NoError(t, gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}))
+				return buf.Bytes()
+			},
+			contentType: TypeGIF,
+			wantType:    TypePNG,
+			want:        map[int]image.Point{16: {16, 16}, 64: {64, 64}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			out, err := process(tt.data(t), tt.contentType, testOptions)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, out.original.contentType)
+			for size, want := range tt.want {
+				thumb := out.thumbnails[size]
+				assert.Equal(t, tt.wantType, thumb.contentType)
+				assert.Equal(t, want, image.Pt(thumb.width, thumb.height), "size %d", size)
+
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(thumb.data))
+				require.NoError(t, err)
+				assert.Equal(t, want, image.Pt(cfg.Width, cfg.Height), "size %d", size)
+			}
+		})
+	}
+}
+
+func TestProcess_Invalid(t *testing.T) {
+	pngData := encodePNG(t, halves(10, 10))
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		opts        Options
+	}{
+		{name: "png declared as jpeg", data: pngData, contentType: TypeJPEG, opts: testOptions},
+		{name: "html declared as png", data: []byte("<html><script>alert(1)</script></html>"), contentType: TypePNG, opts: testOptions},
+		{name: "truncated png", data: pngData[:40], contentType: TypePNG, opts: testOptions},
+		{name: "unsupported format", data: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), contentType: "image/webp", opts: testOptions},
+		{name: "too many pixels", data: pngData, contentType: TypePNG, opts: Options{Sizes: []int{16}, MaxPixels: 99, Quality: 90}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := process(tt.data, tt.contentType, tt.opts)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidImage)
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Top-left red pixel of a 3x2 image
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		red         image.Point
+	}{
+		{orientation: 1, size: image.Pt(3, 2), red: image.Pt(0, 0)},
+		{orientation: 2, size: image.Pt(3, 2), red: image.Pt(2, 0)},
+		{orientation: 3, size: image.Pt(3, 2), red: image.Pt(2, 1)},
+		{orientation: 4, size: image.Pt(3, 2), red: image.Pt(0, 1)},
+		{orientation: 5, size: image.Pt(2, 3), red: image.Pt(0, 0)},
+		{orientation: 6, size: image.Pt(2, 3), red: image.Pt(1, 0)},
+		{orientation: 7, size: image.Pt(2, 3), red: image.Pt(1, 2)},
+		{orientation: 8, size: image.Pt(2, 3), red: image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		// Act
+		out := orient(src, tt.orientation)
+
+		// Assert
+		assert.Equal(t, tt.size, out.Bounds().Size(), "orientation %d", tt.orientation)
+		assert.True(t, isRed(out.At(tt.red.X, tt.red.Y)), "orientation %d", tt.orientation)
+	}
+}
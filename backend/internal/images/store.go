@@ -0,0 +1,92 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store records processed images on their owner's user document
+type Store interface {
+	Save(ctx context.Context, ownerID string, img Image) error
+	// Delete removes the record of fileID; a missing record is not an error
+	Delete(ctx context.Context, ownerID, fileID string) error
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu     sync.Mutex
+	images map[string]Image
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{images: make(map[string]Image)}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, ownerID string, img Image) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.images[ownerID+"/"+img.FileID] = img
+	return nil
+}
+
+// Get returns the record of fileID, or store.ErrNotFound
+func (s *MemoryStore) Get(_ context.Context, ownerID, fileID string) (*Image, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	img, ok := s.images[ownerID+"/"+fileID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &img, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, ownerID, fileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.images, ownerID+"/"+fileID)
+	return nil
+}
+
+// FirestoreStore keeps images in the images map of users/{userId}, keyed by
+// file ID, so clients reading the profile get their thumbnails with it
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) user(ownerID string) *firestore.DocumentRef {
+	return s.client.Collection("users").Doc(ownerID)
+}
+
+// Save implements Store. It merges into the user document, creating it if
+// the user has no profile yet.
+func (s *FirestoreStore) Save(ctx context.Context, ownerID string, img Image) error {
+	data := map[string]any{"images": map[string]any{img.FileID: img}}
+	if _, err := s.user(ownerID).Set(ctx, data, firestore.MergeAll); err != nil {
+		return fmt.Errorf("images: save %s: %w", img.FileID, err)
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *FirestoreStore) Delete(ctx context.Context, ownerID, fileID string) error {
+	_, err := s.user(ownerID).Update(ctx, []firestore.Update{
+		{FieldPath: firestore.FieldPath{"images", fileID}, Value: firestore.Delete},
+	})
+	if err != nil && !store.IsNotFound(err) {
+		return fmt.Errorf("images: delete %s: %w", fileID, err)
+	}
+	return nil
+}
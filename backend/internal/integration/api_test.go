@@ -1,21 +1,31 @@
-// Package integration contains integration tests for the API
+// Package integration contains integration tests for the API. Routes in
+// api/openapi.yaml are called through the generated client, so responses
+// that no longer decode into the spec's types fail the tests.
 package integration
 
 import (
-	"encoding/json"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-app/client"
+	"github.com/your-org/your-app/internal/config"
+	"github.com/your-org/your-app/internal/orgs"
 	"github.com/your-org/your-app/internal/testutil"
 )
 
-// TestAPI_HealthCheck tests the basic health endpoint
+// TestAPI_HealthCheck tests the basic health endpoint, which load
+// balancers call outside the versioned API
 func TestAPI_HealthCheck(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
+	server := testutil.SetupTestServer(t)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	rec := httptest.NewRecorder()
 
@@ -24,75 +34,58 @@ func TestAPI_HealthCheck(t *testing.T) {
 
 	// Assert
 	assert.Equal(t, http.StatusOK, rec.Code)
-
-	var response map[string]string
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
-	require.NoError(t, err)
-	assert.Equal(t, "ok", response["status"])
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
 }
 
 // TestAPI_VersionedHealthCheck tests the versioned health endpoint
 func TestAPI_VersionedHealthCheck(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
-	rec := httptest.NewRecorder()
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
 
 	// Act
-	server.ServeHTTP(rec, req)
+	res, err := api.GetHealthWithResponse(context.Background(), &client.GetHealthParams{})
 
 	// Assert
-	assert.Equal(t, http.StatusOK, rec.Code)
-
-	var response map[string]string
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Equal(t, "ok", response["status"])
-	assert.NotEmpty(t, response["version"])
+	require.NotNil(t, res.JSON200)
+	assert.Equal(t, "ok", res.JSON200.Status)
+	require.NotNil(t, res.JSON200.Version)
+	assert.NotEmpty(t, *res.JSON200.Version)
 }
 
 // TestAPI_Hello_DefaultGreeting tests hello without name parameter
 func TestAPI_Hello_DefaultGreeting(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil)
-	rec := httptest.NewRecorder()
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
 
 	// Act
-	server.ServeHTTP(rec, req)
+	res, err := api.GetHelloWithResponse(context.Background(), &client.GetHelloParams{})
 
 	// Assert
-	assert.Equal(t, http.StatusOK, rec.Code)
-
-	var response map[string]string
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Equal(t, "Hello, World!", response["message"])
+	require.NotNil(t, res.JSON200)
+	assert.Equal(t, "Hello, World!", res.JSON200.Message)
 }
 
 // TestAPI_Hello_CustomGreeting tests hello with name parameter
 func TestAPI_Hello_CustomGreeting(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/hello?name=Alice", nil)
-	rec := httptest.NewRecorder()
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
+	name := "Alice"
 
 	// Act
-	server.ServeHTTP(rec, req)
+	res, err := api.GetHelloWithResponse(context.Background(), &client.GetHelloParams{Name: &name})
 
 	// Assert
-	assert.Equal(t, http.StatusOK, rec.Code)
-
-	var response map[string]string
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Equal(t, "Hello, Alice!", response["message"])
+	require.NotNil(t, res.JSON200)
+	assert.Equal(t, "Hello, Alice!", res.JSON200.Message)
 }
 
 // TestAPI_NotFound tests 404 for unknown routes
 func TestAPI_NotFound(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
+	server := testutil.SetupTestServer(t)
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
 	rec := httptest.NewRecorder()
 
@@ -106,7 +99,7 @@ func TestAPI_NotFound(t *testing.T) {
 // TestAPI_MethodNotAllowed tests 405 for wrong HTTP methods
 func TestAPI_MethodNotAllowed(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
+	server := testutil.SetupTestServer(t)
 	req := httptest.NewRequest(http.MethodPost, "/health", nil)
 	rec := httptest.NewRecorder()
 
@@ -117,16 +110,236 @@ func TestAPI_MethodNotAllowed(t *testing.T) {
 	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
 }
 
-// TestAPI_RequestIDHeader tests that request ID is added
+// TestAPI_RequestIDHeader tests that a request ID is added, or the
+// caller's one kept
 func TestAPI_RequestIDHeader(t *testing.T) {
 	// Arrange
-	server := testutil.SetupTestServer()
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
+	ctx := client.WithRequestID(context.Background(), "caller-request-1")
+
+	// Act
+	generated, err1 := api.GetHealthWithResponse(context.Background(), &client.GetHealthParams{})
+	propagated, err2 := api.GetHealthWithResponse(ctx, &client.GetHealthParams{})
+
+	// Assert
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.NotEmpty(t, generated.HTTPResponse.Header.Get(client.HeaderRequestID))
+	assert.Equal(t, "caller-request-1", propagated.HTTPResponse.Header.Get(client.HeaderRequestID))
+}
+
+// TestAPI_ETag_NotModified tests that an unchanged response is answered with 304
+func TestAPI_ETag_NotModified(t *testing.T) {
+	// Arrange
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
+	ctx := context.Background()
+	name := "Alice"
+	first, err := api.GetHelloWithResponse(ctx, &client.GetHelloParams{Name: &name})
+	require.NoError(t, err)
+	require.NotNil(t, first.Headers200)
+	require.NotNil(t, first.Headers200.ETag)
+	etag := *first.Headers200.ETag
+
+	// Act
+	res, err := api.GetHelloWithResponse(ctx, &client.GetHelloParams{Name: &name, IfNoneMatch: &etag})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, res.StatusCode())
+	require.NotNil(t, res.Headers304)
+	assert.Equal(t, etag, *res.Headers304.ETag)
+	assert.Empty(t, res.Body)
+}
+
+// TestAPI_ETag_Changed tests that a different representation gets a new ETag
+func TestAPI_ETag_Changed(t *testing.T) {
+	// Arrange
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{})
+	ctx := context.Background()
+	alice, bob := "Alice", "Bob"
+	first, err := api.GetHelloWithResponse(ctx, &client.GetHelloParams{Name: &alice})
+	require.NoError(t, err)
+	etag := *first.Headers200.ETag
+
+	// Act
+	res, err := api.GetHelloWithResponse(ctx, &client.GetHelloParams{Name: &bob, IfNoneMatch: &etag})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, res.JSON200)
+	assert.NotEqual(t, etag, *res.Headers200.ETag)
+	assert.Equal(t, "Hello, Bob!", res.JSON200.Message)
+}
+
+// TestAPI_IfMatch_PreventsLostUpdate tests that an update based on a stale
+// read is rejected with 412
+func TestAPI_IfMatch_PreventsLostUpdate(t *testing.T) {
+	// Arrange
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{Token: client.StaticToken(testutil.AdminToken)})
+	ctx := context.Background()
+	read, err := api.GetMaintenanceWithResponse(ctx, &client.GetMaintenanceParams{})
+	require.NoError(t, err)
+	require.NotNil(t, read.Headers200.ETag)
+	etag := *read.Headers200.ETag
+
+	// Act
+	first, firstErr := api.SetMaintenanceWithResponse(ctx, &client.SetMaintenanceParams{IfMatch: &etag},
+		client.SetMaintenanceJSONRequestBody{Mode: client.MaintenanceModeReadOnly})
+	_, secondErr := api.SetMaintenanceWithResponse(ctx, &client.SetMaintenanceParams{IfMatch: &etag},
+		client.SetMaintenanceJSONRequestBody{Mode: client.MaintenanceModeOff})
+	reread, rereadErr := api.GetMaintenanceWithResponse(ctx, &client.GetMaintenanceParams{})
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NotNil(t, first.JSON200)
+	assert.Equal(t, http.StatusPreconditionFailed, client.StatusCode(secondErr))
+	require.NoError(t, rereadErr)
+	assert.Equal(t, client.MaintenanceModeReadOnly, reread.JSON200.Override.Mode)
+	assert.NotEqual(t, etag, *reread.Headers200.ETag)
+}
+
+// TestAPI_CompressedRequestBody tests that a gzip request body is decoded
+// before the handler binds it
+func TestAPI_CompressedRequestBody(t *testing.T) {
+	// Arrange
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{Token: client.StaticToken(testutil.AdminToken)})
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := gz.Write([]byte(`{"mode":"read_only"}`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	contentEncoding := func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Content-Encoding", "gzip")
+		return nil
+	}
+
+	// Act
+	res, err := api.SetMaintenanceWithBodyWithResponse(context.Background(), &client.SetMaintenanceParams{},
+		"application/json", &body, contentEncoding)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, res.JSON200)
+	assert.Equal(t, client.MaintenanceModeReadOnly, res.JSON200.Override.Mode)
+}
+
+// TestAPI_Unauthorized tests that errors come back as typed client errors
+func TestAPI_Unauthorized(t *testing.T) {
+	// Arrange
+	api := testutil.NewClient(t, testutil.SetupTestServer(t), client.Options{Token: client.StaticToken("forged")})
+
+	// Act
+	_, err := api.GetMaintenanceWithResponse(context.Background(), &client.GetMaintenanceParams{})
+
+	// Assert
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.NotEmpty(t, apiErr.Message)
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+// TestAPI_SecurityHeaders tests that responses pass through the production
+// middleware stack
+func TestAPI_SecurityHeaders(t *testing.T) {
+	// Arrange
+	server := testutil.SetupTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/hello", nil)
 	rec := httptest.NewRecorder()
 
 	// Act
 	server.ServeHTTP(rec, req)
 
 	// Assert
-	assert.NotEmpty(t, rec.Header().Get("X-Request-Id"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
+}
+
+// TestAPI_CORS tests that only configured origins are allowed, and that
+// preflights still get the security headers
+func TestAPI_CORS(t *testing.T) {
+	tests := []struct {
+		name           string
+		origin         string
+		expectedOrigin string
+	}{
+		{name: "allowed origin", origin: "https://app.example.com", expectedOrigin: "https://app.example.com"},
+		{name: "other origin", origin: "https://evil.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server := testutil.SetupTestServer(t, testutil.WithConfig(func(cfg *config.Config) {
+				cfg.CORSAllowedOrigins = "https://app.example.com"
+			}))
+			req := httptest.NewRequest(http.MethodOptions, "/api/v1/hello", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tt.expectedOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+			assert.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
+		})
+	}
+}
+
+// TestAPI_EndToEnd tests a signed-in flow over a real listener, checking
+// the result in the store behind the routes
+func TestAPI_EndToEnd(t *testing.T) {
+	// Arrange
+	var store orgs.Store
+	server := testutil.SetupTestServer(t, testutil.Listen(), testutil.Populate(&store))
+	api := testutil.NewClient(t, server, client.Options{Token: client.StaticToken(testutil.UserToken)})
+	ctx := context.Background()
+
+	// Act
+	created, err := api.CreateOrgWithResponse(ctx, client.CreateOrgJSONRequestBody{Name: "Acme"})
+	require.NoError(t, err)
+	require.NotNil(t, created.JSON201)
+	read, err := api.GetOrgWithResponse(ctx, created.JSON201.Id, &client.GetOrgParams{})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, read.JSON200)
+	assert.Equal(t, "Acme", read.JSON200.Name)
+	assert.Equal(t, client.OrgRoleOwner, read.JSON200.Role)
+	member, err := store.GetMember(ctx, created.JSON201.Id, "test-user")
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleOwner, member.Role)
+}
+
+// TestAPI_FakeClockAndIDs tests that the test server stamps, names and
+// expires records by the fakes it is given
+func TestAPI_FakeClockAndIDs(t *testing.T) {
+	// Arrange
+	clk := testutil.NewClock()
+	server := testutil.SetupTestServer(t, testutil.WithClock(clk), testutil.WithIDs(&testutil.IDs{}))
+	owner := testutil.NewClient(t, server, client.Options{Token: client.StaticToken(testutil.UserToken)})
+	invitee := testutil.NewClient(t, server, client.Options{Token: client.StaticToken(testutil.AdminToken)})
+	ctx := context.Background()
+
+	// Act
+	created, err := owner.CreateOrgWithResponse(ctx, client.CreateOrgJSONRequestBody{Name: "Acme"})
+	require.NoError(t, err)
+	require.NotNil(t, created.JSON201)
+	invited, err := owner.CreateOrgInvitationWithResponse(ctx, created.JSON201.Id, client.CreateOrgInvitationJSONRequestBody{Role: client.OrgRoleMember})
+	require.NoError(t, err)
+	require.NotNil(t, invited.JSON201)
+	clk.Advance(7*24*time.Hour + time.Second)
+	_, err = invitee.AcceptOrgInvitationWithResponse(ctx, created.JSON201.Id, client.AcceptOrgInvitationJSONRequestBody{Token: invited.JSON201.Token})
+
+	// Assert
+	assert.Equal(t, "00000000000000000000000000000001", created.HTTPResponse.Header.Get(client.HeaderRequestID))
+	assert.Equal(t, "org_0000000000000002", created.JSON201.Id)
+	assert.True(t, testutil.Epoch.Equal(created.JSON201.CreatedAt))
+	assert.True(t, testutil.Epoch.Add(7*24*time.Hour).Equal(invited.JSON201.ExpiresAt))
+	assert.Equal(t, http.StatusGone, client.StatusCode(err))
 }
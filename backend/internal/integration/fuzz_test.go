@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/your-org/your-app/internal/clientinfo"
+	"github.com/your-org/your-app/internal/server"
+	"github.com/your-org/your-app/internal/testutil"
+)
+
+// fuzzStreamFor is how long an event stream is served before the request
+// is canceled
+const fuzzStreamFor = 50 * time.Millisecond
+
+// fuzzOrigins are the origins the test server allows in development
+var fuzzOrigins = map[string]bool{"http://localhost:3000": true, "http://localhost:8080": true}
+
+// FuzzAPI sends requests built from fuzzed parts to every route of the
+// production middleware stack and handlers. Whatever the client sends, the
+// server must not fail with a 5xx, must send the security headers and must
+// never allow an origin outside its CORS list.
+func FuzzAPI(f *testing.F) {
+	f.Add(uint16(0), "f1", "limit=10", []byte(`{"name":"Acme"}`), echo.MIMEApplicationJSON, "", "http://localhost:3000", "2.4.1", uint8(1))
+	f.Add(uint16(7), "%ff", "name=\xff&limit=-1", []byte("\x1f\x8b\x08"), "application/json; charset=utf-16", "gzip", "null", "-0", uint8(2))
+	s := testutil.SetupTestServer(f)
+	routes := fuzzRoutes(s)
+
+	f.Fuzz(func(t *testing.T, route uint16, param, query string, body []byte, contentType, contentEncoding, origin, clientVersion string, token uint8) {
+		// Arrange
+		r := routes[int(route)%len(routes)]
+		req := httptest.NewRequest(http.MethodGet, "/", bytes.NewReader(body))
+		req.Method = r.Method
+		req.URL.Path = fillParams(r.Path, param)
+		req.URL.RawQuery = query
+		req.Header.Set(echo.HeaderContentType, contentType)
+		req.Header.Set(echo.HeaderContentEncoding, contentEncoding)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		req.Header.Set(clientinfo.HeaderPlatform, clientinfo.PlatformIOS)
+		req.Header.Set(clientinfo.HeaderVersion, clientVersion)
+		switch token % 4 {
+		case 1:
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+testutil.UserToken)
+		case 2:
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+testutil.AdminToken)
+		case 3:
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+param)
+		}
+		if strings.HasSuffix(r.Path, "/stream") {
+			req.Header.Set(echo.HeaderAccept, server.MIMEEventStream)
+			ctx, cancel := context.WithTimeout(req.Context(), fuzzStreamFor)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		rec := httptest.NewRecorder()
+
+		// Act
+		s.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Less(t, rec.Code, http.StatusInternalServerError, "%s %s?%s: %s", req.Method, req.URL.Path, query, rec.Body.String())
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+		if allowed := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); allowed != "" {
+			assert.True(t, fuzzOrigins[allowed], "allowed origin %q", allowed)
+			assert.Equal(t, origin, allowed)
+		}
+	})
+}
+
+// fuzzRoutes returns the server's routes in a stable order, so corpus
+// entries keep naming the same route
+func fuzzRoutes(s *testutil.Server) []*echo.Route {
+	var routes []*echo.Route
+	for _, r := range s.Routes() {
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		routes = append(routes, r)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// fillParams replaces the path parameters and wildcard of a route with
+// value
+func fillParams(path, value string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || s == "*" {
+			segments[i] = value
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/cloudtasks/apiv2/cloudtaskspb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeTaskCreator struct {
+	requests []*cloudtaskspb.CreateTaskRequest
+	err      error
+}
+
+func (f *fakeTaskCreator) CreateTask(_ context.Context, req *cloudtaskspb.CreateTaskRequest, _ ...gax.CallOption) (*cloudtaskspb.Task, error) {
+	f.requests = append(f.requests, req)
+	return req.Task, f.err
+}
+
+func (f *fakeTaskCreator) Close() error { return nil }
+
+var testCloudTasksConfig = CloudTasksConfig{
+	ProjectID:      "demo",
+	Location:       "us-central1",
+	Queue:          "jobs",
+	ServiceURL:     "https://api.example.com",
+	ServiceAccount: "api@demo.iam.gserviceaccount.com",
+}
+
+func TestCloudTasksBackend_Submit(t *testing.T) {
+	// Arrange
+	client := &fakeTaskCreator{}
+	backend := &CloudTasksBackend{client: client, cfg: testCloudTasksConfig}
+	scheduleAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Act
+	err := backend.Submit(context.Background(), Task{
+		Type:         "greet",
+		Name:         "welcome-user-1",
+		Payload:      []byte(`{"name":"Alice"}`),
+		ScheduleTime: scheduleAt,
+		MaxAttempts:  3,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, client.requests, 1)
+
+	req := client.requests[0]
+	assert.Equal(t, "projects/demo/locations/us-central1/queues/jobs", req.Parent)
+	assert.Equal(t, req.Parent+"/tasks/"+taskID("greet", "welcome-user-1"), req.Task.Name)
+	assert.Equal(t, scheduleAt, req.Task.ScheduleTime.AsTime())
+
+	httpReq := req.Task.GetHttpRequest()
+	require.NotNil(t, httpReq)
+	assert.Equal(t, cloudtaskspb.HttpMethod_POST, httpReq.HttpMethod)
+	assert.Equal(t, "https://api.example.com/internal/tasks/greet", httpReq.Url)
+	assert.Equal(t, `{"name":"Alice"}`, string(httpReq.Body))
+	assert.Equal(t, "3", httpReq.Headers[HeaderMaxAttempts])
+	assert.Equal(t, "api@demo.iam.gserviceaccount.com", httpReq.GetOidcToken().ServiceAccountEmail)
+	assert.Equal(t, "https://api.example.com", httpReq.GetOidcToken().Audience)
+}
+
+func TestCloudTasksBackend_Submit_Unnamed(t *testing.T) {
+	// Arrange
+	client := &fakeTaskCreator{}
+	backend := &CloudTasksBackend{client: client, cfg: testCloudTasksConfig}
+
+	// Act
+	err := backend.Submit(context.Background(), Task{Type: "greet"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, client.requests[0].Task.Name)
+}
+
+func TestCloudTasksBackend_Submit_Duplicate(t *testing.T) {
+	// Arrange
+	client := &fakeTaskCreator{err: status.Error(codes.AlreadyExists, "task exists")}
+	backend := &CloudTasksBackend{client: client, cfg: testCloudTasksConfig}
+
+	// Act
+	err := backend.Submit(context.Background(), Task{Type: "greet", Name: "once"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicate)
+}
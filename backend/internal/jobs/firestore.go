@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// deadLettersCollection holds dead letters under generated IDs
+const deadLettersCollection = "jobDeadLetters"
+
+// FirestoreStore keeps dead letters in jobDeadLetters/{id}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+// AddDeadLetter implements Store
+func (s *FirestoreStore) AddDeadLetter(ctx context.Context, d DeadLetter) error {
+	if _, _, err := s.client.Collection(deadLettersCollection).Add(ctx, d); err != nil {
+		return fmt.Errorf("jobs: add dead letter %s: %w", d.Type, err)
+	}
+	return nil
+}
+
+// GetDeadLetter implements Store
+func (s *FirestoreStore) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	snap, err := s.client.Collection(deadLettersCollection).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jobs: get dead letter %s: %w", id, err)
+	}
+
+	var d DeadLetter
+	if err := snap.DataTo(&d); err != nil {
+		return nil, err
+	}
+	d.ID = snap.Ref.ID
+	return &d, nil
+}
+
+// ListDeadLetters implements Store
+func (s *FirestoreStore) ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	query := s.client.Collection(deadLettersCollection).OrderBy("failedAt", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("jobs: list dead letters: %w", err)
+	}
+
+	out := make([]DeadLetter, 0, len(docs))
+	for _, doc := range docs {
+		var d DeadLetter
+		if err := doc.DataTo(&d); err != nil {
+			return nil, err
+		}
+		d.ID = doc.Ref.ID
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// DeleteDeadLetter implements Store
+func (s *FirestoreStore) DeleteDeadLetter(ctx context.Context, id string) error {
+	if _, err := s.client.Collection(deadLettersCollection).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("jobs: delete dead letter %s: %w", id, err)
+	}
+	return nil
+}
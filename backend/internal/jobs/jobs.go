@@ -0,0 +1,184 @@
+// Package jobs defers work out of the request path.
+//
+// Jobs are typed structs registered with a Registry. Queue.Enqueue serialises
+// a job and hands it to a Backend: Cloud Tasks in production, which calls
+// back into POST /internal/tasks/{type} on this service, or an in-process
+// executor for local development and tests.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// DefaultMaxAttempts is used when neither the job options nor the handler
+// registration set a retry limit
+const DefaultMaxAttempts = 5
+
+var (
+	// ErrUnknownType is returned for jobs whose type has no registered handler
+	ErrUnknownType = errors.New("jobs: unknown job type")
+	// ErrDuplicate is returned when a job with the same dedup name was already enqueued
+	ErrDuplicate = errors.New("jobs: duplicate job name")
+	// ErrClosed is returned when enqueueing on a backend that is shutting down
+	ErrClosed = errors.New("jobs: backend closed")
+)
+
+// Job is a unit of deferred work. Implementations are plain structs that
+// marshal to JSON; JobType names the handler that runs them.
+type Job interface {
+	JobType() string
+}
+
+// Options controls how a job is scheduled
+type Options struct {
+	// Delay postpones the first attempt
+	Delay time.Duration
+	// Name deduplicates jobs: enqueueing a second job with the same type and
+	// name while the first is known to the backend returns ErrDuplicate
+	Name string
+	// MaxAttempts overrides the handler's retry limit
+	MaxAttempts int
+}
+
+// Task is a serialised job as handed to a Backend
+type Task struct {
+	Type         string
+	Name         string
+	Payload      []byte
+	ScheduleTime time.Time
+	MaxAttempts  int
+}
+
+// Backend delivers tasks to the registry, now or at their schedule time
+type Backend interface {
+	Submit(ctx context.Context, task Task) error
+	Close(ctx context.Context) error
+}
+
+// Queue enqueues jobs onto a backend
+type Queue struct {
+	backend  Backend
+	registry *Registry
+	now      func() time.Time
+}
+
+// QueueOptions configures a Queue
+type QueueOptions struct {
+	// Clock, the wall clock by default, stamps schedule times; give the
+	// local backend the same one
+	Clock clock.Clock
+}
+
+// NewQueue creates a queue submitting to backend. The registry is used to
+// reject jobs nobody can run before they are enqueued.
+func NewQueue(backend Backend, registry *Registry, opts QueueOptions) *Queue {
+	return &Queue{backend: backend, registry: registry, now: clock.OrSystem(opts.Clock).Now}
+}
+
+// Enqueue schedules job for execution
+func (q *Queue) Enqueue(ctx context.Context, job Job, opts Options) error {
+	def, ok := q.registry.lookup(job.JobType())
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, job.JobType())
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("jobs: marshal %s: %w", job.JobType(), err)
+	}
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = def.maxAttempts
+	}
+
+	return q.backend.Submit(ctx, Task{
+		Type:         job.JobType(),
+		Name:         opts.Name,
+		Payload:      payload,
+		ScheduleTime: q.now().Add(opts.Delay),
+		MaxAttempts:  maxAttempts,
+	})
+}
+
+// Replay enqueues the job of a dead letter again with a fresh set of
+// attempts. The dedup name is dropped, since the backend may still remember
+// it.
+func (q *Queue) Replay(ctx context.Context, d DeadLetter) error {
+	def, ok := q.registry.lookup(d.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, d.Type)
+	}
+
+	return q.backend.Submit(ctx, Task{
+		Type:         d.Type,
+		Payload:      []byte(d.Payload),
+		ScheduleTime: q.now(),
+		MaxAttempts:  def.maxAttempts,
+	})
+}
+
+// Drain waits until the jobs of an in-process backend have finished, or
+// until ctx is done. Backends that run jobs elsewhere return at once.
+// Commands that enqueue jobs and exit drain the queue before closing it,
+// which would drop jobs not started yet.
+func (q *Queue) Drain(ctx context.Context) error {
+	local, ok := q.backend.(*LocalBackend)
+	if !ok {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		local.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close shuts the backend down
+func (q *Queue) Close(ctx context.Context) error {
+	return q.backend.Close(ctx)
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is not retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+type attemptKey struct{}
+
+// Attempt returns the 1-based attempt number of the running job
+func Attempt(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
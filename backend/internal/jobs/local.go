@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// dedupWindow is how long the local backend remembers job names, matching
+// the window in which Cloud Tasks rejects reused task names
+const dedupWindow = time.Hour
+
+// LocalOptions configures the in-process backend
+type LocalOptions struct {
+	// Workers bounds the number of jobs running at once
+	Workers int
+	// MinBackoff is the delay before the first retry; it doubles per attempt
+	MinBackoff time.Duration
+	// MaxBackoff caps the retry delay
+	MaxBackoff time.Duration
+	// DeadLetters records jobs that give up; nil drops them after logging
+	DeadLetters Store
+	// Clock, the wall clock by default, is the one the Queue stamps
+	// schedule times with; delays, dedup and dead letters are measured by it
+	Clock clock.Clock
+}
+
+// LocalBackend runs jobs in goroutines of the current process. It mirrors the
+// Cloud Tasks semantics the rest of the code relies on (delays, dedup names,
+// retries with exponential backoff) but keeps nothing across restarts, so it
+// is meant for local development and tests only.
+type LocalBackend struct {
+	registry *Registry
+	logger   *zap.Logger
+	opts     LocalOptions
+	now      func() time.Time
+	slots    chan struct{}
+
+	mu      sync.Mutex
+	names   map[string]time.Time
+	timers  map[*time.Timer]struct{}
+	closed  bool
+	pending sync.WaitGroup
+}
+
+// NewLocalBackend creates an in-process backend dispatching to registry
+func NewLocalBackend(registry *Registry, logger *zap.Logger, opts LocalOptions) *LocalBackend {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+
+	return &LocalBackend{
+		registry: registry,
+		logger:   logger,
+		opts:     opts,
+		now:      clock.OrSystem(opts.Clock).Now,
+		slots:    make(chan struct{}, opts.Workers),
+		names:    make(map[string]time.Time),
+		timers:   make(map[*time.Timer]struct{}),
+	}
+}
+
+// Submit schedules task to run at its schedule time
+func (b *LocalBackend) Submit(_ context.Context, task Task) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if task.Name != "" {
+		now := b.now()
+		for name, seen := range b.names {
+			if now.Sub(seen) > dedupWindow {
+				delete(b.names, name)
+			}
+		}
+
+		key := task.Type + "/" + task.Name
+		if _, dup := b.names[key]; dup {
+			return ErrDuplicate
+		}
+		b.names[key] = now
+	}
+
+	b.scheduleLocked(task, 1, task.ScheduleTime.Sub(b.now()))
+	return nil
+}
+
+// Wait blocks until every submitted job has either succeeded or given up,
+// including retries still waiting on their backoff. Tests use it to observe
+// side effects deterministically.
+func (b *LocalBackend) Wait() {
+	b.pending.Wait()
+}
+
+// Close stops accepting jobs, drops those not yet started and waits for
+// running ones until ctx is done
+func (b *LocalBackend) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	for t := range b.timers {
+		if t.Stop() {
+			delete(b.timers, t)
+			b.pending.Done()
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *LocalBackend) scheduleLocked(task Task, attempt int, delay time.Duration) {
+	b.pending.Add(1)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(max(delay, 0), func() {
+		b.mu.Lock()
+		delete(b.timers, timer)
+		b.mu.Unlock()
+
+		b.run(task, attempt)
+	})
+	b.timers[timer] = struct{}{}
+}
+
+func (b *LocalBackend) run(task Task, attempt int) {
+	defer b.pending.Done()
+
+	b.slots <- struct{}{}
+	d := Delivery{Type: task.Type, Payload: task.Payload, Attempt: attempt, MaxAttempts: task.MaxAttempts}
+	err := b.registry.Dispatch(context.Background(), d)
+	<-b.slots
+
+	if err == nil {
+		return
+	}
+
+	log := b.logger.With(
+		zap.String("job_type", task.Type),
+		zap.String("job_name", task.Name),
+		zap.Int("attempt", attempt),
+		zap.Error(err),
+	)
+
+	if IsPermanent(err) || d.Exhausted() {
+		log.Error("job failed, giving up")
+		if b.opts.DeadLetters != nil {
+			letter := d.DeadLetter(task.Name, err, b.now())
+			if recordErr := b.opts.DeadLetters.AddDeadLetter(context.Background(), letter); recordErr != nil {
+				log.Error("recording dead letter failed", zap.NamedError("record_error", recordErr))
+			}
+		}
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		log.Warn("job failed, backend closed before retry")
+		return
+	}
+
+	backoff := b.backoff(attempt)
+	log.Warn("job failed, retrying", zap.Duration("backoff", backoff))
+	b.scheduleLocked(task, attempt+1, backoff)
+}
+
+// backoff returns the delay before attempt+1
+func (b *LocalBackend) backoff(attempt int) time.Duration {
+	d := b.opts.MinBackoff
+	for i := 1; i < attempt && d < b.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, b.opts.MaxBackoff)
+}
@@ -0,0 +1,293 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type greetJob struct {
+	Name string `json:"name"`
+}
+
+func (greetJob) JobType() string { return "greet" }
+
+type otherJob struct{}
+
+func (otherJob) JobType() string { return "other" }
+
+func newTestQueue(t *testing.T, maxAttempts int, handler func(context.Context, greetJob) error) (*Queue, *LocalBackend) {
+	t.Helper()
+
+	registry := NewRegistry()
+	Handle(registry, maxAttempts, handler)
+
+	backend := NewLocalBackend(registry, zap.NewNop(), LocalOptions{
+		Workers:    2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	return NewQueue(backend, registry, QueueOptions{}), backend
+}
+
+func TestQueue_Enqueue_RunsJob(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var got []string
+	queue, backend := newTestQueue(t, 0, func(_ context.Context, job greetJob) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, job.Name)
+		return nil
+	})
+
+	// Act
+	err := queue.Enqueue(context.Background(), greetJob{Name: "Alice"}, Options{})
+	backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice"}, got)
+}
+
+func TestQueue_Enqueue_RejectsUnknownType(t *testing.T) {
+	// Arrange
+	queue, _ := newTestQueue(t, 0, func(context.Context, greetJob) error { return nil })
+
+	// Act
+	err := queue.Enqueue(context.Background(), otherJob{}, Options{})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestQueue_Enqueue_Delay(t *testing.T) {
+	// Arrange
+	var ranAt atomic.Int64
+	queue, backend := newTestQueue(t, 0, func(context.Context, greetJob) error {
+		ranAt.Store(time.Now().UnixNano())
+		return nil
+	})
+	start := time.Now()
+
+	// Act
+	err := queue.Enqueue(context.Background(), greetJob{}, Options{Delay: 30 * time.Millisecond})
+	backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Duration(ranAt.Load()-start.UnixNano()), 30*time.Millisecond)
+}
+
+func TestQueue_Enqueue_DedupName(t *testing.T) {
+	// Arrange
+	var runs atomic.Int32
+	queue, backend := newTestQueue(t, 0, func(context.Context, greetJob) error {
+		runs.Add(1)
+		return nil
+	})
+
+	// Act
+	first := queue.Enqueue(context.Background(), greetJob{}, Options{Name: "welcome-user-1"})
+	second := queue.Enqueue(context.Background(), greetJob{}, Options{Name: "welcome-user-1"})
+	other := queue.Enqueue(context.Background(), greetJob{}, Options{Name: "welcome-user-2"})
+	backend.Wait()
+
+	// Assert
+	require.NoError(t, first)
+	assert.ErrorIs(t, second, ErrDuplicate)
+	require.NoError(t, other)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestQueue_Enqueue_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		registered       int
+		override         int
+		failures         int
+		permanent        bool
+		expectedAttempts int
+	}{
+		{
+			name:             "succeeds after transient failures",
+			registered:       5,
+			failures:         2,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "stops at registered limit",
+			registered:       3,
+			failures:         10,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "options override registered limit",
+			registered:       3,
+			override:         1,
+			failures:         10,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "permanent errors are not retried",
+			registered:       5,
+			failures:         10,
+			permanent:        true,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var attempts []int
+			queue, backend := newTestQueue(t, tt.registered, func(ctx context.Context, _ greetJob) error {
+				attempts = append(attempts, Attempt(ctx))
+				if len(attempts) <= tt.failures {
+					err := errors.New("boom")
+					if tt.permanent {
+						return Permanent(err)
+					}
+					return err
+				}
+				return nil
+			})
+
+			// Act
+			err := queue.Enqueue(context.Background(), greetJob{}, Options{MaxAttempts: tt.override})
+			backend.Wait()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Len(t, attempts, tt.expectedAttempts)
+			assert.Equal(t, 1, attempts[0])
+			assert.Equal(t, tt.expectedAttempts, attempts[len(attempts)-1])
+		})
+	}
+}
+
+func TestLocalBackend_Close(t *testing.T) {
+	// Arrange
+	var runs atomic.Int32
+	queue, backend := newTestQueue(t, 0, func(context.Context, greetJob) error {
+		runs.Add(1)
+		return nil
+	})
+	require.NoError(t, queue.Enqueue(context.Background(), greetJob{}, Options{Delay: time.Hour}))
+
+	// Act
+	closeErr := backend.Close(context.Background())
+	enqueueErr := queue.Enqueue(context.Background(), greetJob{}, Options{})
+
+	// Assert
+	require.NoError(t, closeErr)
+	assert.ErrorIs(t, enqueueErr, ErrClosed)
+	assert.Zero(t, runs.Load())
+}
+
+func TestLocalBackend_Backoff(t *testing.T) {
+	// Arrange
+	backend := NewLocalBackend(NewRegistry(), zap.NewNop(), LocalOptions{
+		MinBackoff: time.Second,
+		MaxBackoff: 5 * time.Second,
+	})
+
+	// Act & Assert
+	assert.Equal(t, time.Second, backend.backoff(1))
+	assert.Equal(t, 2*time.Second, backend.backoff(2))
+	assert.Equal(t, 4*time.Second, backend.backoff(3))
+	assert.Equal(t, 5*time.Second, backend.backoff(4))
+	assert.Equal(t, 5*time.Second, backend.backoff(50))
+}
+
+func TestLocalBackend_DeadLetters(t *testing.T) {
+	// Arrange
+	var fixed atomic.Bool
+	var runs atomic.Int32
+	registry := NewRegistry()
+	Handle(registry, 2, func(_ context.Context, job greetJob) error {
+		runs.Add(1)
+		if !fixed.Load() {
+			return errors.New("smtp unavailable")
+		}
+		return nil
+	})
+	letters := NewMemoryStore()
+	backend := NewLocalBackend(registry, zap.NewNop(), LocalOptions{
+		MinBackoff:  time.Millisecond,
+		DeadLetters: letters,
+	})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+	queue := NewQueue(backend, registry, QueueOptions{})
+	ctx := context.Background()
+
+	// Act
+	require.NoError(t, queue.Enqueue(ctx, greetJob{Name: "Alice"}, Options{Name: "welcome-alice"}))
+	backend.Wait()
+	dead, err := letters.ListDeadLetters(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	fixed.Store(true)
+	replayErr := queue.Replay(ctx, dead[0])
+	drainErr := queue.Drain(ctx)
+	unknownErr := queue.Replay(ctx, DeadLetter{Type: "gone"})
+
+	// Assert
+	assert.Equal(t, "greet", dead[0].Type)
+	assert.Equal(t, "welcome-alice", dead[0].Name)
+	assert.JSONEq(t, `{"name":"Alice"}`, dead[0].Payload)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Equal(t, "smtp unavailable", dead[0].Error)
+	require.NoError(t, replayErr)
+	require.NoError(t, drainErr)
+	assert.Equal(t, int32(3), runs.Load())
+	assert.ErrorIs(t, unknownErr, ErrUnknownType)
+}
+
+// fixedClock is a clock.Clock that never moves
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestLocalBackend_Clock(t *testing.T) {
+	// Arrange: a clock years ahead of the wall clock, as in tests
+	clk := fixedClock(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	registry := NewRegistry()
+	Handle(registry, 1, func(context.Context, greetJob) error {
+		return Permanent(errors.New("unknown recipient"))
+	})
+	letters := NewMemoryStore()
+	backend := NewLocalBackend(registry, zap.NewNop(), LocalOptions{DeadLetters: letters, Clock: clk})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+	queue := NewQueue(backend, registry, QueueOptions{Clock: clk})
+	ctx := context.Background()
+
+	// Act
+	err := queue.Enqueue(ctx, greetJob{Name: "Alice"}, Options{Delay: 10 * time.Millisecond})
+	done := make(chan struct{})
+	go func() {
+		backend.Wait()
+		close(done)
+	}()
+
+	// Assert
+	require.NoError(t, err)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job scheduled by the wall clock instead of the injected one")
+	}
+	dead, err := letters.ListDeadLetters(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, time.Time(clk), dead[0].FailedAt)
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Delivery is one attempt at running a task
+type Delivery struct {
+	Type    string
+	Payload []byte
+	// Attempt is 1-based
+	Attempt     int
+	MaxAttempts int
+}
+
+// Exhausted reports whether no further attempts will be made after this one
+func (d Delivery) Exhausted() bool {
+	return d.MaxAttempts > 0 && d.Attempt >= d.MaxAttempts
+}
+
+// DeadLetter describes d as a dead letter of the task named name that
+// failed with err at failedAt
+func (d Delivery) DeadLetter(name string, err error, failedAt time.Time) DeadLetter {
+	return DeadLetter{
+		Type:     d.Type,
+		Name:     name,
+		Payload:  string(d.Payload),
+		Attempts: d.Attempt,
+		Error:    err.Error(),
+		FailedAt: failedAt.UTC(),
+	}
+}
+
+type definition struct {
+	maxAttempts int
+	run         func(ctx context.Context, payload []byte) error
+}
+
+// Registry maps job types to their handlers
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]definition
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{defs: make(map[string]definition)}
+}
+
+// Handle registers handler for jobs of type J. maxAttempts of zero or less
+// uses DefaultMaxAttempts. Registering the same type twice panics, as it
+// always indicates a wiring mistake.
+func Handle[J Job](r *Registry, maxAttempts int, handler func(ctx context.Context, job J) error) {
+	var zero J
+	typ := zero.JobType()
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.defs[typ]; exists {
+		panic(fmt.Sprintf("jobs: handler for %q registered twice", typ))
+	}
+
+	r.defs[typ] = definition{
+		maxAttempts: maxAttempts,
+		run: func(ctx context.Context, payload []byte) error {
+			var job J
+			if err := json.Unmarshal(payload, &job); err != nil {
+				return Permanent(fmt.Errorf("jobs: decode %s: %w", typ, err))
+			}
+			return handler(ctx, job)
+		},
+	}
+}
+
+// Types returns the registered job types
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.defs))
+	for typ := range r.defs {
+		types = append(types, typ)
+	}
+	return types
+}
+
+// Dispatch runs the handler for d.Type
+func (r *Registry) Dispatch(ctx context.Context, d Delivery) error {
+	def, ok := r.lookup(d.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, d.Type)
+	}
+	return def.run(context.WithValue(ctx, attemptKey{}, d.Attempt), d.Payload)
+}
+
+func (r *Registry) lookup(typ string) (definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.defs[typ]
+	return def, ok
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// DeadLetter records a job that failed permanently or ran out of attempts,
+// so it can be inspected and replayed once the cause is fixed
+type DeadLetter struct {
+	ID   string `firestore:"-" json:"id"`
+	Type string `firestore:"type" json:"type"`
+	// Name is the job's dedup name, if it had one
+	Name string `firestore:"name" json:"name,omitempty"`
+	// Payload is the job as JSON
+	Payload  string    `firestore:"payload" json:"payload"`
+	Attempts int       `firestore:"attempts" json:"attempts"`
+	Error    string    `firestore:"error" json:"error"`
+	FailedAt time.Time `firestore:"failedAt" json:"failedAt"`
+}
+
+// Store persists dead letters
+type Store interface {
+	// AddDeadLetter records a dead letter under a new ID
+	AddDeadLetter(ctx context.Context, d DeadLetter) error
+	// GetDeadLetter returns store.ErrNotFound for unknown IDs
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	// ListDeadLetters returns dead letters, newest first
+	ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+	// DeleteDeadLetter removes a dead letter; a missing one is not an error
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu      sync.Mutex
+	letters map[string]DeadLetter
+	nextID  int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{letters: make(map[string]DeadLetter)}
+}
+
+// AddDeadLetter implements Store
+func (s *MemoryStore) AddDeadLetter(_ context.Context, d DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	d.ID = strconv.Itoa(s.nextID)
+	s.letters[d.ID] = d
+	return nil
+}
+
+// GetDeadLetter implements Store
+func (s *MemoryStore) GetDeadLetter(_ context.Context, id string) (*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.letters[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &d, nil
+}
+
+// ListDeadLetters implements Store
+func (s *MemoryStore) ListDeadLetters(_ context.Context, limit int) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]DeadLetter, 0, len(s.letters))
+	for _, d := range s.letters {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FailedAt.After(out[j].FailedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// DeleteDeadLetter implements Store
+func (s *MemoryStore) DeleteDeadLetter(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.letters, id)
+	return nil
+}
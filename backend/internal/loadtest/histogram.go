@@ -0,0 +1,106 @@
+package loadtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// growth is the ratio between the bounds of neighbouring histogram
+// buckets, so percentiles are accurate to 1%
+const growth = 1.01
+
+// maxLatency is the largest latency the histogram tells apart; a soak test
+// runs for hours without its memory growing
+const maxLatency = time.Hour
+
+var bucketCount = int(math.Ceil(math.Log(float64(maxLatency/time.Microsecond))/math.Log(growth))) + 1
+
+// histogram counts latencies in log-spaced buckets from 1µs to maxLatency
+type histogram struct {
+	counts   []uint64
+	total    uint64
+	sum      time.Duration
+	min, max time.Duration
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, bucketCount)}
+}
+
+func (h *histogram) record(d time.Duration) {
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	h.max = max(h.max, d)
+	h.total++
+	h.sum += d
+	h.counts[bucket(d)]++
+}
+
+func (h *histogram) merge(o *histogram) {
+	if o.total == 0 {
+		return
+	}
+	if h.total == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	h.max = max(h.max, o.max)
+	h.total += o.total
+	h.sum += o.sum
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+}
+
+// percentile returns the latency p percent of requests were at or under:
+// the upper bound of its bucket, capped at the largest latency seen, which
+// is also returned for the open-ended last bucket
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(h.total)))
+	rank = max(rank, 1)
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			if i == len(h.counts)-1 {
+				break
+			}
+			return min(upperBound(i), h.max)
+		}
+	}
+	return h.max
+}
+
+func (h *histogram) mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+func bucket(d time.Duration) int {
+	us := float64(d) / float64(time.Microsecond)
+	if us <= 1 {
+		return 0
+	}
+	return min(int(math.Ceil(math.Log(us)/math.Log(growth))), bucketCount-1)
+}
+
+func upperBound(i int) time.Duration {
+	return time.Duration(math.Pow(growth, float64(i)) * float64(time.Microsecond))
+}
+
+// parsePercentile reads names such as "p50", "p99" and "p99.9"
+func parsePercentile(name string) (float64, error) {
+	p, err := strconv.ParseFloat(strings.TrimPrefix(name, "p"), 64)
+	if err != nil || !strings.HasPrefix(name, "p") || p <= 0 || p > 100 {
+		return 0, fmt.Errorf("%q is not a percentile such as p99", name)
+	}
+	return p, nil
+}
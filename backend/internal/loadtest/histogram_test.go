@@ -0,0 +1,58 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_Percentile(t *testing.T) {
+	// Arrange: 1ms to 1000ms, one request each
+	h := newHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		percentile float64
+		expected   time.Duration
+	}{
+		{percentile: 50, expected: 500 * time.Millisecond},
+		{percentile: 99, expected: 990 * time.Millisecond},
+		{percentile: 99.9, expected: 999 * time.Millisecond},
+		{percentile: 100, expected: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(percentileName(tt.percentile), func(t *testing.T) {
+			// Act
+			got := h.percentile(tt.percentile)
+
+			// Assert: within the 1% bucket width
+			assert.InEpsilon(t, float64(tt.expected), float64(got), 0.01)
+		})
+	}
+	assert.Equal(t, time.Millisecond, h.min)
+	assert.Equal(t, time.Second, h.max)
+	assert.Equal(t, 500500*time.Microsecond, h.mean())
+}
+
+func TestHistogram_Merge(t *testing.T) {
+	// Arrange
+	a, b := newHistogram(), newHistogram()
+	a.record(2 * time.Millisecond)
+	b.record(time.Millisecond)
+	b.record(2 * time.Hour)
+
+	// Act
+	a.merge(b)
+	a.merge(newHistogram())
+
+	// Assert
+	assert.Equal(t, uint64(3), a.total)
+	assert.Equal(t, time.Millisecond, a.min)
+	assert.Equal(t, 2*time.Hour, a.max)
+	assert.Equal(t, 2*time.Hour, a.percentile(100), "latencies beyond the last bucket report the maximum")
+	assert.Zero(t, newHistogram().percentile(99))
+}
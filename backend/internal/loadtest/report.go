@@ -0,0 +1,295 @@
+package loadtest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// reportedPercentiles are the latency percentiles every report lists
+var reportedPercentiles = []float64{50, 90, 95, 99, 99.9}
+
+// stats is the outcome of one request of the mix, or of all of them
+type stats struct {
+	requests uint64
+	failures uint64
+	latency  *histogram
+	statuses map[int]uint64
+	errors   map[string]uint64
+}
+
+func newStats() *stats {
+	return &stats{latency: newHistogram(), statuses: map[int]uint64{}, errors: map[string]uint64{}}
+}
+
+func (s *stats) add(latency time.Duration, status int, failure string) {
+	s.requests++
+	if failure != errorDropped && failure != errorInvalid {
+		s.latency.record(latency)
+	}
+	if status != 0 {
+		s.statuses[status]++
+	}
+	if failure != "" {
+		s.failures++
+		s.errors[failure]++
+	}
+}
+
+func (s *stats) merge(o *stats) {
+	s.requests += o.requests
+	s.failures += o.failures
+	s.latency.merge(o.latency)
+	for k, v := range o.statuses {
+		s.statuses[k] += v
+	}
+	for k, v := range o.errors {
+		s.errors[k] += v
+	}
+}
+
+func (s *stats) errorRate() float64 {
+	if s.requests == 0 {
+		return 0
+	}
+	return float64(s.failures) / float64(s.requests)
+}
+
+// Report is the outcome of Run
+type Report struct {
+	Scenario string    `json:"scenario"`
+	BaseURL  string    `json:"baseURL"`
+	Started  time.Time `json:"started"`
+	// Seconds is how long the run took, including requests still answered
+	// after the last stage
+	Seconds  float64 `json:"seconds"`
+	Total    Stats   `json:"total"`
+	Requests []Stats `json:"requests"`
+	// Checks are the SLOs of the scenario, met or not
+	Checks []Check `json:"checks"`
+	// Passed is whether every check was met
+	Passed bool `json:"passed"`
+}
+
+// Stats is the outcome of one request of the mix, or of all of them
+type Stats struct {
+	Name     string `json:"name"`
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+	// ErrorRate is the share of requests that failed, 0 to 1
+	ErrorRate float64 `json:"errorRate"`
+	// Throughput is the rate of successful requests per second
+	Throughput float64 `json:"throughput"`
+	// LatencyMs holds the mean, min, max and percentiles such as "p99", in
+	// milliseconds
+	LatencyMs map[string]float64 `json:"latencyMs"`
+	// Statuses counts responses by status code
+	Statuses map[string]uint64 `json:"statuses"`
+	// ErrorKinds counts failures by kind, e.g. "status 503" or "timeout"
+	ErrorKinds map[string]uint64 `json:"errorKinds,omitempty"`
+}
+
+// Check is one SLO threshold and how the run measured against it
+type Check struct {
+	// Scope is "total" or the name of a request
+	Scope     string `json:"scope"`
+	Metric    string `json:"metric"`
+	Threshold string `json:"threshold"`
+	Actual    string `json:"actual"`
+	Passed    bool   `json:"passed"`
+}
+
+func (rn *runner) report(started time.Time, elapsed time.Duration) *Report {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	r := &Report{
+		Scenario: rn.scenario.Name,
+		BaseURL:  rn.scenario.BaseURL,
+		Started:  started.UTC(),
+		Seconds:  elapsed.Seconds(),
+		Passed:   true,
+	}
+	total := newStats()
+	for i, s := range rn.stats {
+		req := rn.scenario.Requests[i]
+		total.merge(s)
+		r.Requests = append(r.Requests, summarize(req.Name, s, elapsed))
+		r.Checks = append(r.Checks, check(req.Name, req.SLO, s, elapsed)...)
+	}
+	r.Total = summarize("total", total, elapsed)
+	r.Checks = append(check("total", rn.scenario.SLO, total, elapsed), r.Checks...)
+	for _, c := range r.Checks {
+		r.Passed = r.Passed && c.Passed
+	}
+	return r
+}
+
+func summarize(name string, s *stats, elapsed time.Duration) Stats {
+	out := Stats{
+		Name:       name,
+		Requests:   s.requests,
+		Errors:     s.failures,
+		ErrorRate:  s.errorRate(),
+		Throughput: float64(s.requests-s.failures) / elapsed.Seconds(),
+		LatencyMs: map[string]float64{
+			"mean": ms(s.latency.mean()),
+			"min":  ms(s.latency.min),
+			"max":  ms(s.latency.max),
+		},
+		Statuses: map[string]uint64{},
+	}
+	for _, p := range reportedPercentiles {
+		out.LatencyMs[percentileName(p)] = ms(s.latency.percentile(p))
+	}
+	for status, n := range s.statuses {
+		out.Statuses[strconv.Itoa(status)] = n
+	}
+	if len(s.errors) > 0 {
+		out.ErrorKinds = maps.Clone(s.errors)
+	}
+	return out
+}
+
+// check measures s against slo
+func check(scope string, slo SLO, s *stats, elapsed time.Duration) []Check {
+	var checks []Check
+	if slo.MaxErrorRate != nil {
+		checks = append(checks, Check{
+			Scope:     scope,
+			Metric:    "error rate",
+			Threshold: fmt.Sprintf("<= %.2f%%", 100**slo.MaxErrorRate),
+			Actual:    fmt.Sprintf("%.2f%%", 100*s.errorRate()),
+			Passed:    s.errorRate() <= *slo.MaxErrorRate,
+		})
+	}
+	if slo.MinThroughput > 0 {
+		throughput := float64(s.requests-s.failures) / elapsed.Seconds()
+		checks = append(checks, Check{
+			Scope:     scope,
+			Metric:    "throughput",
+			Threshold: fmt.Sprintf(">= %.1f/s", slo.MinThroughput),
+			Actual:    fmt.Sprintf("%.1f/s", throughput),
+			Passed:    throughput >= slo.MinThroughput,
+		})
+	}
+	names := slices.Collect(maps.Keys(slo.Latency))
+	slices.SortFunc(names, func(a, b string) int {
+		pa, _ := parsePercentile(a)
+		pb, _ := parsePercentile(b)
+		return cmp.Compare(pa, pb)
+	})
+	for _, name := range names {
+		p, _ := parsePercentile(name)
+		actual := s.latency.percentile(p)
+		checks = append(checks, Check{
+			Scope:     scope,
+			Metric:    name + " latency",
+			Threshold: "<= " + slo.Latency[name].String(),
+			Actual:    formatLatency(actual),
+			// With nothing answered there is no latency to meet the SLO with
+			Passed: s.latency.total > 0 && actual <= slo.Latency[name],
+		})
+	}
+	return checks
+}
+
+// WriteJSON writes r as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes r as tables for a terminal
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	name := r.Scenario
+	if name == "" {
+		name = "load test"
+	}
+	fmt.Fprintf(&b, "%s: %d requests in %.1fs against %s\n\n", name, r.Total.Requests, r.Seconds, r.BaseURL)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"REQUEST", "REQS", "ERRORS", "OK/S", "MEAN"}
+	for _, p := range reportedPercentiles {
+		header = append(header, strings.ToUpper(percentileName(p)))
+	}
+	header = append(header, "MAX")
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, s := range append(slices.Clone(r.Requests), r.Total) {
+		row := []string{
+			s.Name,
+			strconv.FormatUint(s.Requests, 10),
+			fmt.Sprintf("%.2f%%", 100*s.ErrorRate),
+			fmt.Sprintf("%.1f", s.Throughput),
+			formatMs(s.LatencyMs["mean"]),
+		}
+		for _, p := range reportedPercentiles {
+			row = append(row, formatMs(s.LatencyMs[percentileName(p)]))
+		}
+		row = append(row, formatMs(s.LatencyMs["max"]))
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Total.ErrorKinds) > 0 {
+		b.WriteString("\nErrors:\n")
+		for _, s := range r.Requests {
+			for _, kind := range slices.Sorted(maps.Keys(s.ErrorKinds)) {
+				fmt.Fprintf(&b, "  %s: %s x%d\n", s.Name, kind, s.ErrorKinds[kind])
+			}
+		}
+	}
+
+	if len(r.Checks) > 0 {
+		b.WriteString("\nSLO:\n")
+		for _, c := range r.Checks {
+			result := "PASS"
+			if !c.Passed {
+				result = "FAIL"
+			}
+			fmt.Fprintf(&b, "  %s  %s %s %s (%s)\n", result, c.Scope, c.Metric, c.Actual, c.Threshold)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// percentileName turns 99.9 into "p99.9"
+func percentileName(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func formatMs(v float64) string {
+	return formatLatency(time.Duration(v * float64(time.Millisecond)))
+}
+
+// formatLatency rounds d to three significant digits, e.g. "12.3ms"
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= 100*time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	case d >= 10*time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,339 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Failures of requests that never reached the server, which therefore
+// have no latency
+const (
+	// errorDropped counts arrivals not sent because MaxInFlight requests
+	// were already in flight
+	errorDropped = "dropped at maxInFlight"
+	// errorInvalid counts requests that could not be built, such as a path
+	// with a bad escape
+	errorInvalid = "invalid request"
+)
+
+// Options tunes Run
+type Options struct {
+	// Client sends the requests; one pooling MaxInFlight connections when nil
+	Client *http.Client
+	// Progress receives a line every ProgressEvery while the test runs; nil
+	// for none
+	Progress      io.Writer
+	ProgressEvery time.Duration
+}
+
+// Run sends the traffic of s, which Validate has accepted, and returns its
+// report. Canceling ctx ends the run early; the report covers what was sent
+// until then.
+func Run(ctx context.Context, s *Scenario, opts Options) (*Report, error) {
+	bodies := make([][]byte, len(s.Requests))
+	for i, r := range s.Requests {
+		if r.Body == nil {
+			continue
+		}
+		b, err := json.Marshal(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("loadtest: request %s: body: %w", r.Name, err)
+		}
+		bodies[i] = b
+	}
+
+	client := opts.Client
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = s.MaxInFlight
+		client = &http.Client{Transport: transport}
+	}
+
+	rn := &runner{
+		scenario: s,
+		client:   client,
+		bodies:   bodies,
+		inFlight: make(chan struct{}, s.MaxInFlight),
+		stats:    make([]*stats, len(s.Requests)),
+	}
+	for i := range rn.stats {
+		rn.stats[i] = newStats()
+	}
+	for _, r := range s.Requests {
+		rn.totalWeight += r.Weight
+	}
+
+	started := time.Now()
+	if opts.Progress != nil && opts.ProgressEvery > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go rn.progress(opts.Progress, opts.ProgressEvery, started, done)
+	}
+
+	var rate float64
+	var concurrency int
+	stageStart := started
+	for _, st := range s.Stages {
+		if st.Concurrency > 0 || st.Rate == 0 && concurrency > 0 {
+			rn.closedLoop(ctx, stageStart, st.Duration, concurrency, st.Concurrency)
+			rate, concurrency = 0, st.Concurrency
+		} else {
+			rn.openLoop(ctx, stageStart, st.Duration, rate, st.Rate)
+			rate, concurrency = st.Rate, 0
+		}
+		stageStart = stageStart.Add(st.Duration)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	rn.wg.Wait()
+
+	return rn.report(started, time.Since(started)), nil
+}
+
+type runner struct {
+	scenario    *Scenario
+	client      *http.Client
+	bodies      [][]byte
+	totalWeight int
+	inFlight    chan struct{}
+	wg          sync.WaitGroup
+
+	mu    sync.Mutex
+	stats []*stats
+}
+
+// openLoop sends requests on a schedule ramping linearly from rate from to
+// rate to over d, however long the responses take
+func (rn *runner) openLoop(ctx context.Context, start time.Time, d time.Duration, from, to float64) {
+	for k := 1; ; k++ {
+		offset := arrival(k, from, to, d)
+		if offset >= d {
+			// The stage lasts its full duration even with nothing left to send
+			sleepUntil(ctx, start.Add(d))
+			return
+		}
+		intended := start.Add(offset)
+		if !sleepUntil(ctx, intended) {
+			return
+		}
+		i := rn.pick()
+		select {
+		case rn.inFlight <- struct{}{}:
+		default:
+			rn.record(i, 0, 0, errorDropped)
+			continue
+		}
+		rn.wg.Add(1)
+		go func() {
+			defer rn.wg.Done()
+			defer func() { <-rn.inFlight }()
+			// Latency counts from when the request was due, so time spent
+			// behind schedule is not hidden
+			rn.send(ctx, i, intended)
+		}()
+	}
+}
+
+// arrival returns when the kth request is due in a stage whose rate ramps
+// linearly from from to to over d: the time at which the integral of the
+// rate reaches k
+func arrival(k int, from, to float64, d time.Duration) time.Duration {
+	slope := (to - from) / d.Seconds()
+	var t float64
+	switch {
+	case slope == 0 && from == 0:
+		return d
+	case slope == 0:
+		t = float64(k) / from
+	default:
+		// from*t + slope*t²/2 = k
+		disc := from*from + 2*slope*float64(k)
+		if disc < 0 {
+			// A ramp down that ends before the kth request
+			return d
+		}
+		t = (math.Sqrt(disc) - from) / slope
+	}
+	if t < 0 || t > d.Seconds() {
+		return d
+	}
+	return time.Duration(t * float64(time.Second))
+}
+
+// closedLoop keeps a number of workers, ramping linearly from from to to
+// over d, each sending its next request once the previous one is answered
+func (rn *runner) closedLoop(ctx context.Context, start time.Time, d time.Duration, from, to int) {
+	// Requests started before the stage ends are not cut short by its end
+	parent := ctx
+	ctx, cancel := context.WithDeadline(ctx, start.Add(d))
+	defer cancel()
+
+	var workers sync.WaitGroup
+	for w := range max(from, to) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for ctx.Err() == nil {
+				elapsed := time.Since(start)
+				target := from + int(float64(to-from)*float64(elapsed)/float64(d)+0.5)
+				if w >= target {
+					sleepUntil(ctx, time.Now().Add(10*time.Millisecond))
+					continue
+				}
+				select {
+				case rn.inFlight <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				rn.send(parent, rn.pick(), time.Now())
+				<-rn.inFlight
+			}
+		}()
+	}
+	workers.Wait()
+}
+
+// pick returns a request of the mix at random, by weight
+func (rn *runner) pick() int {
+	n := mathrand.IntN(rn.totalWeight)
+	for i, r := range rn.scenario.Requests {
+		if n < r.Weight {
+			return i
+		}
+		n -= r.Weight
+	}
+	return len(rn.scenario.Requests) - 1
+}
+
+// send sends request i and records its outcome, with latency counted from
+// intended
+func (rn *runner) send(ctx context.Context, i int, intended time.Time) {
+	r := rn.scenario.Requests[i]
+	ctx, cancel := context.WithTimeout(ctx, rn.scenario.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if rn.bodies[i] != nil {
+		body = bytes.NewReader(rn.bodies[i])
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, strings.TrimSuffix(rn.scenario.BaseURL, "/")+r.Path, body)
+	if err != nil {
+		rn.record(i, 0, 0, errorInvalid)
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+rn.scenario.Tokens[r.Token])
+	}
+
+	res, err := rn.client.Do(req)
+	if err == nil {
+		_, err = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+	latency := time.Since(intended)
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		// The run was stopped; the request says nothing about the server
+	case err != nil:
+		rn.record(i, latency, 0, classify(err))
+	case !r.succeeded(res.StatusCode):
+		rn.record(i, latency, res.StatusCode, fmt.Sprintf("status %d", res.StatusCode))
+	default:
+		rn.record(i, latency, res.StatusCode, "")
+	}
+}
+
+// succeeded reports whether status is one r expects
+func (r Request) succeeded(status int) bool {
+	if len(r.Expect) == 0 {
+		return status >= 200 && status < 400
+	}
+	return slices.Contains(r.Expect, status)
+}
+
+// classify names the kind of a transport error for the report, without the
+// addresses and ports that would make every error distinct
+func classify(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return "connection reset"
+	}
+	return "transport error"
+}
+
+// record adds an outcome of request i; a request that was not sent has no
+// latency or status
+func (rn *runner) record(i int, latency time.Duration, status int, failure string) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	rn.stats[i].add(latency, status, failure)
+}
+
+// sleepUntil waits until t, reporting false if ctx ends first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// progress writes a line about the run so far every interval until done
+func (rn *runner) progress(w io.Writer, every time.Duration, started time.Time, done <-chan struct{}) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	var last uint64
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		rn.mu.Lock()
+		total := newStats()
+		for _, s := range rn.stats {
+			total.merge(s)
+		}
+		rn.mu.Unlock()
+		fmt.Fprintf(w, "%6s  %8.1f req/s  p50 %-9s p99 %-9s errors %.2f%%\n",
+			time.Since(started).Round(time.Second),
+			float64(total.requests-last)/every.Seconds(),
+			formatLatency(total.latency.percentile(50)),
+			formatLatency(total.latency.percentile(99)),
+			100*total.errorRate(),
+		)
+		last = total.requests
+	}
+}
@@ -0,0 +1,260 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScenario(t *testing.T, baseURL string, stages []Stage, requests ...Request) *Scenario {
+	t.Helper()
+	s := &Scenario{Name: "test", BaseURL: baseURL, Stages: stages, Requests: requests, Tokens: map[string]string{"user": "secret"}}
+	require.NoError(t, s.Validate())
+	return s
+}
+
+func TestRun_OpenLoop(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 100 * time.Millisecond, Rate: 100}, {Duration: 400 * time.Millisecond, Rate: 100}},
+		Request{Name: "create", Method: http.MethodPost, Path: "/api/v1/orgs", Token: "user", Body: map[string]any{"name": "Acme"}, Expect: []int{201}},
+		Request{Name: "health", Path: "/api/v1/health"},
+	)
+	one := 0.0
+	s.SLO.MaxErrorRate = &one
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.InDelta(t, 45, report.Total.Requests, 3, "5 requests in the ramp, 40 at the full rate")
+	assert.Equal(t, report.Total.Requests, report.Requests[0].Requests+report.Requests[1].Requests)
+	assert.Zero(t, report.Requests[0].Errors)
+	assert.Equal(t, report.Requests[1].Requests, report.Requests[1].Errors)
+	assert.Equal(t, map[string]uint64{"status 503": report.Requests[1].Errors}, report.Requests[1].ErrorKinds)
+	assert.Equal(t, report.Requests[1].Requests, report.Requests[1].Statuses["503"])
+	assert.Greater(t, report.Total.LatencyMs["p99"], 0.0)
+	assert.False(t, report.Passed)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "error rate", report.Checks[0].Metric)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, r := range received {
+		if r.Method == http.MethodPost {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.JSONEq(t, `{"name":"Acme"}`, bodies[i])
+		}
+	}
+}
+
+func TestRun_OpenLoopCountsDelay(t *testing.T) {
+	// Arrange: a server that answers one request at a time in 50ms. A
+	// closed-loop client would only see 50ms; on schedule, requests queue.
+	var serving sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		serving.Lock()
+		defer serving.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 10 * time.Millisecond, Rate: 50}, {Duration: 300 * time.Millisecond, Rate: 50}}, Request{Path: "/"})
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Greater(t, report.Total.LatencyMs["max"], 200.0, "queueing behind slow responses is measured")
+}
+
+func TestArrival(t *testing.T) {
+	tests := []struct {
+		name     string
+		k        int
+		from, to float64
+		expected time.Duration
+	}{
+		{name: "steady rate", k: 5, from: 10, to: 10, expected: 500 * time.Millisecond},
+		// Half the 50 requests of the ramp are sent by t²/2 = 25
+		{name: "ramp up from zero", k: 25, from: 0, to: 10, expected: 7071 * time.Millisecond},
+		{name: "ramp down to zero", k: 25, from: 10, to: 0, expected: 2929 * time.Millisecond},
+		{name: "past a ramp down", k: 100, from: 10, to: 0, expected: 10 * time.Second},
+		{name: "no load", k: 1, from: 0, to: 0, expected: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := arrival(tt.k, tt.from, tt.to, 10*time.Second)
+
+			// Assert
+			assert.InDelta(t, float64(tt.expected), float64(got), float64(time.Millisecond))
+		})
+	}
+}
+
+func TestRun_MaxInFlight(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 200 * time.Millisecond, Rate: 100}}, Request{Path: "/"})
+	s.MaxInFlight = 2
+	time.AfterFunc(300*time.Millisecond, func() { close(release) })
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	// A ramp from 0 to 100/s over 200ms has 10 arrivals, the last one due
+	// as the stage ends
+	assert.Equal(t, uint64(9), report.Total.Requests)
+	assert.Equal(t, uint64(7), report.Total.ErrorKinds[errorDropped])
+}
+
+func TestRun_InvalidRequest(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 100 * time.Millisecond, Concurrency: 1}},
+		Request{Name: "valid", Path: "/"},
+		Request{Name: "invalid", Path: "/%zz"},
+	)
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	var invalid Stats
+	for _, r := range report.Requests {
+		if r.Name == "invalid" {
+			invalid = r
+		}
+	}
+	require.NotZero(t, invalid.Requests)
+	assert.Equal(t, invalid.Requests, invalid.ErrorKinds[errorInvalid])
+	assert.Zero(t, invalid.LatencyMs["max"], "requests never sent have no latency")
+	assert.GreaterOrEqual(t, report.Total.LatencyMs["min"], 20.0, "and do not pull down the total")
+}
+
+func TestRun_ClosedLoop(t *testing.T) {
+	// Arrange
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 300 * time.Millisecond, Concurrency: 3}}, Request{Path: "/"})
+	s.SLO.MinThroughput = 1
+	s.SLO.Latency = map[string]time.Duration{"p50": time.Second}
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, report.Total.Requests, uint64(20))
+	assert.Zero(t, report.Total.Errors)
+	assert.True(t, report.Passed)
+	assert.Len(t, report.Checks, 2)
+}
+
+func TestRun_Canceled(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 10 * time.Millisecond, Rate: 100}, {Duration: time.Minute, Rate: 100}}, Request{Path: "/"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Act
+	start := time.Now()
+	report, err := Run(ctx, s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.NotZero(t, report.Total.Requests)
+}
+
+func TestRun_ConnectionRefused(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	s := newScenario(t, srv.URL, []Stage{{Duration: 10 * time.Millisecond, Rate: 50}, {Duration: 100 * time.Millisecond, Rate: 50}}, Request{Path: "/"})
+
+	// Act
+	report, err := Run(context.Background(), s, Options{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotZero(t, report.Total.Requests)
+	assert.Equal(t, report.Total.Requests, report.Total.ErrorKinds["connection refused"])
+}
+
+func TestReport_Write(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(srv.Close)
+	s := newScenario(t, srv.URL, []Stage{{Duration: 100 * time.Millisecond, Rate: 50}}, Request{Name: "health", Path: "/"})
+	s.SLO.Latency = map[string]time.Duration{"p99": time.Nanosecond}
+	report, err := Run(context.Background(), s, Options{})
+	require.NoError(t, err)
+
+	// Act
+	var text, js bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	require.NoError(t, report.WriteJSON(&js))
+
+	// Assert
+	assert.Contains(t, text.String(), "REQUEST")
+	assert.Contains(t, text.String(), "health")
+	assert.Contains(t, text.String(), "FAIL  total p99 latency")
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, false, decoded["passed"])
+	assert.Contains(t, decoded["total"].(map[string]any)["latencyMs"], "p99.9")
+}
@@ -0,0 +1,188 @@
+// Package loadtest drives the API with the traffic a scenario file
+// describes and reports latency, errors and throughput against SLOs.
+//
+// Rate stages use an open-loop arrival model: requests are sent on a
+// schedule that does not wait for earlier responses, and latency is
+// measured from the scheduled send time. A slow server therefore shows up
+// as latency instead of as fewer requests (coordinated omission).
+package loadtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is the traffic of a load test, read from YAML (or JSON) by Load
+type Scenario struct {
+	// Name labels the report
+	Name string `yaml:"name"`
+	// BaseURL is the API root, e.g. "http://localhost:8080"; the -url flag
+	// overrides it
+	BaseURL string `yaml:"baseURL"`
+	// Tokens are bearer tokens by name, for Request.Token. ${VAR} is
+	// expanded from the environment, so tokens stay out of scenario files.
+	Tokens map[string]string `yaml:"tokens"`
+	// Stages run one after another
+	Stages []Stage `yaml:"stages"`
+	// Requests are picked at random, in proportion to their weight
+	Requests []Request `yaml:"requests"`
+	// Timeout bounds each request; 10s when zero
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxInFlight bounds the requests in flight at once; arrivals beyond it
+	// are dropped and counted as errors. 1000 when zero.
+	MaxInFlight int `yaml:"maxInFlight"`
+	// SLO is checked against the whole run
+	SLO SLO `yaml:"slo"`
+}
+
+// Stage ramps the load linearly, from where the previous stage ended, to
+// Rate arrivals per second or to Concurrency requests in flight
+type Stage struct {
+	Duration time.Duration `yaml:"duration"`
+	// Rate is open-loop: requests are sent on schedule whatever the server
+	// does
+	Rate float64 `yaml:"rate"`
+	// Concurrency is closed-loop: each of that many workers sends a request
+	// as soon as its previous one is answered
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Request is one kind of request of the mix
+type Request struct {
+	// Name labels the request in the report; its method and path when empty
+	Name   string `yaml:"name"`
+	Method string `yaml:"method"`
+	// Path is relative to the base URL and may carry a query, e.g.
+	// "/api/v1/hello?name=load"
+	Path    string            `yaml:"path"`
+	Headers map[string]string `yaml:"headers"`
+	// Token names an entry of Scenario.Tokens
+	Token string `yaml:"token"`
+	// Body is sent as JSON
+	Body any `yaml:"body"`
+	// Weight is the request's share of the mix; 1 when zero
+	Weight int `yaml:"weight"`
+	// Expect lists the statuses counted as success; any 2xx or 3xx when
+	// empty
+	Expect []int `yaml:"expect"`
+	// SLO is checked against this request's results only
+	SLO SLO `yaml:"slo"`
+}
+
+// SLO is the thresholds a run must meet; zero values are not checked
+type SLO struct {
+	// MaxErrorRate is the highest share of failed requests, e.g. 0.01
+	MaxErrorRate *float64 `yaml:"maxErrorRate"`
+	// MinThroughput is the lowest rate of successful requests per second
+	MinThroughput float64 `yaml:"minThroughput"`
+	// Latency bounds percentiles, e.g. {p50: 50ms, p99: 500ms}
+	Latency map[string]time.Duration `yaml:"latency"`
+}
+
+// Load reads and validates the scenario at path
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadtest: reading %s: %w", path, err)
+	}
+	var s Scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("loadtest: parsing %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("loadtest: %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate fills defaults and reports the first mistake in s
+func (s *Scenario) Validate() error {
+	if _, err := url.ParseRequestURI(s.BaseURL); err != nil {
+		return fmt.Errorf("baseURL: %w", err)
+	}
+	if s.Timeout == 0 {
+		s.Timeout = 10 * time.Second
+	}
+	if s.MaxInFlight == 0 {
+		s.MaxInFlight = 1000
+	}
+	if len(s.Stages) == 0 {
+		return errors.New("no stages")
+	}
+	for i, st := range s.Stages {
+		switch {
+		case st.Duration <= 0:
+			return fmt.Errorf("stage %d: duration must be positive", i+1)
+		case st.Rate < 0 || st.Concurrency < 0:
+			return fmt.Errorf("stage %d: rate and concurrency cannot be negative", i+1)
+		case st.Rate > 0 && st.Concurrency > 0:
+			return fmt.Errorf("stage %d: set rate or concurrency, not both", i+1)
+		}
+	}
+	for name, token := range s.Tokens {
+		s.Tokens[name] = os.ExpandEnv(token)
+	}
+	if len(s.Requests) == 0 {
+		return errors.New("no requests")
+	}
+	for i := range s.Requests {
+		r := &s.Requests[i]
+		if r.Method == "" {
+			r.Method = http.MethodGet
+		}
+		r.Method = strings.ToUpper(r.Method)
+		if !strings.HasPrefix(r.Path, "/") {
+			return fmt.Errorf("request %d: path must start with /", i+1)
+		}
+		if r.Name == "" {
+			r.Name = r.Method + " " + r.Path
+		}
+		if r.Weight == 0 {
+			r.Weight = 1
+		}
+		if r.Weight < 0 {
+			return fmt.Errorf("request %s: weight cannot be negative", r.Name)
+		}
+		if token, ok := s.Tokens[r.Token]; r.Token != "" && (!ok || token == "") {
+			return fmt.Errorf("request %s: token %q is not set", r.Name, r.Token)
+		}
+		if err := r.SLO.validate(); err != nil {
+			return fmt.Errorf("request %s: slo: %w", r.Name, err)
+		}
+	}
+	if err := s.SLO.validate(); err != nil {
+		return fmt.Errorf("slo: %w", err)
+	}
+	return nil
+}
+
+// Duration is the length of all stages
+func (s *Scenario) Duration() time.Duration {
+	var d time.Duration
+	for _, st := range s.Stages {
+		d += st.Duration
+	}
+	return d
+}
+
+func (o SLO) validate() error {
+	if o.MaxErrorRate != nil && (*o.MaxErrorRate < 0 || *o.MaxErrorRate > 1) {
+		return errors.New("maxErrorRate must be between 0 and 1")
+	}
+	for name := range o.Latency {
+		if _, err := parsePercentile(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
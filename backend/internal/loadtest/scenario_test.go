@@ -0,0 +1,96 @@
+package loadtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	// Arrange
+	t.Setenv("LOADTEST_TOKEN", "secret")
+	path := writeScenario(t, `
+name: smoke
+baseURL: http://localhost:8080
+tokens:
+  user: ${LOADTEST_TOKEN}
+stages:
+  - {duration: 10s, rate: 20}
+  - {duration: 1m, concurrency: 5}
+requests:
+  - path: /api/v1/health
+  - name: create org
+    method: post
+    path: /api/v1/orgs
+    token: user
+    body: {name: Acme}
+    weight: 3
+    expect: [201]
+    slo: {latency: {p99.9: 1s}}
+slo:
+  maxErrorRate: 0.01
+  minThroughput: 10
+  latency: {p50: 20ms, p99: 200ms}
+`)
+
+	// Act
+	s, err := Load(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "secret", s.Tokens["user"])
+	assert.Equal(t, 10*time.Second, s.Timeout)
+	assert.Equal(t, 1000, s.MaxInFlight)
+	assert.Equal(t, 70*time.Second, s.Duration())
+	assert.Equal(t, Request{Name: "GET /api/v1/health", Method: "GET", Path: "/api/v1/health", Weight: 1}, s.Requests[0])
+	assert.Equal(t, "POST", s.Requests[1].Method)
+	assert.Equal(t, map[string]any{"name": "Acme"}, s.Requests[1].Body)
+	assert.Equal(t, 200*time.Millisecond, s.SLO.Latency["p99"])
+	assert.Equal(t, 0.01, *s.SLO.MaxErrorRate)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	const stages = "stages: [{duration: 1s, rate: 1}]\n"
+	const requests = "requests: [{path: /}]\n"
+	tests := []struct {
+		name          string
+		yaml          string
+		expectedError string
+	}{
+		{name: "unknown field", yaml: "baseURL: http://x\nrate: 5\n" + stages + requests, expectedError: "field rate not found"},
+		{name: "no base URL", yaml: stages + requests, expectedError: "baseURL"},
+		{name: "no stages", yaml: "baseURL: http://x\n" + requests, expectedError: "no stages"},
+		{name: "rate and concurrency", yaml: "baseURL: http://x\nstages: [{duration: 1s, rate: 1, concurrency: 1}]\n" + requests, expectedError: "not both"},
+		{name: "no duration", yaml: "baseURL: http://x\nstages: [{rate: 1}]\n" + requests, expectedError: "duration must be positive"},
+		{name: "no requests", yaml: "baseURL: http://x\n" + stages, expectedError: "no requests"},
+		{name: "relative path", yaml: "baseURL: http://x\n" + stages + "requests: [{path: health}]\n", expectedError: "must start with /"},
+		{name: "unknown token", yaml: "baseURL: http://x\n" + stages + "requests: [{path: /, token: admin}]\n", expectedError: `token "admin" is not set`},
+		{name: "unset token variable", yaml: "baseURL: http://x\ntokens: {user: $LOADTEST_UNSET}\n" + stages + "requests: [{path: /, token: user}]\n", expectedError: `token "user" is not set`},
+		{name: "error rate over 1", yaml: "baseURL: http://x\n" + stages + requests + "slo: {maxErrorRate: 5}\n", expectedError: "between 0 and 1"},
+		{name: "bad percentile", yaml: "baseURL: http://x\n" + stages + requests + "slo: {latency: {max: 1s}}\n", expectedError: "not a percentile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := writeScenario(t, tt.yaml)
+
+			// Act
+			_, err := Load(path)
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
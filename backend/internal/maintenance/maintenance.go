@@ -0,0 +1,212 @@
+// Package maintenance switches the API into read-only or full maintenance
+// mode at runtime, from config, an admin override or the maintenance_mode flag
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+// Mode is how much of the API is unavailable
+type Mode string
+
+// Maintenance modes, from least to most restrictive
+const (
+	ModeOff Mode = "off"
+	// ModeReadOnly blocks mutating requests only
+	ModeReadOnly Mode = "read_only"
+	// ModeFull blocks every request except health checks
+	ModeFull Mode = "full"
+)
+
+// Errors returned by Controller.Set and Controller.Update
+var (
+	ErrInvalidMode  = errors.New("maintenance: mode must be off, read_only or full")
+	ErrInvalidUntil = errors.New("maintenance: until must be in the future")
+)
+
+// Valid reports whether m is a known mode
+func (m Mode) Valid() bool {
+	return m == ModeOff || m == ModeReadOnly || m == ModeFull
+}
+
+func (m Mode) rank() int {
+	switch m {
+	case ModeReadOnly:
+		return 1
+	case ModeFull:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Source is what put the API into maintenance
+type Source string
+
+// Maintenance sources
+const (
+	SourceConfig Source = "config"
+	SourceAdmin  Source = "admin"
+	SourceFlag   Source = "flag"
+)
+
+// State is the admin override shared by every instance through the store
+type State struct {
+	Mode    Mode   `firestore:"mode" json:"mode"`
+	Message string `firestore:"message,omitempty" json:"message,omitempty"`
+	// Until is the expected end of maintenance. The override lapses after it.
+	Until     *time.Time `firestore:"until,omitempty" json:"until,omitempty"`
+	UpdatedAt time.Time  `firestore:"updatedAt" json:"updatedAt"`
+	UpdatedBy string     `firestore:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+}
+
+// Status is the effective maintenance mode
+type Status struct {
+	Mode    Mode       `json:"mode"`
+	Message string     `json:"message,omitempty"`
+	Until   *time.Time `json:"until,omitempty"`
+	Source  Source     `json:"source,omitempty"`
+}
+
+// Active reports whether any maintenance mode is on
+func (s Status) Active() bool {
+	return s.Mode.rank() > 0
+}
+
+// Options configures a Controller
+type Options struct {
+	// Mode is set by config and applies whatever the other sources say
+	Mode Mode
+	// Message is shown when config or the flag turns maintenance on
+	Message string
+	// Flag reports whether the maintenance_mode flag is on, which means full
+	// maintenance. Nil disables the flag source.
+	Flag func() bool
+	// Clock defaults to the wall clock
+	Clock clock.Clock
+}
+
+// Controller combines the maintenance sources. The most restrictive one wins.
+type Controller struct {
+	store    Store
+	opts     Options
+	override atomic.Pointer[State]
+	now      func() time.Time
+	logger   *zap.Logger
+}
+
+// NewController creates a controller. The admin override is off until the
+// first Refresh.
+func NewController(store Store, opts Options, logger *zap.Logger) *Controller {
+	if opts.Mode == "" {
+		opts.Mode = ModeOff
+	}
+	c := &Controller{store: store, opts: opts, now: clock.OrSystem(opts.Clock).Now, logger: logger}
+	c.override.Store(&State{Mode: ModeOff})
+	return c
+}
+
+// Status returns the effective mode
+func (c *Controller) Status() Status {
+	return c.StatusWith(*c.override.Load())
+}
+
+// StatusWith returns the effective mode if o were the admin override
+func (c *Controller) StatusWith(o State) Status {
+	status := Status{Mode: ModeOff}
+	consider := func(s Status) {
+		if s.Mode.rank() > status.Mode.rank() {
+			status = s
+		}
+	}
+
+	// The admin override goes first so it wins ties with its message and end time
+	if o.Until == nil || c.now().Before(*o.Until) {
+		consider(Status{Mode: o.Mode, Message: o.Message, Until: o.Until, Source: SourceAdmin})
+	}
+	consider(Status{Mode: c.opts.Mode, Message: c.opts.Message, Source: SourceConfig})
+	if c.opts.Flag != nil && c.opts.Flag() {
+		consider(Status{Mode: ModeFull, Message: c.opts.Message, Source: SourceFlag})
+	}
+	return status
+}
+
+// Override returns the current admin override
+func (c *Controller) Override() State {
+	return *c.override.Load()
+}
+
+// Set stores a new admin override and applies it on this instance at once.
+// Other instances pick it up on their next refresh.
+func (c *Controller) Set(ctx context.Context, s State) (State, error) {
+	return c.Update(ctx, s, nil)
+}
+
+// Update is Set guarded by check, which sees the stored override and
+// rejects the update by returning an error. The check and the write are
+// atomic, so a concurrent update cannot slip in between; check's error is
+// returned unwrapped.
+func (c *Controller) Update(ctx context.Context, s State, check func(current State) error) (State, error) {
+	if !s.Mode.Valid() {
+		return State{}, ErrInvalidMode
+	}
+	now := c.now()
+	if s.Until != nil && !s.Until.After(now) {
+		return State{}, ErrInvalidUntil
+	}
+	s.UpdatedAt = now.UTC()
+
+	saved, err := c.store.Update(ctx, func(current State) (State, error) {
+		if check != nil {
+			if err := check(current); err != nil {
+				return State{}, err
+			}
+		}
+		return s, nil
+	})
+	if err != nil {
+		return State{}, err
+	}
+	c.override.Store(&saved)
+	return saved, nil
+}
+
+// Refresh reloads the admin override. On error the previous one stays in use.
+func (c *Controller) Refresh(ctx context.Context) error {
+	s, err := c.store.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if prev := c.override.Load(); prev.Mode != s.Mode {
+		c.logger.Info("maintenance override changed",
+			zap.String("from", string(prev.Mode)),
+			zap.String("to", string(s.Mode)),
+			zap.String("updated_by", s.UpdatedBy),
+		)
+	}
+	c.override.Store(&s)
+	return nil
+}
+
+// Run refreshes the admin override every interval until ctx is done
+func (c *Controller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Warn("refreshing maintenance state failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestController_Status(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		config         Mode
+		flag           bool
+		override       State
+		expectedMode   Mode
+		expectedSource Source
+	}{
+		{
+			name:         "everything off",
+			expectedMode: ModeOff,
+		},
+		{
+			name:           "config",
+			config:         ModeReadOnly,
+			expectedMode:   ModeReadOnly,
+			expectedSource: SourceConfig,
+		},
+		{
+			name:           "flag means full",
+			flag:           true,
+			expectedMode:   ModeFull,
+			expectedSource: SourceFlag,
+		},
+		{
+			name:           "admin override",
+			override:       State{Mode: ModeReadOnly, Until: &future},
+			expectedMode:   ModeReadOnly,
+			expectedSource: SourceAdmin,
+		},
+		{
+			name:           "most restrictive source wins",
+			config:         ModeReadOnly,
+			override:       State{Mode: ModeFull},
+			expectedMode:   ModeFull,
+			expectedSource: SourceAdmin,
+		},
+		{
+			name:           "admin override wins ties",
+			config:         ModeFull,
+			override:       State{Mode: ModeFull},
+			expectedMode:   ModeFull,
+			expectedSource: SourceAdmin,
+		},
+		{
+			name:           "admin cannot lift config",
+			config:         ModeReadOnly,
+			override:       State{Mode: ModeOff},
+			expectedMode:   ModeReadOnly,
+			expectedSource: SourceConfig,
+		},
+		{
+			name:         "expired override lapses",
+			override:     State{Mode: ModeFull, Until: &past},
+			expectedMode: ModeOff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := NewMemoryStore()
+			if tt.override.Mode != "" {
+				_, err := store.Update(context.Background(), func(State) (State, error) { return tt.override, nil })
+				require.NoError(t, err)
+			}
+			ctrl := NewController(store, Options{Mode: tt.config, Flag: func() bool { return tt.flag }}, zap.NewNop())
+			ctrl.now = func() time.Time { return now }
+			require.NoError(t, ctrl.Refresh(context.Background()))
+
+			// Act
+			status := ctrl.Status()
+
+			// Assert
+			assert.Equal(t, tt.expectedMode, status.Mode)
+			assert.Equal(t, tt.expectedSource, status.Source)
+		})
+	}
+}
+
+func TestController_Set(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		state       State
+		expectedErr error
+	}{
+		{name: "read only", state: State{Mode: ModeReadOnly, Message: "Migrating"}},
+		{name: "unknown mode", state: State{Mode: "partial"}, expectedErr: ErrInvalidMode},
+		{name: "end in the past", state: State{Mode: ModeFull, Until: &past}, expectedErr: ErrInvalidUntil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := NewMemoryStore()
+			ctrl := NewController(store, Options{}, zap.NewNop())
+			ctrl.now = func() time.Time { return now }
+
+			// Act
+			saved, err := ctrl.Set(context.Background(), tt.state)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, ModeOff, ctrl.Status().Mode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, now, saved.UpdatedAt)
+			assert.Equal(t, tt.state.Mode, ctrl.Status().Mode)
+			stored, _ := store.Get(context.Background())
+			assert.Equal(t, saved, stored)
+		})
+	}
+}
+
+func TestController_RefreshPicksUpOtherInstances(t *testing.T) {
+	// Arrange
+	store := NewMemoryStore()
+	first := NewController(store, Options{}, zap.NewNop())
+	second := NewController(store, Options{}, zap.NewNop())
+
+	// Act
+	_, err := first.Set(context.Background(), State{Mode: ModeFull, UpdatedBy: "admin-1"})
+	require.NoError(t, err)
+	before := second.Status().Mode
+	require.NoError(t, second.Refresh(context.Background()))
+	after := second.Status()
+
+	// Assert
+	assert.Equal(t, ModeOff, before)
+	assert.Equal(t, ModeFull, after.Mode)
+	assert.Equal(t, SourceAdmin, after.Source)
+}
@@ -0,0 +1,157 @@
+package maintenance
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+// HeaderMode is set on every response while maintenance is on, so clients
+// can show a banner even on requests that went through
+const HeaderMode = "X-Maintenance-Mode"
+
+// Default messages when the source sets none
+const (
+	messageFull     = "The service is down for maintenance"
+	messageReadOnly = "The service is read-only during maintenance"
+)
+
+// Response is the body of a request blocked by maintenance
+type Response struct {
+	Message string `json:"message"`
+	// Code is always "maintenance", so clients can tell it from other 503s
+	Code string `json:"code"`
+	Mode Mode   `json:"mode"`
+	// RetryAfter repeats the Retry-After header, in seconds
+	RetryAfter int        `json:"retryAfter"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+// MiddlewareOptions configures which requests get through maintenance
+type MiddlewareOptions struct {
+	// Exempt lists paths that are always served, such as health checks
+	Exempt []string
+	// BypassNets lists client networks that are never blocked
+	BypassNets []netip.Prefix
+	// BypassRole is the role whose holders are never blocked
+	BypassRole string
+	// Verifier authenticates bearer tokens for the BypassRole check
+	Verifier auth.Verifier
+	// RetryAfter is advertised when maintenance has no end time
+	RetryAfter time.Duration
+}
+
+// Middleware answers 503 with Retry-After while maintenance is on. In
+// read-only mode only mutating methods are blocked. It runs before route
+// auth, so it verifies bypass tokens itself, and only for blocked requests.
+func Middleware(ctrl *Controller, opts MiddlewareOptions) echo.MiddlewareFunc {
+	exempt := make(map[string]bool, len(opts.Exempt))
+	for _, p := range opts.Exempt {
+		exempt[p] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			status := ctrl.Status()
+			if !status.Active() {
+				return next(c)
+			}
+			c.Response().Header().Set(HeaderMode, string(status.Mode))
+
+			if exempt[c.Request().URL.Path] {
+				return next(c)
+			}
+			if status.Mode == ModeReadOnly && isSafe(c.Request().Method) {
+				return next(c)
+			}
+			if bypassed(c, opts) {
+				return next(c)
+			}
+
+			retryAfter := retryAfterSeconds(status, opts.RetryAfter, ctrl.now())
+			message := status.Message
+			if message == "" {
+				message = messageFull
+				if status.Mode == ModeReadOnly {
+					message = messageReadOnly
+				}
+			}
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			c.Response().Header().Set("Cache-Control", "no-store")
+			return c.JSON(http.StatusServiceUnavailable, Response{
+				Message:    message,
+				Code:       "maintenance",
+				Mode:       status.Mode,
+				RetryAfter: retryAfter,
+				Until:      status.Until,
+			})
+		}
+	}
+}
+
+// ParseNets parses a list of CIDRs and single IP addresses
+func ParseNets(list []string) ([]netip.Prefix, error) {
+	nets := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("maintenance: invalid address %q: %w", s, err)
+			}
+			nets = append(nets, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance: invalid network %q: %w", s, err)
+		}
+		nets = append(nets, prefix.Masked())
+	}
+	return nets, nil
+}
+
+func isSafe(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// bypassed checks the client address, which relies on the server's
+// IPExtractor not trusting client-supplied headers, then the caller's role
+func bypassed(c echo.Context, opts MiddlewareOptions) bool {
+	if len(opts.BypassNets) > 0 {
+		if addr, err := netip.ParseAddr(c.RealIP()); err == nil {
+			addr = addr.Unmap()
+			for _, n := range opts.BypassNets {
+				if n.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+
+	if opts.Verifier == nil || opts.BypassRole == "" {
+		return false
+	}
+	token, ok := auth.BearerToken(c.Request())
+	if !ok {
+		return false
+	}
+	principal, err := opts.Verifier.Verify(c.Request().Context(), token)
+	return err == nil && auth.HasRole(principal, opts.BypassRole)
+}
+
+func retryAfterSeconds(status Status, fallback time.Duration, now time.Time) int {
+	d := fallback
+	if status.Until != nil {
+		d = status.Until.Sub(now)
+	}
+	return max(1, int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,174 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+func newTestServer(t testing.TB, mode Mode) *echo.Echo {
+	t.Helper()
+	ctrl := NewController(NewMemoryStore(), Options{Mode: mode}, zap.NewNop())
+	nets, err := ParseNets([]string{"203.0.113.0/24", "2001:db8::1"})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(Middleware(ctrl, MiddlewareOptions{
+		Exempt:     []string{"/health"},
+		BypassNets: nets,
+		BypassRole: "admin",
+		Verifier: auth.StaticVerifier{
+			"admin-token": {Subject: "admin", Claims: map[string]any{"admin": true}},
+			"user-token":  {Subject: "user"},
+		},
+		RetryAfter: 90 * time.Second,
+	}))
+	handler := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e.GET("/health", handler)
+	e.GET("/api/v1/things", handler)
+	e.POST("/api/v1/things", handler)
+	return e
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           Mode
+		method         string
+		path           string
+		remoteAddr     string
+		token          string
+		expectedStatus int
+	}{
+		{name: "off", mode: ModeOff, method: http.MethodPost, path: "/api/v1/things", expectedStatus: http.StatusNoContent},
+		{name: "full blocks reads", mode: ModeFull, method: http.MethodGet, path: "/api/v1/things", expectedStatus: http.StatusServiceUnavailable},
+		{name: "full serves health", mode: ModeFull, method: http.MethodGet, path: "/health", expectedStatus: http.StatusNoContent},
+		{name: "read only serves reads", mode: ModeReadOnly, method: http.MethodGet, path: "/api/v1/things", expectedStatus: http.StatusNoContent},
+		{name: "read only blocks writes", mode: ModeReadOnly, method: http.MethodPost, path: "/api/v1/things", expectedStatus: http.StatusServiceUnavailable},
+		{name: "allow-listed network", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", remoteAddr: "203.0.113.7:5000", expectedStatus: http.StatusNoContent},
+		{name: "allow-listed address", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", remoteAddr: "[2001:db8::1]:5000", expectedStatus: http.StatusNoContent},
+		{name: "other address", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", remoteAddr: "198.51.100.1:5000", expectedStatus: http.StatusServiceUnavailable},
+		{name: "admin role", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", token: "admin-token", expectedStatus: http.StatusNoContent},
+		{name: "regular user", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", token: "user-token", expectedStatus: http.StatusServiceUnavailable},
+		{name: "invalid token", mode: ModeFull, method: http.MethodPost, path: "/api/v1/things", token: "forged", expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := newTestServer(t, tt.mode)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestMiddleware_Response(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(10*time.Minute + 500*time.Millisecond)
+	ctrl := NewController(NewMemoryStore(), Options{}, zap.NewNop())
+	ctrl.now = func() time.Time { return now }
+	_, err := ctrl.Set(context.Background(), State{Mode: ModeReadOnly, Message: "Upgrading the database", Until: &until})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Middleware(ctrl, MiddlewareOptions{RetryAfter: time.Minute}))
+	e.GET("/things", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	e.DELETE("/things", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	// Act
+	blocked := httptest.NewRecorder()
+	e.ServeHTTP(blocked, httptest.NewRequest(http.MethodDelete, "/things", nil))
+	allowed := httptest.NewRecorder()
+	e.ServeHTTP(allowed, httptest.NewRequest(http.MethodGet, "/things", nil))
+
+	// Assert
+	require.Equal(t, http.StatusServiceUnavailable, blocked.Code)
+	assert.Equal(t, "601", blocked.Header().Get("Retry-After"))
+	assert.Equal(t, "read_only", blocked.Header().Get(HeaderMode))
+
+	var body Response
+	require.NoError(t, json.Unmarshal(blocked.Body.Bytes(), &body))
+	assert.Equal(t, "maintenance", body.Code)
+	assert.Equal(t, "Upgrading the database", body.Message)
+	assert.Equal(t, ModeReadOnly, body.Mode)
+	assert.Equal(t, 601, body.RetryAfter)
+	require.NotNil(t, body.Until)
+	assert.True(t, until.Equal(*body.Until))
+
+	assert.Equal(t, http.StatusNoContent, allowed.Code)
+	assert.Equal(t, "read_only", allowed.Header().Get(HeaderMode))
+}
+
+func TestParseNets(t *testing.T) {
+	// Act
+	nets, err := ParseNets([]string{"10.1.2.3/8", "192.0.2.4"})
+	_, badErr := ParseNets([]string{"not-an-ip"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", nets[0].String())
+	assert.Equal(t, "192.0.2.4/32", nets[1].String())
+	assert.Error(t, badErr)
+}
+
+// FuzzMiddleware checks that malformed client addresses and tokens never
+// fail a request, and only blocked requests get 503
+func FuzzMiddleware(f *testing.F) {
+	f.Add(uint8(1), http.MethodPost, "/api/v1/things", "203.0.113.7:5000", "Bearer admin-token")
+	f.Add(uint8(2), "get", "/health", "[::ffff:203.0.113.7]", "Bearer")
+	modes := []Mode{ModeOff, ModeFull, ModeReadOnly}
+	servers := make([]*echo.Echo, len(modes))
+	for i, mode := range modes {
+		servers[i] = newTestServer(f, mode)
+	}
+
+	f.Fuzz(func(t *testing.T, mode uint8, method, path, remoteAddr, authorization string) {
+		// Arrange
+		i := int(mode) % len(modes)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Method = method
+		req.URL.Path = path
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		rec := httptest.NewRecorder()
+
+		// Act
+		servers[i].ServeHTTP(rec, req)
+
+		// Assert
+		if rec.Code != http.StatusServiceUnavailable {
+			assert.Less(t, rec.Code, http.StatusInternalServerError)
+			return
+		}
+		assert.NotEqual(t, ModeOff, modes[i])
+		if modes[i] == ModeReadOnly {
+			assert.False(t, isSafe(method), "read-only mode blocked %s", method)
+		}
+		assert.Equal(t, "90", rec.Header().Get("Retry-After"))
+	})
+}
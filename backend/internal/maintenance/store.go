@@ -0,0 +1,114 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists the admin override
+type Store interface {
+	// Get returns the override, with ModeOff if none was ever set
+	Get(ctx context.Context) (State, error)
+	// Update replaces the override with what fn returns for the current one.
+	// Reading and writing are atomic, so fn can check a precondition; its
+	// error is returned as is and nothing is written.
+	Update(ctx context.Context, fn func(current State) (State, error)) (State, error)
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu    sync.Mutex
+	state State
+}
+
+// NewMemoryStore creates a store with maintenance off
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{state: State{Mode: ModeOff}}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(context.Context) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(_ context.Context, fn func(State) (State, error)) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next, err := fn(s.state)
+	if err != nil {
+		return State{}, err
+	}
+	s.state = next
+	return next, nil
+}
+
+// FirestoreStore keeps the override in system/maintenance
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) doc() *firestore.DocumentRef {
+	return s.client.Collection("system").Doc("maintenance")
+}
+
+// Get implements Store
+func (s *FirestoreStore) Get(ctx context.Context) (State, error) {
+	snap, err := s.doc().Get(ctx)
+	if store.IsNotFound(err) {
+		return State{Mode: ModeOff}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("maintenance: get: %w", err)
+	}
+
+	var state State
+	if err := snap.DataTo(&state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+// Update implements Store. Firestore retries the transaction when the
+// document changes underneath it, calling fn again with the newer state.
+func (s *FirestoreStore) Update(ctx context.Context, fn func(State) (State, error)) (State, error) {
+	var next State
+	var fnErr error
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		current := State{Mode: ModeOff}
+		snap, err := tx.Get(s.doc())
+		switch {
+		case store.IsNotFound(err):
+		case err != nil:
+			return err
+		default:
+			if err := snap.DataTo(&current); err != nil {
+				return err
+			}
+		}
+
+		if next, fnErr = fn(current); fnErr != nil {
+			return fnErr
+		}
+		return tx.Set(s.doc(), next)
+	})
+	if fnErr != nil {
+		return State{}, fnErr
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("maintenance: update: %w", err)
+	}
+	return next, nil
+}
@@ -0,0 +1,34 @@
+// Package metrics exports OpenTelemetry metrics to Cloud Monitoring.
+// Packages take a metric.Meter and create their own instruments, so tests
+// can pass a provider with a manual reader.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mexporter "github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/metric"
+	"go.opentelemetry.io/contrib/detectors/gcp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// NewCloudMonitoringProvider creates a provider exporting to projectID every
+// interval. Shut it down to flush the last interval.
+func NewCloudMonitoringProvider(ctx context.Context, projectID string, interval time.Duration) (*sdkmetric.MeterProvider, error) {
+	exporter, err := mexporter.New(mexporter.WithProjectID(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("metrics: create exporter: %w", err)
+	}
+	// Maps metrics to the Cloud Run revision and instance they come from
+	res, err := resource.New(ctx, resource.WithDetectors(gcp.NewDetector()), resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, fmt.Errorf("metrics: detect resource: %w", err)
+	}
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+	), nil
+}
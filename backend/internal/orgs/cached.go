@@ -0,0 +1,45 @@
+package orgs
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-app/internal/cache"
+)
+
+// CachedStore reads organizations through a cache.Loader, since every
+// request in an organization loads it. Organizations do not change after
+// creation, so only DeleteOrg drops them. Members and invitations are read
+// from the underlying Store, so membership changes take effect at once.
+type CachedStore struct {
+	Store
+	loader *cache.Loader
+	ttl    time.Duration
+}
+
+// NewCachedStore caches the organizations of st in loader for ttl
+func NewCachedStore(st Store, loader *cache.Loader, ttl time.Duration) *CachedStore {
+	return &CachedStore{Store: st, loader: loader, ttl: ttl}
+}
+
+// cacheTag files cached values under the organization orgID, the tag the
+// organization routes' cached responses use
+func cacheTag(orgID string) string {
+	return "org:" + orgID
+}
+
+// GetOrg implements Store
+func (s *CachedStore) GetOrg(ctx context.Context, id string) (*Org, error) {
+	return cache.Fetch(ctx, s.loader, "orgs:"+id, s.ttl, []string{cacheTag(id)}, func(ctx context.Context) (*Org, error) {
+		return s.Store.GetOrg(ctx, id)
+	})
+}
+
+// DeleteOrg implements Store
+func (s *CachedStore) DeleteOrg(ctx context.Context, id string) error {
+	if err := s.Store.DeleteOrg(ctx, id); err != nil {
+		return err
+	}
+	s.loader.Invalidate(ctx, cacheTag(id))
+	return nil
+}
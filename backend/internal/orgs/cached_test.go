@@ -0,0 +1,53 @@
+package orgs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/cache"
+	"github.com/your-org/your-app/internal/store"
+)
+
+// countingStore counts organization reads
+type countingStore struct {
+	Store
+	reads atomic.Int32
+}
+
+func (s *countingStore) GetOrg(ctx context.Context, id string) (*Org, error) {
+	s.reads.Add(1)
+	return s.Store.GetOrg(ctx, id)
+}
+
+func TestCachedStore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	backing := &countingStore{Store: NewMemoryStore()}
+	loader, err := cache.NewLoader(cache.NewMemoryCache(cache.MemoryOptions{MaxEntries: 10}), "orgs", nil, zap.NewNop())
+	require.NoError(t, err)
+	st := NewCachedStore(backing, loader, time.Minute)
+	org := Org{ID: "org_1", Name: "Acme", CreatedBy: "alice", CreatedAt: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, st.CreateOrg(ctx, org, Member{OrgID: org.ID, UserID: "alice", Role: RoleOwner}))
+
+	// Act
+	first, err1 := st.GetOrg(ctx, org.ID)
+	second, err2 := st.GetOrg(ctx, org.ID)
+	require.NoError(t, st.DeleteOrg(ctx, org.ID))
+	_, deleted := st.GetOrg(ctx, org.ID)
+	_, unknown := st.GetOrg(ctx, "org_2")
+
+	// Assert
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, org, *first)
+	assert.Equal(t, org, *second)
+	assert.ErrorIs(t, deleted, store.ErrNotFound, "deleting drops the cached organization")
+	assert.ErrorIs(t, unknown, store.ErrNotFound)
+	assert.Equal(t, int32(3), backing.reads.Load(), "the second read is served from the cache")
+}
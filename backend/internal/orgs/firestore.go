@@ -0,0 +1,303 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// orgsCollection holds organizations; members, invitations and tenant data
+// are subcollections of each organization
+const orgsCollection = "orgs"
+
+// Subcollections the service owns; repositories cannot use these names
+const (
+	membersCollection     = "members"
+	invitationsCollection = "invitations"
+)
+
+// FirestoreStore keeps organizations in orgs/{orgId}, members in
+// orgs/{orgId}/members/{userId} and invitations in
+// orgs/{orgId}/invitations/{invitationId}
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) org(id string) *firestore.DocumentRef {
+	return s.client.Collection(orgsCollection).Doc(id)
+}
+
+func (s *FirestoreStore) members(orgID string) *firestore.CollectionRef {
+	return s.org(orgID).Collection(membersCollection)
+}
+
+func (s *FirestoreStore) invitations(orgID string) *firestore.CollectionRef {
+	return s.org(orgID).Collection(invitationsCollection)
+}
+
+// CreateOrg implements Store
+func (s *FirestoreStore) CreateOrg(ctx context.Context, org Org, owner Member) error {
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(s.org(org.ID), org); err != nil {
+			return err
+		}
+		return tx.Create(s.members(org.ID).Doc(owner.UserID), owner)
+	})
+	if err != nil {
+		return fmt.Errorf("orgs: create org %s: %w", org.ID, err)
+	}
+	return nil
+}
+
+// GetOrg implements Store
+func (s *FirestoreStore) GetOrg(ctx context.Context, id string) (*Org, error) {
+	snap, err := s.org(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("orgs: get org %s: %w", id, err)
+	}
+
+	var org Org
+	if err := snap.DataTo(&org); err != nil {
+		return nil, err
+	}
+	org.ID = snap.Ref.ID
+	return &org, nil
+}
+
+// DeleteOrg implements Store
+func (s *FirestoreStore) DeleteOrg(ctx context.Context, id string) error {
+	if _, err := store.DeleteTree(ctx, s.client, s.org(id)); err != nil {
+		return fmt.Errorf("orgs: delete org %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetMember implements Store
+func (s *FirestoreStore) GetMember(ctx context.Context, orgID, userID string) (*Member, error) {
+	snap, err := s.members(orgID).Doc(userID).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("orgs: get member %s of %s: %w", userID, orgID, err)
+	}
+
+	var m Member
+	if err := snap.DataTo(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMembers implements Store
+func (s *FirestoreStore) ListMembers(ctx context.Context, orgID string) ([]Member, error) {
+	return s.queryMembers(ctx, s.members(orgID).OrderBy("joinedAt", firestore.Asc))
+}
+
+// Memberships implements Store. It queries the members collection group,
+// which needs the userId override declared in firestore.indexes.json.
+func (s *FirestoreStore) Memberships(ctx context.Context, userID string) ([]Member, error) {
+	return s.queryMembers(ctx, s.client.CollectionGroup(membersCollection).Where("userId", "==", userID))
+}
+
+// ScanMembers implements Store. Ordering the members collection group by
+// document path puts members in organization and user ID order.
+func (s *FirestoreStore) ScanMembers(ctx context.Context, afterOrgID, afterUserID string, limit int) ([]Member, error) {
+	query := s.client.CollectionGroup(membersCollection).OrderBy(firestore.DocumentID, firestore.Asc)
+	if afterOrgID != "" {
+		query = query.StartAfter(s.members(afterOrgID).Doc(afterUserID))
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return s.queryMembers(ctx, query)
+}
+
+func (s *FirestoreStore) queryMembers(ctx context.Context, query firestore.Query) ([]Member, error) {
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("orgs: list members: %w", err)
+	}
+
+	out := make([]Member, 0, len(docs))
+	for _, doc := range docs {
+		var m Member
+		if err := doc.DataTo(&m); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// SaveMember implements Store
+func (s *FirestoreStore) SaveMember(ctx context.Context, m Member) error {
+	if _, err := s.members(m.OrgID).Doc(m.UserID).Set(ctx, m); err != nil {
+		return fmt.Errorf("orgs: save member %s of %s: %w", m.UserID, m.OrgID, err)
+	}
+	return nil
+}
+
+// DeleteMember implements Store
+func (s *FirestoreStore) DeleteMember(ctx context.Context, orgID, userID string) error {
+	if _, err := s.members(orgID).Doc(userID).Delete(ctx); err != nil {
+		return fmt.Errorf("orgs: delete member %s of %s: %w", userID, orgID, err)
+	}
+	return nil
+}
+
+// CreateInvitation implements Store
+func (s *FirestoreStore) CreateInvitation(ctx context.Context, inv Invitation) error {
+	if _, err := s.invitations(inv.OrgID).Doc(inv.ID).Create(ctx, inv); err != nil {
+		return fmt.Errorf("orgs: create invitation of %s: %w", inv.OrgID, err)
+	}
+	return nil
+}
+
+// GetInvitation implements Store
+func (s *FirestoreStore) GetInvitation(ctx context.Context, orgID, id string) (*Invitation, error) {
+	snap, err := s.invitations(orgID).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("orgs: get invitation of %s: %w", orgID, err)
+	}
+
+	var inv Invitation
+	if err := snap.DataTo(&inv); err != nil {
+		return nil, err
+	}
+	inv.ID = snap.Ref.ID
+	return &inv, nil
+}
+
+// ListInvitations implements Store
+func (s *FirestoreStore) ListInvitations(ctx context.Context, orgID string) ([]Invitation, error) {
+	docs, err := s.invitations(orgID).OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("orgs: list invitations of %s: %w", orgID, err)
+	}
+
+	out := make([]Invitation, 0, len(docs))
+	for _, doc := range docs {
+		var inv Invitation
+		if err := doc.DataTo(&inv); err != nil {
+			return nil, err
+		}
+		inv.ID = doc.Ref.ID
+		out = append(out, inv)
+	}
+	return out, nil
+}
+
+// DeleteInvitation implements Store
+func (s *FirestoreStore) DeleteInvitation(ctx context.Context, orgID, id string) error {
+	if _, err := s.invitations(orgID).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("orgs: delete invitation of %s: %w", orgID, err)
+	}
+	return nil
+}
+
+// AcceptInvitation implements Store
+func (s *FirestoreStore) AcceptInvitation(ctx context.Context, inv Invitation, m Member) error {
+	invRef := s.invitations(inv.OrgID).Doc(inv.ID)
+	memberRef := s.members(m.OrgID).Doc(m.UserID)
+
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(invRef); err != nil {
+			if store.IsNotFound(err) {
+				return store.ErrNotFound
+			}
+			return err
+		}
+		_, err := tx.Get(memberRef)
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !store.IsNotFound(err) {
+			return err
+		}
+
+		if err := tx.Delete(invRef); err != nil {
+			return err
+		}
+		return tx.Create(memberRef, m)
+	})
+	if errors.Is(err, store.ErrNotFound) || errors.Is(err, ErrAlreadyMember) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("orgs: accept invitation of %s: %w", inv.OrgID, err)
+	}
+	return nil
+}
+
+// firestoreBackend keeps repository documents in
+// orgs/{orgId}/{collection}/{id}
+type firestoreBackend struct {
+	client *firestore.Client
+}
+
+// NewFirestoreBackend creates a repository backend on client
+func NewFirestoreBackend(client *firestore.Client) Backend {
+	return firestoreBackend{client: client}
+}
+
+func (b firestoreBackend) collection(orgID, collection string) *firestore.CollectionRef {
+	return b.client.Collection(orgsCollection).Doc(orgID).Collection(collection)
+}
+
+func (b firestoreBackend) get(ctx context.Context, orgID, collection, id string, dst any) error {
+	snap, err := b.collection(orgID, collection).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("orgs: get %s/%s: %w", collection, id, err)
+	}
+	return snap.DataTo(dst)
+}
+
+func (b firestoreBackend) set(ctx context.Context, orgID, collection, id string, v any) error {
+	if _, err := b.collection(orgID, collection).Doc(id).Set(ctx, v); err != nil {
+		return fmt.Errorf("orgs: set %s/%s: %w", collection, id, err)
+	}
+	return nil
+}
+
+func (b firestoreBackend) delete(ctx context.Context, orgID, collection, id string) error {
+	if _, err := b.collection(orgID, collection).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("orgs: delete %s/%s: %w", collection, id, err)
+	}
+	return nil
+}
+
+func (b firestoreBackend) list(ctx context.Context, orgID, collection string) ([]rawDocument, error) {
+	var out []rawDocument
+	docs := b.collection(orgID, collection).Documents(ctx)
+	defer docs.Stop()
+	for {
+		snap, err := docs.Next()
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("orgs: list %s: %w", collection, err)
+		}
+		out = append(out, rawDocument{id: snap.Ref.ID, decode: snap.DataTo})
+	}
+}
@@ -0,0 +1,74 @@
+package orgs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+// HeaderOrg selects the organization of requests whose path does not name one
+const HeaderOrg = "X-Org-ID"
+
+// contextKey is the echo context key holding the resolved tenant
+const contextKey = "orgs.tenant"
+
+// Middleware resolves the organization a request acts in from the path
+// parameter param, or from the X-Org-ID header, and verifies that the
+// caller is a member. Non-members get 404, like unknown organizations. Place
+// it after auth.Middleware.
+func Middleware(s *Service, param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := auth.PrincipalFrom(c)
+			if principal == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+
+			orgID, header := "", c.Request().Header.Get(HeaderOrg)
+			if param != "" {
+				orgID = c.Param(param)
+			}
+			switch {
+			case orgID == "" && header == "":
+				return echo.NewHTTPError(http.StatusBadRequest, "organization required")
+			case orgID == "":
+				orgID = header
+			case header != "" && header != orgID:
+				return echo.NewHTTPError(http.StatusBadRequest, HeaderOrg+" does not match the path")
+			}
+
+			tenant, err := s.Resolve(c.Request().Context(), orgID, principal.Subject)
+			if errors.Is(err, ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "organization not found")
+			}
+			if err != nil {
+				return err
+			}
+
+			c.Set(contextKey, tenant)
+			return next(c)
+		}
+	}
+}
+
+// RequireRole rejects callers whose role in the tenant is below min. Place
+// it after Middleware.
+func RequireRole(min Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if t, ok := TenantFrom(c); !ok || !t.Role().AtLeast(min) {
+				return echo.NewHTTPError(http.StatusForbidden, ErrForbidden.Error())
+			}
+			return next(c)
+		}
+	}
+}
+
+// TenantFrom returns the tenant resolved by Middleware
+func TenantFrom(c echo.Context) (Tenant, bool) {
+	t, ok := c.Get(contextKey).(Tenant)
+	return t, ok
+}
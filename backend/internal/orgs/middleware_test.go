@@ -0,0 +1,111 @@
+package orgs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/your-org/your-app/internal/auth"
+)
+
+func TestMiddleware(t *testing.T) {
+	// Arrange
+	s, _, orgID := setupOrg(t)
+	verifier := auth.StaticVerifier{
+		"alice-token":   {Subject: "alice"},
+		"carol-token":   {Subject: "carol"},
+		"mallory-token": {Subject: "mallory"},
+	}
+	echoTenant := func(c echo.Context) error {
+		t, ok := TenantFrom(c)
+		if !ok {
+			return echo.NewHTTPError(http.StatusInternalServerError, "no tenant")
+		}
+		return c.String(http.StatusOK, t.OrgID()+" "+t.UserID()+" "+string(t.Role()))
+	}
+
+	e := echo.New()
+	e.GET("/orgs/:orgId", echoTenant, auth.Middleware(verifier), Middleware(s, "orgId"))
+	e.GET("/projects", echoTenant, auth.Middleware(verifier), Middleware(s, ""))
+
+	tests := []struct {
+		name     string
+		target   string
+		token    string
+		header   string
+		want     int
+		wantBody string
+	}{
+		{name: "member by path", target: "/orgs/" + orgID, token: "alice-token", want: http.StatusOK, wantBody: orgID + " alice owner"},
+		{name: "member by header", target: "/projects", token: "carol-token", header: orgID, want: http.StatusOK, wantBody: orgID + " carol member"},
+		{name: "header matching the path", target: "/orgs/" + orgID, token: "carol-token", header: orgID, want: http.StatusOK, wantBody: orgID + " carol member"},
+		{name: "header contradicting the path", target: "/orgs/" + orgID, token: "carol-token", header: "org_other", want: http.StatusBadRequest},
+		{name: "no organization", target: "/projects", token: "carol-token", want: http.StatusBadRequest},
+		{name: "non-member", target: "/orgs/" + orgID, token: "mallory-token", want: http.StatusNotFound},
+		{name: "unknown organization", target: "/projects", token: "alice-token", header: "org_missing", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			if tt.header != "" {
+				req.Header.Set(HeaderOrg, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	// Arrange
+	s, _, orgID := setupOrg(t)
+	verifier := auth.StaticVerifier{
+		"alice-token": {Subject: "alice"},
+		"bob-token":   {Subject: "bob"},
+		"carol-token": {Subject: "carol"},
+	}
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+
+	e := echo.New()
+	e.GET("/orgs/:orgId/webhooks", ok, auth.Middleware(verifier), Middleware(s, "orgId"), RequireRole(RoleAdmin))
+	e.GET("/webhooks", ok, RequireRole(RoleAdmin))
+
+	tests := []struct {
+		name   string
+		target string
+		token  string
+		want   int
+	}{
+		{name: "owner", target: "/orgs/" + orgID + "/webhooks", token: "alice-token", want: http.StatusNoContent},
+		{name: "admin", target: "/orgs/" + orgID + "/webhooks", token: "bob-token", want: http.StatusNoContent},
+		{name: "member", target: "/orgs/" + orgID + "/webhooks", token: "carol-token", want: http.StatusForbidden},
+		{name: "no tenant", target: "/webhooks", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
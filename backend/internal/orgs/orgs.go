@@ -0,0 +1,537 @@
+// Package orgs groups users into organizations, the tenants of the API.
+//
+// An organization lives at orgs/{orgId} with one members/{userId} document
+// per member holding their role. Middleware resolves the organization a
+// request acts in and verifies the caller's membership, producing a Tenant;
+// Repository keeps tenant data below orgs/{orgId} and only accepts a
+// Tenant, so handlers cannot reach another organization's documents.
+// Members join by accepting an invitation token, which expires. Listeners
+// added with Service.OnEvent hear about membership changes and invitations.
+package orgs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/store"
+)
+
+// maxNameLength caps organization names, in characters
+const maxNameLength = 100
+
+var (
+	// ErrNotFound is returned for unknown organizations, members and
+	// invitations, and for organizations the caller is not a member of
+	ErrNotFound = errors.New("orgs: not found")
+	// ErrForbidden is returned when the caller's role does not allow the change
+	ErrForbidden = errors.New("orgs: insufficient role")
+	// ErrInvalidName is returned for empty or overlong organization names
+	ErrInvalidName = errors.New("orgs: name must be 1 to 100 characters")
+	// ErrInvalidRole is returned for roles other than owner, admin and member
+	ErrInvalidRole = errors.New("orgs: invalid role")
+	// ErrLastOwner is returned when a change would leave an organization without an owner
+	ErrLastOwner = errors.New("orgs: an organization needs at least one owner")
+	// ErrAlreadyMember is returned when accepting an invitation to an organization the caller belongs to
+	ErrAlreadyMember = errors.New("orgs: already a member")
+	// ErrInvitationExpired is returned when accepting an expired invitation
+	ErrInvitationExpired = errors.New("orgs: invitation expired")
+	// ErrEmailMismatch is returned when an invitation for another email address is accepted
+	ErrEmailMismatch = errors.New("orgs: invitation was sent to another email address")
+	// ErrSoleOwner is returned when a user to be deleted is the only owner
+	// of an organization that has other members
+	ErrSoleOwner = errors.New("orgs: sole owner of an organization with other members")
+)
+
+// Events emitted to the listeners registered with Service.OnEvent
+const (
+	// EventMemberJoined is emitted when someone accepted an invitation
+	EventMemberJoined = "org.member.joined"
+	// EventMemberUpdated is emitted when a member's role changed
+	EventMemberUpdated = "org.member.updated"
+	// EventMemberRemoved is emitted when a member left or was removed
+	EventMemberRemoved = "org.member.removed"
+	// EventInvitationCreated is emitted when someone was invited
+	EventInvitationCreated = "org.invitation.created"
+)
+
+// Listener is notified after an organization changed. data is the Member
+// or Invitation the event is about. It runs synchronously and cannot fail
+// the change, so it should only hand the event off.
+type Listener func(ctx context.Context, event, orgID string, data any)
+
+// Role is a member's role in an organization
+type Role string
+
+// Roles, from least to most privileged
+const (
+	// RoleMember can read the organization and its data
+	RoleMember Role = "member"
+	// RoleAdmin also manages invitations and members up to admin
+	RoleAdmin Role = "admin"
+	// RoleOwner also manages owners
+	RoleOwner Role = "owner"
+)
+
+var roleRank = map[Role]int{RoleMember: 1, RoleAdmin: 2, RoleOwner: 3}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// AtLeast reports whether r is as privileged as min
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[min]
+}
+
+// Org is an organization
+type Org struct {
+	ID        string    `firestore:"-" json:"id"`
+	Name      string    `firestore:"name" json:"name"`
+	CreatedBy string    `firestore:"createdBy" json:"createdBy"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// Member is a user's membership of an organization
+type Member struct {
+	OrgID    string    `firestore:"orgId" json:"orgId"`
+	UserID   string    `firestore:"userId" json:"userId"`
+	Email    string    `firestore:"email" json:"email,omitempty"`
+	Role     Role      `firestore:"role" json:"role"`
+	JoinedAt time.Time `firestore:"joinedAt" json:"joinedAt"`
+}
+
+// Membership is an organization together with the caller's role in it
+type Membership struct {
+	Org
+	Role Role `json:"role"`
+}
+
+// Invitation lets whoever holds its token join an organization with Role.
+// Its ID is the SHA-256 of the token, which is never stored.
+type Invitation struct {
+	ID        string    `firestore:"-" json:"id"`
+	OrgID     string    `firestore:"orgId" json:"orgId"`
+	Email     string    `firestore:"email" json:"email,omitempty"`
+	Role      Role      `firestore:"role" json:"role"`
+	InvitedBy string    `firestore:"invitedBy" json:"invitedBy"`
+	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `firestore:"expiresAt" json:"expiresAt"`
+}
+
+// CreatedInvitation is returned once on creation; the token is not shown again
+type CreatedInvitation struct {
+	Invitation
+	Token string `json:"token"`
+}
+
+// InvitationRequest invites someone to an organization. When Email is set,
+// only a caller with that verified email address can accept.
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  Role   `json:"role"`
+}
+
+// Tenant is the organization a request acts in and the caller's role there.
+// Only Service.Resolve creates one, so holding a Tenant proves membership.
+type Tenant struct {
+	orgID  string
+	userID string
+	role   Role
+}
+
+// OrgID returns the organization ID
+func (t Tenant) OrgID() string { return t.orgID }
+
+// UserID returns the caller's user ID
+func (t Tenant) UserID() string { return t.userID }
+
+// Role returns the caller's role in the organization
+func (t Tenant) Role() Role { return t.role }
+
+// Options tunes the service
+type Options struct {
+	// InvitationTTL is how long an invitation can be accepted
+	InvitationTTL time.Duration
+	// Clock and IDs default to the wall clock and crypto/rand
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// Service manages organizations, their members and invitations
+type Service struct {
+	store  Store
+	opts   Options
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+// NewService creates an organization service
+func NewService(st Store, opts Options, logger *zap.Logger) *Service {
+	opts.IDs = ids.OrRandom(opts.IDs)
+	return &Service{store: st, opts: opts, logger: logger, now: clock.OrSystem(opts.Clock).Now}
+}
+
+// OnEvent adds a listener for organization events. Listeners run in the
+// order they were added.
+func (s *Service) OnEvent(l Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+func (s *Service) emit(ctx context.Context, event, orgID string, data any) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		l(ctx, event, orgID, data)
+	}
+}
+
+// CreateOrg creates an organization with userID as its owner
+func (s *Service) CreateOrg(ctx context.Context, userID, email, name string) (*Membership, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, ErrInvalidName
+	}
+
+	now := s.now().UTC()
+	org := Org{ID: "org_" + s.opts.IDs.NewID(8), Name: name, CreatedBy: userID, CreatedAt: now}
+	owner := Member{OrgID: org.ID, UserID: userID, Email: email, Role: RoleOwner, JoinedAt: now}
+	if err := s.store.CreateOrg(ctx, org, owner); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("organization created", zap.String("org_id", org.ID), zap.String("user_id", userID))
+	return &Membership{Org: org, Role: RoleOwner}, nil
+}
+
+// ListOrgs returns the organizations userID belongs to
+func (s *Service) ListOrgs(ctx context.Context, userID string) ([]Membership, error) {
+	members, err := s.store.Memberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Membership, 0, len(members))
+	for _, m := range members {
+		org, err := s.store.GetOrg(ctx, m.OrgID)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Membership{Org: *org, Role: m.Role})
+	}
+	return out, nil
+}
+
+// Resolve verifies that userID belongs to orgID. Non-members get
+// ErrNotFound, so organization IDs cannot be probed.
+func (s *Service) Resolve(ctx context.Context, orgID, userID string) (Tenant, error) {
+	m, err := s.store.GetMember(ctx, orgID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return Tenant{}, ErrNotFound
+	}
+	if err != nil {
+		return Tenant{}, err
+	}
+	return Tenant{orgID: orgID, userID: userID, role: m.Role}, nil
+}
+
+// GetOrg returns the tenant's organization with the caller's role
+func (s *Service) GetOrg(ctx context.Context, t Tenant) (*Membership, error) {
+	org, err := s.store.GetOrg(ctx, t.orgID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Membership{Org: *org, Role: t.role}, nil
+}
+
+// ListMembers returns the tenant's members, longest-standing first
+func (s *Service) ListMembers(ctx context.Context, t Tenant) ([]Member, error) {
+	return s.store.ListMembers(ctx, t.orgID)
+}
+
+// UpdateMember changes a member's role. Admins manage members and admins;
+// only owners grant or take away ownership.
+func (s *Service) UpdateMember(ctx context.Context, t Tenant, userID string, role Role) (*Member, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	m, err := s.member(ctx, t.orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.role.AtLeast(RoleAdmin) || !t.role.AtLeast(m.Role) || !t.role.AtLeast(role) {
+		return nil, ErrForbidden
+	}
+	if m.Role == role {
+		return m, nil
+	}
+	if m.Role == RoleOwner {
+		if err := s.keepOwner(ctx, t.orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Role = role
+	if err := s.store.SaveMember(ctx, *m); err != nil {
+		return nil, err
+	}
+	s.logger.Info("member role changed",
+		zap.String("org_id", t.orgID), zap.String("user_id", userID), zap.String("role", string(role)), zap.String("by", t.userID))
+	s.emit(ctx, EventMemberUpdated, t.orgID, *m)
+	return m, nil
+}
+
+// RemoveMember removes a member. Anyone may leave; removing others follows
+// the rules of UpdateMember.
+func (s *Service) RemoveMember(ctx context.Context, t Tenant, userID string) error {
+	m, err := s.member(ctx, t.orgID, userID)
+	if err != nil {
+		return err
+	}
+	if userID != t.userID && (!t.role.AtLeast(RoleAdmin) || !t.role.AtLeast(m.Role)) {
+		return ErrForbidden
+	}
+	if m.Role == RoleOwner {
+		if err := s.keepOwner(ctx, t.orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.DeleteMember(ctx, t.orgID, userID); err != nil {
+		return err
+	}
+	s.logger.Info("member removed", zap.String("org_id", t.orgID), zap.String("user_id", userID), zap.String("by", t.userID))
+	s.emit(ctx, EventMemberRemoved, t.orgID, *m)
+	return nil
+}
+
+// CreateInvitation invites someone with a role no higher than the caller's.
+// The token is only returned here.
+func (s *Service) CreateInvitation(ctx context.Context, t Tenant, req InvitationRequest) (*CreatedInvitation, error) {
+	if !req.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if !t.role.AtLeast(RoleAdmin) || !t.role.AtLeast(req.Role) {
+		return nil, ErrForbidden
+	}
+
+	token := s.opts.IDs.NewID(32)
+	now := s.now().UTC()
+	inv := Invitation{
+		ID:        invitationID(token),
+		OrgID:     t.orgID,
+		Email:     strings.TrimSpace(req.Email),
+		Role:      req.Role,
+		InvitedBy: t.userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.opts.InvitationTTL),
+	}
+	if err := s.store.CreateInvitation(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("invitation created", zap.String("org_id", t.orgID), zap.String("invitation_id", inv.ID), zap.String("by", t.userID))
+	s.emit(ctx, EventInvitationCreated, t.orgID, inv)
+	return &CreatedInvitation{Invitation: inv, Token: token}, nil
+}
+
+// ListInvitations returns the tenant's open invitations, including expired ones
+func (s *Service) ListInvitations(ctx context.Context, t Tenant) ([]Invitation, error) {
+	if !t.role.AtLeast(RoleAdmin) {
+		return nil, ErrForbidden
+	}
+	return s.store.ListInvitations(ctx, t.orgID)
+}
+
+// RevokeInvitation deletes an invitation so its token stops working
+func (s *Service) RevokeInvitation(ctx context.Context, t Tenant, id string) error {
+	if !t.role.AtLeast(RoleAdmin) {
+		return ErrForbidden
+	}
+	if _, err := s.invitation(ctx, t.orgID, id); err != nil {
+		return err
+	}
+	return s.store.DeleteInvitation(ctx, t.orgID, id)
+}
+
+// AcceptInvitation adds userID to orgID with the role of the invitation
+// token. Each token can be used once. email must be the caller's verified
+// address, or empty, which only accepts invitations not addressed to anyone.
+func (s *Service) AcceptInvitation(ctx context.Context, orgID, userID, email, token string) (*Member, error) {
+	inv, err := s.invitation(ctx, orgID, invitationID(token))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if !now.Before(inv.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	if inv.Email != "" && !strings.EqualFold(inv.Email, email) {
+		return nil, ErrEmailMismatch
+	}
+
+	m := Member{OrgID: orgID, UserID: userID, Email: email, Role: inv.Role, JoinedAt: now}
+	err = s.store.AcceptInvitation(ctx, *inv, m)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("invitation accepted", zap.String("org_id", orgID), zap.String("invitation_id", inv.ID), zap.String("user_id", userID))
+	s.emit(ctx, EventMemberJoined, orgID, m)
+	return &m, nil
+}
+
+// Memberships returns userID's memberships, for exports of their account
+func (s *Service) Memberships(ctx context.Context, userID string) ([]Member, error) {
+	return s.store.Memberships(ctx, userID)
+}
+
+// CheckRemoveUser returns an error wrapping ErrSoleOwner while userID is
+// the only owner of an organization with other members, who would be left
+// without an owner. The user has to hand over ownership first.
+func (s *Service) CheckRemoveUser(ctx context.Context, userID string) error {
+	memberships, err := s.store.Memberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, m := range memberships {
+		if m.Role != RoleOwner {
+			continue
+		}
+		members, err := s.store.ListMembers(ctx, m.OrgID)
+		if err != nil {
+			return err
+		}
+		if len(members) > 1 && successor(members, userID) != nil {
+			return fmt.Errorf("%w: make another member of %s an owner first", ErrSoleOwner, m.OrgID)
+		}
+	}
+	return nil
+}
+
+// RemoveUser removes userID from every organization when their account is
+// purged and reports how many member documents were deleted. Organizations
+// left without members are deleted. Where the user is still the only owner,
+// because the other owners left during the grace period, ownership passes
+// to the longest-standing admin, or member, so the purge is never stuck.
+func (s *Service) RemoveUser(ctx context.Context, userID string) (int, error) {
+	memberships, err := s.store.Memberships(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, m := range memberships {
+		members, err := s.store.ListMembers(ctx, m.OrgID)
+		if err != nil {
+			return removed, err
+		}
+		if len(members) == 1 {
+			if err := s.store.DeleteOrg(ctx, m.OrgID); err != nil {
+				return removed, err
+			}
+			s.logger.Info("organization deleted with its last member", zap.String("org_id", m.OrgID), zap.String("user_id", userID))
+			removed++
+			continue
+		}
+
+		if next := successor(members, userID); m.Role == RoleOwner && next != nil {
+			next.Role = RoleOwner
+			if err := s.store.SaveMember(ctx, *next); err != nil {
+				return removed, err
+			}
+			s.logger.Info("ownership passed on", zap.String("org_id", m.OrgID), zap.String("from", userID), zap.String("to", next.UserID))
+			s.emit(ctx, EventMemberUpdated, m.OrgID, *next)
+		}
+		if err := s.store.DeleteMember(ctx, m.OrgID, userID); err != nil {
+			return removed, err
+		}
+		s.emit(ctx, EventMemberRemoved, m.OrgID, m)
+		removed++
+	}
+	return removed, nil
+}
+
+// successor returns the member to make owner when userID, an owner, leaves:
+// nil if another owner remains, otherwise the longest-standing admin or,
+// without admins, member. members must be longest-standing first.
+func successor(members []Member, userID string) *Member {
+	var next *Member
+	for i, m := range members {
+		switch {
+		case m.UserID == userID:
+		case m.Role == RoleOwner:
+			return nil
+		case next == nil || (m.Role == RoleAdmin && next.Role != RoleAdmin):
+			next = &members[i]
+		}
+	}
+	return next
+}
+
+func (s *Service) member(ctx context.Context, orgID, userID string) (*Member, error) {
+	m, err := s.store.GetMember(ctx, orgID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+func (s *Service) invitation(ctx context.Context, orgID, id string) (*Invitation, error) {
+	inv, err := s.store.GetInvitation(ctx, orgID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return inv, err
+}
+
+// keepOwner fails unless orgID has another owner besides the one about to
+// be demoted or removed
+func (s *Service) keepOwner(ctx context.Context, orgID string) error {
+	members, err := s.store.ListMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// invitationID derives the stored ID of an invitation from its token
+func invitationID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
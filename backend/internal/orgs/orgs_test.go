@@ -0,0 +1,339 @@
+package orgs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// setupOrg creates acme owned by alice, with bob as admin and carol as member
+func setupOrg(t *testing.T) (*Service, *time.Time, string) {
+	t.Helper()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := NewService(NewMemoryStore(), Options{InvitationTTL: 24 * time.Hour}, zap.NewNop())
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	org, err := s.CreateOrg(ctx, "alice", "alice@example.com", " Acme ")
+	require.NoError(t, err)
+	for _, m := range []struct {
+		user string
+		role Role
+	}{{"bob", RoleAdmin}, {"carol", RoleMember}} {
+		now = now.Add(time.Minute)
+		require.NoError(t, s.store.SaveMember(ctx, Member{OrgID: org.ID, UserID: m.user, Role: m.role, JoinedAt: now}))
+	}
+	return s, &now, org.ID
+}
+
+func resolve(t *testing.T, s *Service, orgID, userID string) Tenant {
+	t.Helper()
+	tenant, err := s.Resolve(context.Background(), orgID, userID)
+	require.NoError(t, err)
+	return tenant
+}
+
+func TestService_CreateOrg(t *testing.T) {
+	// Arrange
+	s, _, orgID := setupOrg(t)
+	ctx := context.Background()
+
+	// Act
+	owned, err := s.ListOrgs(ctx, "alice")
+	require.NoError(t, err)
+	_, invalid := s.CreateOrg(ctx, "alice", "", "   ")
+	_, stranger := s.Resolve(ctx, orgID, "mallory")
+
+	// Assert
+	require.Len(t, owned, 1)
+	assert.Equal(t, "Acme", owned[0].Name)
+	assert.Equal(t, RoleOwner, owned[0].Role)
+	assert.Equal(t, "alice", owned[0].CreatedBy)
+	assert.ErrorIs(t, invalid, ErrInvalidName)
+	assert.ErrorIs(t, stranger, ErrNotFound)
+}
+
+func TestService_UpdateMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller string
+		target string
+		role   Role
+		want   error
+	}{
+		{name: "owner promotes member to owner", caller: "alice", target: "carol", role: RoleOwner},
+		{name: "admin promotes member to admin", caller: "bob", target: "carol", role: RoleAdmin},
+		{name: "admin grants ownership", caller: "bob", target: "carol", role: RoleOwner, want: ErrForbidden},
+		{name: "admin demotes owner", caller: "bob", target: "alice", role: RoleMember, want: ErrForbidden},
+		{name: "member promotes member", caller: "carol", target: "carol", role: RoleAdmin, want: ErrForbidden},
+		{name: "last owner steps down", caller: "alice", target: "alice", role: RoleAdmin, want: ErrLastOwner},
+		{name: "unknown role", caller: "alice", target: "carol", role: "superuser", want: ErrInvalidRole},
+		{name: "unknown member", caller: "alice", target: "mallory", role: RoleMember, want: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s, _, orgID := setupOrg(t)
+
+			// Act
+			m, err := s.UpdateMember(context.Background(), resolve(t, s, orgID, tt.caller), tt.target, tt.role)
+
+			// Assert
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.role, m.Role)
+			assert.Equal(t, tt.role, resolve(t, s, orgID, tt.target).Role())
+		})
+	}
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller string
+		target string
+		want   error
+	}{
+		{name: "member leaves", caller: "carol", target: "carol"},
+		{name: "admin removes member", caller: "bob", target: "carol"},
+		{name: "member removes admin", caller: "carol", target: "bob", want: ErrForbidden},
+		{name: "admin removes owner", caller: "bob", target: "alice", want: ErrForbidden},
+		{name: "last owner leaves", caller: "alice", target: "alice", want: ErrLastOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s, _, orgID := setupOrg(t)
+
+			// Act
+			err := s.RemoveMember(context.Background(), resolve(t, s, orgID, tt.caller), tt.target)
+
+			// Assert
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				return
+			}
+			require.NoError(t, err)
+			_, err = s.Resolve(context.Background(), orgID, tt.target)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestService_CheckRemoveUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		owners []string
+		want   error
+	}{
+		{name: "sole owner with members", user: "alice", want: ErrSoleOwner},
+		{name: "one of two owners", user: "alice", owners: []string{"bob"}},
+		{name: "admin", user: "bob"},
+		{name: "not a member", user: "mallory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s, _, orgID := setupOrg(t)
+			ctx := context.Background()
+			for _, id := range tt.owners {
+				m, err := s.store.GetMember(ctx, orgID, id)
+				require.NoError(t, err)
+				m.Role = RoleOwner
+				require.NoError(t, s.store.SaveMember(ctx, *m))
+			}
+
+			// Act
+			err := s.CheckRemoveUser(ctx, tt.user)
+
+			// Assert
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_RemoveUser(t *testing.T) {
+	// Arrange: alice also owns solo, where she is the only member
+	s, now, orgID := setupOrg(t)
+	ctx := context.Background()
+	*now = now.Add(time.Hour)
+	solo, err := s.CreateOrg(ctx, "alice", "alice@example.com", "Solo")
+	require.NoError(t, err)
+
+	// Act
+	removed, err := s.RemoveUser(ctx, "alice")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	memberships, err := s.Memberships(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+
+	_, err = s.store.GetOrg(ctx, solo.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound, "organization without members is deleted")
+	assert.Equal(t, RoleOwner, resolve(t, s, orgID, "bob").Role(), "longest-standing admin takes over")
+	assert.Equal(t, RoleMember, resolve(t, s, orgID, "carol").Role())
+}
+
+func TestService_Invitations(t *testing.T) {
+	// Arrange
+	s, now, orgID := setupOrg(t)
+	ctx := context.Background()
+	bob := resolve(t, s, orgID, "bob")
+
+	// Act
+	created, err := s.CreateInvitation(ctx, bob, InvitationRequest{Email: "Dave@example.com", Role: RoleAdmin})
+	require.NoError(t, err)
+	listed, err := s.ListInvitations(ctx, bob)
+	require.NoError(t, err)
+	wrongEmail := func() error {
+		_, err := s.AcceptInvitation(ctx, orgID, "erin", "erin@example.com", created.Token)
+		return err
+	}()
+	*now = now.Add(time.Hour)
+	m, err := s.AcceptInvitation(ctx, orgID, "dave", "dave@example.com", created.Token)
+	require.NoError(t, err)
+	_, reused := s.AcceptInvitation(ctx, orgID, "dave", "dave@example.com", created.Token)
+
+	// Assert
+	assert.NotEmpty(t, created.Token)
+	assert.Equal(t, invitationID(created.Token), created.ID)
+	assert.NotContains(t, created.ID, created.Token)
+	require.Len(t, listed, 1)
+	assert.Equal(t, created.Invitation, listed[0])
+	assert.ErrorIs(t, wrongEmail, ErrEmailMismatch)
+	assert.Equal(t, Member{OrgID: orgID, UserID: "dave", Email: "dave@example.com", Role: RoleAdmin, JoinedAt: *now}, *m)
+	assert.Equal(t, RoleAdmin, resolve(t, s, orgID, "dave").Role())
+	assert.ErrorIs(t, reused, ErrNotFound, "tokens are single-use")
+}
+
+func TestService_Invitations_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller string
+		role   Role
+		accept func(s *Service, now *time.Time, orgID, token string) error
+		want   error
+	}{
+		{name: "member invites", caller: "carol", role: RoleMember, want: ErrForbidden},
+		{name: "admin invites owner", caller: "bob", role: RoleOwner, want: ErrForbidden},
+		{name: "unknown role", caller: "alice", role: "guest", want: ErrInvalidRole},
+		{
+			name: "expired", caller: "alice", role: RoleMember,
+			accept: func(s *Service, now *time.Time, orgID, token string) error {
+				*now = now.Add(24 * time.Hour)
+				_, err := s.AcceptInvitation(context.Background(), orgID, "dave", "", token)
+				return err
+			},
+			want: ErrInvitationExpired,
+		},
+		{
+			name: "already a member", caller: "alice", role: RoleAdmin,
+			accept: func(s *Service, _ *time.Time, orgID, token string) error {
+				_, err := s.AcceptInvitation(context.Background(), orgID, "carol", "", token)
+				return err
+			},
+			want: ErrAlreadyMember,
+		},
+		{
+			name: "token of another organization", caller: "alice", role: RoleMember,
+			accept: func(s *Service, _ *time.Time, _, token string) error {
+				other, err := s.CreateOrg(context.Background(), "mallory", "", "Other")
+				require.NoError(t, err)
+				_, err = s.AcceptInvitation(context.Background(), other.ID, "dave", "", token)
+				return err
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "addressed to a caller without verified email", caller: "alice", role: RoleMember,
+			accept: func(s *Service, _ *time.Time, orgID, _ string) error {
+				addressed, err := s.CreateInvitation(context.Background(), resolve(t, s, orgID, "alice"), InvitationRequest{Email: "dave@example.com", Role: RoleMember})
+				require.NoError(t, err)
+				_, err = s.AcceptInvitation(context.Background(), orgID, "dave", "", addressed.Token)
+				return err
+			},
+			want: ErrEmailMismatch,
+		},
+		{
+			name: "revoked", caller: "alice", role: RoleMember,
+			accept: func(s *Service, _ *time.Time, orgID, token string) error {
+				require.NoError(t, s.RevokeInvitation(context.Background(), resolve(t, s, orgID, "bob"), invitationID(token)))
+				_, err := s.AcceptInvitation(context.Background(), orgID, "dave", "", token)
+				return err
+			},
+			want: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s, now, orgID := setupOrg(t)
+
+			// Act
+			created, err := s.CreateInvitation(context.Background(), resolve(t, s, orgID, tt.caller), InvitationRequest{Role: tt.role})
+			if err == nil && tt.accept != nil {
+				err = tt.accept(s, now, orgID, created.Token)
+			}
+
+			// Assert
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestService_OnEvent(t *testing.T) {
+	// Arrange
+	s, _, orgID := setupOrg(t)
+	ctx := context.Background()
+	type event struct {
+		name, orgID, userID string
+	}
+	var got []event
+	s.OnEvent(func(_ context.Context, name, orgID string, data any) {
+		e := event{name: name, orgID: orgID}
+		if m, ok := data.(Member); ok {
+			e.userID = m.UserID
+		}
+		got = append(got, e)
+	})
+	alice := resolve(t, s, orgID, "alice")
+
+	// Act
+	created, err := s.CreateInvitation(ctx, alice, InvitationRequest{Role: RoleMember})
+	require.NoError(t, err)
+	_, err = s.AcceptInvitation(ctx, orgID, "dave", "", created.Token)
+	require.NoError(t, err)
+	_, err = s.UpdateMember(ctx, alice, "dave", RoleAdmin)
+	require.NoError(t, err)
+	require.NoError(t, s.RemoveMember(ctx, alice, "dave"))
+	_, err = s.UpdateMember(ctx, alice, "carol", RoleMember)
+	require.NoError(t, err)
+
+	// Assert: unchanged roles emit nothing
+	assert.Equal(t, []event{
+		{name: EventInvitationCreated, orgID: orgID},
+		{name: EventMemberJoined, orgID: orgID, userID: "dave"},
+		{name: EventMemberUpdated, orgID: orgID, userID: "dave"},
+		{name: EventMemberRemoved, orgID: orgID, userID: "dave"},
+	}, got)
+}
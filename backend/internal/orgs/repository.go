@@ -0,0 +1,183 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNoTenant is returned by repositories given the zero Tenant
+	ErrNoTenant = errors.New("orgs: no tenant")
+	// ErrInvalidID is returned by repositories for empty IDs or IDs containing a slash
+	ErrInvalidID = errors.New("orgs: invalid document id")
+)
+
+// Backend stores the documents of repositories. Its methods are unexported,
+// so the only way to reach tenant data is through a Repository and a
+// Tenant.
+type Backend interface {
+	get(ctx context.Context, orgID, collection, id string, dst any) error
+	set(ctx context.Context, orgID, collection, id string, v any) error
+	delete(ctx context.Context, orgID, collection, id string) error
+	list(ctx context.Context, orgID, collection string) ([]rawDocument, error)
+}
+
+// rawDocument is a listed document not yet decoded into its type
+type rawDocument struct {
+	id     string
+	decode func(dst any) error
+}
+
+// Document is a repository document with its ID
+type Document[T any] struct {
+	ID   string
+	Data T
+}
+
+// Repository stores documents of type T in one collection of every
+// organization, orgs/{orgId}/{collection}/{id}. Every method takes the
+// Tenant a request was resolved to and only touches that organization.
+type Repository[T any] struct {
+	backend    Backend
+	collection string
+}
+
+// NewRepository creates a repository for collection, which must be a single
+// path segment and not one the service keeps members or invitations in
+func NewRepository[T any](backend Backend, collection string) (*Repository[T], error) {
+	if collection == "" || strings.Contains(collection, "/") {
+		return nil, fmt.Errorf("orgs: invalid collection %q", collection)
+	}
+	if collection == membersCollection || collection == invitationsCollection {
+		return nil, fmt.Errorf("orgs: collection %q is reserved", collection)
+	}
+	return &Repository[T]{backend: backend, collection: collection}, nil
+}
+
+func (r *Repository[T]) check(t Tenant, id string) error {
+	if t.orgID == "" {
+		return ErrNoTenant
+	}
+	if id == "" || strings.Contains(id, "/") {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+// Get returns the tenant's document id, or ErrNotFound
+func (r *Repository[T]) Get(ctx context.Context, t Tenant, id string) (*T, error) {
+	if err := r.check(t, id); err != nil {
+		return nil, err
+	}
+	var v T
+	if err := r.backend.get(ctx, t.orgID, r.collection, id, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Put creates or replaces the tenant's document id
+func (r *Repository[T]) Put(ctx context.Context, t Tenant, id string, v T) error {
+	if err := r.check(t, id); err != nil {
+		return err
+	}
+	return r.backend.set(ctx, t.orgID, r.collection, id, v)
+}
+
+// Delete removes the tenant's document id; a missing document is not an error
+func (r *Repository[T]) Delete(ctx context.Context, t Tenant, id string) error {
+	if err := r.check(t, id); err != nil {
+		return err
+	}
+	return r.backend.delete(ctx, t.orgID, r.collection, id)
+}
+
+// List returns the tenant's documents, ordered by ID
+func (r *Repository[T]) List(ctx context.Context, t Tenant) ([]Document[T], error) {
+	if t.orgID == "" {
+		return nil, ErrNoTenant
+	}
+	raw, err := r.backend.list(ctx, t.orgID, r.collection)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Document[T], 0, len(raw))
+	for _, doc := range raw {
+		var v T
+		if err := doc.decode(&v); err != nil {
+			return nil, fmt.Errorf("orgs: decode %s/%s: %w", r.collection, doc.id, err)
+		}
+		out = append(out, Document[T]{ID: doc.id, Data: v})
+	}
+	return out, nil
+}
+
+// memoryBackend is an in-process Backend for local development and tests
+type memoryBackend struct {
+	mu   sync.Mutex
+	docs map[string]map[string]any // by orgId/collection, then id
+}
+
+// NewMemoryBackend creates an empty in-memory repository backend
+func NewMemoryBackend() Backend {
+	return &memoryBackend{docs: make(map[string]map[string]any)}
+}
+
+func (b *memoryBackend) get(_ context.Context, orgID, collection, id string, dst any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.docs[key(orgID, collection)][id]
+	if !ok {
+		return ErrNotFound
+	}
+	return assign(dst, v)
+}
+
+func (b *memoryBackend) set(_ context.Context, orgID, collection, id string, v any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	docs, ok := b.docs[key(orgID, collection)]
+	if !ok {
+		docs = make(map[string]any)
+		b.docs[key(orgID, collection)] = docs
+	}
+	docs[id] = v
+	return nil
+}
+
+func (b *memoryBackend) delete(_ context.Context, orgID, collection, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.docs[key(orgID, collection)], id)
+	return nil
+}
+
+func (b *memoryBackend) list(_ context.Context, orgID, collection string) ([]rawDocument, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	docs := b.docs[key(orgID, collection)]
+	out := make([]rawDocument, 0, len(docs))
+	for id, v := range docs {
+		out = append(out, rawDocument{id: id, decode: func(dst any) error { return assign(dst, v) }})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out, nil
+}
+
+// assign copies v into the value dst points to, as DataTo does for
+// Firestore snapshots
+func assign(dst, v any) error {
+	target := reflect.ValueOf(dst).Elem()
+	value := reflect.ValueOf(v)
+	if !value.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("orgs: cannot decode %s into %s", value.Type(), target.Type())
+	}
+	target.Set(value)
+	return nil
+}
@@ -0,0 +1,86 @@
+package orgs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type project struct {
+	Name string `firestore:"name"`
+}
+
+func TestRepository_IsolatesTenants(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo, err := NewRepository[project](NewMemoryBackend(), "projects")
+	require.NoError(t, err)
+	acme := Tenant{orgID: "acme", userID: "alice", role: RoleOwner}
+	globex := Tenant{orgID: "globex", userID: "bob", role: RoleOwner}
+	require.NoError(t, repo.Put(ctx, acme, "p2", project{Name: "Rockets"}))
+	require.NoError(t, repo.Put(ctx, acme, "p1", project{Name: "Anvils"}))
+
+	// Act
+	own, err := repo.Get(ctx, acme, "p1")
+	require.NoError(t, err)
+	listed, err := repo.List(ctx, acme)
+	require.NoError(t, err)
+	_, otherGet := repo.Get(ctx, globex, "p1")
+	otherList, err := repo.List(ctx, globex)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, globex, "p1"))
+	kept, err := repo.Get(ctx, acme, "p1")
+
+	// Assert
+	assert.Equal(t, project{Name: "Anvils"}, *own)
+	assert.Equal(t, []Document[project]{
+		{ID: "p1", Data: project{Name: "Anvils"}},
+		{ID: "p2", Data: project{Name: "Rockets"}},
+	}, listed)
+	assert.ErrorIs(t, otherGet, ErrNotFound)
+	assert.Empty(t, otherList)
+	require.NoError(t, err, "another tenant's delete does not reach acme")
+	assert.Equal(t, project{Name: "Anvils"}, *kept)
+}
+
+func TestRepository_Rejects(t *testing.T) {
+	ctx := context.Background()
+	acme := Tenant{orgID: "acme", userID: "alice", role: RoleOwner}
+	repo, err := NewRepository[project](NewMemoryBackend(), "projects")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		act  func() error
+		want error
+	}{
+		{name: "zero tenant", act: func() error { return repo.Put(ctx, Tenant{}, "p1", project{}) }, want: ErrNoTenant},
+		{name: "zero tenant list", act: func() error { _, err := repo.List(ctx, Tenant{}); return err }, want: ErrNoTenant},
+		{name: "id escaping the collection", act: func() error { _, err := repo.Get(ctx, acme, "p1/tasks/t1"); return err }, want: ErrInvalidID},
+		{name: "empty id", act: func() error { return repo.Delete(ctx, acme, "") }, want: ErrInvalidID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.act()
+
+			// Assert
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestNewRepository_ReservedCollections(t *testing.T) {
+	for _, collection := range []string{"", "members", "invitations", "projects/p1/tasks"} {
+		t.Run(collection, func(t *testing.T) {
+			// Act
+			_, err := NewRepository[project](NewMemoryBackend(), collection)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
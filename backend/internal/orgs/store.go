@@ -0,0 +1,237 @@
+package orgs
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists organizations, members and invitations
+type Store interface {
+	// CreateOrg creates org together with its first owner
+	CreateOrg(ctx context.Context, org Org, owner Member) error
+	// GetOrg returns store.ErrNotFound for unknown organizations
+	GetOrg(ctx context.Context, id string) (*Org, error)
+	// DeleteOrg deletes the organization with its members, invitations and
+	// tenant data
+	DeleteOrg(ctx context.Context, id string) error
+
+	// GetMember returns store.ErrNotFound if userID is not a member of orgID
+	GetMember(ctx context.Context, orgID, userID string) (*Member, error)
+	// ListMembers returns the members of orgID, longest-standing first
+	ListMembers(ctx context.Context, orgID string) ([]Member, error)
+	// Memberships returns the memberships of userID across organizations
+	Memberships(ctx context.Context, userID string) ([]Member, error)
+	// ScanMembers returns up to limit members of every organization,
+	// ordered by organization and user ID, starting after the member
+	// afterUserID of afterOrgID; empty IDs start at the beginning
+	ScanMembers(ctx context.Context, afterOrgID, afterUserID string, limit int) ([]Member, error)
+	SaveMember(ctx context.Context, m Member) error
+	DeleteMember(ctx context.Context, orgID, userID string) error
+
+	CreateInvitation(ctx context.Context, inv Invitation) error
+	// GetInvitation returns store.ErrNotFound for unknown invitations
+	GetInvitation(ctx context.Context, orgID, id string) (*Invitation, error)
+	// ListInvitations returns the invitations of orgID, oldest first
+	ListInvitations(ctx context.Context, orgID string) ([]Invitation, error)
+	DeleteInvitation(ctx context.Context, orgID, id string) error
+	// AcceptInvitation atomically deletes inv and adds m. It returns
+	// store.ErrNotFound if inv was already used or revoked, and
+	// ErrAlreadyMember if m's user is a member.
+	AcceptInvitation(ctx context.Context, inv Invitation, m Member) error
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu          sync.Mutex
+	orgs        map[string]Org
+	members     map[string]Member
+	invitations map[string]Invitation
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orgs:        make(map[string]Org),
+		members:     make(map[string]Member),
+		invitations: make(map[string]Invitation),
+	}
+}
+
+func key(orgID, id string) string {
+	return orgID + "/" + id
+}
+
+// CreateOrg implements Store
+func (s *MemoryStore) CreateOrg(_ context.Context, org Org, owner Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org.ID] = org
+	s.members[key(org.ID, owner.UserID)] = owner
+	return nil
+}
+
+// GetOrg implements Store
+func (s *MemoryStore) GetOrg(_ context.Context, id string) (*Org, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &org, nil
+}
+
+// DeleteOrg implements Store. Tenant data lives in the memory Backend,
+// which has no link to the store, and is left alone.
+func (s *MemoryStore) DeleteOrg(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.orgs, id)
+	for k, m := range s.members {
+		if m.OrgID == id {
+			delete(s.members, k)
+		}
+	}
+	for k, inv := range s.invitations {
+		if inv.OrgID == id {
+			delete(s.invitations, k)
+		}
+	}
+	return nil
+}
+
+// GetMember implements Store
+func (s *MemoryStore) GetMember(_ context.Context, orgID, userID string) (*Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.members[key(orgID, userID)]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &m, nil
+}
+
+// ListMembers implements Store
+func (s *MemoryStore) ListMembers(_ context.Context, orgID string) ([]Member, error) {
+	return s.filterMembers(func(m Member) bool { return m.OrgID == orgID }), nil
+}
+
+// Memberships implements Store
+func (s *MemoryStore) Memberships(_ context.Context, userID string) ([]Member, error) {
+	return s.filterMembers(func(m Member) bool { return m.UserID == userID }), nil
+}
+
+func (s *MemoryStore) filterMembers(keep func(Member) bool) []Member {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Member
+	for _, m := range s.members {
+		if keep(m) {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].JoinedAt.Before(out[j].JoinedAt) })
+	return out
+}
+
+// ScanMembers implements Store
+func (s *MemoryStore) ScanMembers(_ context.Context, afterOrgID, afterUserID string, limit int) ([]Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	after := ""
+	if afterOrgID != "" {
+		after = key(afterOrgID, afterUserID)
+	}
+	keys := make([]string, 0, len(s.members))
+	for k := range s.members {
+		if k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	out := make([]Member, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, s.members[k])
+	}
+	return out, nil
+}
+
+// SaveMember implements Store
+func (s *MemoryStore) SaveMember(_ context.Context, m Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[key(m.OrgID, m.UserID)] = m
+	return nil
+}
+
+// DeleteMember implements Store
+func (s *MemoryStore) DeleteMember(_ context.Context, orgID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.members, key(orgID, userID))
+	return nil
+}
+
+// CreateInvitation implements Store
+func (s *MemoryStore) CreateInvitation(_ context.Context, inv Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invitations[key(inv.OrgID, inv.ID)] = inv
+	return nil
+}
+
+// GetInvitation implements Store
+func (s *MemoryStore) GetInvitation(_ context.Context, orgID, id string) (*Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.invitations[key(orgID, id)]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &inv, nil
+}
+
+// ListInvitations implements Store
+func (s *MemoryStore) ListInvitations(_ context.Context, orgID string) ([]Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Invitation
+	for _, inv := range s.invitations {
+		if inv.OrgID == orgID {
+			out = append(out, inv)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// DeleteInvitation implements Store
+func (s *MemoryStore) DeleteInvitation(_ context.Context, orgID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.invitations, key(orgID, id))
+	return nil
+}
+
+// AcceptInvitation implements Store
+func (s *MemoryStore) AcceptInvitation(_ context.Context, inv Invitation, m Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.invitations[key(inv.OrgID, inv.ID)]; !ok {
+		return store.ErrNotFound
+	}
+	if _, ok := s.members[key(m.OrgID, m.UserID)]; ok {
+		return ErrAlreadyMember
+	}
+	delete(s.invitations, key(inv.OrgID, inv.ID))
+	s.members[key(m.OrgID, m.UserID)] = m
+	return nil
+}
@@ -0,0 +1,170 @@
+package rulestest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// firestore is shared by every test; nil when no emulator is available
+var firestore *Firestore
+
+func TestMain(m *testing.M) {
+	emu, err := StartFirestore(context.Background())
+	switch {
+	case errors.Is(err, ErrUnavailable):
+		fmt.Fprintln(os.Stderr, "skipping firestore rules tests:", err)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	default:
+		firestore = emu
+	}
+
+	code := m.Run()
+	if firestore != nil {
+		firestore.Stop()
+	}
+	os.Exit(code)
+}
+
+func setupFirestore(t *testing.T) *Firestore {
+	t.Helper()
+	if firestore == nil {
+		t.Skip("firestore emulator unavailable")
+	}
+	ctx := context.Background()
+	rules, err := RulesFile("firestore.rules")
+	require.NoError(t, err)
+	require.NoError(t, firestore.LoadRules(ctx, rules))
+	require.NoError(t, firestore.Reset(ctx))
+
+	// alice's profile plus documents only the backend writes
+	seeds := map[string]map[string]string{
+		"users/alice":              {"displayName": "Alice"},
+		"users/alice/files/f1":     {"name": "a.png"},
+		"users/alice/webhooks/w1":  {"url": "https://example.com/hook"},
+		"users/alice/exports/e1":   {"status": "ready"},
+		"users/dave":               {"displayName": "Dave", "deletedAt": "2026-05-01T00:00:00Z"},
+		"accountDeletions/alice":   {"status": "scheduled"},
+		"orgs/acme":                {"name": "Acme"},
+		"orgs/acme/members/alice":  {"role": "owner", "userId": "alice"},
+		"orgs/acme/invitations/i1": {"role": "member"},
+		"orgs/acme/webhooks/we1":   {"secret": "whsec_1"},
+		"orgs/acme/projects/p1":    {"name": "Rockets"},
+		"system/maintenance":       {"mode": "off"},
+		"cronLocks/prune_history":  {"owner": "instance-1"},
+		"cronExecutions/e1":        {"job": "prune_history"},
+		"auditEvents/a1":           {"action": "files.delete"},
+		"apiKeys/k1":               {"ownerId": "alice"},
+		"jobDeadLetters/d1":        {"type": "webhooks.deliver"},
+		"adminBackfills/b1":        {"cursor": "acme/alice"},
+		"users/alice/unknown/doc1": {"x": "y"},
+	}
+	for path, data := range seeds {
+		require.NoError(t, firestore.Seed(ctx, path, data))
+	}
+	return firestore
+}
+
+// TestFirestoreRules pins the access model of firestore.rules. A scenario
+// that starts passing as allowed means a rules change opened access: update
+// the expectation only if that was intended.
+func TestFirestoreRules(t *testing.T) {
+	profile := map[string]string{"displayName": "Changed"}
+	tests := []struct {
+		name      string
+		principal Principal
+		op        Operation
+		path      string
+		data      map[string]string // written instead of profile when set
+		allowed   bool
+	}{
+		// users/{userId}: readable when signed in, writable by its owner only
+		{name: "signed out reads profile", principal: SignedOut, op: OpGet, path: "users/alice"},
+		{name: "signed out lists profiles", principal: SignedOut, op: OpList, path: "users"},
+		{name: "anonymous reads profile", principal: Anonymous("anon-1"), op: OpGet, path: "users/alice", allowed: true},
+		{name: "other user reads profile", principal: User("bob"), op: OpGet, path: "users/alice", allowed: true},
+		{name: "other user lists profiles", principal: User("bob"), op: OpList, path: "users", allowed: true},
+		{name: "owner reads profile", principal: User("alice"), op: OpGet, path: "users/alice", allowed: true},
+		{name: "owner creates profile", principal: User("carol"), op: OpCreate, path: "users/carol", allowed: true},
+		{name: "anonymous owner creates profile", principal: Anonymous("anon-1"), op: OpCreate, path: "users/anon-1", allowed: true},
+		{name: "other user creates profile", principal: User("bob"), op: OpCreate, path: "users/carol"},
+		{name: "signed out creates profile", principal: SignedOut, op: OpCreate, path: "users/carol"},
+		{name: "owner updates profile", principal: User("alice"), op: OpUpdate, path: "users/alice", allowed: true},
+		{name: "other user updates profile", principal: User("bob"), op: OpUpdate, path: "users/alice"},
+		{name: "anonymous updates profile", principal: Anonymous("anon-1"), op: OpUpdate, path: "users/alice"},
+		{name: "signed out updates profile", principal: SignedOut, op: OpUpdate, path: "users/alice"},
+		{name: "owner deletes profile", principal: User("alice"), op: OpDelete, path: "users/alice"},
+		{name: "other user deletes profile", principal: User("bob"), op: OpDelete, path: "users/alice"},
+
+		// Soft-delete markers are written by the backend only
+		{name: "owner sets deletedAt", principal: User("alice"), op: OpUpdate, path: "users/alice", data: map[string]string{"deletedAt": "now"}},
+		{name: "owner creates profile with purgeAfter", principal: User("carol"), op: OpCreate, path: "users/carol", data: map[string]string{"purgeAfter": "later"}},
+		{name: "owner clears deletedAt", principal: User("dave"), op: OpUpdate, path: "users/dave", data: map[string]string{"displayName": "Dave"}},
+
+		// Backend-only data stays behind the default deny, even for its owner
+		{name: "owner reads file metadata", principal: User("alice"), op: OpGet, path: "users/alice/files/f1"},
+		{name: "owner lists file metadata", principal: User("alice"), op: OpList, path: "users/alice/files"},
+		{name: "owner creates file metadata", principal: User("alice"), op: OpCreate, path: "users/alice/files/f2"},
+		{name: "owner reads webhook secret", principal: User("alice"), op: OpGet, path: "users/alice/webhooks/w1"},
+		{name: "owner reads export", principal: User("alice"), op: OpGet, path: "users/alice/exports/e1"},
+		{name: "user reads account deletion", principal: User("alice"), op: OpGet, path: "accountDeletions/alice"},
+		{name: "owner updates unknown subcollection", principal: User("alice"), op: OpUpdate, path: "users/alice/unknown/doc1"},
+		// orgs/{orgId}: readable by members, written through the API only
+		{name: "member reads organization", principal: User("alice"), op: OpGet, path: "orgs/acme", allowed: true},
+		{name: "non-member reads organization", principal: User("bob"), op: OpGet, path: "orgs/acme"},
+		{name: "signed out reads organization", principal: SignedOut, op: OpGet, path: "orgs/acme"},
+		{name: "member lists members", principal: User("alice"), op: OpList, path: "orgs/acme/members", allowed: true},
+		{name: "non-member lists members", principal: User("bob"), op: OpList, path: "orgs/acme/members"},
+		{name: "member reads tenant data", principal: User("alice"), op: OpGet, path: "orgs/acme/projects/p1", allowed: true},
+		{name: "non-member reads tenant data", principal: User("bob"), op: OpGet, path: "orgs/acme/projects/p1"},
+		{name: "member reads invitation", principal: User("alice"), op: OpGet, path: "orgs/acme/invitations/i1"},
+		{name: "member reads webhook endpoint", principal: User("alice"), op: OpGet, path: "orgs/acme/webhooks/we1"},
+		{name: "member lists webhook endpoints", principal: User("alice"), op: OpList, path: "orgs/acme/webhooks"},
+		{name: "owner updates organization", principal: User("alice"), op: OpUpdate, path: "orgs/acme"},
+		{name: "owner writes tenant data", principal: User("alice"), op: OpCreate, path: "orgs/acme/projects/p2"},
+		{name: "user adds own membership", principal: User("bob"), op: OpCreate, path: "orgs/acme/members/bob"},
+		{name: "user creates organization", principal: User("bob"), op: OpCreate, path: "orgs/globex"},
+
+		{name: "user reads maintenance state", principal: User("bob"), op: OpGet, path: "system/maintenance"},
+		{name: "user updates maintenance state", principal: User("bob"), op: OpUpdate, path: "system/maintenance"},
+		{name: "user reads cron lock", principal: User("bob"), op: OpGet, path: "cronLocks/prune_history"},
+		{name: "user lists cron executions", principal: User("bob"), op: OpList, path: "cronExecutions"},
+		{name: "user reads audit event", principal: User("alice"), op: OpGet, path: "auditEvents/a1"},
+		{name: "user lists audit events", principal: User("alice"), op: OpList, path: "auditEvents"},
+		{name: "user forges audit event", principal: User("alice"), op: OpCreate, path: "auditEvents/a2"},
+		{name: "user deletes audit event", principal: User("alice"), op: OpDelete, path: "auditEvents/a1"},
+		{name: "owner reads API key", principal: User("alice"), op: OpGet, path: "apiKeys/k1"},
+		{name: "user lists API keys", principal: User("alice"), op: OpList, path: "apiKeys"},
+		{name: "user creates API key", principal: User("alice"), op: OpCreate, path: "apiKeys/k2"},
+		{name: "user reads dead letter", principal: User("bob"), op: OpGet, path: "jobDeadLetters/d1"},
+		{name: "user deletes dead letter", principal: User("bob"), op: OpDelete, path: "jobDeadLetters/d1"},
+		{name: "user reads backfill checkpoint", principal: User("bob"), op: OpGet, path: "adminBackfills/b1"},
+		{name: "user updates backfill checkpoint", principal: User("bob"), op: OpUpdate, path: "adminBackfills/b1"},
+		{name: "user creates unknown collection", principal: User("bob"), op: OpCreate, path: "posts/p1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			emu := setupFirestore(t)
+			data := profile
+			if tt.data != nil {
+				data = tt.data
+			}
+
+			// Act
+			allowed, err := emu.Allowed(context.Background(), tt.principal, tt.op, tt.path, data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, allowed)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Package rulestest checks Firebase security rules against the emulators.
+//
+// Rules run on the client path only: the backend's service account bypasses
+// them, so nothing else catches a rules change that opens access. Tests in
+// this package replay allow/deny scenarios as signed-out, anonymous and
+// signed-in principals and skip when no emulator is available.
+package rulestest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProjectID is the emulator project. The demo- prefix keeps the emulators
+// from ever reaching a real project.
+const ProjectID = "demo-rules-test"
+
+// ErrUnavailable is returned by StartFirestore when no emulator is running
+// and the firebase CLI or Java is not installed
+var ErrUnavailable = errors.New("rulestest: firestore emulator unavailable")
+
+// Principal is the caller a scenario runs as
+type Principal struct {
+	// UID is empty for signed-out callers
+	UID string
+	// Provider is the Firebase sign-in provider, e.g. "password" or "anonymous"
+	Provider string
+}
+
+// SignedOut is a caller without an ID token
+var SignedOut = Principal{}
+
+// User is a caller signed in with a password
+func User(uid string) Principal {
+	return Principal{UID: uid, Provider: "password"}
+}
+
+// Anonymous is a caller signed in with Firebase anonymous auth. The rules
+// see it as authenticated.
+func Anonymous(uid string) Principal {
+	return Principal{UID: uid, Provider: "anonymous"}
+}
+
+// IDToken returns an unsigned ID token for p; the emulators do not check
+// signatures. It is empty for signed-out callers.
+func IDToken(p Principal) string {
+	if p.UID == "" {
+		return ""
+	}
+	now := time.Now().Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":       "https://securetoken.google.com/" + ProjectID,
+		"aud":       ProjectID,
+		"sub":       p.UID,
+		"user_id":   p.UID,
+		"iat":       now,
+		"exp":       now + 3600,
+		"auth_time": now,
+		"firebase":  map[string]any{"sign_in_provider": p.Provider, "identities": map[string]any{}},
+	})
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(header) + "." + enc.EncodeToString(claims) + "."
+}
+
+// Operation is a Firestore rules operation
+type Operation string
+
+// Operations a scenario can perform
+const (
+	OpGet    Operation = "get"
+	OpList   Operation = "list"
+	OpCreate Operation = "create"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+)
+
+// Firestore is a Firestore emulator reached through its REST API
+type Firestore struct {
+	base   string
+	client *http.Client
+	cmd    *exec.Cmd
+}
+
+// StartFirestore connects to the emulator at FIRESTORE_EMULATOR_HOST, or
+// starts one with the firebase CLI from the repository root. Call Stop when
+// done.
+func StartFirestore(ctx context.Context) (*Firestore, error) {
+	f := &Firestore{client: &http.Client{Timeout: 10 * time.Second}}
+	if host := os.Getenv("FIRESTORE_EMULATOR_HOST"); host != "" {
+		f.base = "http://" + host
+		return f, f.wait(ctx, 5*time.Second)
+	}
+
+	firebase, err := exec.LookPath("firebase")
+	if err != nil {
+		return nil, fmt.Errorf("%w: firebase CLI not found", ErrUnavailable)
+	}
+	if _, err := exec.LookPath("java"); err != nil {
+		return nil, fmt.Errorf("%w: java not found", ErrUnavailable)
+	}
+	root, err := repoRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	// The port comes from firebase.json
+	f.base = "http://127.0.0.1:8081"
+	f.cmd = exec.Command(firebase, "emulators:start", "--only", "firestore", "--project", ProjectID)
+	f.cmd.Dir = root
+	if err := f.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("rulestest: start emulator: %w", err)
+	}
+	if err := f.wait(ctx, 90*time.Second); err != nil {
+		f.Stop()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Stop shuts down an emulator started by StartFirestore
+func (f *Firestore) Stop() {
+	if f.cmd == nil || f.cmd.Process == nil {
+		return
+	}
+	_ = f.cmd.Process.Signal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		_ = f.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(15 * time.Second):
+		_ = f.cmd.Process.Kill()
+	}
+}
+
+// LoadRules replaces the emulator's rules with the file at path
+func (f *Firestore) LoadRules(ctx context.Context, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("rulestest: read rules: %w", err)
+	}
+	body, _ := json.Marshal(map[string]any{
+		"rules": map[string]any{
+			"files": []map[string]string{{"name": filepath.Base(path), "content": string(content)}},
+		},
+	})
+	return f.admin(ctx, http.MethodPut, "/emulator/v1/projects/"+ProjectID+":securityRules", body)
+}
+
+// Reset deletes every document
+func (f *Firestore) Reset(ctx context.Context) error {
+	return f.admin(ctx, http.MethodDelete, "/emulator/v1/projects/"+ProjectID+"/databases/(default)/documents", nil)
+}
+
+// Seed writes a document as an admin, bypassing the rules
+func (f *Firestore) Seed(ctx context.Context, path string, data map[string]string) error {
+	return f.admin(ctx, http.MethodPatch, documentsPath(path), documentBody(data))
+}
+
+// Allowed performs op on path as p and reports whether the rules let it
+// through. Writes send data as the document.
+func (f *Firestore) Allowed(ctx context.Context, p Principal, op Operation, path string, data map[string]string) (bool, error) {
+	method, target, body := http.MethodGet, documentsPath(path), []byte(nil)
+	switch op {
+	case OpCreate:
+		method, target, body = http.MethodPatch, target+"?currentDocument.exists=false", documentBody(data)
+	case OpUpdate:
+		method, target, body = http.MethodPatch, target+"?currentDocument.exists=true", documentBody(data)
+	case OpDelete:
+		method = http.MethodDelete
+	}
+
+	token := IDToken(p)
+	if token != "" {
+		token = "Bearer " + token
+	}
+	status, msg, err := f.do(ctx, method, target, token, body)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("rulestest: %s %s: unexpected status %d: %s", op, path, status, msg)
+	}
+}
+
+func (f *Firestore) admin(ctx context.Context, method, path string, body []byte) error {
+	// The emulator treats this token as an admin that bypasses the rules
+	status, msg, err := f.do(ctx, method, path, "Bearer owner", body)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("rulestest: %s %s: status %d: %s", method, path, status, msg)
+	}
+	return nil
+}
+
+func (f *Firestore) do(ctx context.Context, method, path, authorization string, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.base+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("rulestest: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, strings.TrimSpace(string(msg)), nil
+}
+
+// wait polls the emulator until it answers
+func (f *Firestore) wait(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, f.base+"/", nil)
+		if resp, err := f.client.Do(req); err == nil {
+			_ = resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rulestest: emulator at %s not reachable: %w", f.base, ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// RulesFile returns the path of a rules file in the repository root
+func RulesFile(name string) (string, error) {
+	root, err := repoRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, name), nil
+}
+
+// repoRoot finds the directory holding firebase.json above the working
+// directory, which go test sets to the package directory
+func repoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "firebase.json")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("rulestest: firebase.json not found above the working directory")
+		}
+		dir = parent
+	}
+}
+
+func documentsPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return "/v1/projects/" + ProjectID + "/databases/(default)/documents/" + strings.Join(segments, "/")
+}
+
+// documentBody encodes string fields as a Firestore REST document
+func documentBody(data map[string]string) []byte {
+	fields := make(map[string]any, len(data))
+	for k, v := range data {
+		fields[k] = map[string]string{"stringValue": v}
+	}
+	body, _ := json.Marshal(map[string]any{"fields": fields})
+	return body
+}
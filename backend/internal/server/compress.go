@@ -0,0 +1,430 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+)
+
+// Content codings, in the order the server prefers them when a client
+// accepts several equally
+const (
+	EncodingZstd   = "zstd"
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+var encodings = []string{EncodingZstd, EncodingBrotli, EncodingGzip}
+
+// errRequestTooLarge is returned by request bodies that decompress beyond
+// the limit
+var errRequestTooLarge = errors.New("server: decompressed request body too large")
+
+// encoder is the part of the gzip, brotli and zstd writers responses use
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderPools reuse encoders, whose buffers are expensive to allocate
+var encoderPools = map[string]*sync.Pool{
+	EncodingZstd: {New: func() any {
+		// Only fails for invalid options
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return enc
+	}},
+	EncodingBrotli: {New: func() any { return brotli.NewWriterLevel(nil, 4) }},
+	EncodingGzip:   {New: func() any { return gzip.NewWriter(nil) }},
+}
+
+// CompressOptions configures response compression
+type CompressOptions struct {
+	// MinSize is the smallest body compressed, in bytes; smaller bodies
+	// cost more to compress than they save
+	MinSize int
+	// Types lists compressible content types; "text/*" matches any text
+	// type. Event streams are never compressed.
+	Types []string
+}
+
+// Compress encodes responses with the best coding the client accepts in
+// Accept-Encoding: zstd, br or gzip. Only bodies of an allowed type and at
+// least MinSize bytes are compressed; every response that could be gets
+// "Vary: Accept-Encoding", added next to any Vary set by CORS. Strong ETags
+// of compressed responses get a "-<coding>" suffix, as the compressed bytes
+// are a different representation; the suffix is removed from If-None-Match
+// and If-Match before handlers see them. Server-Sent Events requests pass
+// through untouched.
+func Compress(opts CompressOptions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Method == http.MethodHead || IsEventStream(c) {
+				return next(c)
+			}
+
+			res := c.Response()
+			w := &compressWriter{
+				ResponseWriter: res.Writer,
+				encoding:       negotiate(req.Header.Get(echo.HeaderAcceptEncoding)),
+				opts:           &opts,
+				validators:     unsuffixValidators(req.Header),
+			}
+			res.Writer = w
+			err := next(c)
+			if cerr := w.finish(); cerr != nil && err == nil {
+				err = cerr
+			}
+			res.Writer = w.ResponseWriter
+			return err
+		}
+	}
+}
+
+// negotiate returns the preferred coding accepted by an Accept-Encoding
+// header, or "" for identity
+func negotiate(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	q := make(map[string]float64)
+	for _, item := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "x-gzip" {
+			coding = EncodingGzip
+		}
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			weight = f
+		}
+		q[coding] = weight
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range encodings {
+		weight, ok := q[coding]
+		if !ok {
+			weight = q["*"]
+		}
+		if weight > bestQ {
+			best, bestQ = coding, weight
+		}
+	}
+	return best
+}
+
+// unsuffixValidators strips coding suffixes from the request's entity tags
+// and returns the original of each stripped tag
+func unsuffixValidators(h http.Header) map[string]string {
+	var originals map[string]string
+	for _, name := range []string{HeaderIfNoneMatch, HeaderIfMatch} {
+		header := h.Get(name)
+		if header == "" {
+			continue
+		}
+		tags := strings.Split(header, ",")
+		changed := false
+		for i, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			tags[i] = tag
+			for _, coding := range encodings {
+				if stripped, ok := strings.CutSuffix(tag, "-"+coding+`"`); ok {
+					if originals == nil {
+						originals = make(map[string]string)
+					}
+					tags[i] = stripped + `"`
+					originals[tags[i]] = tag
+					changed = true
+					break
+				}
+			}
+		}
+		if changed {
+			h.Set(name, strings.Join(tags, ", "))
+		}
+	}
+	return originals
+}
+
+// suffixETag marks a strong or weak ETag as belonging to a coding
+func suffixETag(tag, coding string) string {
+	if !strings.HasSuffix(tag, `"`) {
+		return tag
+	}
+	return strings.TrimSuffix(tag, `"`) + "-" + coding + `"`
+}
+
+// addVary adds a header name to Vary unless it is listed already
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values(echo.HeaderVary) {
+		for _, listed := range strings.Split(v, ",") {
+			if listed = strings.TrimSpace(listed); listed == "*" || strings.EqualFold(listed, name) {
+				return
+			}
+		}
+	}
+	h.Add(echo.HeaderVary, name)
+}
+
+// compressWriter buffers the first MinSize bytes of a compressible
+// response and then either compresses it or, for a short body, writes it
+// as is
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	opts       *CompressOptions
+	validators map[string]string
+
+	status      int
+	buf         []byte
+	enc         encoder
+	passthrough bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+
+	h := w.Header()
+	if code == http.StatusNotModified {
+		// Answer with the tag the client holds, suffix included
+		if original, ok := w.validators[h.Get(HeaderETag)]; ok {
+			h.Set(HeaderETag, original)
+		}
+	}
+	if !w.compressible() {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	addVary(h, echo.HeaderAcceptEncoding)
+	if w.encoding == "" {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// compressible reports whether the response may be compressed
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified ||
+		h.Get(echo.HeaderContentEncoding) != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	if n, err := strconv.Atoi(h.Get(echo.HeaderContentLength)); err == nil && n < w.opts.MinSize {
+		return false
+	}
+
+	contentType, _, _ := strings.Cut(h.Get(echo.HeaderContentType), ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" || contentType == MIMEEventStream {
+		return false
+	}
+	for _, allowed := range w.opts.Types {
+		allowed = strings.ToLower(allowed)
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case w.passthrough:
+		return w.ResponseWriter.Write(b)
+	case w.enc != nil:
+		return w.enc.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.opts.MinSize {
+		if err := w.startCompressing(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// startCompressing sends the headers of the compressed response and the
+// bytes buffered so far
+func (w *compressWriter) startCompressing() error {
+	h := w.Header()
+	h.Set(echo.HeaderContentEncoding, w.encoding)
+	h.Del(echo.HeaderContentLength)
+	if tag := h.Get(HeaderETag); tag != "" {
+		h.Set(HeaderETag, suffixETag(tag, w.encoding))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.enc = encoderPools[w.encoding].Get().(encoder)
+	w.enc.Reset(w.ResponseWriter)
+	buffered := w.buf
+	w.buf = nil
+	if _, err := w.enc.Write(buffered); err != nil {
+		return fmt.Errorf("server: compress response: %w", err)
+	}
+	return nil
+}
+
+// finish completes the response once the handler returned
+func (w *compressWriter) finish() error {
+	switch {
+	case w.status == 0 || w.passthrough:
+		return nil
+	case w.enc != nil:
+		err := w.enc.Close()
+		w.enc.Reset(nil)
+		encoderPools[w.encoding].Put(w.enc)
+		w.enc = nil
+		return err
+	}
+
+	// Shorter than MinSize: not worth compressing
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// Flush sends what was written so far, compressed if the response is
+func (w *compressWriter) Flush() {
+	if !w.passthrough && w.status != 0 && w.enc == nil {
+		_ = w.startCompressing()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// DecompressOptions configures request body decompression
+type DecompressOptions struct {
+	// MaxSize caps the decompressed body in bytes, so a small compressed
+	// body cannot expand into gigabytes
+	MaxSize int64
+}
+
+// Decompress decodes request bodies sent with Content-Encoding gzip, br or
+// zstd before handlers read them. Other codings get 415 Unsupported Media
+// Type, and bodies decompressing beyond MaxSize 413 Request Entity Too
+// Large.
+func Decompress(opts DecompressOptions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			coding := strings.ToLower(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding)))
+			if coding == "" || coding == "identity" || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			decoded, err := newDecoder(coding, req.Body)
+			if errors.Is(err, errUnsupportedEncoding) {
+				return echo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported Content-Encoding "+coding)
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "malformed "+coding+" request body")
+			}
+
+			body := &limitedBody{decoded: decoded, raw: req.Body, max: opts.MaxSize}
+			req.Body = body
+			req.ContentLength = -1
+			req.Header.Del(echo.HeaderContentEncoding)
+			req.Header.Del(echo.HeaderContentLength)
+
+			err = next(c)
+			if body.exceeded {
+				// Handlers see a read error; the limit is the real cause
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body exceeds %d bytes once decompressed", opts.MaxSize))
+			}
+			return err
+		}
+	}
+}
+
+var errUnsupportedEncoding = errors.New("server: unsupported content encoding")
+
+// newDecoder returns a reader decoding r from coding
+func newDecoder(coding string, r io.Reader) (io.ReadCloser, error) {
+	switch coding {
+	case EncodingGzip, "x-gzip":
+		return gzip.NewReader(r)
+	case EncodingBrotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case EncodingZstd:
+		// A bounded window keeps a hostile frame from reserving memory
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(8<<20))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	default:
+		return nil, errUnsupportedEncoding
+	}
+}
+
+// limitedBody is a decompressed request body that fails once more than max
+// bytes were read
+type limitedBody struct {
+	decoded  io.ReadCloser
+	raw      io.Closer
+	max      int64
+	read     int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errRequestTooLarge
+	}
+	n, err := b.decoded.Read(p)
+	b.read += int64(n)
+	if b.read > b.max {
+		b.exceeded = true
+		return n - int(b.read-b.max), errRequestTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	b.decoded.Close()
+	return b.raw.Close()
+}
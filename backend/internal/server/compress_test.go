@@ -0,0 +1,374 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCompressOptions = CompressOptions{MinSize: 64, Types: []string{echo.MIMEApplicationJSON, "text/*"}}
+
+func decode(t *testing.T, coding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch coding {
+	case EncodingGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		r = gz
+	case EncodingBrotli:
+		r = brotli.NewReader(bytes.NewReader(body))
+	case EncodingZstd:
+		dec, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer dec.Close()
+		r = dec
+	default:
+		return string(body)
+	}
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+// encode compresses body with coding, or returns it as is for any other
+// coding
+func encode(t testing.TB, coding string, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case EncodingGzip:
+		w = gzip.NewWriter(&buf)
+	case EncodingBrotli:
+		w = brotli.NewWriter(&buf)
+	case EncodingZstd:
+		enc, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		w = enc
+	default:
+		return body
+	}
+	_, err := w.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip", want: EncodingGzip},
+		{header: "gzip, deflate, br", want: EncodingBrotli},
+		{header: "gzip, deflate, br, zstd", want: EncodingZstd},
+		{header: "br;q=0.5, gzip;q=0.8", want: EncodingGzip},
+		{header: "zstd;q=0, gzip", want: EncodingGzip},
+		{header: "*", want: EncodingZstd},
+		{header: "*;q=0.1, br;q=0", want: EncodingZstd},
+		{header: "identity", want: ""},
+		{header: "X-GZIP", want: EncodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			// Act
+			got := negotiate(tt.header)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("compressible ", 20)
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		accept           string
+		contentType      string
+		body             string
+		expectedEncoding string
+		expectVary       bool
+	}{
+		{name: "gzip", acceptEncoding: "gzip", contentType: echo.MIMEApplicationJSON, body: large, expectedEncoding: EncodingGzip, expectVary: true},
+		{name: "brotli", acceptEncoding: "gzip, br", contentType: echo.MIMEApplicationJSON, body: large, expectedEncoding: EncodingBrotli, expectVary: true},
+		{name: "zstd", acceptEncoding: "gzip, br, zstd", contentType: "text/plain; charset=utf-8", body: large, expectedEncoding: EncodingZstd, expectVary: true},
+		{name: "below the minimum size", acceptEncoding: "gzip", contentType: echo.MIMEApplicationJSON, body: "{}", expectVary: true},
+		{name: "client accepts none", contentType: echo.MIMEApplicationJSON, body: large, expectVary: true},
+		{name: "type not allowed", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "event stream", acceptEncoding: "gzip", contentType: MIMEEventStream, body: large},
+		{name: "event stream request", acceptEncoding: "gzip", accept: MIMEEventStream, contentType: echo.MIMEApplicationJSON, body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			e.Use(Compress(testCompressOptions))
+			e.GET("/", func(c echo.Context) error {
+				c.Response().Header().Add(echo.HeaderVary, "Origin")
+				return c.Blob(http.StatusOK, tt.contentType, []byte(tt.body))
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAcceptEncoding, tt.acceptEncoding)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedEncoding, rec.Header().Get(echo.HeaderContentEncoding))
+			assert.Equal(t, tt.body, decode(t, tt.expectedEncoding, rec.Body.Bytes()))
+			vary := rec.Header().Values(echo.HeaderVary)
+			assert.Contains(t, vary, "Origin", "CORS Vary is kept")
+			if tt.expectVary {
+				assert.Contains(t, vary, echo.HeaderAcceptEncoding)
+			} else {
+				assert.NotContains(t, vary, echo.HeaderAcceptEncoding)
+			}
+		})
+	}
+}
+
+func TestCompress_ErrorResponse(t *testing.T) {
+	// Arrange
+	e := echo.New()
+	e.Use(Compress(testCompressOptions))
+	e.GET("/", func(echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, strings.Repeat("missing ", 20))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing")
+}
+
+func TestCompress_ETags(t *testing.T) {
+	// Arrange
+	e := echo.New()
+	e.Use(Compress(testCompressOptions))
+	e.Use(ETags())
+	e.GET("/", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"text": strings.Repeat("x", 200)})
+	})
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		req.Header.Set(HeaderIfNoneMatch, ifNoneMatch)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Act
+	first := get("")
+	tag := first.Header().Get(HeaderETag)
+	second := get(tag)
+
+	// Assert
+	assert.Equal(t, EncodingGzip, first.Header().Get(echo.HeaderContentEncoding))
+	assert.True(t, strings.HasSuffix(tag, `-gzip"`), tag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Equal(t, tag, second.Header().Get(HeaderETag))
+}
+
+func TestCompress_FlushingHandler(t *testing.T) {
+	// Arrange
+	e := echo.New()
+	e.Use(Compress(testCompressOptions))
+	e.GET("/", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c.Response().WriteHeader(http.StatusOK)
+		_, _ = c.Response().Write([]byte(`{"part":1}`))
+		c.Response().Flush()
+		_, _ = c.Response().Write([]byte(`{"part":2}`))
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, `{"part":1}{"part":2}`, decode(t, rec.Header().Get(echo.HeaderContentEncoding), rec.Body.Bytes()))
+}
+
+func TestDecompress(t *testing.T) {
+	payload := `{"name":"` + strings.Repeat("a", 100) + `"}`
+	encode := func(coding, body string) []byte {
+		return encode(t, coding, []byte(body))
+	}
+
+	tests := []struct {
+		name           string
+		coding         string
+		body           []byte
+		expectedStatus int
+	}{
+		{name: "gzip", coding: EncodingGzip, body: encode(EncodingGzip, payload), expectedStatus: http.StatusOK},
+		{name: "brotli", coding: EncodingBrotli, body: encode(EncodingBrotli, payload), expectedStatus: http.StatusOK},
+		{name: "zstd", coding: EncodingZstd, body: encode(EncodingZstd, payload), expectedStatus: http.StatusOK},
+		{name: "uncompressed", body: []byte(payload), expectedStatus: http.StatusOK},
+		{name: "unsupported coding", coding: "deflate", body: []byte(payload), expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed gzip", coding: EncodingGzip, body: []byte(payload), expectedStatus: http.StatusBadRequest},
+		{name: "decompression bomb", coding: EncodingGzip, body: encode(EncodingGzip, `{"name":"`+strings.Repeat("a", 10<<20)+`"}`), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			e.Use(Decompress(DecompressOptions{MaxSize: 1024}))
+			e.POST("/", func(c echo.Context) error {
+				var req struct {
+					Name string `json:"name"`
+				}
+				if err := c.Bind(&req); err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+				}
+				return c.String(http.StatusOK, req.Name)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.coding != "" {
+				req.Header.Set(echo.HeaderContentEncoding, tt.coding)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, strings.Repeat("a", 100), rec.Body.String())
+			}
+		})
+	}
+}
+
+// FuzzNegotiate checks that any Accept-Encoding header negotiates a coding
+// the server has, and identity when none is named
+func FuzzNegotiate(f *testing.F) {
+	f.Add("gzip, deflate, br, zstd")
+	f.Add("*;q=NaN, br;q=-1")
+
+	f.Fuzz(func(t *testing.T, header string) {
+		// Act
+		got := negotiate(header)
+
+		// Assert
+		assert.Contains(t, []string{"", EncodingZstd, EncodingBrotli, EncodingGzip}, got)
+		lower := strings.ToLower(header)
+		if !strings.Contains(lower, "gzip") && !strings.Contains(lower, "br") && !strings.Contains(lower, "zstd") && !strings.Contains(lower, "*") {
+			assert.Empty(t, got)
+		}
+	})
+}
+
+// FuzzCompress checks that responses, conditional or not, decode to what
+// the handler wrote in whatever coding the client negotiated
+func FuzzCompress(f *testing.F) {
+	f.Add("gzip", "", []byte(`{"message":"hello"}`))
+	f.Add("br;q=0.5, zstd", `"x-zstd", W/"y"`, []byte(strings.Repeat("a", 200)))
+	e := echo.New()
+	e.Use(Compress(testCompressOptions))
+	e.Use(ETags())
+	e.POST("/", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, body)
+	})
+
+	f.Fuzz(func(t *testing.T, acceptEncoding, ifNoneMatch string, body []byte) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		req.Header.Set(HeaderIfNoneMatch, ifNoneMatch)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		require.Equal(t, http.StatusOK, rec.Code)
+		coding := rec.Header().Get(echo.HeaderContentEncoding)
+		assert.Contains(t, []string{"", negotiate(acceptEncoding)}, coding)
+		assert.Equal(t, string(body), decode(t, coding, rec.Body.Bytes()))
+	})
+}
+
+// FuzzDecompress checks that request bodies in any coding, well-formed or
+// not, reach the handler intact or are rejected with a client error
+func FuzzDecompress(f *testing.F) {
+	f.Add(EncodingGzip, []byte(`{"name":"a"}`), true)
+	f.Add(EncodingZstd, []byte("\x28\xb5\x2f\xfd"), false)
+	const maxSize = 1024
+	e := echo.New()
+	e.Use(Decompress(DecompressOptions{MaxSize: maxSize}))
+	e.POST("/", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+		}
+		return c.Blob(http.StatusOK, echo.MIMEOctetStream, body)
+	})
+
+	f.Fuzz(func(t *testing.T, coding string, body []byte, compress bool) {
+		// Arrange
+		sent := body
+		if compress {
+			normalized := strings.ToLower(strings.TrimSpace(coding))
+			if normalized == "x-gzip" {
+				normalized = EncodingGzip
+			}
+			sent = encode(t, normalized, body)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(sent))
+		req.Header.Set(echo.HeaderContentEncoding, coding)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Contains(t, []int{http.StatusOK, http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType}, rec.Code)
+		if rec.Code == http.StatusOK {
+			assert.LessOrEqual(t, rec.Body.Len(), maxSize)
+		}
+		if compress && len(body) <= maxSize && rec.Code != http.StatusUnsupportedMediaType {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(body), rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Conditional request headers
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// ETags gives successful JSON responses to GET requests a strong ETag and
+// answers 304 Not Modified when it matches If-None-Match. Handlers that
+// know their resource's version set the ETag header themselves, with
+// VersionETag, and the body is not hashed; other responses pass through
+// unbuffered. Event streams are skipped.
+func ETags() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet || IsEventStream(c) {
+				return next(c)
+			}
+
+			res := c.Response()
+			w := &etagWriter{ResponseWriter: res.Writer}
+			res.Writer = w
+			err := next(c)
+			res.Writer = w.ResponseWriter
+			if w.passthrough || w.status == 0 {
+				return err
+			}
+
+			// A buffered 200 JSON response
+			h := res.Header()
+			tag := h.Get(HeaderETag)
+			if tag == "" {
+				tag = hashETag(w.buf.Bytes())
+				h.Set(HeaderETag, tag)
+			}
+			if noneMatch(c.Request().Header.Get(HeaderIfNoneMatch), tag) {
+				h.Del(echo.HeaderContentType)
+				h.Del(echo.HeaderContentLength)
+				res.Status = http.StatusNotModified
+				res.Size = 0
+				w.ResponseWriter.WriteHeader(http.StatusNotModified)
+				return err
+			}
+			w.ResponseWriter.WriteHeader(w.status)
+			if _, werr := w.ResponseWriter.Write(w.buf.Bytes()); werr != nil && err == nil {
+				return werr
+			}
+			return err
+		}
+	}
+}
+
+// ETagOf returns the strong ETag ETags gives the JSON encoding of v, for
+// update handlers checking If-Match against the current representation
+func ETagOf(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("server: etag: %w", err)
+	}
+	return hashETag(data), nil
+}
+
+// VersionETag returns a strong ETag for a resource version, such as its ID
+// and update time. The version must change whenever the representation does.
+func VersionETag(parts ...string) string {
+	return hashETag([]byte(strings.Join(parts, "\x00")))
+}
+
+// CheckIfMatch enforces an If-Match precondition against the current ETag
+// of the resource, or "" if it does not exist. Requests without If-Match
+// pass; otherwise 412 Precondition Failed is returned unless a listed tag
+// strongly matches, or "*" is sent for an existing resource. Call it inside
+// the read-modify-write of the resource, or a concurrent update can land
+// between the check and the write.
+func CheckIfMatch(c echo.Context, current string) error {
+	header := c.Request().Header.Get(HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if current != "" && (tag == "*" || tag == current && !strings.HasPrefix(tag, "W/")) {
+			return nil
+		}
+	}
+	return echo.NewHTTPError(http.StatusPreconditionFailed, "resource was modified; fetch it again and retry")
+}
+
+// hashETag returns a strong ETag over data, ignoring the trailing newline
+// echo's JSON encoder adds
+func hashETag(data []byte) string {
+	sum := sha256.Sum256(bytes.TrimRight(data, "\n"))
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// noneMatch reports whether an If-None-Match header matches tag, using
+// the weak comparison RFC 9110 prescribes for it
+func noneMatch(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers a 200 JSON response until its ETag is known and
+// passes anything else through
+type etagWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code != http.StatusOK || !strings.HasPrefix(w.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush gives up on the ETag: a flushing handler wants bytes on the wire
+func (w *etagWriter) Flush() {
+	if !w.passthrough && w.status != 0 {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETags(t *testing.T) {
+	body := map[string]string{"message": "hello"}
+	tag, err := ETagOf(body)
+	require.NoError(t, err)
+	version := VersionETag("f1", "42")
+
+	tests := []struct {
+		name           string
+		handler        echo.HandlerFunc
+		ifNoneMatch    string
+		expectedStatus int
+		expectedETag   string
+		expectedBody   bool
+	}{
+		{
+			name:           "json response is tagged",
+			handler:        func(c echo.Context) error { return c.JSON(http.StatusOK, body) },
+			expectedStatus: http.StatusOK,
+			expectedETag:   tag,
+			expectedBody:   true,
+		},
+		{
+			name:           "matching If-None-Match",
+			handler:        func(c echo.Context) error { return c.JSON(http.StatusOK, body) },
+			ifNoneMatch:    `"stale", ` + tag,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   tag,
+		},
+		{
+			name:           "weak If-None-Match",
+			handler:        func(c echo.Context) error { return c.JSON(http.StatusOK, body) },
+			ifNoneMatch:    "W/" + tag,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   tag,
+		},
+		{
+			name:           "stale If-None-Match",
+			handler:        func(c echo.Context) error { return c.JSON(http.StatusOK, body) },
+			ifNoneMatch:    `"stale"`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   tag,
+			expectedBody:   true,
+		},
+		{
+			name: "version set by handler",
+			handler: func(c echo.Context) error {
+				c.Response().Header().Set(HeaderETag, version)
+				return c.JSON(http.StatusOK, body)
+			},
+			ifNoneMatch:    version,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   version,
+		},
+		{
+			name:           "error response",
+			handler:        func(echo.Context) error { return echo.NewHTTPError(http.StatusNotFound, "missing") },
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   true,
+		},
+		{
+			name:           "non-json response",
+			handler:        func(c echo.Context) error { return c.String(http.StatusOK, "plain") },
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusOK,
+			expectedBody:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			e.Use(ETags())
+			e.GET("/", tt.handler)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(HeaderIfNoneMatch, tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedETag, rec.Header().Get(HeaderETag))
+			assert.Equal(t, tt.expectedBody, rec.Body.Len() > 0)
+		})
+	}
+}
+
+func TestETags_FlushingHandler(t *testing.T) {
+	// Arrange
+	e := echo.New()
+	e.Use(ETags())
+	e.GET("/", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c.Response().WriteHeader(http.StatusOK)
+		_, _ = c.Response().Write([]byte(`{"part":1}`))
+		c.Response().Flush()
+		_, _ = c.Response().Write([]byte(`{"part":2}`))
+		return nil
+	})
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Assert
+	assert.Equal(t, `{"part":1}{"part":2}`, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(HeaderETag))
+	assert.True(t, rec.Flushed)
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	current := VersionETag("v2")
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		current string
+		wantErr bool
+	}{
+		{name: "no precondition", current: current},
+		{name: "current tag", ifMatch: current, current: current},
+		{name: "one of several", ifMatch: `"old", ` + current, current: current},
+		{name: "stale tag", ifMatch: VersionETag("v1"), current: current, wantErr: true},
+		{name: "weak tag", ifMatch: "W/" + current, current: current, wantErr: true},
+		{name: "any existing", ifMatch: "*", current: current},
+		{name: "any missing", ifMatch: "*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set(HeaderIfMatch, tt.ifMatch)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			// Act
+			err := CheckIfMatch(c, tt.current)
+
+			// Assert
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var he *echo.HTTPError
+			require.ErrorAs(t, err, &he)
+			assert.Equal(t, http.StatusPreconditionFailed, he.Code)
+		})
+	}
+}
+
+// FuzzETags checks that GET responses are 304 exactly when If-None-Match
+// names their ETag, and carry the handler's body otherwise
+func FuzzETags(f *testing.F) {
+	f.Add(`"stale", W/"x"`, `{"message":"hello"}`)
+	f.Add("*", "")
+	e := echo.New()
+	e.Use(ETags())
+	e.GET("/", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, []byte(c.QueryParam("body")))
+	})
+
+	f.Fuzz(func(t *testing.T, ifNoneMatch, body string) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "/?"+url.Values{"body": {body}}.Encode(), nil)
+		req.Header.Set(HeaderIfNoneMatch, ifNoneMatch)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		tag := hashETag([]byte(body))
+		assert.Equal(t, tag, rec.Header().Get(HeaderETag))
+		if noneMatch(ifNoneMatch, tag) {
+			assert.Equal(t, http.StatusNotModified, rec.Code)
+			assert.Empty(t, rec.Body.String())
+		} else {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, body, rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/your-org/your-app/internal/clock"
+)
+
+func TestRequestLogger_LogsFinalStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        echo.HandlerFunc
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			handler:        func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "http error",
+			handler:        func(echo.Context) error { return echo.NewHTTPError(http.StatusNotFound, "missing") },
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unexpected error",
+			handler:        func(echo.Context) error { return assert.AnError },
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			core, logs := observer.New(zapcore.InfoLevel)
+			e := echo.New()
+			e.Use(RequestLogger(zap.New(core), clock.System{}))
+			e.GET("/", tt.handler)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			entries := logs.FilterMessage("request").All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, int64(tt.expectedStatus), entries[0].ContextMap()["status"])
+		})
+	}
+}
+
+func TestRequestLogger_Stream(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zapcore.InfoLevel)
+	e := echo.New()
+	e.Use(RequestLogger(zap.New(core), clock.System{}))
+
+	var openedBeforeHandler bool
+	e.GET("/stream", func(c echo.Context) error {
+		openedBeforeHandler = logs.FilterMessage("stream opened").Len() == 1
+		c.Response().Header().Set(echo.HeaderContentType, MIMEEventStream)
+		c.Response().WriteHeader(http.StatusOK)
+		_, _ = c.Response().Write([]byte(": ping\n\n"))
+		c.Response().Flush()
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set(echo.HeaderAccept, MIMEEventStream)
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.True(t, openedBeforeHandler)
+	assert.True(t, rec.Flushed)
+	entries := logs.FilterMessage("request").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, true, entries[0].ContextMap()["stream"])
+	assert.Equal(t, int64(len(": ping\n\n")), entries[0].ContextMap()["bytes_out"])
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		production      bool
+		header          string
+		value           string
+		expectNoStore   bool
+		expectHSTS      bool
+		flushFromHandle bool
+	}{
+		{name: "anonymous request", expectNoStore: false},
+		{name: "authenticated request", header: echo.HeaderAuthorization, value: "Bearer t", expectNoStore: true},
+		{name: "event stream flushed by handler", header: echo.HeaderAccept, value: MIMEEventStream, expectNoStore: true, flushFromHandle: true},
+		{name: "production", production: true, expectHSTS: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			e.Use(SecurityHeaders(tt.production))
+			e.GET("/", func(c echo.Context) error {
+				if tt.flushFromHandle {
+					c.Response().WriteHeader(http.StatusOK)
+					c.Response().Flush()
+					return nil
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+			assert.Equal(t, tt.expectNoStore, rec.Header().Get("Cache-Control") != "")
+			assert.Equal(t, tt.expectHSTS, rec.Header().Get("Strict-Transport-Security") != "")
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		origin         string
+		expectedOrigin string
+		expectedStatus int
+	}{
+		{name: "allowed origin", method: http.MethodGet, origin: "https://app.example.com", expectedOrigin: "https://app.example.com", expectedStatus: http.StatusOK},
+		{name: "origin listed with spaces", method: http.MethodGet, origin: "http://localhost:3000", expectedOrigin: "http://localhost:3000", expectedStatus: http.StatusOK},
+		{name: "other origin", method: http.MethodGet, origin: "https://evil.example.com", expectedStatus: http.StatusOK},
+		{name: "allowed origin as a prefix", method: http.MethodGet, origin: "https://app.example.com.evil.com", expectedStatus: http.StatusOK},
+		{name: "no origin", method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "preflight", method: http.MethodOptions, origin: "https://app.example.com", expectedOrigin: "https://app.example.com", expectedStatus: http.StatusNoContent},
+		{name: "preflight from other origin", method: http.MethodOptions, origin: "https://evil.example.com", expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := newCORSServer()
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, tt.origin)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedOrigin, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+			assert.Equal(t, tt.expectedOrigin != "", rec.Header().Get(echo.HeaderAccessControlAllowCredentials) == "true")
+		})
+	}
+}
+
+var corsAllowedOrigins = []string{"https://app.example.com", " http://localhost:3000"}
+
+func newCORSServer() *echo.Echo {
+	e := echo.New()
+	e.Use(CORS(corsAllowedOrigins))
+	e.Any("/", func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
+	return e
+}
+
+// FuzzCORS checks that the middleware never echoes an origin that is not
+// allowed, whatever the Origin header holds
+func FuzzCORS(f *testing.F) {
+	f.Add(http.MethodGet, "https://app.example.com")
+	f.Add(http.MethodOptions, "https://evil.example.com")
+	e := newCORSServer()
+
+	f.Fuzz(func(t *testing.T, method, origin string) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Method = method
+		req.Header.Set(echo.HeaderOrigin, origin)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Less(t, rec.Code, http.StatusInternalServerError)
+		allowed := origin == "https://app.example.com" || origin == "http://localhost:3000"
+		if allowed {
+			assert.Equal(t, origin, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		} else {
+			for name := range rec.Header() {
+				assert.NotContains(t, name, "Access-Control-", "no CORS headers for origin %q", origin)
+			}
+		}
+		if method == http.MethodOptions {
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		}
+	})
+}
+
+// FuzzSecurityHeaders checks that every response carries the security
+// headers and that credentialed or streamed ones are never cacheable
+func FuzzSecurityHeaders(f *testing.F) {
+	f.Add(http.MethodGet, "", "", false)
+	f.Add(http.MethodPost, "Bearer t", MIMEEventStream, true)
+	e := echo.New()
+	e.Use(SecurityHeaders(false))
+	e.Any("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	production := echo.New()
+	production.Use(SecurityHeaders(true))
+
+	f.Fuzz(func(t *testing.T, method, authorization, accept string, isProduction bool) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Method = method
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		req.Header.Set(echo.HeaderAccept, accept)
+		rec := httptest.NewRecorder()
+
+		// Act
+		if isProduction {
+			production.ServeHTTP(rec, req)
+		} else {
+			e.ServeHTTP(rec, req)
+		}
+
+		// Assert
+		assert.Less(t, rec.Code, http.StatusInternalServerError)
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+		assert.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, isProduction, rec.Header().Get("Strict-Transport-Security") != "")
+		if authorization != "" || strings.Contains(accept, MIMEEventStream) {
+			assert.Contains(t, rec.Header().Get("Cache-Control"), "no-store")
+		}
+	})
+}
+
+// FuzzRequestLogger checks that any request, however large or malformed
+// its path and headers, is logged exactly once with the status sent
+func FuzzRequestLogger(f *testing.F) {
+	f.Add(http.MethodGet, "/", "curl/8.0", uint16(1))
+	f.Add(http.MethodPost, "/a/../b", "\xff\xfe", uint16(4096))
+
+	f.Fuzz(func(t *testing.T, method, path, header string, repeat uint16) {
+		// Arrange: repeat makes headers up to the 1 MB the server accepts
+		value := strings.Repeat(header, int(repeat))
+		if len(value) > 1<<20 {
+			value = value[:1<<20]
+		}
+		core, logs := observer.New(zapcore.InfoLevel)
+		e := echo.New()
+		e.Use(RequestLogger(zap.New(core), clock.System{}))
+		e.Any("/*", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Method = method
+		req.URL.Path = path
+		req.Header.Set("User-Agent", value)
+		req.Header.Set(echo.HeaderXForwardedFor, value)
+		req.Header.Set(echo.HeaderAccept, value)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		entries := logs.FilterMessage("request").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(rec.Code), entries[0].ContextMap()["status"])
+	})
+}
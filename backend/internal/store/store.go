@@ -0,0 +1,42 @@
+// Package store connects to the datastore shared by the backend's subsystems.
+//
+// Each subsystem defines its own store interface with a Firestore and an
+// in-memory implementation; this package only owns the client they share
+// and helpers for whole document trees.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound is returned by stores when a document does not exist
+var ErrNotFound = errors.New("store: not found")
+
+// NewFirestoreClient connects to Firestore, or to the emulator when
+// FIRESTORE_EMULATOR_HOST is set
+func NewFirestoreClient(ctx context.Context, projectID string) (*firestore.Client, error) {
+	if projectID == "" {
+		if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+			return nil, errors.New("store: GCP_PROJECT is required for Firestore")
+		}
+		projectID = "demo-project"
+	}
+
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("store: create firestore client: %w", err)
+	}
+	return client, nil
+}
+
+// IsNotFound reports whether err is a Firestore NotFound error
+func IsNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
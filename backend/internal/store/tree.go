@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// Walk calls fn for ref, if it exists, and then for every document in its
+// subcollections, depth first. Parents that only exist as the path of a
+// subcollection are walked without calling fn.
+func Walk(ctx context.Context, ref *firestore.DocumentRef, fn func(*firestore.DocumentSnapshot) error) error {
+	snap, err := ref.Get(ctx)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if err := fn(snap); err != nil {
+			return err
+		}
+	}
+
+	collections := ref.Collections(ctx)
+	for {
+		col, err := collections.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		docs := col.DocumentRefs(ctx)
+		for {
+			doc, err := docs.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := Walk(ctx, doc, fn); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DeleteTree deletes ref and every document below it. Firestore does not
+// delete subcollections with their parent, so every document found by Walk
+// is deleted. It reports how many documents were deleted.
+func DeleteTree(ctx context.Context, client *firestore.Client, ref *firestore.DocumentRef) (int, error) {
+	var refs []*firestore.DocumentRef
+	err := Walk(ctx, ref, func(snap *firestore.DocumentSnapshot) error {
+		refs = append(refs, snap.Ref)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	writer := client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(refs))
+	for _, ref := range refs {
+		job, err := writer.Delete(ref)
+		if err != nil {
+			writer.End()
+			return 0, fmt.Errorf("store: delete %s: %w", RelativePath(ref), err)
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	var errs []error
+	for i, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, fmt.Errorf("store: delete %s: %w", RelativePath(refs[i]), err))
+		}
+	}
+	return len(refs) - len(errs), errors.Join(errs...)
+}
+
+// RelativePath strips the projects/{p}/databases/{d}/documents/ prefix
+func RelativePath(ref *firestore.DocumentRef) string {
+	if _, rest, ok := strings.Cut(ref.Path, "/documents/"); ok {
+		return rest
+	}
+	return ref.Path
+}
@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Epoch is the time a Clock from NewClock starts at
+var Epoch = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a clock.Clock that only moves when told to
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a Clock stopped at Epoch
+func NewClock() *Clock {
+	return &Clock{now: Epoch}
+}
+
+// Now returns the time the clock was set to
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// IDs is an ids.Generator counting up from 1, so the nth ID drawn is the
+// same on every run. IDs keep the length of real ones: n bytes give 2n hex
+// digits, e.g. "0000000000000003" for the third 8-byte ID.
+type IDs struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewID returns the next number of the sequence, hex encoded as n bytes
+func (g *IDs) NewID(n int) string {
+	g.mu.Lock()
+	g.next++
+	id := fmt.Sprintf("%0*x", 2*n, g.next)
+	g.mu.Unlock()
+	return id[len(id)-2*n:]
+}
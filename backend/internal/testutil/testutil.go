@@ -2,31 +2,237 @@
 package testutil
 
 import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-	"github.com/your-org/your-app/internal/handlers"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/client"
+	"github.com/your-org/your-app/internal/account"
+	"github.com/your-org/your-app/internal/app"
+	"github.com/your-org/your-app/internal/auth"
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/config"
+	"github.com/your-org/your-app/internal/ids"
+)
+
+// Bearer tokens the test server accepts
+const (
+	// AdminToken signs in test-admin, who holds the admin role
+	AdminToken = "test-admin-token"
+	// UserToken signs in test-user, who holds no role
+	UserToken = "test-user-token"
+	// InternalToken authorizes the /internal routes
+	InternalToken = "test-internal-token"
 )
 
-// SetupTestServer creates a configured Echo server for testing
-func SetupTestServer() *echo.Echo {
-	e := echo.New()
-	e.HideBanner = true
-	e.HidePort = true
+// Server is the API built by SetupTestServer
+type Server struct {
+	*echo.Echo
+	// URL is the root of the server, e.g. "http://127.0.0.1:41235", when it
+	// was started with Listen
+	URL string
+}
+
+// Option changes the server SetupTestServer builds
+type Option func(*options)
+
+type options struct {
+	configure []func(*config.Config)
+	verifier  auth.Verifier
+	logger    *zap.Logger
+	clock     clock.Clock
+	ids       ids.Generator
+	fx        []fx.Option
+	listen    bool
+}
+
+// WithConfig changes the test config before the server is built from it
+func WithConfig(fn func(cfg *config.Config)) Option {
+	return func(o *options) {
+		o.configure = append(o.configure, fn)
+	}
+}
+
+// WithVerifier replaces the verifier of end-user tokens, which accepts
+// AdminToken and UserToken by default
+func WithVerifier(v auth.Verifier) Option {
+	return func(o *options) {
+		o.verifier = v
+	}
+}
 
-	// Standard middleware
-	e.Use(middleware.Recover())
-	e.Use(middleware.RequestID())
+// WithLogger replaces the logger, which discards everything by default
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
 
-	// Register handlers
-	healthHandler := handlers.NewHealthHandler("1.0.0-test")
-	helloHandler := handlers.NewHelloHandler()
+// WithClock replaces the wall clock the server stamps records, expires
+// invitations and measures latency by, e.g. with a Clock from NewClock
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
 
-	// Routes
-	e.GET("/health", healthHandler.Health)
+// WithIDs replaces the crypto/rand generator of request IDs, record IDs and
+// tokens, e.g. with an IDs sequence
+func WithIDs(g ids.Generator) Option {
+	return func(o *options) {
+		o.ids = g
+	}
+}
 
-	api := e.Group("/api/v1")
-	api.GET("/health", healthHandler.HealthWithVersion)
-	api.GET("/hello", helloHandler.Hello)
+// Replace swaps provided values for fakes, as fx.Replace does; annotate
+// interface values with fx.As. The user verifier, logger, clock and ID
+// generator have their own options.
+func Replace(values ...any) Option {
+	return func(o *options) {
+		o.fx = append(o.fx, fx.Replace(values...))
+	}
+}
+
+// Decorate wraps or swaps provided values, as fx.Decorate does
+func Decorate(decorators ...any) Option {
+	return func(o *options) {
+		o.fx = append(o.fx, fx.Decorate(decorators...))
+	}
+}
+
+// Populate fills targets from the graph, as fx.Populate does, so tests can
+// reach stores and services behind the routes
+func Populate(targets ...any) Option {
+	return func(o *options) {
+		o.fx = append(o.fx, fx.Populate(targets...))
+	}
+}
+
+// Listen serves the API on a real loopback listener, for end-to-end tests
+// over HTTP; Server.URL is its address
+func Listen() Option {
+	return func(o *options) {
+		o.listen = true
+	}
+}
+
+// Config returns the config of the test server: memory stores, local files
+// under a temporary directory, the in-process job queue and no external
+// providers
+func Config(t testing.TB) *config.Config {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("testutil: load config: %v", err)
+	}
+	cfg.Env = "development"
+	cfg.Store.Backend = config.StoreBackendMemory
+	cfg.Jobs.Backend = config.JobsBackendLocal
+	cfg.Files.Backend = config.FilesBackendLocal
+	cfg.Files.LocalDir = t.TempDir()
+	cfg.Flags.Provider = config.FlagsProviderNone
+	cfg.Cache.Backend = config.CacheBackendMemory
+	cfg.Metrics.Exporter = config.MetricsExporterNone
+	cfg.InternalAuth.Audience = ""
+	cfg.InternalAuth.Token = InternalToken
+	cfg.Maintenance.Mode = "off"
+	cfg.Maintenance.BypassIPs = nil
+	cfg.CORSAllowedOrigins = ""
+	return cfg
+}
+
+// SetupTestServer builds the API from the same fx graph as main, with the
+// test Config and fakes for Firebase Auth, and stops it when the test ends.
+// The clock and IDs stay real unless WithClock or WithIDs is given.
+func SetupTestServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+	o := options{
+		verifier: auth.StaticVerifier{
+			AdminToken: {Subject: "test-admin", Claims: map[string]any{"role": "admin"}},
+			UserToken:  {Subject: "test-user"},
+		},
+		logger: zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg := Config(t)
+	for _, fn := range o.configure {
+		fn(cfg)
+	}
+
+	var listener net.Listener
+	if o.listen {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("testutil: listen: %v", err)
+		}
+		listener = ln
+		_, cfg.Port, _ = net.SplitHostPort(ln.Addr().String())
+		cfg.ServiceURL = "http://" + ln.Addr().String()
+	}
+
+	replace := []any{
+		fx.Annotate(o.verifier, fx.As(new(auth.Verifier))),
+		fx.Annotate(account.NopIdentity{}, fx.As(new(account.Identity))),
+		o.logger,
+	}
+	if o.clock != nil {
+		replace = append(replace, fx.Annotate(o.clock, fx.As(new(clock.Clock))))
+	}
+	if o.ids != nil {
+		replace = append(replace, fx.Annotate(o.ids, fx.As(new(ids.Generator))))
+	}
+
+	s := &Server{}
+	fxOpts := []fx.Option{
+		fx.NopLogger,
+		app.Module(cfg),
+		fx.Replace(replace...),
+		fx.Decorate(func(e *echo.Echo) *echo.Echo {
+			// Echo's access log would otherwise go to stdout
+			e.Logger.SetOutput(io.Discard)
+			e.Listener = listener
+			return e
+		}),
+		fx.Populate(&s.Echo),
+	}
+	fxOpts = append(fxOpts, o.fx...)
+	if o.listen {
+		fxOpts = append(fxOpts, fx.Invoke(app.StartServer))
+		s.URL = cfg.ServiceURL
+	}
+
+	fxApp := fxtest.New(t, fxOpts...)
+	fxApp.RequireStart()
+	t.Cleanup(fxApp.RequireStop)
+	return s
+}
 
-	return e
+// NewClient returns a typed API client of s, serving it over HTTP for the
+// duration of the test unless it was started with Listen. opts.Token is
+// left to the caller; retries are disabled so tests see every response.
+func NewClient(t testing.TB, s *Server, opts client.Options) *client.ClientWithResponses {
+	t.Helper()
+	url := s.URL
+	if url == "" {
+		srv := httptest.NewServer(s)
+		t.Cleanup(srv.Close)
+		url = srv.URL
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = -1
+	}
+	c, err := client.New(url+"/api/v1", opts)
+	if err != nil {
+		t.Fatalf("testutil: create client: %v", err)
+	}
+	return c
 }
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// FirestoreStore keeps endpoints in users/{userId}/webhooks/{endpointId}, or
+// orgs/{orgId}/webhooks/{endpointId} for organizations, and their
+// deliveries in a deliveries subcollection of each endpoint. Organization
+// endpoints go when the organization is deleted.
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore creates a store backed by client
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{client: client}
+}
+
+func (s *FirestoreStore) endpoints(ownerID string) *firestore.CollectionRef {
+	if orgID, ok := strings.CutPrefix(ownerID, orgOwnerPrefix); ok {
+		return s.client.Collection("orgs").Doc(orgID).Collection("webhooks")
+	}
+	return s.client.Collection("users").Doc(ownerID).Collection("webhooks")
+}
+
+func (s *FirestoreStore) deliveries(ownerID, endpointID string) *firestore.CollectionRef {
+	return s.endpoints(ownerID).Doc(endpointID).Collection("deliveries")
+}
+
+// CreateEndpoint implements Store
+func (s *FirestoreStore) CreateEndpoint(ctx context.Context, e Endpoint) error {
+	if _, err := s.endpoints(e.OwnerID).Doc(e.ID).Create(ctx, e); err != nil {
+		return fmt.Errorf("webhooks: create endpoint %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// GetEndpoint implements Store
+func (s *FirestoreStore) GetEndpoint(ctx context.Context, ownerID, id string) (*Endpoint, error) {
+	snap, err := s.endpoints(ownerID).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: get endpoint %s: %w", id, err)
+	}
+
+	var e Endpoint
+	if err := snap.DataTo(&e); err != nil {
+		return nil, err
+	}
+	e.ID = snap.Ref.ID
+	return &e, nil
+}
+
+// ListEndpoints implements Store
+func (s *FirestoreStore) ListEndpoints(ctx context.Context, ownerID string) ([]Endpoint, error) {
+	docs, err := s.endpoints(ownerID).OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: list endpoints: %w", err)
+	}
+
+	out := make([]Endpoint, 0, len(docs))
+	for _, doc := range docs {
+		var e Endpoint
+		if err := doc.DataTo(&e); err != nil {
+			return nil, err
+		}
+		e.ID = doc.Ref.ID
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// DeleteEndpoint implements Store. Firestore does not delete subcollections
+// with their parent, so the delivery log is removed first.
+func (s *FirestoreStore) DeleteEndpoint(ctx context.Context, ownerID, id string) error {
+	iter := s.deliveries(ownerID, id).Documents(ctx)
+	defer iter.Stop()
+
+	writer := s.client.BulkWriter(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			writer.End()
+			return fmt.Errorf("webhooks: delete endpoint %s: %w", id, err)
+		}
+		if _, err := writer.Delete(doc.Ref); err != nil {
+			writer.End()
+			return fmt.Errorf("webhooks: delete endpoint %s: %w", id, err)
+		}
+	}
+	writer.End()
+
+	if _, err := s.endpoints(ownerID).Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("webhooks: delete endpoint %s: %w", id, err)
+	}
+	return nil
+}
+
+// CreateDelivery implements Store
+func (s *FirestoreStore) CreateDelivery(ctx context.Context, d Delivery) error {
+	if _, err := s.deliveries(d.OwnerID, d.EndpointID).Doc(d.ID).Create(ctx, d); err != nil {
+		return fmt.Errorf("webhooks: create delivery %s: %w", d.ID, err)
+	}
+	return nil
+}
+
+// GetDelivery implements Store
+func (s *FirestoreStore) GetDelivery(ctx context.Context, ownerID, endpointID, id string) (*Delivery, error) {
+	snap, err := s.deliveries(ownerID, endpointID).Doc(id).Get(ctx)
+	if store.IsNotFound(err) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: get delivery %s: %w", id, err)
+	}
+
+	var d Delivery
+	if err := snap.DataTo(&d); err != nil {
+		return nil, err
+	}
+	d.ID = snap.Ref.ID
+	return &d, nil
+}
+
+// UpdateDelivery implements Store
+func (s *FirestoreStore) UpdateDelivery(ctx context.Context, d Delivery) error {
+	if _, err := s.deliveries(d.OwnerID, d.EndpointID).Doc(d.ID).Set(ctx, d); err != nil {
+		return fmt.Errorf("webhooks: update delivery %s: %w", d.ID, err)
+	}
+	return nil
+}
+
+// ListDeliveries implements Store
+func (s *FirestoreStore) ListDeliveries(ctx context.Context, ownerID, endpointID string, limit int) ([]Delivery, error) {
+	return s.queryDeliveries(ctx, s.deliveries(ownerID, endpointID).OrderBy("createdAt", firestore.Desc), limit)
+}
+
+// ListDeliveriesByStatus implements Store. It queries the deliveries
+// collection group, which needs the (status, createdAt) index declared in
+// firestore.indexes.json.
+func (s *FirestoreStore) ListDeliveriesByStatus(ctx context.Context, status DeliveryStatus, limit int) ([]Delivery, error) {
+	query := s.client.CollectionGroup("deliveries").
+		Where("status", "==", string(status)).
+		OrderBy("createdAt", firestore.Desc)
+	return s.queryDeliveries(ctx, query, limit)
+}
+
+func (s *FirestoreStore) queryDeliveries(ctx context.Context, query firestore.Query, limit int) ([]Delivery, error) {
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: list deliveries: %w", err)
+	}
+
+	out := make([]Delivery, 0, len(docs))
+	for _, doc := range docs {
+		var d Delivery
+		if err := doc.DataTo(&d); err != nil {
+			return nil, err
+		}
+		d.ID = doc.Ref.ID
+		out = append(out, d)
+	}
+	return out, nil
+}
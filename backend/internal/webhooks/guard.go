@@ -0,0 +1,155 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInvalidURL is returned for endpoint URLs that cannot be delivered to
+	ErrInvalidURL = errors.New("webhooks: invalid endpoint url")
+	// ErrPrivateAddress is returned for endpoints resolving to non-public addresses
+	ErrPrivateAddress = errors.New("webhooks: endpoint resolves to a private address")
+)
+
+// blockedPrefixes are special-purpose ranges not covered by the netip
+// predicates used in isPublic
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, incl. broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, may map to private IPv4
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// isPublic reports whether addr is a globally routable unicast address
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Guard keeps deliveries away from internal infrastructure (SSRF). URLs are
+// checked at registration, and every connection is checked again after DNS
+// resolution, so a hostname cannot be re-pointed at a private address later.
+type Guard struct {
+	// AllowPrivate disables address checks, for local development only
+	AllowPrivate bool
+	// RequireHTTPS rejects plain http endpoints
+	RequireHTTPS bool
+
+	lookup func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// ValidateURL checks that raw is an absolute http(s) URL without credentials
+// whose host only resolves to public addresses
+func (g *Guard) ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	switch {
+	case u.Scheme != "https" && u.Scheme != "http":
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidURL)
+	case g.RequireHTTPS && u.Scheme != "https":
+		return fmt.Errorf("%w: scheme must be https", ErrInvalidURL)
+	case u.Hostname() == "":
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
+	case u.User != nil:
+		return fmt.Errorf("%w: credentials are not allowed in the url", ErrInvalidURL)
+	case u.Fragment != "":
+		return fmt.Errorf("%w: fragments are not allowed", ErrInvalidURL)
+	case len(raw) > 2048:
+		return fmt.Errorf("%w: url too long", ErrInvalidURL)
+	}
+
+	if g.AllowPrivate {
+		return nil
+	}
+
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublic(addr) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	lookup := g.lookup
+	if lookup == nil {
+		lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		}
+	}
+	addrs, err := lookup(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve host %q", ErrInvalidURL, host)
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// control rejects connections to non-public addresses after resolution
+func (g *Guard) control(_, address string, _ syscall.RawConn) error {
+	if g.AllowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// Client returns an HTTP client that enforces the guard on every connection,
+// ignores proxy settings (the proxy would be the one dialing) and does not
+// follow redirects (a redirect could point anywhere)
+func (g *Guard) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: g.control,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"evt_1"}`)
+	valid := Sign("secret", now, body)
+
+	tests := []struct {
+		name        string
+		secret      string
+		header      string
+		body        []byte
+		now         time.Time
+		expectedErr error
+	}{
+		{name: "accepts valid signature", secret: "secret", header: valid, body: body, now: now},
+		{name: "accepts signature within tolerance", secret: "secret", header: valid, body: body, now: now.Add(4 * time.Minute)},
+		{name: "accepts any of several signatures", secret: "secret", header: valid + ",v1=deadbeef", body: body, now: now},
+		{name: "rejects other secret", secret: "other", header: valid, body: body, now: now, expectedErr: ErrInvalidSignature},
+		{name: "rejects modified body", secret: "secret", header: valid, body: []byte(`{"id":"evt_2"}`), now: now, expectedErr: ErrInvalidSignature},
+		{name: "rejects expired timestamp", secret: "secret", header: valid, body: body, now: now.Add(6 * time.Minute), expectedErr: ErrSignatureExpired},
+		{name: "rejects future timestamp", secret: "secret", header: valid, body: body, now: now.Add(-6 * time.Minute), expectedErr: ErrSignatureExpired},
+		{name: "rejects missing timestamp", secret: "secret", header: "v1=abc", body: body, now: now, expectedErr: ErrInvalidSignature},
+		{name: "rejects missing signature", secret: "secret", header: "t=1700000000", body: body, now: now, expectedErr: ErrInvalidSignature},
+		{name: "rejects empty header", secret: "secret", header: "", body: body, now: now, expectedErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Verify(tt.secret, tt.header, tt.body, DefaultTolerance, tt.now)
+
+			// Assert
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestSign_Format(t *testing.T) {
+	// Act
+	header := Sign("secret", time.Unix(1700000000, 0), []byte("body"))
+
+	// Assert
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+}
@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/your-org/your-app/internal/store"
+)
+
+// Store persists endpoints and their delivery log, scoped by owner
+type Store interface {
+	CreateEndpoint(ctx context.Context, e Endpoint) error
+	// GetEndpoint returns store.ErrNotFound if ownerID has no endpoint id
+	GetEndpoint(ctx context.Context, ownerID, id string) (*Endpoint, error)
+	// ListEndpoints returns the owner's endpoints, oldest first
+	ListEndpoints(ctx context.Context, ownerID string) ([]Endpoint, error)
+	// DeleteEndpoint removes an endpoint together with its deliveries
+	DeleteEndpoint(ctx context.Context, ownerID, id string) error
+
+	CreateDelivery(ctx context.Context, d Delivery) error
+	// GetDelivery returns store.ErrNotFound if the endpoint has no delivery id
+	GetDelivery(ctx context.Context, ownerID, endpointID, id string) (*Delivery, error)
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	// ListDeliveries returns the endpoint's deliveries, newest first
+	ListDeliveries(ctx context.Context, ownerID, endpointID string, limit int) ([]Delivery, error)
+	// ListDeliveriesByStatus returns deliveries in status across owners,
+	// newest first
+	ListDeliveriesByStatus(ctx context.Context, status DeliveryStatus, limit int) ([]Delivery, error)
+}
+
+// MemoryStore is an in-process Store for local development and tests
+type MemoryStore struct {
+	mu         sync.Mutex
+	endpoints  map[string]Endpoint
+	deliveries map[string]Delivery
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		endpoints:  make(map[string]Endpoint),
+		deliveries: make(map[string]Delivery),
+	}
+}
+
+func endpointKey(ownerID, id string) string {
+	return ownerID + "/" + id
+}
+
+func deliveryKey(ownerID, endpointID, id string) string {
+	return ownerID + "/" + endpointID + "/" + id
+}
+
+// CreateEndpoint implements Store
+func (s *MemoryStore) CreateEndpoint(_ context.Context, e Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints[endpointKey(e.OwnerID, e.ID)] = e
+	return nil
+}
+
+// GetEndpoint implements Store
+func (s *MemoryStore) GetEndpoint(_ context.Context, ownerID, id string) (*Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.endpoints[endpointKey(ownerID, id)]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &e, nil
+}
+
+// ListEndpoints implements Store
+func (s *MemoryStore) ListEndpoints(_ context.Context, ownerID string) ([]Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Endpoint
+	for _, e := range s.endpoints {
+		if e.OwnerID == ownerID {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// DeleteEndpoint implements Store
+func (s *MemoryStore) DeleteEndpoint(_ context.Context, ownerID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.endpoints, endpointKey(ownerID, id))
+	for key, d := range s.deliveries {
+		if d.OwnerID == ownerID && d.EndpointID == id {
+			delete(s.deliveries, key)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery implements Store
+func (s *MemoryStore) CreateDelivery(_ context.Context, d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[deliveryKey(d.OwnerID, d.EndpointID, d.ID)] = d
+	return nil
+}
+
+// GetDelivery implements Store
+func (s *MemoryStore) GetDelivery(_ context.Context, ownerID, endpointID, id string) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[deliveryKey(ownerID, endpointID, id)]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &d, nil
+}
+
+// UpdateDelivery implements Store
+func (s *MemoryStore) UpdateDelivery(_ context.Context, d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := deliveryKey(d.OwnerID, d.EndpointID, d.ID)
+	if _, ok := s.deliveries[key]; !ok {
+		return store.ErrNotFound
+	}
+	s.deliveries[key] = d
+	return nil
+}
+
+// ListDeliveries implements Store
+func (s *MemoryStore) ListDeliveries(_ context.Context, ownerID, endpointID string, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Delivery
+	for _, d := range s.deliveries {
+		if d.OwnerID == ownerID && d.EndpointID == endpointID {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ListDeliveriesByStatus implements Store
+func (s *MemoryStore) ListDeliveriesByStatus(_ context.Context, status DeliveryStatus, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Delivery
+	for _, d := range s.deliveries {
+		if d.Status == status {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,522 @@
+// Package webhooks notifies integrators' HTTP endpoints about events.
+//
+// Owners, users or organizations, register endpoints for event types from
+// their Catalogue or OrgCatalogue. Publish
+// records one delivery per subscribed endpoint and hands it to the job
+// queue; each attempt POSTs the signed event and, on failure, schedules the
+// next attempt with exponential backoff. Deliveries that exhaust their
+// attempts are kept as dead letters and can be replayed.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/clock"
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/ids"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/orgs"
+	"github.com/your-org/your-app/internal/store"
+)
+
+// EventPing is sent by Service.Ping to test an endpoint; it needs no subscription
+const EventPing = "webhook.ping"
+
+// maxEndpoints caps the endpoints a single owner may register
+const maxEndpoints = 10
+
+var (
+	// ErrNotFound is returned for unknown endpoints or deliveries, or those of another owner
+	ErrNotFound = errors.New("webhooks: not found")
+	// ErrNoEvents is returned when registering an endpoint without event types
+	ErrNoEvents = errors.New("webhooks: at least one event type is required")
+	// ErrUnknownEvent is returned for event types outside the catalogue
+	ErrUnknownEvent = errors.New("webhooks: unknown event type")
+	// ErrTooManyEndpoints is returned when an owner reaches the endpoint limit
+	ErrTooManyEndpoints = errors.New("webhooks: endpoint limit reached")
+)
+
+// EventType describes an event endpoints can subscribe to
+type EventType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Catalogue lists the events user endpoints can subscribe to. Each one is
+// also declared as a callback of the registration operation in
+// api/openapi.yaml.
+var Catalogue = []EventType{
+	{Name: files.EventReady, Description: "An uploaded file passed validation and can be downloaded"},
+	{Name: files.EventRejected, Description: "An uploaded file failed validation and was deleted"},
+	{Name: files.EventDeleted, Description: "A file was deleted by its owner"},
+}
+
+// OrgCatalogue lists the events organization endpoints can subscribe to,
+// declared as callbacks of the organization registration operation
+var OrgCatalogue = []EventType{
+	{Name: orgs.EventMemberJoined, Description: "Someone accepted an invitation and joined the organization"},
+	{Name: orgs.EventMemberUpdated, Description: "A member's role changed"},
+	{Name: orgs.EventMemberRemoved, Description: "A member left or was removed"},
+	{Name: orgs.EventInvitationCreated, Description: "Someone was invited to the organization"},
+}
+
+// orgOwnerPrefix starts the owner IDs of organization endpoints. User IDs
+// never contain a slash, as they name users/{userId} documents, so the two
+// cannot collide.
+const orgOwnerPrefix = "orgs/"
+
+// OrgOwner returns the owner ID of orgID's endpoints
+func OrgOwner(orgID string) string {
+	return orgOwnerPrefix + orgID
+}
+
+// CatalogueOf returns the events the endpoints of ownerID can subscribe to
+func CatalogueOf(ownerID string) []EventType {
+	if strings.HasPrefix(ownerID, orgOwnerPrefix) {
+		return OrgCatalogue
+	}
+	return Catalogue
+}
+
+func knownEvent(ownerID, name string) bool {
+	return slices.ContainsFunc(CatalogueOf(ownerID), func(e EventType) bool { return e.Name == name })
+}
+
+// Endpoint is a registered webhook receiver
+type Endpoint struct {
+	ID          string    `firestore:"-" json:"id"`
+	OwnerID     string    `firestore:"ownerId" json:"ownerId"`
+	URL         string    `firestore:"url" json:"url"`
+	Description string    `firestore:"description" json:"description,omitempty"`
+	Events      []string  `firestore:"events" json:"events"`
+	Secret      string    `firestore:"secret" json:"-"`
+	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// CreatedEndpoint is returned once on registration; the secret is not shown again
+type CreatedEndpoint struct {
+	Endpoint
+	Secret string `json:"secret"`
+}
+
+// EndpointRequest registers an endpoint
+type EndpointRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+}
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+// Delivery states
+const (
+	// DeliveryPending deliveries have an attempt scheduled
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded deliveries received a 2xx response
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead deliveries exhausted their attempts
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is the log entry of one event sent to one endpoint
+type Delivery struct {
+	ID             string         `firestore:"-" json:"id"`
+	OwnerID        string         `firestore:"ownerId" json:"-"`
+	EndpointID     string         `firestore:"endpointId" json:"endpointId"`
+	EventID        string         `firestore:"eventId" json:"eventId"`
+	EventType      string         `firestore:"eventType" json:"eventType"`
+	Payload        string         `firestore:"payload" json:"-"`
+	Status         DeliveryStatus `firestore:"status" json:"status"`
+	Attempts       int            `firestore:"attempts" json:"attempts"`
+	ResponseStatus int            `firestore:"responseStatus" json:"responseStatus,omitempty"`
+	LastError      string         `firestore:"lastError" json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time     `firestore:"nextAttemptAt" json:"nextAttemptAt,omitempty"`
+	ReplayOf       string         `firestore:"replayOf" json:"replayOf,omitempty"`
+	CreatedAt      time.Time      `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time      `firestore:"updatedAt" json:"updatedAt"`
+}
+
+// Event is the JSON body POSTed to endpoints
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// Options tunes delivery
+type Options struct {
+	// MaxAttempts is the number of attempts before a delivery is dead
+	MaxAttempts int
+	// MinBackoff is the delay after the first failed attempt; it doubles per attempt
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// Timeout bounds a single attempt
+	Timeout time.Duration
+	// Clock and IDs default to the wall clock and crypto/rand
+	Clock clock.Clock
+	IDs   ids.Generator
+}
+
+// deliverJob runs one attempt of a delivery
+type deliverJob struct {
+	OwnerID    string `json:"ownerId"`
+	EndpointID string `json:"endpointId"`
+	DeliveryID string `json:"deliveryId"`
+	Attempt    int    `json:"attempt"`
+}
+
+func (deliverJob) JobType() string { return "webhooks.deliver" }
+
+// Service manages endpoints and delivers events to them
+type Service struct {
+	store  Store
+	queue  *jobs.Queue
+	guard  *Guard
+	client *http.Client
+	opts   Options
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a webhook service and registers its delivery job with
+// registry, which must be the registry behind queue
+func NewService(st Store, queue *jobs.Queue, registry *jobs.Registry, guard *Guard, opts Options, logger *zap.Logger) *Service {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+	opts.IDs = ids.OrRandom(opts.IDs)
+
+	s := &Service{
+		store:  st,
+		queue:  queue,
+		guard:  guard,
+		client: guard.Client(opts.Timeout),
+		opts:   opts,
+		logger: logger,
+		now:    clock.OrSystem(opts.Clock).Now,
+	}
+	// Attempts are scheduled by the service itself; queue-level retries only
+	// cover failures to read or record a delivery
+	jobs.Handle(registry, 3, s.deliver)
+	return s
+}
+
+// CreateEndpoint registers an endpoint for ownerID and generates its signing secret
+func (s *Service) CreateEndpoint(ctx context.Context, ownerID string, req EndpointRequest) (*CreatedEndpoint, error) {
+	if len(req.Events) == 0 {
+		return nil, ErrNoEvents
+	}
+	for _, event := range req.Events {
+		if !knownEvent(ownerID, event) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+		}
+	}
+	if err := s.guard.ValidateURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.ListEndpoints(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxEndpoints {
+		return nil, ErrTooManyEndpoints
+	}
+
+	id := s.newID("we", 12)
+	secret := s.newID("whsec", 32)
+
+	slices.Sort(req.Events)
+	e := Endpoint{
+		ID:          id,
+		OwnerID:     ownerID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      slices.Compact(req.Events),
+		Secret:      secret,
+		CreatedAt:   s.now(),
+	}
+	if err := s.store.CreateEndpoint(ctx, e); err != nil {
+		return nil, err
+	}
+	return &CreatedEndpoint{Endpoint: e, Secret: secret}, nil
+}
+
+// ListEndpoints returns the owner's endpoints
+func (s *Service) ListEndpoints(ctx context.Context, ownerID string) ([]Endpoint, error) {
+	return s.store.ListEndpoints(ctx, ownerID)
+}
+
+// GetEndpoint returns one of the owner's endpoints
+func (s *Service) GetEndpoint(ctx context.Context, ownerID, id string) (*Endpoint, error) {
+	e, err := s.store.GetEndpoint(ctx, ownerID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return e, err
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log. Scheduled attempts
+// find the delivery gone and stop.
+func (s *Service) DeleteEndpoint(ctx context.Context, ownerID, id string) error {
+	if _, err := s.GetEndpoint(ctx, ownerID, id); err != nil {
+		return err
+	}
+	return s.store.DeleteEndpoint(ctx, ownerID, id)
+}
+
+// ListDeliveries returns the endpoint's delivery log, newest first
+func (s *Service) ListDeliveries(ctx context.Context, ownerID, endpointID string, limit int) ([]Delivery, error) {
+	if _, err := s.GetEndpoint(ctx, ownerID, endpointID); err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(ctx, ownerID, endpointID, limit)
+}
+
+// DeadDeliveries returns the deliveries of every owner that exhausted their
+// attempts, newest first, for operators to replay
+func (s *Service) DeadDeliveries(ctx context.Context, limit int) ([]Delivery, error) {
+	return s.store.ListDeliveriesByStatus(ctx, DeliveryDead, limit)
+}
+
+// Publish sends an event to every endpoint of ownerID subscribed to eventType
+func (s *Service) Publish(ctx context.Context, ownerID, eventType string, data any) error {
+	endpoints, err := s.store.ListEndpoints(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	var subscribed []Endpoint
+	for _, e := range endpoints {
+		if slices.Contains(e.Events, eventType) {
+			subscribed = append(subscribed, e)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	event, payload, err := s.newEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, e := range subscribed {
+		if _, err := s.schedule(ctx, e, event, payload, ""); err != nil {
+			errs = append(errs, fmt.Errorf("webhooks: endpoint %s: %w", e.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ping sends a webhook.ping event to one of the owner's endpoints
+func (s *Service) Ping(ctx context.Context, ownerID, endpointID string) (*Delivery, error) {
+	e, err := s.GetEndpoint(ctx, ownerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, payload, err := s.newEvent(EventPing, map[string]string{"endpointId": e.ID})
+	if err != nil {
+		return nil, err
+	}
+	return s.schedule(ctx, *e, event, payload, "")
+}
+
+// Replay sends the event of a previous delivery again as a new delivery,
+// typically after a dead letter once the receiver has been fixed
+func (s *Service) Replay(ctx context.Context, ownerID, endpointID, deliveryID string) (*Delivery, error) {
+	e, err := s.GetEndpoint(ctx, ownerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	orig, err := s.store.GetDelivery(ctx, ownerID, endpointID, deliveryID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := Event{ID: orig.EventID, Type: orig.EventType}
+	return s.schedule(ctx, *e, event, orig.Payload, orig.ID)
+}
+
+func (s *Service) newEvent(eventType string, data any) (Event, string, error) {
+	event := Event{ID: s.newID("evt", 12), Type: eventType, CreatedAt: s.now().UTC(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Event{}, "", fmt.Errorf("webhooks: marshal %s: %w", eventType, err)
+	}
+	return event, string(payload), nil
+}
+
+// schedule records a pending delivery and enqueues its first attempt
+func (s *Service) schedule(ctx context.Context, e Endpoint, event Event, payload, replayOf string) (*Delivery, error) {
+	id := s.newID("wd", 12)
+	now := s.now()
+	d := Delivery{
+		ID:            id,
+		OwnerID:       e.OwnerID,
+		EndpointID:    e.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOf:      replayOf,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.store.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.enqueue(ctx, d, 1, 0); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (s *Service) enqueue(ctx context.Context, d Delivery, attempt int, delay time.Duration) error {
+	err := s.queue.Enqueue(ctx, deliverJob{
+		OwnerID:    d.OwnerID,
+		EndpointID: d.EndpointID,
+		DeliveryID: d.ID,
+		Attempt:    attempt,
+	}, jobs.Options{
+		Delay: delay,
+		Name:  fmt.Sprintf("%s-%d", d.ID, attempt),
+	})
+	if errors.Is(err, jobs.ErrDuplicate) {
+		return nil
+	}
+	return err
+}
+
+// deliver runs one attempt. It is idempotent: attempts for deliveries that
+// are gone, finished or already past this attempt are dropped.
+func (s *Service) deliver(ctx context.Context, job deliverJob) error {
+	d, err := s.store.GetDelivery(ctx, job.OwnerID, job.EndpointID, job.DeliveryID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if d.Status != DeliveryPending || d.Attempts >= job.Attempt {
+		return nil
+	}
+
+	e, err := s.store.GetEndpoint(ctx, job.OwnerID, job.EndpointID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	status, sendErr := s.send(ctx, e, d)
+
+	now := s.now()
+	d.Attempts = job.Attempt
+	d.ResponseStatus = status
+	d.UpdatedAt = now
+	d.NextAttemptAt = nil
+	d.LastError = ""
+
+	log := s.logger.With(
+		zap.String("delivery_id", d.ID),
+		zap.String("endpoint_id", d.EndpointID),
+		zap.String("event_type", d.EventType),
+		zap.Int("attempt", d.Attempts),
+	)
+
+	var delay time.Duration
+	switch {
+	case sendErr == nil:
+		d.Status = DeliverySucceeded
+	case d.Attempts >= s.opts.MaxAttempts:
+		d.Status = DeliveryDead
+		d.LastError = sendErr.Error()
+		log.Warn("webhook delivery dead-lettered", zap.Error(sendErr))
+	default:
+		d.LastError = sendErr.Error()
+		delay = s.backoff(d.Attempts)
+		next := now.Add(delay)
+		d.NextAttemptAt = &next
+		log.Info("webhook delivery failed, retrying", zap.Duration("delay", delay), zap.Error(sendErr))
+	}
+
+	if err := s.store.UpdateDelivery(ctx, *d); err != nil {
+		return err
+	}
+	if d.Status == DeliveryPending {
+		return s.enqueue(ctx, *d, d.Attempts+1, delay)
+	}
+	return nil
+}
+
+// send POSTs the signed payload and returns the response status
+func (s *Service) send(ctx context.Context, e *Endpoint, d *Delivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "your-app-webhooks/1.0")
+	req.Header.Set(HeaderID, d.EventID)
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderSignature, Sign(e.Secret, s.now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after failed attempt n: MinBackoff doubled per
+// attempt, capped at MaxBackoff, with up to 10% jitter so failing receivers
+// are not hit by synchronised retries
+func (s *Service) backoff(attempt int) time.Duration {
+	delay := s.opts.MinBackoff
+	for i := 1; i < attempt && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, s.opts.MaxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay + mathrand.N(delay/10+1)
+}
+
+// newID returns prefix_ followed by n random bytes in hex
+func (s *Service) newID(prefix string, n int) string {
+	return prefix + "_" + s.opts.IDs.NewID(n)
+}
@@ -0,0 +1,393 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/your-org/your-app/internal/files"
+	"github.com/your-org/your-app/internal/jobs"
+	"github.com/your-org/your-app/internal/orgs"
+)
+
+// receiver is a webhook endpoint answering with the configured statuses in turn
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+type testEnv struct {
+	service *Service
+	backend *jobs.LocalBackend
+	store   *MemoryStore
+}
+
+func setupService(t *testing.T, maxAttempts int) *testEnv {
+	t.Helper()
+
+	registry := jobs.NewRegistry()
+	backend := jobs.NewLocalBackend(registry, zap.NewNop(), jobs.LocalOptions{Workers: 2})
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	st := NewMemoryStore()
+	service := NewService(st, jobs.NewQueue(backend, registry, jobs.QueueOptions{}), registry, &Guard{AllowPrivate: true}, Options{
+		MaxAttempts: maxAttempts,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  4 * time.Millisecond,
+		Timeout:     time.Second,
+	}, zap.NewNop())
+
+	return &testEnv{service: service, backend: backend, store: st}
+}
+
+func (env *testEnv) register(t *testing.T, url string, events ...string) *CreatedEndpoint {
+	t.Helper()
+	created, err := env.service.CreateEndpoint(context.Background(), "user-1", EndpointRequest{URL: url, Events: events})
+	require.NoError(t, err)
+	return created
+}
+
+func (env *testEnv) deliveries(t *testing.T, endpointID string) []Delivery {
+	t.Helper()
+	list, err := env.service.ListDeliveries(context.Background(), "user-1", endpointID, 0)
+	require.NoError(t, err)
+	return list
+}
+
+func TestService_Publish_DeliversSignedEvent(t *testing.T) {
+	// Arrange
+	env := setupService(t, 3)
+	rcv := newReceiver(t)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+
+	// Act
+	err := env.service.Publish(context.Background(), "user-1", files.EventReady, map[string]string{"id": "file-1"})
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, rcv.count())
+
+	req, body := rcv.requests[0], rcv.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, files.EventReady, req.Header.Get(HeaderEvent))
+	assert.NoError(t, Verify(endpoint.Secret, req.Header.Get(HeaderSignature), body, DefaultTolerance, time.Now()))
+
+	var event Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, req.Header.Get(HeaderID), event.ID)
+	assert.Equal(t, files.EventReady, event.Type)
+	assert.Equal(t, map[string]any{"id": "file-1"}, event.Data)
+
+	deliveries := env.deliveries(t, endpoint.ID)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+	assert.Nil(t, deliveries[0].NextAttemptAt)
+}
+
+func TestService_Publish_OnlySubscribedEndpoints(t *testing.T) {
+	// Arrange
+	env := setupService(t, 3)
+	subscribed := newReceiver(t)
+	other := newReceiver(t)
+	env.register(t, subscribed.server.URL, files.EventDeleted, files.EventReady)
+	env.register(t, other.server.URL, files.EventRejected)
+
+	// Act
+	err := env.service.Publish(context.Background(), "user-1", files.EventDeleted, nil)
+	otherOwnerErr := env.service.Publish(context.Background(), "user-2", files.EventDeleted, nil)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, otherOwnerErr)
+	assert.Equal(t, 1, subscribed.count())
+	assert.Equal(t, 0, other.count())
+}
+
+func TestService_Publish_OrgEndpoints(t *testing.T) {
+	// Arrange
+	env := setupService(t, 3)
+	rcv := newReceiver(t)
+	ctx := context.Background()
+	owner := OrgOwner("org_1")
+	endpoint, err := env.service.CreateEndpoint(ctx, owner, EndpointRequest{URL: rcv.server.URL, Events: []string{orgs.EventMemberJoined}})
+	require.NoError(t, err)
+
+	// Act
+	err = env.service.Publish(ctx, owner, orgs.EventMemberJoined, map[string]string{"userId": "dave"})
+	otherOrgErr := env.service.Publish(ctx, OrgOwner("org_2"), orgs.EventMemberJoined, nil)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, otherOrgErr)
+	require.Equal(t, 1, rcv.count())
+	assert.Equal(t, orgs.EventMemberJoined, rcv.requests[0].Header.Get(HeaderEvent))
+	deliveries, err := env.service.ListDeliveries(ctx, owner, endpoint.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliverySucceeded, deliveries[0].Status)
+	_, err = env.service.GetEndpoint(ctx, "user-1", endpoint.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "organization endpoints are not the user's")
+}
+
+func TestService_Deliver_RetriesWithBackoff(t *testing.T) {
+	// Arrange
+	env := setupService(t, 5)
+	rcv := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+
+	// Act
+	err := env.service.Publish(context.Background(), "user-1", files.EventReady, nil)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, rcv.count())
+
+	// Every attempt carries the same event ID, so receivers can deduplicate
+	assert.Equal(t, rcv.requests[0].Header.Get(HeaderID), rcv.requests[2].Header.Get(HeaderID))
+
+	deliveries := env.deliveries(t, endpoint.ID)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Empty(t, deliveries[0].LastError)
+}
+
+func TestService_Deliver_DeadLettersAndReplays(t *testing.T) {
+	// Arrange
+	env := setupService(t, 2)
+	rcv := newReceiver(t, http.StatusInternalServerError, http.StatusGone)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+	ctx := context.Background()
+
+	require.NoError(t, env.service.Publish(ctx, "user-1", files.EventReady, nil))
+	env.backend.Wait()
+	dead := env.deliveries(t, endpoint.ID)[0]
+
+	// Act
+	allDead, listErr := env.service.DeadDeliveries(ctx, 10)
+	replay, err := env.service.Replay(ctx, "user-1", endpoint.ID, dead.ID)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, listErr)
+	assert.Equal(t, []Delivery{dead}, allDead)
+	assert.Equal(t, DeliveryDead, dead.Status)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Equal(t, http.StatusGone, dead.ResponseStatus)
+	assert.Equal(t, "endpoint responded 410", dead.LastError)
+
+	require.NoError(t, err)
+	assert.Equal(t, dead.ID, replay.ReplayOf)
+	assert.Equal(t, dead.EventID, replay.EventID)
+	assert.Equal(t, 3, rcv.count())
+	assert.Equal(t, rcv.bodies[0], rcv.bodies[2])
+
+	replayed, err := env.store.GetDelivery(ctx, "user-1", endpoint.ID, replay.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliverySucceeded, replayed.Status)
+}
+
+func TestService_Deliver_BlocksPrivateAddressAtConnect(t *testing.T) {
+	// Arrange
+	env := setupService(t, 1)
+	rcv := newReceiver(t)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+
+	// The endpoint passed registration, then its host moved to a private address
+	env.service.guard.AllowPrivate = false
+
+	// Act
+	err := env.service.Publish(context.Background(), "user-1", files.EventReady, nil)
+	env.backend.Wait()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, rcv.count())
+	deliveries := env.deliveries(t, endpoint.ID)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliveryDead, deliveries[0].Status)
+	assert.Contains(t, deliveries[0].LastError, ErrPrivateAddress.Error())
+}
+
+func TestService_Ping(t *testing.T) {
+	// Arrange
+	env := setupService(t, 1)
+	rcv := newReceiver(t)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+
+	// Act
+	delivery, err := env.service.Ping(context.Background(), "user-1", endpoint.ID)
+	env.backend.Wait()
+	_, otherErr := env.service.Ping(context.Background(), "user-2", endpoint.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, EventPing, delivery.EventType)
+	assert.Equal(t, 1, rcv.count())
+	assert.Equal(t, EventPing, rcv.requests[0].Header.Get(HeaderEvent))
+	assert.ErrorIs(t, otherErr, ErrNotFound)
+}
+
+func TestService_CreateEndpoint_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		owner       string
+		req         EndpointRequest
+		expectedErr error
+	}{
+		{
+			name:        "requires events",
+			req:         EndpointRequest{URL: "http://127.0.0.1/hook"},
+			expectedErr: ErrNoEvents,
+		},
+		{
+			name:        "rejects unknown event",
+			req:         EndpointRequest{URL: "http://127.0.0.1/hook", Events: []string{"user.created"}},
+			expectedErr: ErrUnknownEvent,
+		},
+		{
+			name:        "rejects ping subscription",
+			req:         EndpointRequest{URL: "http://127.0.0.1/hook", Events: []string{EventPing}},
+			expectedErr: ErrUnknownEvent,
+		},
+		{
+			name:        "rejects organization event for a user",
+			req:         EndpointRequest{URL: "http://127.0.0.1/hook", Events: []string{orgs.EventMemberJoined}},
+			expectedErr: ErrUnknownEvent,
+		},
+		{
+			name:        "rejects file event for an organization",
+			owner:       OrgOwner("org_1"),
+			req:         EndpointRequest{URL: "http://127.0.0.1/hook", Events: []string{files.EventReady}},
+			expectedErr: ErrUnknownEvent,
+		},
+		{
+			name:        "rejects invalid url",
+			req:         EndpointRequest{URL: "file:///etc/passwd", Events: []string{files.EventReady}},
+			expectedErr: ErrInvalidURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			env := setupService(t, 1)
+			owner := tt.owner
+			if owner == "" {
+				owner = "user-1"
+			}
+
+			// Act
+			_, err := env.service.CreateEndpoint(context.Background(), owner, tt.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestService_CreateEndpoint_Limit(t *testing.T) {
+	// Arrange
+	env := setupService(t, 1)
+	for range maxEndpoints {
+		env.register(t, "http://127.0.0.1/hook", files.EventReady)
+	}
+
+	// Act
+	_, err := env.service.CreateEndpoint(context.Background(), "user-1", EndpointRequest{
+		URL: "http://127.0.0.1/hook", Events: []string{files.EventReady},
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyEndpoints)
+}
+
+func TestService_DeleteEndpoint_StopsDeliveries(t *testing.T) {
+	// Arrange
+	env := setupService(t, 3)
+	rcv := newReceiver(t)
+	endpoint := env.register(t, rcv.server.URL, files.EventReady)
+	ctx := context.Background()
+
+	// Act
+	err := env.service.DeleteEndpoint(ctx, "user-1", endpoint.ID)
+	publishErr := env.service.Publish(ctx, "user-1", files.EventReady, nil)
+	env.backend.Wait()
+	_, listErr := env.service.ListDeliveries(ctx, "user-1", endpoint.ID, 0)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, publishErr)
+	assert.Equal(t, 0, rcv.count())
+	assert.ErrorIs(t, listErr, ErrNotFound)
+}
+
+func TestService_Backoff(t *testing.T) {
+	// Arrange
+	s := &Service{opts: Options{MinBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}}
+
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{attempt: 1, base: 30 * time.Second},
+		{attempt: 2, base: time.Minute},
+		{attempt: 3, base: 2 * time.Minute},
+		{attempt: 6, base: 10 * time.Minute},
+		{attempt: 20, base: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		// Act
+		delay := s.backoff(tt.attempt)
+
+		// Assert
+		assert.GreaterOrEqual(t, delay, tt.base, "attempt %d", tt.attempt)
+		assert.LessOrEqual(t, delay, tt.base+tt.base/10, "attempt %d", tt.attempt)
+	}
+}
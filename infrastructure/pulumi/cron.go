@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/cloudscheduler"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// cronManifestPath is generated by the backend from its cron definitions
+// (go generate ./internal/cron), so schedules are declared in one place
+const cronManifestPath = "../../backend/api/cron.json"
+
+// cronJob mirrors an entry of the backend's cron manifest
+type cronJob struct {
+	Name            string `json:"name"`
+	Schedule        string `json:"schedule"`
+	TimeZone        string `json:"timeZone"`
+	Description     string `json:"description"`
+	AttemptDeadline string `json:"attemptDeadline"`
+}
+
+func loadCronJobs() ([]cronJob, error) {
+	data, err := os.ReadFile(cronManifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("read cron manifest: %w", err)
+	}
+
+	var jobs []cronJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("parse cron manifest: %w", err)
+	}
+	return jobs, nil
+}
+
+// newSchedulerJobs creates one Cloud Scheduler job per cron manifest entry,
+// each calling POST /internal/cron/{name} with an OIDC token for the API
+// service account
+func newSchedulerJobs(ctx *pulumi.Context, prefix, projectID, region, serviceURL string,
+	serviceAccountEmail pulumi.StringInput, opts ...pulumi.ResourceOption) error {
+	jobs, err := loadCronJobs()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		_, err := cloudscheduler.NewJob(ctx, fmt.Sprintf("cron-%s", job.Name), &cloudscheduler.JobArgs{
+			Name:            pulumi.String(fmt.Sprintf("%s-%s", prefix, job.Name)),
+			Project:         pulumi.String(projectID),
+			Region:          pulumi.String(region),
+			Description:     pulumi.String(job.Description),
+			Schedule:        pulumi.String(job.Schedule),
+			TimeZone:        pulumi.String(job.TimeZone),
+			AttemptDeadline: pulumi.String(job.AttemptDeadline),
+			// The backend already serialises runs with a lock; a failed run
+			// is simply picked up by the next trigger
+			RetryConfig: &cloudscheduler.JobRetryConfigArgs{
+				RetryCount: pulumi.Int(0),
+			},
+			HttpTarget: &cloudscheduler.JobHttpTargetArgs{
+				HttpMethod: pulumi.String("POST"),
+				Uri:        pulumi.Sprintf("%s/internal/cron/%s", serviceURL, job.Name),
+				OidcToken: &cloudscheduler.JobHttpTargetOidcTokenArgs{
+					ServiceAccountEmail: serviceAccountEmail,
+					Audience:            pulumi.String(serviceURL),
+				},
+			},
+		}, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
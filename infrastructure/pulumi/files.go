@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/pubsub"
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/serviceaccount"
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/storage"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// newFilesBucket creates the bucket behind the backend's files API. Clients
+// upload and download directly with V4 signed URLs, so the bucket stays
+// private and only allows the CORS requests those URLs need.
+func newFilesBucket(ctx *pulumi.Context, name, projectID, region string) (*storage.Bucket, error) {
+	return storage.NewBucket(ctx, "files-bucket", &storage.BucketArgs{
+		Name:                     pulumi.String(name),
+		Project:                  pulumi.String(projectID),
+		Location:                 pulumi.String(region),
+		UniformBucketLevelAccess: pulumi.Bool(true),
+		PublicAccessPrevention:   pulumi.String("enforced"),
+		// Deleted and overwritten files stay recoverable for a week
+		Versioning: &storage.BucketVersioningArgs{
+			Enabled: pulumi.Bool(true),
+		},
+		LifecycleRules: storage.BucketLifecycleRuleArray{
+			&storage.BucketLifecycleRuleArgs{
+				Action: &storage.BucketLifecycleRuleActionArgs{
+					Type: pulumi.String("Delete"),
+				},
+				Condition: &storage.BucketLifecycleRuleConditionArgs{
+					DaysSinceNoncurrentTime: pulumi.Int(7),
+					WithState:               pulumi.String("ARCHIVED"),
+				},
+			},
+			// Account exports expire after ACCOUNT_EXPORT_TTL, at most a week
+			&storage.BucketLifecycleRuleArgs{
+				Action: &storage.BucketLifecycleRuleActionArgs{
+					Type: pulumi.String("Delete"),
+				},
+				Condition: &storage.BucketLifecycleRuleConditionArgs{
+					Age:             pulumi.Int(7),
+					MatchesPrefixes: pulumi.StringArray{pulumi.String("exports/")},
+				},
+			},
+			&storage.BucketLifecycleRuleArgs{
+				Action: &storage.BucketLifecycleRuleActionArgs{
+					Type: pulumi.String("AbortIncompleteMultipartUpload"),
+				},
+				Condition: &storage.BucketLifecycleRuleConditionArgs{
+					Age: pulumi.Int(1),
+				},
+			},
+		},
+		// Signed URLs are the authorization; CORS only lets browsers use them
+		Cors: storage.BucketCorArray{
+			&storage.BucketCorArgs{
+				Origins:         pulumi.StringArray{pulumi.String("*")},
+				Methods:         pulumi.StringArray{pulumi.String("GET"), pulumi.String("PUT")},
+				ResponseHeaders: pulumi.StringArray{pulumi.String("Content-Type"), pulumi.String("x-goog-content-length-range")},
+				MaxAgeSeconds:   pulumi.Int(3600),
+			},
+		},
+	})
+}
+
+// newFilesNotifications publishes finalized objects to a Pub/Sub topic and
+// pushes them to POST /internal/storage/events with an OIDC token for the
+// API service account, so the backend can mark uploads as ready
+func newFilesNotifications(ctx *pulumi.Context, prefix, projectID, projectNumber, serviceURL string,
+	bucket *storage.Bucket, serviceAccount *serviceaccount.Account, opts ...pulumi.ResourceOption) error {
+	topic, err := pubsub.NewTopic(ctx, "files-events", &pubsub.TopicArgs{
+		Name:    pulumi.String(fmt.Sprintf("%s-files-events", prefix)),
+		Project: pulumi.String(projectID),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The Cloud Storage service agent publishes the notifications
+	gcsAgent, err := storage.GetProjectServiceAccount(ctx, &storage.GetProjectServiceAccountArgs{
+		Project: &projectID,
+	})
+	if err != nil {
+		return err
+	}
+	publisher, err := pubsub.NewTopicIAMMember(ctx, "files-events-publisher", &pubsub.TopicIAMMemberArgs{
+		Project: pulumi.String(projectID),
+		Topic:   topic.Name,
+		Role:    pulumi.String("roles/pubsub.publisher"),
+		Member:  pulumi.String(gcsAgent.Member),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = storage.NewNotification(ctx, "files-finalize", &storage.NotificationArgs{
+		Bucket:           bucket.Name,
+		Topic:            topic.ID(),
+		PayloadFormat:    pulumi.String("JSON_API_V1"),
+		EventTypes:       pulumi.StringArray{pulumi.String("OBJECT_FINALIZE")},
+		ObjectNamePrefix: pulumi.String("users/"),
+	}, pulumi.DependsOn([]pulumi.Resource{publisher}))
+	if err != nil {
+		return err
+	}
+
+	// The Pub/Sub service agent signs the push OIDC tokens as the API service account
+	_, err = serviceaccount.NewIAMMember(ctx, "pubsub-token-creator", &serviceaccount.IAMMemberArgs{
+		ServiceAccountId: serviceAccount.Name,
+		Role:             pulumi.String("roles/iam.serviceAccountTokenCreator"),
+		Member:           pulumi.Sprintf("serviceAccount:service-%s@gcp-sa-pubsub.iam.gserviceaccount.com", projectNumber),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = pubsub.NewSubscription(ctx, "files-events-push", &pubsub.SubscriptionArgs{
+		Name:               pulumi.String(fmt.Sprintf("%s-files-events-push", prefix)),
+		Project:            pulumi.String(projectID),
+		Topic:              topic.Name,
+		AckDeadlineSeconds: pulumi.Int(60),
+		PushConfig: &pubsub.SubscriptionPushConfigArgs{
+			PushEndpoint: pulumi.Sprintf("%s/internal/storage/events", serviceURL),
+			OidcToken: &pubsub.SubscriptionPushConfigOidcTokenArgs{
+				ServiceAccountEmail: serviceAccount.Email,
+				Audience:            pulumi.String(serviceURL),
+			},
+		},
+		RetryPolicy: &pubsub.SubscriptionRetryPolicyArgs{
+			MinimumBackoff: pulumi.String("10s"),
+			MaximumBackoff: pulumi.String("600s"),
+		},
+	}, opts...)
+	return err
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/artifactregistry"
 	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/cloudrun"
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/cloudtasks"
 	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/firestore"
+	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/organizations"
 	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/projects"
 	"github.com/pulumi/pulumi-gcp/sdk/v7/go/gcp/serviceaccount"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -49,6 +51,11 @@ func main() {
 			"storage.googleapis.com",
 			"secretmanager.googleapis.com",
 			"iam.googleapis.com",
+			"cloudtasks.googleapis.com",
+			"cloudscheduler.googleapis.com",
+			"pubsub.googleapis.com",
+			"iamcredentials.googleapis.com",
+			"firebaseremoteconfig.googleapis.com",
 		}
 
 		for _, api := range apis {
@@ -106,10 +113,12 @@ func main() {
 
 		// Grant IAM roles to service account
 		roles := []string{
-			"roles/datastore.user",           // Firestore access
-			"roles/storage.objectAdmin",      // Storage access
+			"roles/datastore.user",               // Firestore access
+			"roles/storage.objectAdmin",          // Storage access
 			"roles/secretmanager.secretAccessor", // Secrets access
-			"roles/firebase.admin",           // Firebase Admin
+			"roles/firebase.admin",               // Firebase Admin
+			"roles/cloudtasks.enqueuer",          // Background jobs
+			"roles/monitoring.metricWriter",      // Custom metrics
 		}
 
 		for i, role := range roles {
@@ -123,10 +132,75 @@ func main() {
 			}
 		}
 
+		// Let the service account mint OIDC tokens as itself, which Cloud Tasks
+		// attaches when calling back into /internal/tasks
+		_, err = serviceaccount.NewIAMMember(ctx, "sa-act-as-self", &serviceaccount.IAMMemberArgs{
+			ServiceAccountId: serviceAccount.Name,
+			Role:             pulumi.String("roles/iam.serviceAccountUser"),
+			Member:           pulumi.Sprintf("serviceAccount:%s", serviceAccount.Email),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Let the service account sign blobs as itself, which V4 signed URLs
+		// need on Cloud Run where there is no private key
+		_, err = serviceaccount.NewIAMMember(ctx, "sa-sign-as-self", &serviceaccount.IAMMemberArgs{
+			ServiceAccountId: serviceAccount.Name,
+			Role:             pulumi.String("roles/iam.serviceAccountTokenCreator"),
+			Member:           pulumi.Sprintf("serviceAccount:%s", serviceAccount.Email),
+		})
+		if err != nil {
+			return err
+		}
+
+		// ============================================
+		// Cloud Storage Bucket (files API)
+		// ============================================
+		filesBucket, err := newFilesBucket(ctx, fmt.Sprintf("%s-%s-%s-files", projectID, appName, environment),
+			projectID, region)
+		if err != nil {
+			return err
+		}
+
+		// ============================================
+		// Cloud Tasks Queue (background jobs)
+		// ============================================
+		jobsQueue, err := cloudtasks.NewQueue(ctx, "jobs-queue", &cloudtasks.QueueArgs{
+			Name:     pulumi.String(fmt.Sprintf("%s-%s-jobs", appName, environment)),
+			Location: pulumi.String(region),
+			Project:  pulumi.String(projectID),
+			RateLimits: &cloudtasks.QueueRateLimitsArgs{
+				MaxDispatchesPerSecond:  pulumi.Float64(50),
+				MaxConcurrentDispatches: pulumi.Int(20),
+			},
+			// Upper bound only: each job also carries its own attempt limit
+			RetryConfig: &cloudtasks.QueueRetryConfigArgs{
+				MaxAttempts:      pulumi.Int(10),
+				MinBackoff:       pulumi.String("1s"),
+				MaxBackoff:       pulumi.String("300s"),
+				MaxDoublings:     pulumi.Int(5),
+				MaxRetryDuration: pulumi.String("86400s"),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
 		// ============================================
 		// Cloud Run Service
 		// ============================================
 		serviceName := fmt.Sprintf("%s-api", appName)
+
+		// Cloud Run's deterministic URL, known before the service exists. The
+		// service needs it as the target and OIDC audience of its own tasks.
+		project, err := organizations.LookupProject(ctx, &organizations.LookupProjectArgs{
+			ProjectId: &projectID,
+		})
+		if err != nil {
+			return err
+		}
+		serviceURL := fmt.Sprintf("https://%s-%s.%s.run.app", serviceName, project.Number, region)
 		cloudRunService, err := cloudrun.NewService(ctx, "api-service", &cloudrun.ServiceArgs{
 			Name:     pulumi.String(serviceName),
 			Location: pulumi.String(region),
@@ -134,6 +208,8 @@ func main() {
 			Template: &cloudrun.ServiceTemplateArgs{
 				Spec: &cloudrun.ServiceTemplateSpecArgs{
 					ServiceAccountName: serviceAccount.Email,
+					// Event streams stay open for up to EVENTS_MAX_DURATION (55m)
+					TimeoutSeconds: pulumi.Int(3600),
 					Containers: cloudrun.ServiceTemplateSpecContainerArray{
 						&cloudrun.ServiceTemplateSpecContainerArgs{
 							Image: pulumi.Sprintf("%s-docker.pkg.dev/%s/api/%s:latest",
@@ -152,6 +228,46 @@ func main() {
 									Name:  pulumi.String("GCP_PROJECT"),
 									Value: pulumi.String(projectID),
 								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("GCP_REGION"),
+									Value: pulumi.String(region),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("SERVICE_URL"),
+									Value: pulumi.String(serviceURL),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("STORE_BACKEND"),
+									Value: pulumi.String("firestore"),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("JOBS_BACKEND"),
+									Value: pulumi.String("cloudtasks"),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("TASKS_QUEUE"),
+									Value: jobsQueue.Name,
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("TASKS_SERVICE_ACCOUNT"),
+									Value: serviceAccount.Email,
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("FILES_BACKEND"),
+									Value: pulumi.String("gcs"),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("FILES_BUCKET"),
+									Value: filesBucket.Name,
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("FLAGS_PROVIDER"),
+									Value: pulumi.String("remoteconfig"),
+								},
+								&cloudrun.ServiceTemplateSpecContainerEnvArgs{
+									Name:  pulumi.String("METRICS_EXPORTER"),
+									Value: pulumi.String("gcp"),
+								},
 							},
 							Resources: &cloudrun.ServiceTemplateSpecContainerResourcesArgs{
 								Limits: pulumi.StringMap{
@@ -186,6 +302,24 @@ func main() {
 			return err
 		}
 
+		// ============================================
+		// Cloud Scheduler (one job per backend cron definition)
+		// ============================================
+		err = newSchedulerJobs(ctx, fmt.Sprintf("%s-%s", appName, environment), projectID, region,
+			serviceURL, serviceAccount.Email, pulumi.DependsOn([]pulumi.Resource{cloudRunService}))
+		if err != nil {
+			return err
+		}
+
+		// ============================================
+		// Storage notifications (marks uploaded files ready)
+		// ============================================
+		err = newFilesNotifications(ctx, fmt.Sprintf("%s-%s", appName, environment), projectID, project.Number,
+			serviceURL, filesBucket, serviceAccount, pulumi.DependsOn([]pulumi.Resource{cloudRunService}))
+		if err != nil {
+			return err
+		}
+
 		// ============================================
 		// Outputs
 		// ============================================
@@ -197,6 +331,8 @@ func main() {
 		}).(pulumi.StringOutput))
 		ctx.Export("serviceAccountEmail", serviceAccount.Email)
 		ctx.Export("cloudRunUrl", cloudRunService.Statuses.Index(pulumi.Int(0)).Url())
+		ctx.Export("jobsQueue", jobsQueue.Name)
+		ctx.Export("filesBucket", filesBucket.Name)
 
 		return nil
 	})
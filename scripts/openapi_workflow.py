@@ -18,7 +18,6 @@ Usage:
 import argparse
 import subprocess
 import sys
-import os
 from pathlib import Path
 from typing import List, Tuple
 
@@ -122,34 +121,13 @@ def validate_openapi_spec() -> bool:
 
 
 def generate_backend_code() -> bool:
-    """Step 2a: Generate Go backend code using oapi-codegen"""
+    """Step 2a: Generate the Go client using oapi-codegen"""
     print_step("Step 2a: Generating Backend Code (Go)")
 
-    # Check for oapi-codegen
-    gopath = os.environ.get('GOPATH', os.path.expanduser('~/go'))
-    oapi_codegen = Path(gopath) / "bin" / "oapi-codegen"
-
-    if not oapi_codegen.exists():
-        print_warning(f"oapi-codegen not found at {oapi_codegen}")
-        print_warning("Install with: go install github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@latest")
-        return True
-
-    # Check for config file
-    config_file = BACKEND_DIR / "api" / "oapi-codegen.yaml"
-    if not config_file.exists():
-        print_warning(f"oapi-codegen config not found: {config_file}")
-        print_warning("Skipping backend code generation...")
-        return True
-
-    # Generate code
-    returncode, stdout, stderr = run_command(
-        [str(oapi_codegen), "-config", str(config_file), str(OPENAPI_SPEC)],
-        cwd=BACKEND_DIR,
-        check=False
-    )
-
+    # The Go client pins its generator version, so it needs no install
+    returncode, stdout, stderr = run_command(["go", "generate", "./client"], cwd=BACKEND_DIR, check=False)
     if returncode != 0:
-        print_error("Backend code generation failed!")
+        print_error("Go client generation failed!")
         print(stderr)
         return False
 